@@ -0,0 +1,151 @@
+package tgbotapi
+
+import "errors"
+
+// MaxAlbumSize is the most items Telegram allows in one media group.
+const MaxAlbumSize = 10
+
+// AlbumConfig is an album of any number of items, sent by SendAlbum
+// in as many media groups as needed.
+type AlbumConfig struct {
+	BaseChat
+	// Media are InputMediaPhoto and InputMediaVideo, InputMediaAudio, or
+	// InputMediaDocument items.
+	Media []interface{}
+	// Caption is the album's caption, if set. It is put on the first item
+	// of photo and video albums, and the last item of audio and document
+	// albums, where Telegram shows it.
+	Caption   string
+	ParseMode string
+}
+
+// NewAlbum creates a new album to send to a chat.
+func NewAlbum(chatID int64, media ...interface{}) AlbumConfig {
+	return AlbumConfig{
+		BaseChat: BaseChat{ChatID: chatID},
+		Media:    media,
+	}
+}
+
+// SendAlbum sends an album, split into media groups of at most
+// MaxAlbumSize items in order. Groups are kept about the same size, as
+// Telegram needs at least two items in each.
+//
+// Only the first group replies to ReplyToMessageID or ReplyParameters.
+// If a group fails to send, the messages already sent are returned along
+// with the error.
+func (bot *BotAPI) SendAlbum(config AlbumConfig) ([]Message, error) {
+	if err := validateAlbum(config.Media); err != nil {
+		return nil, err
+	}
+
+	media := make([]interface{}, len(config.Media))
+	copy(media, config.Media)
+
+	if config.Caption != "" {
+		i := 0
+		if !isVisualMedia(media[0]) {
+			i = len(media) - 1
+		}
+		media[i] = setMediaCaption(media[i], config.Caption, config.ParseMode)
+	}
+
+	var messages []Message
+	for i, group := range chunkAlbum(media) {
+		mediaGroup := MediaGroupConfig{
+			BaseChat: config.BaseChat,
+			Media:    group,
+		}
+		if i > 0 {
+			mediaGroup.ReplyToMessageID = 0
+			mediaGroup.ReplyParameters = nil
+		}
+
+		sent, err := bot.SendMediaGroup(mediaGroup)
+		messages = append(messages, sent...)
+		if err != nil {
+			return messages, err
+		}
+	}
+
+	return messages, nil
+}
+
+// validateAlbum checks an album's items may be sent together.
+func validateAlbum(media []interface{}) error {
+	if len(media) < 2 {
+		return errors.New(ErrAlbumTooSmall)
+	}
+
+	var kind string
+	for _, m := range media {
+		var k string
+		switch m.(type) {
+		case InputMediaPhoto, InputMediaVideo:
+			k = "visual"
+		case InputMediaAudio:
+			k = "audio"
+		case InputMediaDocument:
+			k = "document"
+		default:
+			return errors.New(ErrBadAlbumMedia)
+		}
+
+		if kind != "" && k != kind {
+			return errors.New(ErrAlbumMixedMedia)
+		}
+		kind = k
+	}
+
+	return nil
+}
+
+// isVisualMedia returns if an album item is a photo or video.
+func isVisualMedia(media interface{}) bool {
+	switch media.(type) {
+	case InputMediaPhoto, InputMediaVideo:
+		return true
+	}
+
+	return false
+}
+
+// setMediaCaption returns an album item with its caption replaced.
+func setMediaCaption(media interface{}, caption, parseMode string) interface{} {
+	switch m := media.(type) {
+	case InputMediaPhoto:
+		m.Caption, m.ParseMode = caption, parseMode
+		return m
+	case InputMediaVideo:
+		m.Caption, m.ParseMode = caption, parseMode
+		return m
+	case InputMediaAudio:
+		m.Caption, m.ParseMode = caption, parseMode
+		return m
+	case InputMediaDocument:
+		m.Caption, m.ParseMode = caption, parseMode
+		return m
+	}
+
+	return media
+}
+
+// chunkAlbum splits an album into the fewest media groups it can be sent
+// as, with the earlier groups taking any extra items.
+func chunkAlbum(media []interface{}) [][]interface{} {
+	groups := (len(media) + MaxAlbumSize - 1) / MaxAlbumSize
+	size, extra := len(media)/groups, len(media)%groups
+
+	chunks := make([][]interface{}, 0, groups)
+	for i := 0; i < groups; i++ {
+		n := size
+		if i < extra {
+			n++
+		}
+
+		chunks = append(chunks, media[:n])
+		media = media[n:]
+	}
+
+	return chunks
+}
@@ -0,0 +1,138 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func albumPhotos(n int) []interface{} {
+	media := make([]interface{}, n)
+	for i := range media {
+		media[i] = tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("photo-" + strconv.Itoa(i)))
+	}
+
+	return media
+}
+
+func TestSendAlbumChunks(t *testing.T) {
+	var groups [][]map[string]interface{}
+	var replies []string
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendMediaGroup" {
+			return nil
+		}
+
+		var media []map[string]interface{}
+		if err := json.Unmarshal([]byte(r.FormValue("media")), &media); err != nil {
+			t.Fatal(err)
+		}
+		groups = append(groups, media)
+		replies = append(replies, r.FormValue("reply_to_message_id"))
+
+		messages := make([]tgbotapi.Message, len(media))
+		for i := range messages {
+			messages[i].MessageID = len(groups)*100 + i
+		}
+		return messages
+	})
+
+	album := tgbotapi.NewAlbum(ChatID, albumPhotos(23)...)
+	album.Caption = "Holiday"
+	album.ReplyToMessageID = 5
+
+	messages, err := bot.SendAlbum(album)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 23 {
+		t.Errorf("expected 23 messages, got %d", len(messages))
+	}
+	if len(groups) != 3 || len(groups[0]) != 8 || len(groups[1]) != 8 || len(groups[2]) != 7 {
+		t.Fatalf("expected groups of 8, 8 and 7, got %d groups", len(groups))
+	}
+
+	n := 0
+	for _, group := range groups {
+		for _, item := range group {
+			if item["media"] != "photo-"+strconv.Itoa(n) {
+				t.Errorf("expected photo-%d, got %v", n, item["media"])
+			}
+			if caption, _ := item["caption"].(string); (n == 0) != (caption == "Holiday") {
+				t.Errorf("item %d has caption %q", n, caption)
+			}
+			n++
+		}
+	}
+
+	if replies[0] != "5" || replies[1] != "" || replies[2] != "" {
+		t.Errorf("expected only the first group to reply, got %q", replies)
+	}
+}
+
+func TestSendAlbumDocumentCaption(t *testing.T) {
+	var media []map[string]interface{}
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendMediaGroup" {
+			return nil
+		}
+
+		json.Unmarshal([]byte(r.FormValue("media")), &media)
+		return []tgbotapi.Message{{}, {}}
+	})
+
+	album := tgbotapi.NewAlbum(ChatID,
+		tgbotapi.NewInputMediaDocument(tgbotapi.FileID("a")),
+		tgbotapi.NewInputMediaDocument(tgbotapi.FileID("b")),
+	)
+	album.Caption = "Reports"
+
+	if _, err := bot.SendAlbum(album); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(media) != 2 || media[0]["caption"] != nil || media[1]["caption"] != "Reports" {
+		t.Errorf("expected the caption on the last document, got %v", media)
+	}
+}
+
+func TestSendAlbumValidation(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMediaGroup" {
+			t.Error("invalid album was sent")
+		}
+		return nil
+	})
+
+	photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("photo"))
+	video := tgbotapi.NewInputMediaVideo(tgbotapi.FileID("video"))
+	audio := tgbotapi.NewInputMediaAudio(tgbotapi.FileID("audio"))
+	document := tgbotapi.NewInputMediaDocument(tgbotapi.FileID("document"))
+
+	tests := []struct {
+		name  string
+		media []interface{}
+		err   string
+	}{
+		{"Single", []interface{}{photo}, tgbotapi.ErrAlbumTooSmall},
+		{"AudioWithPhoto", []interface{}{photo, audio}, tgbotapi.ErrAlbumMixedMedia},
+		{"DocumentWithAudio", []interface{}{document, audio}, tgbotapi.ErrAlbumMixedMedia},
+		{"DocumentWithVideo", []interface{}{video, document}, tgbotapi.ErrAlbumMixedMedia},
+		{"NotMedia", []interface{}{photo, "photo"}, tgbotapi.ErrBadAlbumMedia},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := bot.SendAlbum(tgbotapi.NewAlbum(ChatID, test.media...))
+			if err == nil || err.Error() != test.err {
+				t.Errorf("expected %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// AskConfig contains information about asking a question with Ask.
+type AskConfig struct {
+	MessageConfig
+	// UserID is the user whose answer is waited for. If it is zero, the
+	// next message in the chat is the answer, which is enough for private
+	// chats.
+	UserID int64
+	// ForceReply shows the reply interface to the user, replacing any
+	// ReplyMarkup.
+	ForceReply bool
+	// Timeout is how long to wait for an answer, if more than zero.
+	Timeout time.Duration
+}
+
+// NewAsk creates a question to ask in a chat with Ask.
+func NewAsk(chatID int64, question string) AskConfig {
+	return AskConfig{
+		MessageConfig: NewMessage(chatID, question),
+	}
+}
+
+// answerWaiter is an Ask waiting for an answer.
+type answerWaiter struct {
+	chatID int64
+	userID int64
+	answer chan Message
+}
+
+// Ask sends a question and waits for the answer, which is the next
+// message from the user in the same chat.
+//
+// Answers are taken from updates received by GetUpdatesChan or a webhook,
+// so one of them must be running, and answers are not sent to their
+// UpdatesChannel. If ctx is done or Timeout passes first, its error is
+// returned.
+func (bot *BotAPI) Ask(ctx context.Context, config AskConfig) (Message, error) {
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	if config.ForceReply {
+		config.ReplyMarkup = ForceReply{ForceReply: true, Selective: config.UserID != 0}
+	}
+
+	// Wait before sending, so a quick answer is not missed.
+	waiter := &answerWaiter{
+		chatID: config.ChatID,
+		userID: config.UserID,
+		answer: make(chan Message, 1),
+	}
+	bot.waitForAnswer(waiter)
+	defer bot.stopWaiting(waiter)
+
+	if _, err := bot.SendContext(ctx, config.MessageConfig); err != nil {
+		return Message{}, err
+	}
+
+	select {
+	case answer := <-waiter.answer:
+		return answer, nil
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+}
+
+func (bot *BotAPI) waitForAnswer(waiter *answerWaiter) {
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	bot.waiters = append(bot.waiters, waiter)
+}
+
+func (bot *BotAPI) stopWaiting(waiter *answerWaiter) {
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	for i, w := range bot.waiters {
+		if w == waiter {
+			bot.waiters = append(bot.waiters[:i], bot.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// hasWaiters reports if any Ask or SendAndWaitCallback is waiting for
+// an answer.
+func (bot *BotAPI) hasWaiters() bool {
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	return len(bot.waiters) > 0 || len(bot.callbackWaiters) > 0
+}
+
+// answer gives an update to the first Ask or SendAndWaitCallback waiting
+// for it, returning true if it was an answer.
+func (bot *BotAPI) answer(update *Update) bool {
+	if update.CallbackQuery != nil {
+		return bot.answerCallback(update.CallbackQuery)
+	}
+
+	message := update.Message
+	if message == nil || message.Chat == nil {
+		return false
+	}
+
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	for i, w := range bot.waiters {
+		if w.chatID != message.Chat.ID {
+			continue
+		}
+		if w.userID != 0 && (message.From == nil || message.From.ID != w.userID) {
+			continue
+		}
+
+		bot.waiters = append(bot.waiters[:i], bot.waiters[i+1:]...)
+		w.answer <- *message
+
+		return true
+	}
+
+	return false
+}
+
+// DefaultCallbackTimeout is how long SendAndWaitCallback waits for a
+// button to be pressed if ctx has no deadline.
+const DefaultCallbackTimeout = 10 * time.Minute
+
+// callbackWaiter is a SendAndWaitCallback waiting for a button of a
+// message to be pressed.
+type callbackWaiter struct {
+	chatID    int64
+	messageID int
+	answer    chan CallbackQuery
+}
+
+// SendAndWaitCallback sends a message with an inline keyboard and waits
+// for one of its buttons to be pressed, by anyone who can see it. The
+// callback query is answered before it is returned, so the button stops
+// showing as loading; answer it again with text to show a notification.
+//
+// As with Ask, callback queries are taken from updates received by
+// GetUpdatesChan or a webhook, and are not sent to their UpdatesChannel.
+// If ctx is done first, or DefaultCallbackTimeout passes when it has no
+// deadline, its error is returned.
+func (bot *BotAPI) SendAndWaitCallback(ctx context.Context, config MessageConfig) (CallbackQuery, error) {
+	switch config.ReplyMarkup.(type) {
+	case InlineKeyboardMarkup, *InlineKeyboardMarkup:
+	default:
+		return CallbackQuery{}, errors.New(ErrNoInlineKeyboard)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultCallbackTimeout)
+		defer cancel()
+	}
+
+	message, err := bot.SendContext(ctx, config)
+	if err != nil {
+		return CallbackQuery{}, err
+	}
+
+	// The message's ID is needed to recognise its buttons being pressed,
+	// so waiting only starts once it is sent.
+	waiter := &callbackWaiter{
+		chatID:    message.Chat.ID,
+		messageID: message.MessageID,
+		answer:    make(chan CallbackQuery, 1),
+	}
+	bot.waitForCallback(waiter)
+	defer bot.stopWaitingForCallback(waiter)
+
+	select {
+	case query := <-waiter.answer:
+		if _, err := bot.AnswerCallbackQuery(CallbackConfig{CallbackQueryID: query.ID}); err != nil {
+			log.Println(err)
+		}
+		return query, nil
+	case <-ctx.Done():
+		return CallbackQuery{}, ctx.Err()
+	}
+}
+
+func (bot *BotAPI) waitForCallback(waiter *callbackWaiter) {
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	bot.callbackWaiters = append(bot.callbackWaiters, waiter)
+}
+
+func (bot *BotAPI) stopWaitingForCallback(waiter *callbackWaiter) {
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	for i, w := range bot.callbackWaiters {
+		if w == waiter {
+			bot.callbackWaiters = append(bot.callbackWaiters[:i], bot.callbackWaiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// answerCallback gives a callback query to the SendAndWaitCallback
+// waiting for a button of its message to be pressed, returning true if
+// there was one.
+func (bot *BotAPI) answerCallback(query *CallbackQuery) bool {
+	message := query.Message
+	if message == nil || message.Chat == nil {
+		return false
+	}
+
+	bot.waitersMu.Lock()
+	defer bot.waitersMu.Unlock()
+
+	for i, w := range bot.callbackWaiters {
+		if w.chatID != message.Chat.ID || w.messageID != message.MessageID {
+			continue
+		}
+
+		bot.callbackWaiters = append(bot.callbackWaiters[:i], bot.callbackWaiters[i+1:]...)
+		w.answer <- *query
+
+		return true
+	}
+
+	return false
+}
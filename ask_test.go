@@ -0,0 +1,147 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestAsk(t *testing.T) {
+	sent := make(chan string, 1)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			sent <- r.FormValue("reply_markup")
+		}
+		return nil
+	})
+
+	handler, updates := bot.WebhookHandler()
+	post := func(update string) {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(update))
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	config := tgbotapi.NewAsk(ChatID, "What is your name?")
+	config.UserID = 2
+	config.ForceReply = true
+
+	type result struct {
+		answer tgbotapi.Message
+		err    error
+	}
+	done := make(chan result)
+	go func() {
+		answer, err := bot.Ask(context.Background(), config)
+		done <- result{answer, err}
+	}()
+
+	if markup := <-sent; !strings.Contains(markup, `"force_reply":true`) {
+		t.Errorf("got reply_markup %s", markup)
+	}
+
+	post(`{"update_id":1,"message":{"message_id":1,"text":"not me","chat":{"id":76918703},"from":{"id":3}}}`)
+	post(`{"update_id":2,"message":{"message_id":2,"text":"Alice","chat":{"id":76918703},"from":{"id":2}}}`)
+
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if res.answer.Text != "Alice" {
+		t.Errorf("got answer %q", res.answer.Text)
+	}
+
+	if update := <-updates; update.UpdateID != 1 {
+		t.Errorf("expected update 1 to be passed on, got %d", update.UpdateID)
+	}
+	select {
+	case update := <-updates:
+		t.Errorf("answer was passed on as update %d", update.UpdateID)
+	default:
+	}
+}
+
+func TestAskTimeout(t *testing.T) {
+	bot := newTestBot(t, func(string, *http.Request) interface{} { return nil })
+
+	config := tgbotapi.NewAsk(ChatID, "Anyone?")
+	config.Timeout = 10 * time.Millisecond
+
+	if _, err := bot.Ask(context.Background(), config); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestSendAndWaitCallback(t *testing.T) {
+	answered := make(chan string, 1)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "sendMessage":
+			return tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: ChatID}}
+		case "answerCallbackQuery":
+			answered <- r.FormValue("callback_query_id")
+			return true
+		}
+		return nil
+	})
+
+	if _, err := bot.SendAndWaitCallback(context.Background(), tgbotapi.NewMessage(ChatID, "No keyboard")); err == nil || err.Error() != tgbotapi.ErrNoInlineKeyboard {
+		t.Errorf("expected an error for a message without a keyboard, got %v", err)
+	}
+
+	handler, updates := bot.WebhookHandler()
+	post := func(update string) {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(update))
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	config := tgbotapi.NewMessage(ChatID, "Delete everything?")
+	config.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Yes", "yes"),
+		tgbotapi.NewInlineKeyboardButtonData("No", "no"),
+	))
+
+	type result struct {
+		query tgbotapi.CallbackQuery
+		err   error
+	}
+	done := make(chan result)
+	go func() {
+		query, err := bot.SendAndWaitCallback(context.Background(), config)
+		done <- result{query, err}
+	}()
+
+	// Wait for the message to be sent, then press a button of another
+	// message and one of it.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		post(`{"update_id":1,"callback_query":{"id":"other","data":"yes","message":{"message_id":4,"chat":{"id":76918703}}}}`)
+		if update := <-updates; update.CallbackQuery == nil {
+			t.Fatal("expected the other callback query to be passed on")
+		}
+
+		post(`{"update_id":2,"callback_query":{"id":"mine","data":"no","message":{"message_id":5,"chat":{"id":76918703}}}}`)
+		select {
+		case <-updates:
+			time.Sleep(10 * time.Millisecond)
+			continue
+		case res := <-done:
+			if res.err != nil {
+				t.Fatal(res.err)
+			}
+			if res.query.Data != "no" {
+				t.Errorf("got callback data %q", res.query.Data)
+			}
+			if id := <-answered; id != "mine" {
+				t.Errorf("expected the callback query answered, got %q", id)
+			}
+			return
+		}
+	}
+
+	t.Fatal("button press was not waited for")
+}
@@ -4,98 +4,576 @@ package tgbotapi
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/fu-tyan/multipartstreamer"
+	"io"
 	"io/ioutil"
 	"log"
+	"math/rand"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // BotAPI allows you to interact with the Telegram Bot API.
 type BotAPI struct {
-	Token  string `json:"token"`
-	Debug  bool   `json:"debug"`
-	Buffer int    `json:"buffer"`
+	Token  Token `json:"token"`
+	Debug  bool  `json:"debug"`
+	Buffer int   `json:"buffer"`
 
 	Self   User         `json:"-"`
 	Client *http.Client `json:"-"`
+
+	// UserAgent, if set, is sent as the User-Agent of every request to
+	// the API, including downloads.
+	UserAgent string `json:"-"`
+	// Header is added to every request to the API, such as to identify
+	// the bot to a proxy or authenticate with a self-hosted Bot API
+	// server.
+	Header http.Header `json:"-"`
+
+	// SelfRefreshInterval is how long the bot returned by Me is cached
+	// before it is fetched again. If it is zero, it is never refreshed.
+	SelfRefreshInterval time.Duration `json:"-"`
+
+	// RetryMigratedChats makes requests to a group which was migrated to
+	// a supergroup be retried with the supergroup's ID.
+	RetryMigratedChats bool `json:"-"`
+	// OnChatMigrated, if set, is called when a request fails because a
+	// group was migrated to a supergroup, so stored IDs can be updated.
+	OnChatMigrated func(fromChatID, toChatID int64) `json:"-"`
+	// OnUndeliverable, if set, is called when a request fails because
+	// messages can no longer be delivered to a chat, such as when a user
+	// blocked the bot, so it can be removed from lists of subscribers.
+	OnUndeliverable func(chatID int64, reason DeliveryFailure) `json:"-"`
+	// DryRun logs requests instead of making them, returning results
+	// like Telegram's, such as a Message for those that send one, so a
+	// bot can be tried out without messaging anyone. Requests to methods
+	// which only get something, such as getUpdates, are still made.
+	DryRun bool `json:"-"`
+	// OnUnknownFields, if set, is called with each update received which
+	// has fields this library doesn't support, as returned by
+	// UnknownFields, so new additions to the API are noticed instead of
+	// being silently dropped. It is called before the update is handled.
+	OnUnknownFields func(update *Update, fields []string) `json:"-"`
+
+	// UploadCache, if set, remembers the file_id of each uploaded file by
+	// a hash of its contents, so sending the same file again reuses it
+	// instead of uploading it again.
+	UploadCache KeyValueStore `json:"-"`
+
+	// LocalFiles makes OpenFile read files directly from disk when their
+	// path is absolute, as it is with a local Bot API server started with
+	// --local, instead of downloading them.
+	LocalFiles bool `json:"-"`
+
+	// UploadLimits, if set, makes Send check files with ValidateUpload
+	// before uploading them, such as DefaultUploadLimits.
+	UploadLimits *UploadLimits `json:"-"`
+
+	// DownloadLimits, if set, limits how long downloads by OpenFile,
+	// DownloadFile and DownloadFileParallel may take and how large the
+	// files may be.
+	DownloadLimits *DownloadLimits `json:"-"`
+
+	// FileLinkCache, if set, keeps the file paths returned by getFile so
+	// GetFileDirectURL only calls it again once a link is about to
+	// expire. Only paths are stored, never the token.
+	FileLinkCache KeyValueStore `json:"-"`
+
+	// IgnoreMessageNotModified makes Send treat editing a message without
+	// changing it as a success, returning the edited message's chat and
+	// ID. Otherwise, IsMessageNotModified reports these errors.
+	IgnoreMessageNotModified bool `json:"-"`
+
+	// OnResponse, if set, is called with every response from the API
+	// before it is decoded.
+	OnResponse func(resp RawResponse) `json:"-"`
+	// OnRequestTiming, if set, is called after every request to the API
+	// with how long each part of it took.
+	OnRequestTiming func(timing RequestTiming) `json:"-"`
+
+	// DefaultParseMode, if set, is used by requests that accept a parse
+	// mode but don't set one or any entities. Messages with a ParseMode
+	// of ModeNone are sent without it.
+	DefaultParseMode string `json:"-"`
+	// DefaultDisableNotification makes requests that send a message send
+	// it silently. As DisableNotification is false unless set, it can't be
+	// turned back on for a single request.
+	DefaultDisableNotification bool `json:"-"`
+
+	// ChatMemberCache, if set, keeps the members fetched by IsChatAdmin
+	// and MemberHasPermission for ChatMemberCacheTTL, or
+	// DefaultChatMemberCacheTTL if it is zero, so checking the same user
+	// again does not call getChatMember.
+	ChatMemberCache    KeyValueStore `json:"-"`
+	ChatMemberCacheTTL time.Duration `json:"-"`
+
+	// InvoiceStore, if set, keeps a SentInvoice for each invoice sent
+	// for InvoiceStoreTTL, or DefaultInvoiceStoreTTL if it is zero, so a
+	// SuccessfulPayment can be matched with it by InvoiceForPayment.
+	InvoiceStore    KeyValueStore `json:"-"`
+	InvoiceStoreTTL time.Duration `json:"-"`
+
+	// WebhookSecretToken, if set, makes webhook handlers refuse requests
+	// without it, which Telegram sends when it is the SecretToken of the
+	// WebhookConfig.
+	WebhookSecretToken string `json:"-"`
+
+	// RateLimiter, if set, delays requests which send messages to stay
+	// within Telegram's limits.
+	RateLimiter *RateLimiter `json:"-"`
+	// JobStore, if set, keeps when each job added with Every should next
+	// run, so jobs missed while the bot was stopped run when it starts.
+	JobStore KeyValueStore `json:"-"`
+
+	selfMu      sync.RWMutex
+	selfFetched time.Time
+
+	waitersMu       sync.Mutex
+	waiters         []*answerWaiter
+	callbackWaiters []*callbackWaiter
+
+	callbacksMu sync.Mutex
+	callbacks   map[string]bool
+
+	jobsMu sync.Mutex
+	jobs   []*Job
 }
 
 // NewBotAPI creates a new BotAPI instance.
 //
 // It requires a token, provided by @BotFather on Telegram.
-func NewBotAPI(token string) (*BotAPI, error) {
-	return NewBotAPIWithClient(token, &http.Client{})
+// Its client uses a transport from NewTransport with
+// DefaultTransportConfig.
+func NewBotAPI(token string, options ...BotOption) (*BotAPI, error) {
+	return NewBotAPIWithClient(token, NewHTTPClient(DefaultTransportConfig), options...)
 }
 
 // NewBotAPIWithClient creates a new BotAPI instance
 // and allows you to pass a http.Client.
 //
 // It requires a token, provided by @BotFather on Telegram.
-func NewBotAPIWithClient(token string, client *http.Client) (*BotAPI, error) {
+// Any options, such as WithDefaultParseMode, are applied before the
+// bot's information is fetched.
+func NewBotAPIWithClient(token string, client *http.Client, options ...BotOption) (*BotAPI, error) {
 	bot := &BotAPI{
-		Token:  token,
+		Token:  Token(token),
 		Client: client,
 		Buffer: 100,
 	}
 
-	self, err := bot.GetMe()
+	for _, option := range options {
+		option(bot)
+	}
+
+	_, err := bot.RefreshSelf()
 	if err != nil {
 		return nil, err
 	}
 
-	bot.Self = self
-
 	return bot, nil
 }
 
 // MakeRequest makes a request to a specific endpoint with our token.
 func (bot *BotAPI) MakeRequest(endpoint string, params url.Values) (APIResponse, error) {
-	method := fmt.Sprintf(APIEndpoint, bot.Token, endpoint)
+	return bot.makeRequestContext(context.Background(), endpoint, params)
+}
+
+// CallMethod calls any method of the API, such as one not yet supported
+// by this library, decoding its result into result unless it is nil.
+// Files which need uploading are sent as multipart form data, as with
+// UploadFiles, and the request is otherwise made like any other, using
+// the bot's defaults, RateLimiter and hooks. A result which can't be
+// decoded into result is a *DecodeError.
+//
+// The request is aborted if the context is done.
+func (bot *BotAPI) CallMethod(ctx context.Context, name string, params Params, files []RequestFile, result interface{}) error {
+	p := make(map[string]string, len(params))
+	for key, value := range params {
+		p[key] = value
+	}
+
+	_, err := bot.uploadFilesInto(ctx, name, p, files, result)
+
+	return err
+}
 
-	resp, err := bot.Client.PostForm(method, params)
+// makeRequestContext makes a request to a specific endpoint with our token,
+// aborting it if the context is done.
+func (bot *BotAPI) makeRequestContext(ctx context.Context, endpoint string, params url.Values) (APIResponse, error) {
+	return bot.makeRequestInto(ctx, endpoint, params, nil)
+}
+
+// makeRequestInto is makeRequestContext, decoding the result of a
+// successful request into result if it is not nil instead of keeping it
+// in the response.
+func (bot *BotAPI) makeRequestInto(ctx context.Context, endpoint string, params url.Values, result interface{}) (APIResponse, error) {
+	params = bot.withDefaultValues(endpoint, params)
+
+	if bot.isDryRun(endpoint) {
+		return bot.dryRun(endpoint, params, nil, result)
+	}
+
+	resp, err := bot.request(ctx, endpoint, params, result)
+
+	if toChatID, ok := bot.chatMigrated(err, params.Get("chat_id")); ok && bot.RetryMigratedChats {
+		retry := make(url.Values, len(params))
+		for k, v := range params {
+			retry[k] = v
+		}
+		retry.Set("chat_id", strconv.FormatInt(toChatID, 10))
+
+		return bot.request(ctx, endpoint, retry, result)
+	}
 	if err != nil {
-		return APIResponse{}, err
+		bot.chatUndeliverable(err, params.Get("chat_id"))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusForbidden {
-		return APIResponse{}, errors.New(ErrAPIForbidden)
+	return resp, err
+}
+
+// chatMigrated checks if err happened because the chat was a group that
+// has been migrated to a supergroup, calling OnChatMigrated if so.
+//
+// It returns the ID of the supergroup.
+func (bot *BotAPI) chatMigrated(err error, chatID string) (int64, bool) {
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.MigrateToChatID == 0 {
+		return 0, false
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return APIResponse{}, errors.New(http.StatusText(resp.StatusCode))
+	if bot.OnChatMigrated != nil {
+		fromChatID, _ := strconv.ParseInt(chatID, 10, 64)
+		bot.OnChatMigrated(fromChatID, apiErr.MigrateToChatID)
+	}
+
+	return apiErr.MigrateToChatID, true
+}
+
+// request makes a single request to a specific endpoint with our token,
+// decoding its result into result if it is not nil.
+func (bot *BotAPI) request(ctx context.Context, endpoint string, params url.Values, result interface{}) (APIResponse, error) {
+	if err := bot.rateLimit(ctx, endpoint, params.Get("chat_id")); err != nil {
+		return APIResponse{}, err
 	}
 
-	bytes, err := ioutil.ReadAll(resp.Body)
+	// A bytes.Reader lets the transport replay the body, to retry on a
+	// stale connection or follow a redirect.
+	req, err := http.NewRequest("POST", bot.endpointURL(endpoint), bytes.NewReader(encodeForm(params)))
 	if err != nil {
+		return APIResponse{}, bot.redactError(err)
+	}
+	bot.setHeaders(req)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	req, done := bot.traceRequest(endpoint, req.WithContext(ctx))
+
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		err = bot.redactError(err)
+		done(err)
 		return APIResponse{}, err
 	}
+	defer resp.Body.Close()
 
-	if bot.Debug {
-		log.Println(endpoint, string(bytes))
+	apiResp, err := bot.decodeResponse(endpoint, func() url.Values { return params }, resp, result)
+	done(err)
+	if err != nil {
+		return APIResponse{}, statusError(resp, err)
 	}
 
-	var apiResp APIResponse
-	json.Unmarshal(bytes, &apiResp)
+	if resp.StatusCode != http.StatusOK && apiResp.Description == "" {
+		return APIResponse{}, statusError(resp, nil)
+	}
 
 	if !apiResp.Ok {
-		return apiResp, errors.New(apiResp.Description)
+		err := newError(apiResp)
+		bot.rateLimited(err, params.Get("chat_id"))
+
+		return apiResp, err
+	}
+
+	return apiResp, nil
+}
+
+// decodingResponse is an APIResponse whose result is decoded straight
+// into a value.
+type decodingResponse struct {
+	Ok          bool                `json:"ok"`
+	Result      interface{}         `json:"result"`
+	ErrorCode   int                 `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  *ResponseParameters `json:"parameters"`
+}
+
+// DecodeError happens when a response from the API can't be decoded,
+// such as one which isn't JSON, or a result which doesn't fit the value
+// it is decoded into.
+type DecodeError struct {
+	Method string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s response: %v", e.Method, e.Err)
+}
+
+// Unwrap returns the error from decoding.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// statusError returns an error for an unsuccessful response's status in
+// place of err if it couldn't be decoded, as an error page from a proxy
+// isn't JSON, and otherwise err.
+func statusError(resp *http.Response, err error) error {
+	if resp.StatusCode == http.StatusOK {
+		return err
+	}
+	if _, ok := err.(*DecodeError); err != nil && !ok {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return errors.New(ErrAPIForbidden)
+	}
+
+	return errors.New(http.StatusText(resp.StatusCode))
+}
+
+// decodeResponse decodes the body of a response from the API, decoding
+// its result into result if it is not nil.
+//
+// The body is decoded as it is read, unless Debug or OnResponse need all
+// of it. An error decoding it is a *DecodeError, so it can be told apart
+// from an error reading it.
+func (bot *BotAPI) decodeResponse(endpoint string, params func() url.Values, resp *http.Response, result interface{}) (APIResponse, error) {
+	var apiResp APIResponse
+	var into *decodingResponse
+
+	decode := func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&apiResp)
+	}
+	if streamer, ok := result.(resultStreamer); ok {
+		decode = func(r io.Reader) error {
+			return streamResponse(json.NewDecoder(r), &apiResp, streamer)
+		}
+	} else if result != nil {
+		into = &decodingResponse{Result: result}
+		decode = func(r io.Reader) error {
+			return json.NewDecoder(r).Decode(into)
+		}
+	}
+
+	var decodeErr error
+	if bot.Debug || bot.OnResponse != nil {
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return APIResponse{}, err
+		}
+
+		if bot.Debug {
+			log.Println(endpoint, string(data))
+		}
+
+		if bot.OnResponse != nil {
+			bot.observeResponse(endpoint, params(), resp, data)
+		}
+
+		decodeErr = decode(bytes.NewReader(data))
+	} else {
+		body := &readErrorRecorder{r: resp.Body}
+		decodeErr = decode(body)
+		if body.err != nil {
+			return APIResponse{}, body.err
+		}
+
+		// Read anything left so the connection can be reused.
+		io.Copy(ioutil.Discard, resp.Body)
+	}
+
+	if into != nil {
+		apiResp = APIResponse{
+			Ok:          into.Ok,
+			ErrorCode:   into.ErrorCode,
+			Description: into.Description,
+			Parameters:  into.Parameters,
+		}
+	}
+
+	if decodeErr != nil {
+		return apiResp, &DecodeError{Method: endpoint, Err: decodeErr}
 	}
 
 	return apiResp, nil
 }
 
+// resultStreamer is a result which is decoded from a response as it is
+// read, rather than all at once, such as the elements of an array.
+type resultStreamer interface {
+	streamResult(dec *json.Decoder) error
+}
+
+// streamResponse decodes a response into apiResp, passing its result to
+// streamer to decode.
+func streamResponse(dec *json.Decoder, apiResp *APIResponse, streamer resultStreamer) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case "ok":
+			err = dec.Decode(&apiResp.Ok)
+		case "error_code":
+			err = dec.Decode(&apiResp.ErrorCode)
+		case "description":
+			err = dec.Decode(&apiResp.Description)
+		case "parameters":
+			err = dec.Decode(&apiResp.Parameters)
+		case "result":
+			err = streamer.streamResult(dec)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token, failing if it isn't delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s in response, got %v", delim, token)
+	}
+
+	return nil
+}
+
+// readErrorRecorder keeps the error from reading a response, other than
+// io.EOF, so it can be told apart from an error decoding it.
+type readErrorRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+
+	return n, err
+}
+
+// apiEndpointPrefix is APIEndpoint up to the token, so URLs can be built
+// without formatting.
+var apiEndpointPrefix = APIEndpoint[:strings.Index(APIEndpoint, "%s")]
+
+// endpointURL returns the URL of a method for the bot's token.
+func (bot *BotAPI) endpointURL(endpoint string) string {
+	var b strings.Builder
+	b.Grow(len(apiEndpointPrefix) + len(bot.Token) + 1 + len(endpoint))
+	b.WriteString(apiEndpointPrefix)
+	b.WriteString(string(bot.Token))
+	b.WriteByte('/')
+	b.WriteString(endpoint)
+
+	return b.String()
+}
+
+// encodeForm encodes params as a form, sized up front so it is built in
+// a single allocation.
+//
+// Unlike url.Values.Encode, keys are not sorted.
+func encodeForm(params url.Values) []byte {
+	size := 0
+	for key, values := range params {
+		for _, value := range values {
+			size += len(key) + len(value) + 2
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size+size/4))
+	for key, values := range params {
+		for _, value := range values {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			writeFormEscaped(buf, key)
+			buf.WriteByte('=')
+			writeFormEscaped(buf, value)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// writeFormEscaped writes s escaped as url.QueryEscape would, without
+// allocating.
+func writeFormEscaped(buf *bytes.Buffer, s string) {
+	const hex = "0123456789ABCDEF"
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			buf.WriteByte(c)
+		case c == ' ':
+			buf.WriteByte('+')
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&15])
+		}
+	}
+}
+
+// observeResponse calls OnResponse, if it is set, with a response.
+func (bot *BotAPI) observeResponse(endpoint string, params url.Values, resp *http.Response, body []byte) {
+	if bot.OnResponse == nil {
+		return
+	}
+
+	bot.OnResponse(RawResponse{
+		Method:     endpoint,
+		Params:     params,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+}
+
 // makeMessageRequest makes a request to a method that returns a Message.
-func (bot *BotAPI) makeMessageRequest(endpoint string, params url.Values) (Message, error) {
-	resp, err := bot.MakeRequest(endpoint, params)
+func (bot *BotAPI) makeMessageRequest(ctx context.Context, endpoint string, params url.Values) (Message, error) {
+	resp, err := bot.makeRequestContext(ctx, endpoint, params)
 	if err != nil {
 		return Message{}, err
 	}
@@ -111,105 +589,343 @@ func (bot *BotAPI) makeMessageRequest(endpoint string, params url.Values) (Messa
 // UploadFile makes a request to the API with a file.
 //
 // Requires the parameter to hold the file not be in the params.
-// File should be a string to a file path, a FileBytes struct,
-// a FileReader struct, or a url.URL.
+// File should be a RequestFileData, or for compatibility a string path
+// to a file or a url.URL.
 //
-// Note that if your FileReader has a size set to -1, it will read
-// the file into memory to calculate a size.
+// Files which need uploading are streamed as multipart form data. Others,
+// such as a FileURL or FileID, are sent as a regular parameter.
 func (bot *BotAPI) UploadFile(endpoint string, params map[string]string, fieldname string, file interface{}) (APIResponse, error) {
-	ms := multipartstreamer.New()
-
-	switch f := file.(type) {
-	case string:
-		ms.WriteFields(params)
-
-		fileHandle, err := os.Open(f)
-		if err != nil {
-			return APIResponse{}, err
-		}
-		defer fileHandle.Close()
-
-		fi, err := os.Stat(f)
-		if err != nil {
-			return APIResponse{}, err
-		}
+	data, err := newRequestFileData(file)
+	if err != nil {
+		return APIResponse{}, err
+	}
 
-		ms.WriteReader(fieldname, fileHandle.Name(), fi.Size(), fileHandle)
-	case FileBytes:
-		ms.WriteFields(params)
+	return bot.UploadFiles(endpoint, params, []RequestFile{{Name: fieldname, Data: data}})
+}
 
-		buf := bytes.NewBuffer(f.Bytes)
-		ms.WriteReader(fieldname, f.Name, int64(len(f.Bytes)), buf)
-	case FileReader:
-		ms.WriteFields(params)
+// UploadFiles makes a request to the API with any number of files.
+//
+// Files which need uploading are streamed as multipart form data, and
+// may be referenced from other parameters as attach://<name>. Others,
+// such as a FileURL or FileID, are sent as regular parameters.
+//
+// The request has a Content-Length when the size of every file is known,
+// so it isn't sent chunked. This is the case for FileBytes, FilePath, and
+// FileReader with a Size more than zero.
+func (bot *BotAPI) UploadFiles(endpoint string, params map[string]string, files []RequestFile) (APIResponse, error) {
+	return bot.uploadFilesInto(context.Background(), endpoint, params, files, nil)
+}
 
-		if f.Size != -1 {
-			ms.WriteReader(fieldname, f.Name, f.Size, f.Reader)
+// uploadFilesInto is UploadFiles, aborting the request if the context is
+// done and decoding the result of a successful request into result if it
+// is not nil.
+func (bot *BotAPI) uploadFilesInto(ctx context.Context, endpoint string, params map[string]string, files []RequestFile, result interface{}) (APIResponse, error) {
+	params = bot.withDefaultParams(endpoint, params)
 
-			break
+	var uploads []RequestFile
+	for _, file := range files {
+		if file.Data.NeedsUpload() {
+			uploads = append(uploads, file)
 		}
+	}
 
-		data, err := ioutil.ReadAll(f.Reader)
-		if err != nil {
-			return APIResponse{}, err
+	if len(uploads) == 0 {
+		v := make(url.Values, len(params)+len(files))
+		for key, value := range params {
+			v.Add(key, value)
+		}
+		for _, file := range files {
+			v.Add(file.Name, file.Data.SendData())
 		}
 
-		buf := bytes.NewBuffer(data)
+		return bot.makeRequestInto(ctx, endpoint, v, result)
+	}
 
-		ms.WriteReader(fieldname, f.Name, int64(len(data)), buf)
-	case url.URL:
-		params[fieldname] = f.String()
+	if bot.isDryRun(endpoint) {
+		return bot.dryRun(endpoint, paramsToValues(params), uploads, result)
+	}
 
-		ms.WriteFields(params)
-	default:
-		return APIResponse{}, errors.New(ErrBadFileType)
+	if err := bot.rateLimit(ctx, endpoint, params["chat_id"]); err != nil {
+		return APIResponse{}, err
 	}
 
-	method := fmt.Sprintf(APIEndpoint, bot.Token, endpoint)
+	r, w := io.Pipe()
+	m := multipart.NewWriter(w)
+
+	length, known := multipartLength(m.Boundary(), params, files)
 
-	req, err := http.NewRequest("POST", method, nil)
+	go func() {
+		w.CloseWithError(writeMultipart(m, params, files))
+	}()
+
+	req, err := http.NewRequest("POST", bot.endpointURL(endpoint), r)
 	if err != nil {
-		return APIResponse{}, err
+		r.Close()
+		return APIResponse{}, bot.redactError(err)
+	}
+
+	bot.setHeaders(req)
+	req.Header.Set("Content-Type", m.FormDataContentType())
+	if known {
+		req.ContentLength = length
 	}
 
-	ms.SetupRequest(req)
+	req, done := bot.traceRequest(endpoint, req.WithContext(ctx))
 
 	res, err := bot.Client.Do(req)
 	if err != nil {
+		err = bot.redactError(err)
+		done(err)
 		return APIResponse{}, err
 	}
 	defer res.Body.Close()
 
-	bytes, err := ioutil.ReadAll(res.Body)
+	apiResp, err := bot.decodeResponse(endpoint, func() url.Values { return paramsToValues(params) }, res, result)
+	done(err)
 	if err != nil {
+		return APIResponse{}, statusError(res, err)
+	}
+
+	if !apiResp.Ok {
+		err := newError(apiResp)
+		bot.rateLimited(err, params["chat_id"])
+
+		if toChatID, ok := bot.chatMigrated(err, params["chat_id"]); ok && bot.RetryMigratedChats && canReupload(uploads) {
+			retry := make(map[string]string, len(params))
+			for k, v := range params {
+				retry[k] = v
+			}
+			retry["chat_id"] = strconv.FormatInt(toChatID, 10)
+
+			return bot.uploadFilesInto(ctx, endpoint, retry, files, result)
+		}
+		bot.chatUndeliverable(err, params["chat_id"])
+
 		return APIResponse{}, err
 	}
 
-	if bot.Debug {
-		log.Println(string(bytes))
+	return apiResp, nil
+}
+
+// canReupload returns if files can be uploaded again, which is not
+// possible for readers that have already been consumed.
+func canReupload(files []RequestFile) bool {
+	for _, file := range files {
+		if _, ok := file.Data.(FileReader); ok {
+			return false
+		}
 	}
 
-	var apiResp APIResponse
-	json.Unmarshal(bytes, &apiResp)
+	return true
+}
 
-	if !apiResp.Ok {
-		return APIResponse{}, errors.New(apiResp.Description)
+// writeMultipart writes params and files as multipart form data, closing
+// any files opened from a FilePath afterwards.
+func writeMultipart(m *multipart.Writer, params map[string]string, files []RequestFile) error {
+	for key, value := range params {
+		if err := m.WriteField(key, value); err != nil {
+			return err
+		}
 	}
 
-	return apiResp, nil
+	for _, file := range files {
+		if !file.Data.NeedsUpload() {
+			if err := m.WriteField(file.Name, file.Data.SendData()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := writeMultipartFile(m, file); err != nil {
+			return err
+		}
+	}
+
+	return m.Close()
+}
+
+func writeMultipartFile(m *multipart.Writer, file RequestFile) error {
+	name, reader, err := file.Data.UploadData()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := file.Data.(FilePath); ok {
+		defer reader.(io.Closer).Close()
+	}
+
+	part, err := m.CreatePart(multipartFileHeader(file, name))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, reader)
+
+	return err
+}
+
+// multipartFileHeader returns the header of the part for a file.
+func multipartFileHeader(file RequestFile, name string) textproto.MIMEHeader {
+	contentType := "application/octet-stream"
+	if f, ok := file.Data.(FileReader); ok && f.ContentType != "" {
+		contentType = f.ContentType
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(file.Name), escapeQuotes(filepath.Base(name))))
+	header.Set("Content-Type", contentType)
+
+	return header
+}
+
+// multipartLength returns the length of the body writeMultipart writes
+// with a boundary, if the size of every file is known.
+//
+// Everything but the files' contents is written out to be counted, then
+// the sizes of the files are added.
+func multipartLength(boundary string, params map[string]string, files []RequestFile) (int64, bool) {
+	var counter byteCounter
+	m := multipart.NewWriter(&counter)
+	if err := m.SetBoundary(boundary); err != nil {
+		return 0, false
+	}
+
+	for key, value := range params {
+		m.WriteField(key, value)
+	}
+
+	for _, file := range files {
+		if !file.Data.NeedsUpload() {
+			m.WriteField(file.Name, file.Data.SendData())
+			continue
+		}
+
+		name, size, ok := uploadSize(file.Data)
+		if !ok {
+			return 0, false
+		}
+
+		m.CreatePart(multipartFileHeader(file, name))
+		counter += byteCounter(size)
+	}
+
+	m.Close()
+
+	return int64(counter), true
+}
+
+// uploadSize returns the name and size of a file to upload, without
+// reading it, if its size is known.
+func uploadSize(file RequestFileData) (string, int64, bool) {
+	switch f := file.(type) {
+	case FileBytes:
+		return f.Name, int64(len(f.Bytes)), true
+	case FileReader:
+		return f.Name, f.Size, f.Size > 0
+	case FilePath:
+		info, err := os.Stat(string(f))
+		if err != nil || !info.Mode().IsRegular() {
+			return "", 0, false
+		}
+
+		return string(f), info.Size(), true
+	}
+
+	return "", 0, false
+}
+
+// byteCounter is a writer which counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes a name for a Content-Disposition header, the same
+// way mime/multipart does.
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
 }
 
 // GetFileDirectURL returns direct URL to file
 //
-// It requires the FileID.
+// It requires the FileID. If FileLinkCache is set, a link is reused until
+// shortly before it expires, then getFile is called again.
 func (bot *BotAPI) GetFileDirectURL(fileID string) (string, error) {
+	key := "filepath:" + fileID
+
+	if bot.FileLinkCache != nil {
+		path, ok, err := bot.FileLinkCache.Get(key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			file := File{FileID: fileID, FilePath: string(path)}
+			return file.Link(string(bot.Token)), nil
+		}
+	}
+
 	file, err := bot.GetFile(FileConfig{fileID})
 
 	if err != nil {
 		return "", err
 	}
 
-	return file.Link(bot.Token), nil
+	if bot.FileLinkCache != nil {
+		if err := bot.FileLinkCache.Set(key, []byte(file.FilePath), FileLinkValidity-fileLinkMargin); err != nil {
+			return "", err
+		}
+	}
+
+	return file.Link(string(bot.Token)), nil
+}
+
+// DownloadFile gets a file by its ID and opens it with OpenFile.
+//
+// The returned ReadCloser must be closed.
+func (bot *BotAPI) DownloadFile(fileID string) (io.ReadCloser, error) {
+	file, err := bot.GetFile(FileConfig{fileID})
+	if err != nil {
+		return nil, err
+	}
+
+	return bot.OpenFile(file)
+}
+
+// OpenFile opens the contents of a File returned by GetFile for reading.
+// If LocalFiles is set and the file has an absolute path, it is opened
+// directly, otherwise it is downloaded.
+//
+// The returned ReadCloser must be closed.
+func (bot *BotAPI) OpenFile(file File) (io.ReadCloser, error) {
+	return bot.OpenFileContext(context.Background(), file)
+}
+
+// OpenFileContext is OpenFile, aborting the download if the context is
+// done. Downloads are also limited by DownloadLimits, if it is set.
+//
+// If a download ends before the file's FileSize, or the size the server
+// sent, was received, reading it returns a *TruncatedDownloadError.
+func (bot *BotAPI) OpenFileContext(ctx context.Context, file File) (io.ReadCloser, error) {
+	if bot.LocalFiles && filepath.IsAbs(file.FilePath) {
+		return bot.openLocalFile(file)
+	}
+
+	resp, err := bot.getFile(ctx, file, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.New(http.StatusText(resp.StatusCode))
+	}
+
+	return resp.Body, nil
 }
 
 // GetMe fetches the currently authenticated bot.
@@ -218,7 +934,11 @@ func (bot *BotAPI) GetFileDirectURL(fileID string) (string, error) {
 // and so you may get this data from BotAPI.Self without the need for
 // another request.
 func (bot *BotAPI) GetMe() (User, error) {
-	resp, err := bot.MakeRequest("getMe", nil)
+	return bot.getMe(context.Background())
+}
+
+func (bot *BotAPI) getMe(ctx context.Context) (User, error) {
+	resp, err := bot.makeRequestContext(ctx, "getMe", nil)
 	if err != nil {
 		return User{}, err
 	}
@@ -231,22 +951,88 @@ func (bot *BotAPI) GetMe() (User, error) {
 	return user, nil
 }
 
+// Me returns the currently authenticated bot.
+//
+// The result of getMe is cached, and is only fetched again once it is
+// older than SelfRefreshInterval. It is safe to call from multiple
+// goroutines, unlike reading BotAPI.Self directly.
+func (bot *BotAPI) Me(ctx context.Context) (User, error) {
+	bot.selfMu.RLock()
+	self, fetched := bot.Self, bot.selfFetched
+	bot.selfMu.RUnlock()
+
+	if !fetched.IsZero() && (bot.SelfRefreshInterval == 0 || time.Since(fetched) < bot.SelfRefreshInterval) {
+		return self, nil
+	}
+
+	return bot.refreshSelf(ctx)
+}
+
+// RefreshSelf fetches the currently authenticated bot and updates the
+// cached BotAPI.Self, so changes such as a new username are noticed.
+func (bot *BotAPI) RefreshSelf() (User, error) {
+	return bot.refreshSelf(context.Background())
+}
+
+func (bot *BotAPI) refreshSelf(ctx context.Context) (User, error) {
+	self, err := bot.getMe(ctx)
+	if err != nil {
+		return User{}, err
+	}
+
+	bot.selfMu.Lock()
+	bot.Self = self
+	bot.selfFetched = time.Now()
+	bot.selfMu.Unlock()
+
+	return self, nil
+}
+
+// self returns the cached bot without making any requests.
+func (bot *BotAPI) self() User {
+	bot.selfMu.RLock()
+	defer bot.selfMu.RUnlock()
+
+	return bot.Self
+}
+
 // IsMessageToMe returns true if message directed to this bot.
 //
 // It requires the Message.
 func (bot *BotAPI) IsMessageToMe(message Message) bool {
-	return strings.Contains(message.Text, "@"+bot.Self.UserName)
+	return strings.Contains(message.Text, "@"+bot.self().UserName)
 }
 
 // Send will send a Chattable item to Telegram.
 //
 // It requires the Chattable to send.
 func (bot *BotAPI) Send(c Chattable) (Message, error) {
+	return bot.SendContext(context.Background(), c)
+}
+
+// SendContext is Send, aborting the request if the context is done. The
+// context can also give the request a priority with WithPriority.
+func (bot *BotAPI) SendContext(ctx context.Context, c Chattable) (Message, error) {
+	message, err := bot.send(ctx, c)
+	if err != nil && bot.IgnoreMessageNotModified && IsMessageNotModified(err) {
+		if edit, ok := c.(editable); ok {
+			return edit.message(), nil
+		}
+	}
+
+	return message, err
+}
+
+func (bot *BotAPI) send(ctx context.Context, c Chattable) (Message, error) {
 	switch c.(type) {
+	case multiFileable:
+		return bot.sendFiles(ctx, c.(multiFileable))
 	case Fileable:
-		return bot.sendFile(c.(Fileable))
+		return bot.sendFile(ctx, c.(Fileable))
+	case InvoiceConfig:
+		return bot.sendInvoice(ctx, c.(InvoiceConfig))
 	default:
-		return bot.sendChattable(c)
+		return bot.sendChattable(ctx, c)
 	}
 }
 
@@ -261,14 +1047,14 @@ func (bot *BotAPI) debugLog(context string, v url.Values, message interface{}) {
 }
 
 // sendExisting will send a Message with an existing file to Telegram.
-func (bot *BotAPI) sendExisting(method string, config Fileable) (Message, error) {
+func (bot *BotAPI) sendExisting(ctx context.Context, method string, config Fileable) (Message, error) {
 	v, err := config.values()
 
 	if err != nil {
 		return Message{}, err
 	}
 
-	message, err := bot.makeMessageRequest(method, v)
+	message, err := bot.makeMessageRequest(ctx, method, v)
 	if err != nil {
 		return Message{}, err
 	}
@@ -277,15 +1063,23 @@ func (bot *BotAPI) sendExisting(method string, config Fileable) (Message, error)
 }
 
 // uploadAndSend will send a Message with a new file to Telegram.
-func (bot *BotAPI) uploadAndSend(method string, config Fileable) (Message, error) {
+func (bot *BotAPI) uploadAndSend(ctx context.Context, method string, config Fileable) (Message, error) {
 	params, err := config.params()
 	if err != nil {
 		return Message{}, err
 	}
 
-	file := config.getFile()
+	file, err := newRequestFileData(config.getFile())
+	if err != nil {
+		return Message{}, err
+	}
+
+	files, err := fileableFiles(config, file, params)
+	if err != nil {
+		return Message{}, err
+	}
 
-	resp, err := bot.UploadFile(method, params, config.name(), file)
+	resp, err := bot.uploadFilesInto(ctx, method, params, files, nil)
 	if err != nil {
 		return Message{}, err
 	}
@@ -298,24 +1092,94 @@ func (bot *BotAPI) uploadAndSend(method string, config Fileable) (Message, error
 	return message, nil
 }
 
+// fileableFiles returns the files to send with a config: its file, and
+// its thumbnail if it has one, which is referenced from params.
+func fileableFiles(config Fileable, file RequestFileData, params map[string]string) ([]RequestFile, error) {
+	files := []RequestFile{{Name: config.name(), Data: file}}
+
+	if t, ok := config.(thumbnailer); ok {
+		thumb := t.thumbnail()
+		attached, err := attachThumb(&thumb, "file-thumb")
+		if err != nil {
+			return nil, err
+		}
+		if attached != nil {
+			files = append(files, attached...)
+			params["thumb"] = thumb.SendData()
+		}
+	}
+
+	return files, nil
+}
+
 // sendFile determines if the file is using an existing file or uploading
 // a new file, then sends it as needed.
-func (bot *BotAPI) sendFile(config Fileable) (Message, error) {
+func (bot *BotAPI) sendFile(ctx context.Context, config Fileable) (Message, error) {
 	if config.useExistingFile() {
-		return bot.sendExisting(config.method(), config)
+		return bot.sendExisting(ctx, config.Method(), config)
+	}
+
+	if bot.UploadLimits != nil {
+		if err := ValidateUpload(config, *bot.UploadLimits); err != nil {
+			return Message{}, err
+		}
 	}
 
-	return bot.uploadAndSend(config.method(), config)
+	if bot.UploadCache != nil {
+		return bot.sendCachedFile(ctx, config)
+	}
+
+	return bot.uploadAndSend(ctx, config.Method(), config)
+}
+
+// sendFiles sends a config which may include several files to upload.
+func (bot *BotAPI) sendFiles(ctx context.Context, config multiFileable) (Message, error) {
+	resp, err := bot.uploadFiles(ctx, config)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var message Message
+	json.Unmarshal(resp.Result, &message)
+
+	bot.debugLog(config.Method(), nil, message)
+
+	return message, nil
+}
+
+func (bot *BotAPI) uploadFiles(ctx context.Context, config multiFileable) (APIResponse, error) {
+	params, files, err := config.files()
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	return bot.uploadFilesInto(ctx, config.Method(), params, files, nil)
+}
+
+// SendMediaGroup sends a group of photos or videos as an album, uploading
+// any local files.
+func (bot *BotAPI) SendMediaGroup(config MediaGroupConfig) ([]Message, error) {
+	resp, err := bot.uploadFiles(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	json.Unmarshal(resp.Result, &messages)
+
+	bot.debugLog(config.Method(), nil, messages)
+
+	return messages, nil
 }
 
 // sendChattable sends a Chattable.
-func (bot *BotAPI) sendChattable(config Chattable) (Message, error) {
+func (bot *BotAPI) sendChattable(ctx context.Context, config Chattable) (Message, error) {
 	v, err := config.values()
 	if err != nil {
 		return Message{}, err
 	}
 
-	message, err := bot.makeMessageRequest(config.method(), v)
+	message, err := bot.makeMessageRequest(ctx, config.Method(), v)
 
 	if err != nil {
 		return Message{}, err
@@ -329,14 +1193,7 @@ func (bot *BotAPI) sendChattable(config Chattable) (Message, error) {
 // It requires UserID.
 // Offset and Limit are optional.
 func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserProfilePhotos, error) {
-	v := url.Values{}
-	v.Add("user_id", strconv.Itoa(config.UserID))
-	if config.Offset != 0 {
-		v.Add("offset", strconv.Itoa(config.Offset))
-	}
-	if config.Limit != 0 {
-		v.Add("limit", strconv.Itoa(config.Limit))
-	}
+	v := config.values()
 
 	resp, err := bot.MakeRequest("getUserProfilePhotos", v)
 	if err != nil {
@@ -355,52 +1212,128 @@ func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserPro
 //
 // Requires FileID.
 func (bot *BotAPI) GetFile(config FileConfig) (File, error) {
-	v := url.Values{}
-	v.Add("file_id", config.FileID)
+	v := config.values()
 
 	resp, err := bot.MakeRequest("getFile", v)
 	if err != nil {
 		return File{}, err
 	}
 
-	var file File
-	json.Unmarshal(resp.Result, &file)
+	var file File
+	json.Unmarshal(resp.Result, &file)
+
+	bot.debugLog("GetFile", v, file)
+
+	return file, nil
+}
+
+// GetUpdates fetches updates.
+// If a WebHook is set, this will not return any data!
+//
+// Offset, Limit, and Timeout are optional.
+// To avoid stale items, set Offset to one higher than the previous item.
+// Set Timeout to a large number to reduce requests so you can get updates
+// instantly instead of having to wait between requests.
+//
+// An update which can't be fully decoded is logged, and returned with
+// the fields which could be.
+func (bot *BotAPI) GetUpdates(config UpdateConfig) ([]Update, error) {
+	v := config.values()
+
+	var updates []Update
+	add := func(update *Update) { updates = append(updates, *update) }
+	stream := &updateStream{
+		fn: func(update *Update) error {
+			add(update)
+			return nil
+		},
+		skip: add,
+	}
+
+	_, err := bot.makeRequestInto(context.Background(), "getUpdates", v, stream)
+	if err != nil {
+		return []Update{}, err
+	}
+
+	bot.debugLog("getUpdates", v, updates)
+
+	for i := range updates {
+		bot.reportUnknownFields(&updates[i])
+	}
+
+	return updates, nil
+}
 
-	bot.debugLog("GetFile", v, file)
+// streamUpdates gets updates like GetUpdates, calling fn with each one
+// as soon as it is decoded instead of decoding them all first, and
+// stopping if it returns an error. An update which can't be decoded is
+// logged and given to skip instead. It returns how many updates there
+// were, which may be some even if it fails.
+func (bot *BotAPI) streamUpdates(ctx context.Context, config UpdateConfig, fn func(update *Update) error, skip func(update *Update)) (int, error) {
+	stream := &updateStream{fn: fn, skip: skip}
+
+	_, err := bot.makeRequestInto(ctx, "getUpdates", config.values(), stream)
+	if stream.err != nil {
+		return stream.count, stream.err
+	}
 
-	return file, nil
+	return stream.count, err
 }
 
-// GetUpdates fetches updates.
-// If a WebHook is set, this will not return any data!
-//
-// Offset, Limit, and Timeout are optional.
-// To avoid stale items, set Offset to one higher than the previous item.
-// Set Timeout to a large number to reduce requests so you can get updates
-// instantly instead of having to wait between requests.
-func (bot *BotAPI) GetUpdates(config UpdateConfig) ([]Update, error) {
-	v := url.Values{}
-	if config.Offset != 0 {
-		v.Add("offset", strconv.Itoa(config.Offset))
+// updateStream decodes the updates returned by getUpdates one at a time.
+type updateStream struct {
+	fn    func(update *Update) error
+	skip  func(update *Update)
+	count int
+	// err is the error from fn, which stopped the stream.
+	err error
+}
+
+func (s *updateStream) streamResult(dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
 	}
-	if config.Limit > 0 {
-		v.Add("limit", strconv.Itoa(config.Limit))
+	// An error response may have a null result.
+	if token == nil {
+		return nil
 	}
-	if config.Timeout > 0 {
-		v.Add("timeout", strconv.Itoa(config.Timeout))
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected [ in response, got %v", token)
 	}
 
-	resp, err := bot.MakeRequest("getUpdates", v)
-	if err != nil {
-		return []Update{}, err
-	}
+	for dec.More() {
+		// Each update is read whole first, so one which doesn't fit an
+		// Update doesn't stop the rest being decoded.
+		var data json.RawMessage
+		if err := dec.Decode(&data); err != nil {
+			return err
+		}
+		s.count++
 
-	var updates []Update
-	json.Unmarshal(resp.Result, &updates)
+		update := new(Update)
+		if err := json.Unmarshal(data, update); err != nil {
+			var id struct {
+				UpdateID int `json:"update_id"`
+			}
+			json.Unmarshal(data, &id)
+			update.UpdateID = id.UpdateID
 
-	bot.debugLog("getUpdates", v, updates)
+			log.Printf("Failed to decode update %d: %v", id.UpdateID, err)
 
-	return updates, nil
+			if s.skip != nil {
+				s.skip(update)
+			}
+			continue
+		}
+
+		if err := s.fn(update); err != nil {
+			s.err = err
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
 }
 
 // RemoveWebhook unsets the webhook.
@@ -415,22 +1348,11 @@ func (bot *BotAPI) RemoveWebhook() (APIResponse, error) {
 // If you do not have a legitimate TLS certificate, you need to include
 // your self signed certificate with the config.
 func (bot *BotAPI) SetWebhook(config WebhookConfig) (APIResponse, error) {
-
 	if config.Certificate == nil {
-		v := url.Values{}
-		v.Add("url", config.URL.String())
-		if config.MaxConnections != 0 {
-			v.Add("max_connections", strconv.Itoa(config.MaxConnections))
-		}
-
-		return bot.MakeRequest("setWebhook", v)
+		return bot.MakeRequest("setWebhook", config.values())
 	}
 
-	params := make(map[string]string)
-	params["url"] = config.URL.String()
-	if config.MaxConnections != 0 {
-		params["max_connections"] = strconv.Itoa(config.MaxConnections)
-	}
+	params := valuesToParams(config.values())
 
 	resp, err := bot.UploadFile("setWebhook", params, "certificate", config.Certificate)
 	if err != nil {
@@ -463,43 +1385,327 @@ func (bot *BotAPI) GetWebhookInfo() (WebhookInfo, error) {
 
 // GetUpdatesChan starts and returns a channel for getting updates.
 func (bot *BotAPI) GetUpdatesChan(config UpdateConfig) (UpdatesChannel, error) {
+	return bot.GetUpdatesChanContext(context.Background(), config)
+}
+
+// GetUpdatesChanContext starts polling for updates, returning the channel
+// they are sent to. Polling stops when the context is done, aborting any
+// request in progress, and the channel is then closed.
+//
+// An update is only confirmed once it has been sent to the channel, so
+// updates which were not are returned again when polling is restarted
+// with the same Offset, or OffsetStore.
+func (bot *BotAPI) GetUpdatesChanContext(ctx context.Context, config UpdateConfig) (UpdatesChannel, error) {
 	ch := make(chan Update, bot.Buffer)
 
+	err := bot.startPolling(ctx, config, func(update *Update) bool {
+		select {
+		case ch <- *update:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(ch) })
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+// GetUpdatesPtrChanContext is GetUpdatesChanContext for a channel of
+// pointers to updates, so the large Update struct isn't copied through
+// the channel and into every handler.
+func (bot *BotAPI) GetUpdatesPtrChanContext(ctx context.Context, config UpdateConfig) (UpdatesPtrChannel, error) {
+	ch := make(chan *Update, bot.Buffer)
+
+	err := bot.startPolling(ctx, config, func(update *Update) bool {
+		select {
+		case ch <- update:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(ch) })
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+// startPolling starts polling for updates in the background, giving
+// each to deliver, which returns false if the context was done first.
+// done is called once polling stops.
+func (bot *BotAPI) startPolling(ctx context.Context, config UpdateConfig, deliver func(update *Update) bool, done func()) error {
+	if config.OffsetStore != nil {
+		offset, err := loadOffset(config.OffsetStore)
+		if err != nil {
+			return err
+		}
+
+		if offset > config.Offset {
+			config.Offset = offset
+		}
+	}
+
+	p := &updatePoller{
+		bot:        bot,
+		config:     config,
+		maxTimeout: config.Timeout,
+		deliver:    deliver,
+	}
+
 	go func() {
-		for {
-			updates, err := bot.GetUpdates(config)
-			if err != nil {
+		defer done()
+		p.run(ctx)
+	}()
+
+	return nil
+}
+
+// updatePoller is the state of polling for updates, all of which is kept
+// by a single goroutine.
+type updatePoller struct {
+	bot        *BotAPI
+	config     UpdateConfig
+	maxTimeout int
+	failures   int
+	deliver    func(update *Update) bool
+}
+
+// run polls for updates until the context is done.
+func (p *updatePoller) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := p.poll(ctx)
+
+		if count > 0 && p.config.OffsetStore != nil {
+			if err := saveOffset(p.config.OffsetStore, p.config.Offset); err != nil {
 				log.Println(err)
-				log.Println("Failed to get updates, retrying in 3 seconds...")
-				time.Sleep(time.Second * 3)
+			}
+		}
 
-				continue
+		if err != nil {
+			if ctx.Err() != nil {
+				return
 			}
 
-			for _, update := range updates {
-				if update.UpdateID >= config.Offset {
-					config.Offset = update.UpdateID + 1
-					ch <- update
-				}
+			p.failures++
+			delay := retryDelay(p.failures)
+
+			log.Println(err)
+			log.Printf("Failed to get updates, retrying in %s...", delay)
+
+			if err := sleepContext(ctx, delay); err != nil {
+				return
 			}
+
+			continue
 		}
-	}()
+		p.failures = 0
 
-	return ch, nil
+		if p.config.AdaptiveTimeout && p.maxTimeout > 0 {
+			p.config.Timeout = adaptTimeout(p.config.Timeout, p.maxTimeout, count)
+		}
+	}
+}
+
+// poll gets one batch of updates, delivering each as it is decoded.
+func (p *updatePoller) poll(ctx context.Context) (int, error) {
+	return p.bot.streamUpdates(ctx, p.config, func(update *Update) error {
+		if update.UpdateID < p.config.Offset {
+			return nil
+		}
+
+		p.bot.reportUnknownFields(update)
+
+		if !p.bot.answer(update) && !p.deliver(update) {
+			return ctx.Err()
+		}
+
+		p.config.Offset = update.UpdateID + 1
+
+		return nil
+	}, func(update *Update) {
+		// It would fail to decode again, so it is confirmed.
+		if update.UpdateID >= p.config.Offset {
+			p.config.Offset = update.UpdateID + 1
+		}
+	})
+}
+
+// retryDelay returns how long to wait before retrying after a number of
+// consecutive failures. It backs off exponentially from 3 seconds up to
+// a minute, and is randomized so many bots don't all retry at once.
+func retryDelay(failures int) time.Duration {
+	delay := time.Minute
+	if failures < 6 {
+		delay = time.Second * 3 << uint(failures-1)
+		if delay > time.Minute {
+			delay = time.Minute
+		}
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// adaptTimeout halves the long polling timeout while updates are arriving
+// and doubles it, up to max, while idle.
+func adaptTimeout(timeout, max, received int) int {
+	if received > 0 {
+		if timeout /= 2; timeout < 1 {
+			timeout = 1
+		}
+		return timeout
+	}
+
+	if timeout *= 2; timeout > max {
+		timeout = max
+	}
+	return timeout
 }
 
 // ListenForWebhook registers a http handler for a webhook.
 func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
+	handler, ch := bot.WebhookHandler()
+
+	http.Handle(pattern, handler)
+
+	return ch
+}
+
+// webhookBufferPool holds the buffers webhook requests are read into.
+var webhookBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// maxPooledWebhookBuffer is the largest buffer kept in webhookBufferPool.
+const maxPooledWebhookBuffer = 64 << 10
+
+// MaxWebhookBodySize is the largest webhook request body accepted, after
+// it is decompressed, so a small compressed body can't expand to fill
+// memory.
+const MaxWebhookBodySize = 16 << 20
+
+// webhookSecretHeader is the header Telegram sends a webhook's secret
+// token in.
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// readWebhookBody reads the body of a webhook request into buf,
+// decompressing it if a proxy in front of the bot compressed it with
+// gzip or deflate. If it can't be read, or doesn't have the bot's
+// WebhookSecretToken, an error is sent in response and false is returned.
+func (bot *BotAPI) readWebhookBody(w http.ResponseWriter, r *http.Request, buf *bytes.Buffer) bool {
+	if bot.WebhookSecretToken != "" && !secretsEqual(r.Header.Get(webhookSecretHeader), bot.WebhookSecretToken) {
+		http.Error(w, "bad secret token", http.StatusUnauthorized)
+		return false
+	}
+
+	var body io.Reader = r.Body
+
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+		defer gz.Close()
+
+		body = gz
+	case "deflate":
+		zr, err := zlib.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+		defer zr.Close()
+
+		body = zr
+	default:
+		http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	n, err := buf.ReadFrom(io.LimitReader(body, MaxWebhookBodySize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if n > MaxWebhookBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return false
+	}
+
+	return true
+}
+
+// WebhookHandler returns a http.Handler for a webhook along with the
+// channel it sends updates to, so it may be mounted on any existing
+// server or router instead of http.DefaultServeMux.
+func (bot *BotAPI) WebhookHandler() (http.Handler, UpdatesChannel) {
 	ch := make(chan Update, bot.Buffer)
 
-	http.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
-		bytes, _ := ioutil.ReadAll(r.Body)
+	return bot.webhookHandler(func(update *Update) { ch <- *update }), ch
+}
+
+// WebhookPtrHandler is WebhookHandler for a channel of pointers to
+// updates, so the large Update struct isn't copied through the channel
+// and into every handler.
+func (bot *BotAPI) WebhookPtrHandler() (http.Handler, UpdatesPtrChannel) {
+	ch := make(chan *Update, bot.Buffer)
 
-		var update Update
-		json.Unmarshal(bytes, &update)
+	return bot.webhookHandler(func(update *Update) { ch <- update }), ch
+}
+
+// webhookHandler returns a http.Handler for a webhook which gives each
+// update to deliver.
+func (bot *BotAPI) webhookHandler(deliver func(update *Update)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := webhookBufferPool.Get().(*bytes.Buffer)
+		buf.Reset()
+
+		// Decoding copies everything kept from the buffer, so it can be
+		// reused, unless an unusually large update made it grow.
+		defer func() {
+			if buf.Cap() <= maxPooledWebhookBuffer {
+				webhookBufferPool.Put(buf)
+			}
+		}()
+
+		if !bot.readWebhookBody(w, r, buf) {
+			return
+		}
+
+		update := new(Update)
+		json.Unmarshal(buf.Bytes(), update)
 
-		ch <- update
+		bot.reportUnknownFields(update)
+
+		if !bot.answer(update) {
+			deliver(update)
+		}
 	})
+}
+
+// ServeWebhook serves a webhook at path on an existing net.Listener, such
+// as a unix socket or one passed in by systemd socket activation.
+//
+// The listener is served in the background until it is closed. Errors
+// other than it being closed are logged.
+func (bot *BotAPI) ServeWebhook(l net.Listener, path string) UpdatesChannel {
+	handler, ch := bot.WebhookHandler()
+
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+
+	go func() {
+		err := http.Serve(l, mux)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+			log.Println(err)
+		}
+	}()
 
 	return ch
 }
@@ -508,71 +1714,109 @@ func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
 //
 // Note that you must respond to an inline query within 30 seconds.
 func (bot *BotAPI) AnswerInlineQuery(config InlineConfig) (APIResponse, error) {
-	v := url.Values{}
-
-	v.Add("inline_query_id", config.InlineQueryID)
-	v.Add("cache_time", strconv.Itoa(config.CacheTime))
-	v.Add("is_personal", strconv.FormatBool(config.IsPersonal))
-	v.Add("next_offset", config.NextOffset)
-	data, err := json.Marshal(config.Results)
+	v, err := config.values()
 	if err != nil {
 		return APIResponse{}, err
 	}
-	v.Add("results", string(data))
-	v.Add("switch_pm_text", config.SwitchPMText)
-	v.Add("switch_pm_parameter", config.SwitchPMParameter)
 
 	bot.debugLog("answerInlineQuery", v, nil)
 
 	return bot.MakeRequest("answerInlineQuery", v)
 }
 
+// AnswerInlineQueryPaged answers an inline query with the page of results
+// requested by offset, which should be the InlineQuery's Offset.
+//
+// The results are fetched with fetch, and NextOffset is managed so
+// Telegram requests the following page when the user scrolls.
+func (bot *BotAPI) AnswerInlineQueryPaged(config InlineConfig, offset string, fetch InlinePageFunc) (APIResponse, error) {
+	start := parseInlineOffset(offset)
+
+	results, err := fetch(start, InlineResultsPerPage)
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	if len(results) > InlineResultsPerPage {
+		results = results[:InlineResultsPerPage]
+	}
+
+	config.Results = results
+	config.NextOffset = ""
+	if len(results) == InlineResultsPerPage {
+		config.NextOffset = strconv.Itoa(start + len(results))
+	}
+
+	return bot.AnswerInlineQuery(config)
+}
+
 // AnswerCallbackQuery sends a response to an inline query callback.
 func (bot *BotAPI) AnswerCallbackQuery(config CallbackConfig) (APIResponse, error) {
-	v := url.Values{}
+	v := config.values()
+
+	bot.debugLog("answerCallbackQuery", v, nil)
 
-	v.Add("callback_query_id", config.CallbackQueryID)
-	if config.Text != "" {
-		v.Add("text", config.Text)
+	resp, err := bot.MakeRequest("answerCallbackQuery", v)
+	if err == nil {
+		bot.callbackAnswered(config.CallbackQueryID)
 	}
-	v.Add("show_alert", strconv.FormatBool(config.ShowAlert))
-	if config.URL != "" {
-		v.Add("url", config.URL)
+
+	return resp, err
+}
+
+// AnswerShippingQuery answers a shipping query.
+//
+// Note that you must respond to a shipping query within 10 seconds.
+func (bot *BotAPI) AnswerShippingQuery(config ShippingConfig) (APIResponse, error) {
+	v, err := config.values()
+	if err != nil {
+		return APIResponse{}, err
 	}
-	v.Add("cache_time", strconv.Itoa(config.CacheTime))
 
-	bot.debugLog("answerCallbackQuery", v, nil)
+	bot.debugLog("answerShippingQuery", v, nil)
 
-	return bot.MakeRequest("answerCallbackQuery", v)
+	return bot.MakeRequest("answerShippingQuery", v)
+}
+
+// AnswerPreCheckoutQuery answers a pre-checkout query.
+//
+// Note that you must respond to a pre-checkout query within 10 seconds.
+func (bot *BotAPI) AnswerPreCheckoutQuery(config PreCheckoutConfig) (APIResponse, error) {
+	v := config.values()
+
+	bot.debugLog("answerPreCheckoutQuery", v, nil)
+
+	return bot.MakeRequest("answerPreCheckoutQuery", v)
 }
 
 // KickChatMember kicks a user from a chat. Note that this only will work
 // in supergroups, and requires the bot to be an admin. Also note they
 // will be unable to rejoin until they are unbanned.
 func (bot *BotAPI) KickChatMember(config ChatMemberConfig) (APIResponse, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
-	v.Add("user_id", strconv.Itoa(config.UserID))
+	v := config.values()
 
 	bot.debugLog("kickChatMember", v, nil)
 
 	return bot.MakeRequest("kickChatMember", v)
 }
 
+// RestrictChatMember restricts what a user may do in a supergroup, or
+// lifts restrictions if given all permissions. The bot must be an
+// administrator able to restrict members.
+func (bot *BotAPI) RestrictChatMember(config RestrictChatMemberConfig) (APIResponse, error) {
+	v, err := config.values()
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	bot.debugLog("restrictChatMember", v, nil)
+
+	return bot.MakeRequest("restrictChatMember", v)
+}
+
 // LeaveChat makes the bot leave the chat.
 func (bot *BotAPI) LeaveChat(config ChatConfig) (APIResponse, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
+	v := config.values()
 
 	bot.debugLog("leaveChat", v, nil)
 
@@ -581,13 +1825,7 @@ func (bot *BotAPI) LeaveChat(config ChatConfig) (APIResponse, error) {
 
 // GetChat gets information about a chat.
 func (bot *BotAPI) GetChat(config ChatConfig) (Chat, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
+	v := config.values()
 
 	resp, err := bot.MakeRequest("getChat", v)
 	if err != nil {
@@ -602,18 +1840,67 @@ func (bot *BotAPI) GetChat(config ChatConfig) (Chat, error) {
 	return chat, err
 }
 
+// GetMyCommands gets the list of the bot's commands for a scope and
+// language.
+func (bot *BotAPI) GetMyCommands(config MyCommandsConfig) ([]BotCommand, error) {
+	return bot.getMyCommands(context.Background(), config)
+}
+
+func (bot *BotAPI) getMyCommands(ctx context.Context, config MyCommandsConfig) ([]BotCommand, error) {
+	v, err := config.values(false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := bot.makeRequestContext(ctx, "getMyCommands", v)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []BotCommand
+	err = json.Unmarshal(resp.Result, &commands)
+
+	bot.debugLog("getMyCommands", v, commands)
+
+	return commands, err
+}
+
+// SetMyCommands changes the list of the bot's commands for a scope and
+// language.
+func (bot *BotAPI) SetMyCommands(config MyCommandsConfig) (APIResponse, error) {
+	return bot.setMyCommands(context.Background(), config)
+}
+
+func (bot *BotAPI) setMyCommands(ctx context.Context, config MyCommandsConfig) (APIResponse, error) {
+	v, err := config.values(true)
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	bot.debugLog("setMyCommands", v, nil)
+
+	return bot.makeRequestContext(ctx, "setMyCommands", v)
+}
+
+// DeleteMyCommands deletes the list of the bot's commands for a scope
+// and language, so users see the commands of a broader scope instead.
+func (bot *BotAPI) DeleteMyCommands(ctx context.Context, config MyCommandsConfig) (APIResponse, error) {
+	v, err := config.values(false)
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	bot.debugLog("deleteMyCommands", v, nil)
+
+	return bot.makeRequestContext(ctx, "deleteMyCommands", v)
+}
+
 // GetChatAdministrators gets a list of administrators in the chat.
 //
 // If none have been appointed, only the creator will be returned.
 // Bots are not shown, even if they are an administrator.
 func (bot *BotAPI) GetChatAdministrators(config ChatConfig) ([]ChatMember, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
+	v := config.values()
 
 	resp, err := bot.MakeRequest("getChatAdministrators", v)
 	if err != nil {
@@ -630,13 +1917,7 @@ func (bot *BotAPI) GetChatAdministrators(config ChatConfig) ([]ChatMember, error
 
 // GetChatMembersCount gets the number of users in a chat.
 func (bot *BotAPI) GetChatMembersCount(config ChatConfig) (int, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
+	v := config.values()
 
 	resp, err := bot.MakeRequest("getChatMembersCount", v)
 	if err != nil {
@@ -653,14 +1934,7 @@ func (bot *BotAPI) GetChatMembersCount(config ChatConfig) (int, error) {
 
 // GetChatMember gets a specific chat member.
 func (bot *BotAPI) GetChatMember(config ChatConfigWithUser) (ChatMember, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
-	v.Add("user_id", strconv.Itoa(config.UserID))
+	v := config.values()
 
 	resp, err := bot.MakeRequest("getChatMember", v)
 	if err != nil {
@@ -678,25 +1952,63 @@ func (bot *BotAPI) GetChatMember(config ChatConfigWithUser) (ChatMember, error)
 // UnbanChatMember unbans a user from a chat. Note that this only will work
 // in supergroups, and requires the bot to be an admin.
 func (bot *BotAPI) UnbanChatMember(config ChatMemberConfig) (APIResponse, error) {
-	v := url.Values{}
-
-	if config.SuperGroupUsername == "" {
-		v.Add("chat_id", strconv.FormatInt(config.ChatID, 10))
-	} else {
-		v.Add("chat_id", config.SuperGroupUsername)
-	}
-	v.Add("user_id", strconv.Itoa(config.UserID))
+	v := config.values()
 
 	bot.debugLog("unbanChatMember", v, nil)
 
 	return bot.MakeRequest("unbanChatMember", v)
 }
 
+// ApproveChatJoinRequest approves a user's request to join a chat. The
+// bot must be an administrator able to invite users.
+func (bot *BotAPI) ApproveChatJoinRequest(config ChatMemberConfig) (APIResponse, error) {
+	return bot.chatJoinRequest("approveChatJoinRequest", config)
+}
+
+// DeclineChatJoinRequest declines a user's request to join a chat. The
+// bot must be an administrator able to invite users.
+func (bot *BotAPI) DeclineChatJoinRequest(config ChatMemberConfig) (APIResponse, error) {
+	return bot.chatJoinRequest("declineChatJoinRequest", config)
+}
+
+func (bot *BotAPI) chatJoinRequest(method string, config ChatMemberConfig) (APIResponse, error) {
+	v := config.values()
+
+	bot.debugLog(method, v, nil)
+
+	return bot.MakeRequest(method, v)
+}
+
+// DeleteMessage deletes a message. Bots can delete their own messages,
+// and other messages in chats where they are an admin.
+func (bot *BotAPI) DeleteMessage(config DeleteMessageConfig) (APIResponse, error) {
+	v, err := config.values()
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	bot.debugLog(config.Method(), v, nil)
+
+	return bot.MakeRequest(config.Method(), v)
+}
+
+// SetMessageReaction changes the bot's reactions to a message.
+func (bot *BotAPI) SetMessageReaction(config SetMessageReactionConfig) (APIResponse, error) {
+	v, err := config.values()
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	bot.debugLog(config.Method(), v, nil)
+
+	return bot.MakeRequest(config.Method(), v)
+}
+
 // GetGameHighScores allows you to get the high scores for a game.
 func (bot *BotAPI) GetGameHighScores(config GetGameHighScoresConfig) ([]GameHighScore, error) {
 	v, _ := config.values()
 
-	resp, err := bot.MakeRequest(config.method(), v)
+	resp, err := bot.MakeRequest(config.Method(), v)
 	if err != nil {
 		return []GameHighScore{}, err
 	}
@@ -1,10 +1,22 @@
 package tgbotapi_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,6 +46,56 @@ func getBot(t *testing.T) (*tgbotapi.BotAPI, error) {
 	return bot, err
 }
 
+// testTransport sends every request to a local test server.
+type testTransport struct {
+	url *url.URL
+}
+
+func (tr testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = tr.url.Scheme
+	req.URL.Host = tr.url.Host
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestBot creates a bot whose requests are answered locally instead of
+// by Telegram. handler gets the method name and returns the result,
+// or an APIResponse to send as is. getMe is answered if handler returns nil.
+func newTestBot(t testing.TB, handler func(method string, r *http.Request) interface{}) *tgbotapi.BotAPI {
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+		result := handler(method, r)
+		if result == nil && method == "getMe" {
+			result = tgbotapi.User{ID: 1, FirstName: "Test", UserName: "testbot"}
+		}
+
+		resp, ok := result.(tgbotapi.APIResponse)
+		if !ok {
+			data, _ := json.Marshal(result)
+			resp = tgbotapi.APIResponse{Ok: true, Result: data}
+		}
+
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	u, _ := url.Parse(server.URL)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", &http.Client{Transport: testTransport{u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bot
+}
+
 func TestNewBotAPI_notoken(t *testing.T) {
 	_, err := tgbotapi.NewBotAPI("")
 
@@ -43,6 +105,34 @@ func TestNewBotAPI_notoken(t *testing.T) {
 	}
 }
 
+func TestMeCachesSelf(t *testing.T) {
+	calls := 0
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "getMe" {
+			calls++
+			return tgbotapi.User{ID: 1, UserName: "name" + strconv.Itoa(calls)}
+		}
+		return nil
+	})
+
+	me, err := bot.Me(context.Background())
+	if err != nil || me.UserName != "name1" || calls != 1 {
+		t.Fatal(me, err, calls)
+	}
+
+	bot.SelfRefreshInterval = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	me, err = bot.Me(context.Background())
+	if err != nil || me.UserName != "name2" || bot.Self.UserName != "name2" {
+		t.Fatal(me, err)
+	}
+
+	if _, err := bot.RefreshSelf(); err != nil || calls != 3 {
+		t.Fatal(err, calls)
+	}
+}
+
 func TestGetUpdates(t *testing.T) {
 	bot, _ := getBot(t)
 
@@ -430,7 +520,7 @@ func TestSetWebhookWithCert(t *testing.T) {
 
 	bot.RemoveWebhook()
 
-	wh := tgbotapi.NewWebhookWithCert("https://example.com/tgbotapi-test/"+bot.Token, "tests/cert.pem")
+	wh := tgbotapi.NewWebhookWithCert("https://example.com/tgbotapi-test/"+string(bot.Token), "tests/cert.pem")
 	_, err := bot.SetWebhook(wh)
 	if err != nil {
 		t.Error(err)
@@ -447,7 +537,7 @@ func TestSetWebhookWithoutCert(t *testing.T) {
 
 	bot.RemoveWebhook()
 
-	wh := tgbotapi.NewWebhook("https://example.com/tgbotapi-test/" + bot.Token)
+	wh := tgbotapi.NewWebhook("https://example.com/tgbotapi-test/" + string(bot.Token))
 	_, err := bot.SetWebhook(wh)
 	if err != nil {
 		t.Error(err)
@@ -470,6 +560,1227 @@ func TestUpdatesChan(t *testing.T) {
 	}
 }
 
+func TestUpdatesChanAdaptiveTimeout(t *testing.T) {
+	timeouts := make(chan string, 10)
+	polls := 0
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+
+		polls++
+		if polls <= 4 {
+			timeouts <- r.FormValue("timeout")
+		}
+		if polls == 1 {
+			return []tgbotapi.Update{{UpdateID: 1}}
+		}
+		return []tgbotapi.Update{}
+	})
+
+	config := tgbotapi.NewUpdate(0)
+	config.Timeout = 60
+	config.AdaptiveTimeout = true
+	bot.GetUpdatesChan(config)
+
+	for _, want := range []string{"60", "30", "60", "60"} {
+		if got := <-timeouts; got != want {
+			t.Fatalf("timeout %s, want %s", got, want)
+		}
+	}
+}
+
+func TestUpdatesChanStreamsUpdates(t *testing.T) {
+	limits := make(chan string, 10)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+
+		limits <- r.FormValue("limit")
+		// The second update can't be decoded, but the first is still
+		// delivered.
+		return tgbotapi.APIResponse{Ok: true, Result: json.RawMessage(`[{"update_id":1},{"update_id":"two"}]`)}
+	})
+
+	config := tgbotapi.NewUpdate(0)
+	config.Limit = 2
+	updates, _ := bot.GetUpdatesChan(config)
+
+	if limit := <-limits; limit != "2" {
+		t.Errorf("expected limit 2, got %s", limit)
+	}
+
+	select {
+	case update := <-updates:
+		if update.UpdateID != 1 {
+			t.Errorf("got update %d", update.UpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("update was not delivered")
+	}
+}
+
+func TestUpdatesChanSkipsUndecodableUpdates(t *testing.T) {
+	offsets := make(chan string, 100)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+
+		select {
+		case offsets <- r.FormValue("offset"):
+		default:
+		}
+		if r.FormValue("offset") == "3" {
+			time.Sleep(10 * time.Millisecond)
+			return []tgbotapi.Update{}
+		}
+		return tgbotapi.APIResponse{Ok: true, Result: json.RawMessage(
+			`[{"update_id":1,"message":{"message_id":"bad"}},{"update_id":2,"message":{"message_id":2}}]`)}
+	})
+
+	updates, err := bot.GetUpdates(tgbotapi.NewUpdate(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || updates[0].UpdateID != 1 || updates[1].Message.MessageID != 2 {
+		t.Errorf("expected both updates, got %+v", updates)
+	}
+	<-offsets
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := bot.GetUpdatesChanContext(ctx, tgbotapi.NewUpdate(0))
+
+	select {
+	case update := <-ch:
+		if update.UpdateID != 2 {
+			t.Errorf("expected the update which could be decoded, got %d", update.UpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("update was not delivered")
+	}
+
+	<-offsets
+	if offset := <-offsets; offset != "3" {
+		t.Errorf("expected polling to go on from offset 3, got %s", offset)
+	}
+}
+
+func TestUpdatesChanContextStopsAndResumes(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		if offset == 0 {
+			offset = 1
+		}
+
+		var updates []tgbotapi.Update
+		for id := offset; id <= 3; id++ {
+			updates = append(updates, tgbotapi.Update{UpdateID: id})
+		}
+		if len(updates) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return updates
+	})
+	bot.Buffer = 0
+
+	config := tgbotapi.NewUpdate(0)
+	config.OffsetStore = tgbotapi.NewMemoryStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := bot.GetUpdatesChanContext(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if update := <-updates; update.UpdateID != 1 {
+		t.Fatalf("got update %d", update.UpdateID)
+	}
+	cancel()
+
+	// An update may still be received while stopping, but it must not be
+	// received again after resuming.
+	next := 2
+	for update := range updates {
+		if update.UpdateID != next {
+			t.Fatalf("expected update %d, got %d", next, update.UpdateID)
+		}
+		next++
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	updates, err = bot.GetUpdatesChanContext(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ; next <= 3; next++ {
+		if update := <-updates; update.UpdateID != next {
+			t.Errorf("expected update %d, got %d", next, update.UpdateID)
+		}
+	}
+}
+
+func TestUpdatesPtrChan(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+		if r.FormValue("offset") == "" {
+			return []tgbotapi.Update{{UpdateID: 1, Message: &tgbotapi.Message{Text: "polled"}}}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return []tgbotapi.Update{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := bot.GetUpdatesPtrChanContext(ctx, tgbotapi.NewUpdate(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update := <-updates; update.UpdateID != 1 || update.Message.Text != "polled" {
+		t.Errorf("got update %+v", update)
+	}
+
+	handler, hooked := bot.WebhookPtrHandler()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/",
+		strings.NewReader(`{"update_id":2,"message":{"message_id":1,"text":"hooked","chat":{"id":1}}}`)))
+	if update := <-hooked; update.UpdateID != 2 || update.Message.Text != "hooked" {
+		t.Errorf("got update %+v", update)
+	}
+}
+
+func TestAnswerInlineQueryPaged(t *testing.T) {
+	var form url.Values
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "answerInlineQuery" {
+			r.ParseForm()
+			form = r.PostForm
+			return true
+		}
+		return nil
+	})
+
+	fetch := func(offset, limit int) ([]interface{}, error) {
+		if offset != 50 || limit != tgbotapi.InlineResultsPerPage {
+			t.Fatal(offset, limit)
+		}
+		results := make([]interface{}, limit)
+		for i := range results {
+			results[i] = tgbotapi.NewInlineQueryResultArticle(strconv.Itoa(offset+i), "title", "message")
+		}
+		return results, nil
+	}
+
+	_, err := bot.AnswerInlineQueryPaged(tgbotapi.InlineConfig{InlineQueryID: "id"}, "50", fetch)
+	if err != nil || form.Get("next_offset") != "100" {
+		t.Fatal(err, form)
+	}
+}
+
+func TestRetryMigratedChats(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendMessage" && method != "sendDocument" {
+			return nil
+		}
+		if r.FormValue("chat_id") == "1" {
+			return tgbotapi.APIResponse{
+				ErrorCode:   400,
+				Description: "Bad Request: group chat was upgraded to a supergroup chat",
+				Parameters:  &tgbotapi.ResponseParameters{MigrateToChatID: -1001},
+			}
+		}
+		return tgbotapi.Message{MessageID: 1}
+	})
+
+	var from, to int64
+	bot.OnChatMigrated = func(fromChatID, toChatID int64) {
+		from, to = fromChatID, toChatID
+	}
+
+	_, err := bot.Send(tgbotapi.NewMessage(1, "text"))
+	if apiErr, ok := err.(*tgbotapi.Error); !ok || apiErr.MigrateToChatID != -1001 || from != 1 || to != -1001 {
+		t.Fatal(err, from, to)
+	}
+
+	bot.RetryMigratedChats = true
+	if msg, err := bot.Send(tgbotapi.NewMessage(1, "text")); err != nil || msg.MessageID != 1 {
+		t.Fatal(err)
+	}
+
+	params := map[string]string{"chat_id": "1"}
+	if _, err := bot.UploadFile("sendDocument", params, "document", tgbotapi.FileBytes{Name: "a.txt", Bytes: []byte("a")}); err != nil {
+		t.Fatal(err)
+	}
+	if params["chat_id"] != "1" {
+		t.Errorf("expected the caller's params left unchanged, got %v", params)
+	}
+}
+
+func TestUploadCache(t *testing.T) {
+	uploads, shares := 0, 0
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendDocument" {
+			return nil
+		}
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			uploads++
+		} else if r.FormValue("document") == "cached-id" {
+			shares++
+		}
+		return tgbotapi.Message{Document: &tgbotapi.Document{FileID: "cached-id"}}
+	})
+	bot.UploadCache = tgbotapi.NewMemoryStore()
+
+	for i := 0; i < 3; i++ {
+		b := tgbotapi.FileBytes{Name: "file.txt", Bytes: []byte("contents")}
+		if _, err := bot.Send(tgbotapi.NewDocumentUpload(ChatID, b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if uploads != 1 || shares != 2 {
+		t.Fatal(uploads, shares)
+	}
+}
+
+func TestUploadCacheThumbnail(t *testing.T) {
+	var thumbs []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendDocument" {
+			return nil
+		}
+
+		r.ParseMultipartForm(1 << 20)
+		if f, _, err := r.FormFile("file-thumb"); err == nil && r.FormValue("thumb") == "attach://file-thumb" {
+			data, _ := ioutil.ReadAll(f)
+			thumbs = append(thumbs, r.FormValue("document")+":"+string(data))
+		}
+		return tgbotapi.Message{Document: &tgbotapi.Document{FileID: "cached-id"}}
+	})
+	bot.UploadCache = tgbotapi.NewMemoryStore()
+
+	for i := 0; i < 2; i++ {
+		doc := tgbotapi.NewDocument(ChatID, tgbotapi.FileBytes{Name: "file.txt", Bytes: []byte("contents")})
+		doc.Thumb = tgbotapi.FileBytes{Name: "thumb.jpg", Bytes: []byte("jpeg")}
+		if _, err := bot.Send(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(thumbs) != 2 || thumbs[1] != "cached-id:jpeg" {
+		t.Errorf("expected the thumbnail uploaded with the cached file, got %v", thumbs)
+	}
+}
+
+func TestSendRequestFileData(t *testing.T) {
+	var uploaded, param string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendPhoto" {
+			return nil
+		}
+
+		uploaded, param = "", ""
+		if f, header, err := r.FormFile("photo"); err == nil {
+			data, _ := ioutil.ReadAll(f)
+			uploaded = header.Filename + ":" + string(data)
+		} else {
+			param = r.FormValue("photo")
+		}
+
+		if r.FormValue("chat_id") != strconv.Itoa(ChatID) {
+			t.Error("missing chat_id")
+		}
+		return tgbotapi.Message{}
+	})
+
+	tests := []struct {
+		file     tgbotapi.RequestFileData
+		uploaded string
+		param    string
+	}{
+		{tgbotapi.FileBytes{Name: "a.jpg", Bytes: []byte("bytes")}, "a.jpg:bytes", ""},
+		{tgbotapi.FileReader{Name: "b.jpg", Reader: strings.NewReader("reader"), Size: -1}, "b.jpg:reader", ""},
+		{tgbotapi.FilePath("tests/image.jpg"), "image.jpg:", ""},
+		{tgbotapi.FileURL("https://example.com/c.jpg"), "", "https://example.com/c.jpg"},
+		{tgbotapi.FileID(ExistingPhotoFileID), "", ExistingPhotoFileID},
+	}
+
+	for _, test := range tests {
+		if _, err := bot.Send(tgbotapi.NewPhoto(ChatID, test.file)); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(uploaded, test.uploaded) || (test.uploaded == "" && uploaded != "") || param != test.param {
+			t.Errorf("%T: uploaded %q, param %q", test.file, uploaded, param)
+		}
+	}
+}
+
+func TestUploadContentLength(t *testing.T) {
+	var length int64
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "getMe" {
+			return nil
+		}
+
+		length = r.ContentLength
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		return tgbotapi.Message{}
+	})
+
+	tests := []struct {
+		file  tgbotapi.RequestFileData
+		known bool
+	}{
+		{tgbotapi.FileBytes{Name: "a.jpg", Bytes: []byte("bytes")}, true},
+		{tgbotapi.FilePath("tests/image.jpg"), true},
+		{tgbotapi.FileReader{Name: "b.jpg", Reader: strings.NewReader("reader"), Size: 6}, true},
+		{tgbotapi.FileReader{Name: "c.jpg", Reader: strings.NewReader("reader"), Size: -1}, false},
+	}
+
+	for _, test := range tests {
+		photo := tgbotapi.NewPhoto(ChatID, test.file)
+		photo.Caption = "a caption"
+		if _, err := bot.Send(photo); err != nil {
+			t.Fatal(err)
+		}
+
+		if (length > 0) != test.known {
+			t.Errorf("%T: got content length %d", test.file, length)
+		}
+	}
+}
+
+func TestWebhookHandlerReusesBuffers(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	handler, updates := bot.WebhookHandler()
+
+	bodies := []string{
+		`{"update_id":1,"message":{"message_id":1,"text":"first","chat":{"id":1}}}`,
+		`{"update_id":2,"message":{"message_id":2,"text":"second!","chat":{"id":1}}}`,
+	}
+	for _, body := range bodies {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	}
+
+	for i, text := range []string{"first", "second!"} {
+		update := <-updates
+		if update.Message.Text != text {
+			t.Errorf("expected %q, got %q", text, update.Message.Text)
+		}
+
+		if string(update.Raw()) != bodies[i] {
+			t.Errorf("expected %s, got %s", bodies[i], update.Raw())
+		}
+	}
+}
+
+func BenchmarkWebhookHandler(b *testing.B) {
+	bot := newTestBot(b, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	handler, updates := bot.WebhookHandler()
+	go func() {
+		for range updates {
+		}
+	}()
+
+	body := `{"update_id":1,"message":{"message_id":1,"date":1600000000,"text":"A message with some text in it",` +
+		`"chat":{"id":76918703,"type":"private"},"from":{"id":76918703,"first_name":"Test"}}}`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+}
+
+func TestWebhookHandlerCompressed(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	handler, updates := bot.WebhookHandler()
+	body := `{"update_id":7,"message":{"message_id":1,"text":"zipped","chat":{"id":1}}}`
+
+	var gzipped, deflated bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(body))
+	gw.Close()
+	zw := zlib.NewWriter(&deflated)
+	zw.Write([]byte(body))
+	zw.Close()
+
+	for encoding, data := range map[string][]byte{"gzip": gzipped.Bytes(), "deflate": deflated.Bytes()} {
+		r := httptest.NewRequest("POST", "/", bytes.NewReader(data))
+		r.Header.Set("Content-Encoding", encoding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d", encoding, w.Code)
+		}
+		if update := <-updates; update.Message.Text != "zipped" {
+			t.Errorf("%s: got update %+v", encoding, update)
+		}
+	}
+
+	// A body which expands past the limit is refused.
+	var bomb bytes.Buffer
+	gw = gzip.NewWriter(&bomb)
+	gw.Write(make([]byte, tgbotapi.MaxWebhookBodySize+1))
+	gw.Close()
+
+	r := httptest.NewRequest("POST", "/", &bomb)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected the body to be refused, got status %d", w.Code)
+	}
+}
+
+func TestSendMediaGroupAttachesFiles(t *testing.T) {
+	var media []map[string]string
+	parts := make(map[string]string)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendMediaGroup" {
+			return nil
+		}
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		for name, headers := range r.MultipartForm.File {
+			f, _ := headers[0].Open()
+			data, _ := ioutil.ReadAll(f)
+			parts[name] = string(data)
+		}
+		json.Unmarshal([]byte(r.FormValue("media")), &media)
+
+		return []tgbotapi.Message{{MessageID: 1}, {MessageID: 2}, {MessageID: 3}}
+	})
+
+	config := tgbotapi.NewMediaGroup(ChatID, []interface{}{
+		tgbotapi.NewInputMediaPhoto(tgbotapi.FileBytes{Name: "a.jpg", Bytes: []byte("a")}),
+		tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(ExistingPhotoFileID)),
+		tgbotapi.NewInputMediaVideo(tgbotapi.FileBytes{Name: "c.mp4", Bytes: []byte("c")}),
+	})
+
+	messages, err := bot.SendMediaGroup(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 3 {
+		t.Errorf("got %d messages, expected 3", len(messages))
+	}
+
+	expected := []string{"attach://file-0", ExistingPhotoFileID, "attach://file-2"}
+	if len(media) != len(expected) {
+		t.Fatalf("got media %v", media)
+	}
+	for i, m := range media {
+		if m["media"] != expected[i] {
+			t.Errorf("media %d is %q, expected %q", i, m["media"], expected[i])
+		}
+	}
+
+	if parts["file-0"] != "a" || parts["file-2"] != "c" || len(parts) != 2 {
+		t.Errorf("got parts %v", parts)
+	}
+}
+
+func TestEditMessageMediaWithoutUpload(t *testing.T) {
+	var media string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "editMessageMedia" {
+			media = r.FormValue("media")
+		}
+		return tgbotapi.Message{}
+	})
+
+	edit := tgbotapi.NewEditMessageMedia(ChatID, 1, tgbotapi.NewInputMediaDocument(tgbotapi.FileURL("https://example.com/a.pdf")))
+	if _, err := bot.Send(edit); err != nil {
+		t.Fatal(err)
+	}
+
+	if media != `{"type":"document","media":"https://example.com/a.pdf"}` {
+		t.Errorf("got media %s", media)
+	}
+}
+
+func TestDownloadFileLocal(t *testing.T) {
+	path, err := filepath.Abs("tests/image.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "getFile":
+			return tgbotapi.File{FileID: r.FormValue("file_id"), FilePath: path}
+		case "image.jpg":
+			t.Error("file was downloaded instead of opened")
+		}
+		return nil
+	})
+	bot.LocalFiles = true
+
+	f, err := bot.DownloadFile(ExistingPhotoFileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := ioutil.ReadFile(path)
+	if string(data) != string(expected) {
+		t.Error("file contents differ")
+	}
+}
+
+func TestIgnoreMessageNotModified(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "editMessageText" {
+			return nil
+		}
+		return tgbotapi.APIResponse{
+			ErrorCode:   400,
+			Description: "Bad Request: message is not modified: specified new message content and reply markup are exactly the same",
+		}
+	})
+
+	edit := tgbotapi.NewEditMessageText(ChatID, 5, "same")
+
+	_, err := bot.Send(edit)
+	if !tgbotapi.IsMessageNotModified(err) {
+		t.Fatalf("expected message not modified error, got %v", err)
+	}
+
+	bot.IgnoreMessageNotModified = true
+
+	message, err := bot.Send(edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 5 || message.Chat.ID != ChatID {
+		t.Errorf("got message %d in chat %d", message.MessageID, message.Chat.ID)
+	}
+}
+
+func TestOnResponse(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return tgbotapi.Message{MessageID: 7}
+	})
+
+	var responses []tgbotapi.RawResponse
+	bot.OnResponse = func(resp tgbotapi.RawResponse) {
+		responses = append(responses, resp)
+	}
+
+	if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hi")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(responses) != 1 {
+		t.Fatalf("got %d responses", len(responses))
+	}
+
+	resp := responses[0]
+	if resp.Method != "sendMessage" || resp.Params.Get("text") != "hi" || resp.StatusCode != http.StatusOK {
+		t.Errorf("got response %+v", resp)
+	}
+	if !strings.Contains(string(resp.Body), `"message_id":7`) {
+		t.Errorf("got body %s", resp.Body)
+	}
+}
+
+func TestResponseDecodeErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:] {
+		case "getMe":
+			json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: json.RawMessage(`{"id":1}`)})
+			return
+		case "sendMessage":
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		w.Write([]byte("<html>error</html>"))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	bot, err := tgbotapi.NewBotAPIWithClient("token", &http.Client{Transport: testTransport{u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = bot.MakeRequest("sendMessage", url.Values{})
+	if decodeErr, ok := err.(*tgbotapi.DecodeError); !ok || decodeErr.Method != "sendMessage" {
+		t.Errorf("expected a decode error, got %v", err)
+	}
+
+	// An error page from a proxy is reported by its status.
+	_, err = bot.MakeRequest("getChat", url.Values{})
+	if err == nil || err.Error() != "Bad Gateway" {
+		t.Errorf("expected Bad Gateway, got %v", err)
+	}
+
+	files := []tgbotapi.RequestFile{{Name: "document", Data: tgbotapi.FileBytes{Name: "a.txt", Bytes: []byte("a")}}}
+	_, err = bot.UploadFiles("sendDocument", map[string]string{}, files)
+	if err == nil || err.Error() != "Bad Gateway" {
+		t.Errorf("expected Bad Gateway from an upload, got %v", err)
+	}
+}
+
+func TestGetUpdatesDecodes(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+		if r.FormValue("offset") == "-1" {
+			return tgbotapi.APIResponse{Ok: false, ErrorCode: 409, Description: "Conflict"}
+		}
+		return []tgbotapi.Update{
+			{UpdateID: 1, Message: &tgbotapi.Message{MessageID: 1, Text: "one"}},
+			{UpdateID: 2, Message: &tgbotapi.Message{MessageID: 2, Text: "two"}},
+		}
+	})
+
+	for _, observe := range []bool{false, true} {
+		if observe {
+			bot.OnResponse = func(resp tgbotapi.RawResponse) {}
+		}
+
+		updates, err := bot.GetUpdates(tgbotapi.NewUpdate(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(updates) != 2 || updates[1].Message.Text != "two" {
+			t.Errorf("got updates %+v", updates)
+		}
+
+		_, err = bot.GetUpdates(tgbotapi.NewUpdate(-1))
+		if err == nil || !strings.Contains(err.Error(), "Conflict") {
+			t.Errorf("expected a conflict, got %v", err)
+		}
+	}
+}
+
+func TestGetFileDirectURLCache(t *testing.T) {
+	calls := 0
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getFile" {
+			return nil
+		}
+		calls++
+		return tgbotapi.File{FileID: r.FormValue("file_id"), FilePath: "photos/file_1.jpg"}
+	})
+	bot.FileLinkCache = tgbotapi.NewMemoryStore()
+
+	for i := 0; i < 2; i++ {
+		link, err := bot.GetFileDirectURL(ExistingPhotoFileID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link != "https://api.telegram.org/file/bottoken/photos/file_1.jpg" {
+			t.Errorf("got link %s", link)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("getFile called %d times, expected 1", calls)
+	}
+}
+
+func TestChatUsernames(t *testing.T) {
+	chatIDs := make(map[string]string)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		chatIDs[method] = r.FormValue("chat_id")
+		if method == "forwardMessage" {
+			chatIDs["from"] = r.FormValue("from_chat_id")
+		}
+		return nil
+	})
+
+	forward := tgbotapi.NewForward(ChatID, 0, 1)
+	forward.FromChannelUsername = "@fromchannel"
+	bot.Send(forward)
+
+	bot.GetChat(tgbotapi.ChatConfig{SuperGroupUsername: "@channel"})
+	bot.Send(tgbotapi.ChatActionConfig{BaseChat: tgbotapi.BaseChat{ChannelUsername: "@channel"}, Action: tgbotapi.ChatTyping})
+
+	expected := map[string]string{
+		"forwardMessage": strconv.Itoa(ChatID),
+		"from":           "@fromchannel",
+		"getChat":        "@channel",
+		"sendChatAction": "@channel",
+	}
+	for key, value := range expected {
+		if chatIDs[key] != value {
+			t.Errorf("%s: got chat_id %q, expected %q", key, chatIDs[key], value)
+		}
+	}
+}
+
+func TestUploadFromReaderContentType(t *testing.T) {
+	var filename, contentType, data string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendDocument" {
+			return nil
+		}
+
+		f, header, err := r.FormFile("document")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := ioutil.ReadAll(f)
+		filename, contentType, data = header.Filename, header.Header.Get("Content-Type"), string(content)
+
+		return tgbotapi.Message{}
+	})
+
+	config := tgbotapi.NewDocumentUploadFromReader(ChatID, strings.NewReader("a,b"), "report.csv", "text/csv")
+	if _, err := bot.Send(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if filename != "report.csv" || contentType != "text/csv" || data != "a,b" {
+		t.Errorf("got %s (%s): %q", filename, contentType, data)
+	}
+}
+
+func TestSendThumbnail(t *testing.T) {
+	var thumbParam, thumb string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendVideo" {
+			return nil
+		}
+
+		thumbParam = r.FormValue("thumb")
+		if f, _, err := r.FormFile("file-thumb"); err == nil {
+			data, _ := ioutil.ReadAll(f)
+			thumb = string(data)
+		}
+
+		return tgbotapi.Message{}
+	})
+
+	config := tgbotapi.NewVideo(ChatID, tgbotapi.FileBytes{Name: "video.mp4", Bytes: []byte("video")})
+	config.Thumb = tgbotapi.FileBytes{Name: "thumb.jpg", Bytes: []byte("thumb")}
+
+	if _, err := bot.Send(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if thumbParam != "attach://file-thumb" || thumb != "thumb" {
+		t.Errorf("got thumb param %q and part %q", thumbParam, thumb)
+	}
+}
+
+func TestServeWebhookUnixSocket(t *testing.T) {
+	bot := newTestBot(t, func(string, *http.Request) interface{} { return nil })
+
+	socket := filepath.Join(t.TempDir(), "bot.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	updates := bot.ServeWebhook(l, "/hook")
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}}
+
+	resp, err := client.Post("http://bot/hook", "application/json", strings.NewReader(`{"update_id":42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if update := <-updates; update.UpdateID != 42 {
+		t.Fail()
+	}
+
+	logged := &syncBuffer{}
+	log.SetOutput(logged)
+	defer log.SetOutput(os.Stderr)
+
+	l.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	if logged.String() != "" {
+		t.Errorf("expected closing the listener not to be logged, got %q", logged.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer which may be written to by other
+// goroutines, such as by the log package.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestRequestBodyReplayedOnRedirect(t *testing.T) {
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getMe"):
+			json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: json.RawMessage(`{"id":1}`)})
+		case strings.HasPrefix(r.URL.Path, "/moved/"):
+			text = r.FormValue("text")
+			json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: json.RawMessage(`{"message_id":1}`)})
+		default:
+			http.Redirect(w, r, "/moved"+r.URL.Path, http.StatusTemporaryRedirect)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	bot, err := tgbotapi.NewBotAPIWithClient("token", &http.Client{Transport: testTransport{u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hello")); err != nil {
+		t.Fatal(err)
+	}
+	if text != "hello" {
+		t.Errorf("expected the body sent again after the redirect, got %q", text)
+	}
+}
+
+func TestSendEncodesForm(t *testing.T) {
+	var form url.Values
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			if r.ContentLength <= 0 {
+				t.Errorf("expected a content length, got %d", r.ContentLength)
+			}
+			r.ParseForm()
+			form = r.PostForm
+		}
+		return nil
+	})
+
+	text := "a b&c=d/é+%\n"
+	msg := tgbotapi.NewMessage(ChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("1", "one")),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, err := bot.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+		if form.Get("text") != text || !strings.Contains(form.Get("reply_markup"), `"one"`) {
+			t.Errorf("unexpected form %v", form)
+		}
+	}
+}
+
+func BenchmarkSend(b *testing.B) {
+	bot := newTestBot(b, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	msg := tgbotapi.NewMessage(ChatID, "A message with some text in it")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := bot.Send(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// cannedTransport answers every request with the same response, so
+// benchmarks measure the cost of making requests rather than of serving
+// them.
+type cannedTransport struct {
+	body []byte
+}
+
+func (tr cannedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		ioutil.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(tr.body)),
+		Request:    req,
+	}, nil
+}
+
+func BenchmarkSendConfigs(b *testing.B) {
+	bot := newTestBot(b, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+	bot.Client = &http.Client{Transport: cannedTransport{[]byte(`{"ok":true,"result":{"message_id":1}}`)}}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Yes", "yes")),
+	)
+
+	b.Run("Message", func(b *testing.B) {
+		msg := tgbotapi.NewMessage(ChatID, "A message with some text in it")
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = keyboard
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bot.Send(msg)
+		}
+	})
+
+	b.Run("ChatAction", func(b *testing.B) {
+		action := tgbotapi.NewChatAction(ChatID, tgbotapi.ChatTyping)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bot.Send(action)
+		}
+	})
+
+	b.Run("Callback", func(b *testing.B) {
+		callback := tgbotapi.NewCallback("query", "Done")
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bot.AnswerCallbackQuery(callback)
+		}
+	})
+}
+
+func TestRequestParams(t *testing.T) {
+	msg := tgbotapi.NewMessage(ChatID, "hello")
+	msg.ParseMode = tgbotapi.ModeHTML
+
+	var req tgbotapi.Request = msg
+	if req.Method() != "sendMessage" {
+		t.Errorf("unexpected method %s", req.Method())
+	}
+
+	params, err := req.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["chat_id"] != strconv.Itoa(ChatID) || params["text"] != "hello" || params["parse_mode"] != tgbotapi.ModeHTML {
+		t.Errorf("unexpected params %v", params)
+	}
+
+	var sent url.Values
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			r.ParseForm()
+			sent = r.PostForm
+			return tgbotapi.Message{MessageID: 1}
+		}
+		return nil
+	})
+
+	params["message_effect_id"] = "123"
+	if _, err := bot.Send(tgbotapi.NewMethodConfig(req.Method(), params)); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent.Get("text") != "hello" || sent.Get("message_effect_id") != "123" {
+		t.Errorf("expected the changed params sent, got %v", sent)
+	}
+}
+
+func TestFileableParams(t *testing.T) {
+	params, err := tgbotapi.NewPhoto(ChatID, tgbotapi.FileURL("https://example.com/a.jpg")).Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["photo"] != "https://example.com/a.jpg" {
+		t.Errorf("expected the photo's URL, got %v", params)
+	}
+
+	doc := tgbotapi.NewDocument(ChatID, tgbotapi.FileBytes{Name: "a.txt", Bytes: []byte("a")})
+	doc.Thumb = tgbotapi.FileBytes{Name: "thumb.jpg", Bytes: []byte("jpeg")}
+	params, err = doc.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := params["document"]; ok || params["thumb"] != "attach://file-thumb" {
+		t.Errorf("expected only the thumbnail referenced, got %v", params)
+	}
+}
+
+func TestSendVideoNote(t *testing.T) {
+	var got string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendVideoNote" {
+			return nil
+		}
+
+		r.ParseMultipartForm(1 << 20)
+		_, note, _ := r.FormFile("video_note")
+		_, thumb, _ := r.FormFile("file-thumb")
+		if note != nil && thumb != nil {
+			got = note.Filename + " " + thumb.Filename + " " + r.FormValue("length") + " " + r.FormValue("thumb")
+		}
+		return tgbotapi.Message{VideoNote: &tgbotapi.VideoNote{FileID: "note"}}
+	})
+
+	note := tgbotapi.NewVideoNoteUpload(ChatID, 240, tgbotapi.FileBytes{Name: "note.mp4", Bytes: []byte("mp4")})
+	note.Thumb = tgbotapi.FileBytes{Name: "thumb.jpg", Bytes: []byte("jpeg")}
+	message, err := bot.Send(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "note.mp4 thumb.jpg 240 attach://file-thumb" || message.VideoNote == nil {
+		t.Errorf("expected the video note and thumbnail uploaded, got %q", got)
+	}
+
+	note.Thumb = tgbotapi.FileID("thumb")
+	if _, err := bot.Send(note); err == nil || err.Error() != tgbotapi.ErrThumbNotUploaded {
+		t.Errorf("expected a thumbnail which isn't uploaded to be an error, got %v", err)
+	}
+}
+
+func TestConfigParams(t *testing.T) {
+	tests := []struct {
+		req    tgbotapi.Request
+		method string
+		params tgbotapi.Params
+	}{
+		{tgbotapi.FileConfig{FileID: "f"}, "getFile", tgbotapi.Params{"file_id": "f"}},
+		{tgbotapi.ChatConfig{ChatID: 5}, "getChat", tgbotapi.Params{"chat_id": "5"}},
+		{tgbotapi.ChatMemberConfig{ChatID: 5, UserID: 6}, "kickChatMember", tgbotapi.Params{"chat_id": "5", "user_id": "6"}},
+		{tgbotapi.NewUpdate(3), "getUpdates", tgbotapi.Params{"offset": "3"}},
+		{tgbotapi.PreCheckoutConfig{PreCheckoutQueryID: "q", OK: true}, "answerPreCheckoutQuery", tgbotapi.Params{"pre_checkout_query_id": "q", "ok": "true"}},
+		{tgbotapi.MyCommandsConfig{LanguageCode: "de"}, "getMyCommands", tgbotapi.Params{"language_code": "de"}},
+		{tgbotapi.MyCommandsConfig{Commands: []tgbotapi.BotCommand{}}, "setMyCommands", tgbotapi.Params{"commands": "[]"}},
+	}
+
+	for _, test := range tests {
+		params, err := test.req.Params()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.req.Method() != test.method || len(params) != len(test.params) {
+			t.Errorf("expected %s %v, got %s %v", test.method, test.params, test.req.Method(), params)
+			continue
+		}
+		for key, value := range test.params {
+			if params[key] != value {
+				t.Errorf("expected %s %v, got %v", test.method, test.params, params)
+			}
+		}
+	}
+}
+
+func TestCallMethod(t *testing.T) {
+	var uploaded string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "getStarBalance":
+			if r.FormValue("currency") != "XTR" {
+				t.Errorf("unexpected params %v", r.Form)
+			}
+			return map[string]int{"amount": 42}
+		case "uploadStory":
+			if f, _, err := r.FormFile("story"); err == nil {
+				data, _ := ioutil.ReadAll(f)
+				uploaded = string(data)
+			}
+			return true
+		case "missing":
+			return tgbotapi.APIResponse{Ok: false, ErrorCode: 404, Description: "Not Found"}
+		case "getBadBalance", "uploadBadStory":
+			return map[string]string{"amount": "notanint"}
+		}
+		return nil
+	})
+
+	var balance struct {
+		Amount int `json:"amount"`
+	}
+	err := bot.CallMethod(context.Background(), "getStarBalance", tgbotapi.Params{"currency": "XTR"}, nil, &balance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Amount != 42 {
+		t.Errorf("expected the result decoded, got %+v", balance)
+	}
+
+	var ok bool
+	files := []tgbotapi.RequestFile{{Name: "story", Data: tgbotapi.FileBytes{Name: "story.jpg", Bytes: []byte("jpeg")}}}
+	if err := bot.CallMethod(context.Background(), "uploadStory", nil, files, &ok); err != nil {
+		t.Fatal(err)
+	}
+	if !ok || uploaded != "jpeg" {
+		t.Errorf("expected the file uploaded, got %v, %q", ok, uploaded)
+	}
+
+	err = bot.CallMethod(context.Background(), "missing", nil, nil, nil)
+	if apiErr, ok := err.(*tgbotapi.Error); !ok || apiErr.Code != 404 {
+		t.Errorf("expected an API error, got %v", err)
+	}
+
+	// A result which doesn't fit is an error, whether files were
+	// uploaded or not.
+	err = bot.CallMethod(context.Background(), "getBadBalance", nil, nil, &balance)
+	if _, ok := err.(*tgbotapi.DecodeError); !ok {
+		t.Errorf("expected a decode error, got %v", err)
+	}
+	err = bot.CallMethod(context.Background(), "uploadBadStory", nil, files, &balance)
+	if _, ok := err.(*tgbotapi.DecodeError); !ok {
+		t.Errorf("expected a decode error from an upload, got %v", err)
+	}
+}
+
 func ExampleNewBotAPI() {
 	bot, err := tgbotapi.NewBotAPI("MyAwesomeBotToken")
 	if err != nil {
@@ -514,12 +1825,12 @@ func ExampleNewWebhook() {
 
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
-	_, err = bot.SetWebhook(tgbotapi.NewWebhookWithCert("https://www.google.com:8443/"+bot.Token, "cert.pem"))
+	_, err = bot.SetWebhook(tgbotapi.NewWebhookWithCert("https://www.google.com:8443/"+string(bot.Token), "cert.pem"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	updates := bot.ListenForWebhook("/" + bot.Token)
+	updates := bot.ListenForWebhook("/" + string(bot.Token))
 	go http.ListenAndServeTLS("0.0.0.0:8443", "cert.pem", "key.pem", nil)
 
 	for update := range updates {
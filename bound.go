@@ -0,0 +1,50 @@
+package tgbotapi
+
+// BoundMessage is a message bound to the bot that received it, to make
+// the most common follow-up actions without building configs.
+type BoundMessage struct {
+	*Message
+	Bot *BotAPI
+}
+
+// Bind binds a received message to the bot.
+func (bot *BotAPI) Bind(message *Message) BoundMessage {
+	return BoundMessage{Message: message, Bot: bot}
+}
+
+// Reply sends text in reply to the message.
+func (bm BoundMessage) Reply(text string) (Message, error) {
+	msg := NewMessage(bm.Chat.ID, text)
+	msg.ReplyToMessageID = bm.MessageID
+
+	return bm.Bot.Send(msg)
+}
+
+// ReplyHTML sends text formatted with ModeHTML in reply to the message.
+func (bm BoundMessage) ReplyHTML(text string) (Message, error) {
+	msg := NewMessage(bm.Chat.ID, text)
+	msg.ReplyToMessageID = bm.MessageID
+	msg.ParseMode = ModeHTML
+
+	return bm.Bot.Send(msg)
+}
+
+// Edit replaces the text of the message. Bots can only edit their own
+// messages.
+func (bm BoundMessage) Edit(text string) (Message, error) {
+	return bm.Bot.Send(NewEditMessageText(bm.Chat.ID, bm.MessageID, text))
+}
+
+// Delete deletes the message.
+func (bm BoundMessage) Delete() error {
+	_, err := bm.Bot.DeleteMessage(NewDeleteMessage(bm.Chat.ID, bm.MessageID))
+
+	return err
+}
+
+// React sets the bot's reaction to the message to emoji.
+func (bm BoundMessage) React(emoji string) error {
+	_, err := bm.Bot.SetMessageReaction(NewReaction(bm.Chat.ID, bm.MessageID, emoji))
+
+	return err
+}
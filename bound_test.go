@@ -0,0 +1,49 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestBoundMessage(t *testing.T) {
+	requests := make(map[string]url.Values)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		r.ParseForm()
+		requests[method] = r.Form
+		if method == "deleteMessage" || method == "setMessageReaction" {
+			return true
+		}
+		return tgbotapi.Message{MessageID: 2}
+	})
+
+	bm := bot.Bind(&tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: ChatID}})
+
+	if _, err := bm.ReplyHTML("<b>hi</b>"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bm.Edit("edited"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.React("👍"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := requests["sendMessage"]; v.Get("reply_to_message_id") != "1" || v.Get("parse_mode") != "HTML" {
+		t.Errorf("sendMessage got %v", v)
+	}
+	if v := requests["editMessageText"]; v.Get("message_id") != "1" || v.Get("text") != "edited" {
+		t.Errorf("editMessageText got %v", v)
+	}
+	if v := requests["setMessageReaction"]; v.Get("reaction") != `[{"type":"emoji","emoji":"👍"}]` {
+		t.Errorf("setMessageReaction got %v", v)
+	}
+	if v := requests["deleteMessage"]; v.Get("message_id") != "1" {
+		t.Errorf("deleteMessage got %v", v)
+	}
+}
@@ -0,0 +1,203 @@
+package tgbotapi
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+// DefaultBroadcastRate is how many messages per second a broadcast sends
+// if no rate is set, staying below Telegram's limit of about 30.
+const DefaultBroadcastRate = 25
+
+// BroadcastConfig contains information about sending a message to many
+// chats with Broadcast.
+type BroadcastConfig struct {
+	// ID identifies the broadcast in Store, so an interrupted broadcast
+	// may be resumed. It is required if Store is set.
+	ID      string
+	ChatIDs []int64
+	// Config creates the message to send to each chat.
+	Config func(chatID int64) Chattable
+	// Rate is how many messages to send per second, DefaultBroadcastRate
+	// if zero.
+	Rate float64
+	// Store, if set, saves progress so an interrupted broadcast with the
+	// same ID continues where it stopped instead of starting over.
+	Store KeyValueStore
+	// Progress, if set, is called after each chat with the number of
+	// chats done so far and the total.
+	Progress func(done, total int)
+}
+
+// BroadcastReport is the result of a broadcast.
+//
+// When a broadcast is resumed, it only contains the chats sent to since
+// resuming.
+type BroadcastReport struct {
+	Sent         int
+	Blocked      []int64         // the bot was blocked by the user
+	Deactivated  []int64         // the user's account was deleted
+	ChatNotFound []int64         // the chat doesn't exist
+	Kicked       []int64         // the bot was removed from the group or channel
+	Failed       map[int64]error // any other errors
+}
+
+// Undeliverable returns the chats messages can no longer be delivered
+// to, which may be removed from the list of subscribers.
+func (r BroadcastReport) Undeliverable() []int64 {
+	var chatIDs []int64
+	chatIDs = append(chatIDs, r.Blocked...)
+	chatIDs = append(chatIDs, r.Deactivated...)
+	chatIDs = append(chatIDs, r.ChatNotFound...)
+	chatIDs = append(chatIDs, r.Kicked...)
+
+	return chatIDs
+}
+
+// NewBroadcast creates a broadcast sending msg to each chat in chatIDs.
+func NewBroadcast(id string, chatIDs []int64, msg MessageConfig) BroadcastConfig {
+	return BroadcastConfig{
+		ID:      id,
+		ChatIDs: chatIDs,
+		Config: func(chatID int64) Chattable {
+			msg := msg
+			msg.ChatID = chatID
+			msg.ChannelUsername = ""
+			return msg
+		},
+	}
+}
+
+// Broadcast sends a message to every chat in a BroadcastConfig, at a
+// limited rate. If Telegram asks to wait because of flooding, it waits
+// and retries that chat.
+//
+// Messages are sent with PriorityLow, unless ctx was given a priority
+// with WithPriority, so a RateLimiter sends other messages first.
+//
+// It stops if ctx is done, returning the report so far along with the
+// context's error. Other errors for individual chats are only reported.
+func (bot *BotAPI) Broadcast(ctx context.Context, config BroadcastConfig) (BroadcastReport, error) {
+	report := BroadcastReport{Failed: make(map[int64]error)}
+
+	if _, ok := ctx.Value(priorityKey{}).(Priority); !ok {
+		ctx = WithPriority(ctx, PriorityLow)
+	}
+
+	start := 0
+	key := "broadcast:" + config.ID
+	if config.Store != nil {
+		value, ok, err := config.Store.Get(key)
+		if err != nil {
+			return report, err
+		}
+		if ok {
+			start, _ = strconv.Atoi(string(value))
+		}
+	}
+
+	rate := config.Rate
+	if rate <= 0 {
+		rate = DefaultBroadcastRate
+	}
+	ticker := time.NewTicker(broadcastInterval(rate))
+	defer ticker.Stop()
+
+	for i := start; i < len(config.ChatIDs); i++ {
+		chatID := config.ChatIDs[i]
+
+		for {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-ticker.C:
+			}
+
+			_, err := bot.SendContext(ctx, config.Config(chatID))
+			if err == nil {
+				report.Sent++
+				break
+			}
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+
+			if apiErr, ok := err.(*Error); ok {
+				if apiErr.RetryAfter > 0 {
+					if err := sleepContext(ctx, time.Duration(apiErr.RetryAfter)*time.Second); err != nil {
+						return report, err
+					}
+					continue
+				}
+			}
+
+			if reason, ok := UndeliverableReason(err); ok {
+				report.addUndeliverable(chatID, reason)
+				break
+			}
+
+			report.Failed[chatID] = err
+			break
+		}
+
+		if config.Store != nil {
+			if err := config.Store.Set(key, []byte(strconv.Itoa(i+1)), 0); err != nil {
+				return report, err
+			}
+		}
+
+		if config.Progress != nil {
+			config.Progress(i+1, len(config.ChatIDs))
+		}
+	}
+
+	if config.Store != nil {
+		return report, config.Store.Delete(key)
+	}
+
+	return report, nil
+}
+
+// addUndeliverable adds a chat messages can't be delivered to.
+func (r *BroadcastReport) addUndeliverable(chatID int64, reason DeliveryFailure) {
+	switch reason {
+	case DeliveryBlocked:
+		r.Blocked = append(r.Blocked, chatID)
+	case DeliveryDeactivated:
+		r.Deactivated = append(r.Deactivated, chatID)
+	case DeliveryChatNotFound:
+		r.ChatNotFound = append(r.ChatNotFound, chatID)
+	case DeliveryKicked:
+		r.Kicked = append(r.Kicked, chatID)
+	}
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// broadcastInterval returns the time between messages sent at rate per
+// second, kept within what a ticker accepts for very large or small rates.
+func broadcastInterval(rate float64) time.Duration {
+	interval := float64(time.Second) / rate
+
+	switch {
+	case !(interval >= 1):
+		return 1
+	case interval >= math.MaxInt64:
+		return math.MaxInt64
+	}
+
+	return time.Duration(interval)
+}
@@ -0,0 +1,90 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestBroadcast(t *testing.T) {
+	flooded := false
+	var sentTo []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "sendMessage" {
+			return nil
+		}
+
+		switch chatID := r.FormValue("chat_id"); chatID {
+		case "2":
+			return tgbotapi.APIResponse{ErrorCode: 403, Description: "Forbidden: bot was blocked by the user"}
+		case "3":
+			return tgbotapi.APIResponse{ErrorCode: 403, Description: "Forbidden: user is deactivated"}
+		case "4":
+			if !flooded {
+				flooded = true
+				return tgbotapi.APIResponse{
+					ErrorCode:   429,
+					Description: "Too Many Requests: retry after 1",
+					Parameters:  &tgbotapi.ResponseParameters{RetryAfter: 1},
+				}
+			}
+			fallthrough
+		default:
+			sentTo = append(sentTo, chatID)
+			return tgbotapi.Message{}
+		}
+	})
+
+	store := tgbotapi.NewMemoryStore()
+	store.Set("broadcast:news", []byte("1"), 0)
+
+	var progress []int
+	config := tgbotapi.NewBroadcast("news", []int64{1, 2, 3, 4, 5}, tgbotapi.NewMessage(0, "news"))
+	config.Rate = 1000
+	config.Store = store
+	config.Progress = func(done, total int) {
+		progress = append(progress, done)
+	}
+
+	report, err := bot.Broadcast(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Sent != 2 || len(sentTo) != 2 || sentTo[0] != "4" ||
+		len(report.Blocked) != 1 || report.Blocked[0] != 2 ||
+		len(report.Deactivated) != 1 || report.Deactivated[0] != 3 ||
+		len(report.Failed) != 0 {
+		t.Fatal(report, sentTo)
+	}
+
+	if len(progress) != 4 || progress[3] != 5 {
+		t.Fatal(progress)
+	}
+
+	if _, ok, _ := store.Get("broadcast:news"); ok {
+		t.Fail()
+	}
+}
+
+func TestBroadcastLargeRate(t *testing.T) {
+	sent := 0
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			sent++
+		}
+		return tgbotapi.Message{}
+	})
+
+	config := tgbotapi.NewBroadcast("", []int64{1, 2}, tgbotapi.NewMessage(0, "news"))
+	config.Rate = 1e12
+
+	if _, err := bot.Broadcast(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Errorf("expected 2 messages sent, got %d", sent)
+	}
+}
@@ -0,0 +1,85 @@
+package tgbotapi
+
+import (
+	"context"
+	"log"
+)
+
+// AnswerCallback answers a callback query, showing text to the user as a
+// notification if it is not empty.
+func (bot *BotAPI) AnswerCallback(query *CallbackQuery, text string) error {
+	_, err := bot.AnswerCallbackQuery(NewCallback(query.ID, text))
+
+	return err
+}
+
+// AnswerCallbackAlert answers a callback query, showing text to the user
+// as an alert.
+func (bot *BotAPI) AnswerCallbackAlert(query *CallbackQuery, text string) error {
+	_, err := bot.AnswerCallbackQuery(NewCallbackWithAlert(query.ID, text))
+
+	return err
+}
+
+// AutoAnswerCallbacks returns Middleware which answers callback queries
+// the handler did not answer once it returns, so the button being pressed
+// stops showing as loading.
+func AutoAnswerCallbacks() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			if update.CallbackQuery == nil {
+				next(ctx, bot, update)
+				return
+			}
+
+			id := update.CallbackQuery.ID
+			bot.trackCallback(id)
+
+			defer func() {
+				if bot.untrackCallback(id) {
+					return
+				}
+
+				if _, err := bot.AnswerCallbackQuery(CallbackConfig{CallbackQueryID: id}); err != nil {
+					log.Println(err)
+				}
+			}()
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// trackCallback starts noting if the callback query with id is answered.
+func (bot *BotAPI) trackCallback(id string) {
+	bot.callbacksMu.Lock()
+	defer bot.callbacksMu.Unlock()
+
+	if bot.callbacks == nil {
+		bot.callbacks = make(map[string]bool)
+	}
+	bot.callbacks[id] = false
+}
+
+// untrackCallback stops noting if the callback query with id is answered,
+// returning if it was.
+func (bot *BotAPI) untrackCallback(id string) bool {
+	bot.callbacksMu.Lock()
+	defer bot.callbacksMu.Unlock()
+
+	answered := bot.callbacks[id]
+	delete(bot.callbacks, id)
+
+	return answered
+}
+
+// callbackAnswered notes that the callback query with id was answered, if
+// it is being tracked.
+func (bot *BotAPI) callbackAnswered(id string) {
+	bot.callbacksMu.Lock()
+	defer bot.callbacksMu.Unlock()
+
+	if _, ok := bot.callbacks[id]; ok {
+		bot.callbacks[id] = true
+	}
+}
@@ -0,0 +1,34 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestAutoAnswerCallbacks(t *testing.T) {
+	var answers []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "answerCallbackQuery" {
+			answers = append(answers, r.FormValue("callback_query_id")+":"+r.FormValue("text"))
+			return true
+		}
+		return nil
+	})
+
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		if update.CallbackQuery.Data == "answer" {
+			bot.AnswerCallback(update.CallbackQuery, "done")
+		}
+	})
+	d.Use(tgbotapi.AutoAnswerCallbacks())
+
+	d.Dispatch(context.Background(), tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "1", Data: "answer"}})
+	d.Dispatch(context.Background(), tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "2", Data: "forget"}})
+
+	if len(answers) != 2 || answers[0] != "1:done" || answers[1] != "2:" {
+		t.Fatal(answers)
+	}
+}
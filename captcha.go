@@ -0,0 +1,320 @@
+package tgbotapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	mathrand "math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CaptchaKind is the kind of challenge a Captcha gives new members.
+type CaptchaKind int
+
+// Kinds of challenge.
+const (
+	// CaptchaButton asks the user to press a button.
+	CaptchaButton CaptchaKind = iota
+	// CaptchaEmoji asks the user to press the button with a particular
+	// emoji out of several.
+	CaptchaEmoji
+)
+
+// CaptchaConfig configures a Captcha.
+type CaptchaConfig struct {
+	Kind CaptchaKind
+	// Timeout is how long users have to answer. If it is zero, they have
+	// 2 minutes.
+	Timeout time.Duration
+	// Prompt is the challenge's text. The first %s in it is replaced
+	// with the user's name, and it is sent as is otherwise. If it is
+	// empty, a default in English is used.
+	Prompt string
+	// Emojis are the emojis CaptchaEmoji chooses from. If there are none,
+	// a default set is used.
+	Emojis []string
+}
+
+// Defaults for CaptchaConfig.
+const (
+	defaultCaptchaTimeout = 2 * time.Minute
+	defaultCaptchaPrompt  = "Welcome, %s! Please answer in time to show you're not a bot."
+	captchaChoices        = 6
+	captchaPrefix         = "captcha:"
+)
+
+var defaultCaptchaEmojis = []string{"🍎", "🚗", "🐶", "⚽", "🌵", "🎸", "🚀", "🍕", "🐟", "🌙"}
+
+// Captcha verifies that new members of groups are people. Members who
+// join are restricted and sent a challenge, and are unrestricted once
+// they answer it, or removed if they answer wrongly or not in time.
+//
+// Users who request to join a chat are sent the challenge privately, and
+// their request is approved or declined by their answer.
+//
+// Members who pass are given the chat's default permissions.
+//
+// The bot must be an administrator able to restrict members, and to
+// invite users for join requests. Captcha.Middleware must see member
+// joins, join requests and callback queries.
+//
+// Challenges are only kept in memory. Members with a challenge pending
+// when the bot stops stay restricted, and join requests stay waiting,
+// until an administrator lets them in.
+type Captcha struct {
+	config CaptchaConfig
+
+	mu         sync.Mutex
+	challenges map[string]*captchaChallenge
+}
+
+// captchaChallenge is a challenge waiting for an answer.
+type captchaChallenge struct {
+	id          string
+	chatID      int64
+	userID      int64
+	joinRequest bool
+	answer      string
+
+	// messageChat and messageID are where the challenge was sent.
+	messageChat int64
+	messageID   int
+
+	timer *time.Timer
+}
+
+// NewCaptcha creates a Captcha.
+func NewCaptcha(config CaptchaConfig) *Captcha {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultCaptchaTimeout
+	}
+	if config.Prompt == "" {
+		config.Prompt = defaultCaptchaPrompt
+	}
+	if len(config.Emojis) < captchaChoices {
+		config.Emojis = defaultCaptchaEmojis
+	}
+
+	return &Captcha{
+		config:     config,
+		challenges: make(map[string]*captchaChallenge),
+	}
+}
+
+// Middleware returns Middleware which challenges new members and handles
+// their answers. Answers are not passed on to the handler.
+func (c *Captcha) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			switch {
+			case update.CallbackQuery != nil && strings.HasPrefix(update.CallbackQuery.Data, captchaPrefix):
+				c.answer(bot, update.CallbackQuery)
+				return
+			case update.ChatJoinRequest != nil:
+				request := update.ChatJoinRequest
+				if request.Chat != nil && request.From != nil {
+					privateChat := request.UserChatID
+					if privateChat == 0 {
+						privateChat = request.From.ID
+					}
+					c.challenge(ctx, bot, request.Chat.ID, request.From, privateChat, true)
+				}
+			case update.Message != nil && update.Message.Chat != nil:
+				for i := range update.Message.NewChatMembers {
+					if member := &update.Message.NewChatMembers[i]; !member.IsBot {
+						c.challenge(ctx, bot, update.Message.Chat.ID, member, update.Message.Chat.ID, false)
+					}
+				}
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// Pending returns how many challenges are waiting for an answer.
+func (c *Captcha) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.challenges)
+}
+
+// challenge restricts a new member, unless they only requested to join,
+// and sends them a challenge in messageChat.
+func (c *Captcha) challenge(ctx context.Context, bot *BotAPI, chatID int64, user *User, messageChat int64, joinRequest bool) {
+	ch := &captchaChallenge{
+		id:          newCaptchaID(),
+		chatID:      chatID,
+		userID:      user.ID,
+		joinRequest: joinRequest,
+		messageChat: messageChat,
+	}
+
+	if !joinRequest {
+		_, err := bot.RestrictChatMember(RestrictChatMemberConfig{
+			ChatMemberConfig: ChatMemberConfig{ChatID: chatID, UserID: user.ID},
+		})
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	}
+
+	text := strings.Replace(c.config.Prompt, "%s", user.FirstName, 1)
+
+	var buttons []InlineKeyboardButton
+	switch c.config.Kind {
+	case CaptchaEmoji:
+		choices := mathrand.Perm(len(c.config.Emojis))[:captchaChoices]
+		answer := choices[mathrand.Intn(len(choices))]
+		ch.answer = strconv.Itoa(answer)
+		text += "\n\n" + c.config.Emojis[answer]
+
+		for _, choice := range choices {
+			buttons = append(buttons, NewInlineKeyboardButtonData(c.config.Emojis[choice], captchaPrefix+ch.id+":"+strconv.Itoa(choice)))
+		}
+	default:
+		ch.answer = "ok"
+		buttons = append(buttons, NewInlineKeyboardButtonData("I'm not a bot", captchaPrefix+ch.id+":ok"))
+	}
+
+	msg := NewMessage(messageChat, text)
+	msg.ReplyMarkup = NewInlineKeyboardMarkup(buttons)
+
+	sent, err := bot.SendContext(ctx, msg)
+	if err != nil {
+		log.Println(err)
+		c.reject(bot, ch)
+		return
+	}
+	ch.messageID = sent.MessageID
+
+	c.mu.Lock()
+	c.challenges[ch.id] = ch
+	ch.timer = time.AfterFunc(c.config.Timeout, func() {
+		if c.take(ch.id) != nil {
+			c.finish(bot, ch, false)
+		}
+	})
+	c.mu.Unlock()
+}
+
+// answer handles a press of a challenge's button.
+func (c *Captcha) answer(bot *BotAPI, query *CallbackQuery) {
+	parts := strings.SplitN(strings.TrimPrefix(query.Data, captchaPrefix), ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+
+	c.mu.Lock()
+	ch := c.challenges[parts[0]]
+	c.mu.Unlock()
+
+	if ch == nil {
+		bot.AnswerCallback(query, "")
+		return
+	}
+	if query.From == nil || query.From.ID != ch.userID {
+		bot.AnswerCallback(query, "This isn't for you.")
+		return
+	}
+
+	if c.take(ch.id) == nil {
+		bot.AnswerCallback(query, "")
+		return
+	}
+	ch.timer.Stop()
+
+	passed := parts[1] == ch.answer
+	if passed {
+		bot.AnswerCallback(query, "Thank you!")
+	} else {
+		bot.AnswerCallback(query, "Wrong answer.")
+	}
+
+	c.finish(bot, ch, passed)
+}
+
+// take removes a challenge, returning it if it was still waiting.
+func (c *Captcha) take(id string) *captchaChallenge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := c.challenges[id]
+	delete(c.challenges, id)
+
+	return ch
+}
+
+// finish lets a user in if they passed, or removes them, and deletes
+// the challenge.
+func (c *Captcha) finish(bot *BotAPI, ch *captchaChallenge, passed bool) {
+	if _, err := bot.DeleteMessage(NewDeleteMessage(ch.messageChat, ch.messageID)); err != nil {
+		log.Println(err)
+	}
+
+	if !passed {
+		c.reject(bot, ch)
+		return
+	}
+
+	member := ChatMemberConfig{ChatID: ch.chatID, UserID: ch.userID}
+
+	if ch.joinRequest {
+		if _, err := bot.ApproveChatJoinRequest(member); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	chat, err := bot.GetChat(ChatConfig{ChatID: ch.chatID})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if chat.Permissions == nil {
+		log.Printf("Chat %d has no default permissions to give user %d", ch.chatID, ch.userID)
+		return
+	}
+
+	_, err = bot.RestrictChatMember(RestrictChatMemberConfig{
+		ChatMemberConfig: member,
+		Permissions:      *chat.Permissions,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// reject declines a join request, or removes a member while leaving
+// them able to join again.
+func (c *Captcha) reject(bot *BotAPI, ch *captchaChallenge) {
+	member := ChatMemberConfig{ChatID: ch.chatID, UserID: ch.userID}
+
+	if ch.joinRequest {
+		if _, err := bot.DeclineChatJoinRequest(member); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	if _, err := bot.KickChatMember(member); err != nil {
+		log.Println(err)
+		return
+	}
+	if _, err := bot.UnbanChatMember(member); err != nil {
+		log.Println(err)
+	}
+}
+
+func newCaptchaID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,161 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// captchaBot records the methods called, the last challenge sent and the
+// last permissions given.
+type captchaBot struct {
+	mu          sync.Mutex
+	calls       []string
+	buttons     []tgbotapi.InlineKeyboardButton
+	text        string
+	permissions string
+}
+
+func (b *captchaBot) handle(method string, r *http.Request) interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if method == "getMe" {
+		return nil
+	}
+	b.calls = append(b.calls, method)
+
+	switch method {
+	case "sendMessage":
+		var markup tgbotapi.InlineKeyboardMarkup
+		json.Unmarshal([]byte(r.FormValue("reply_markup")), &markup)
+		b.buttons = markup.InlineKeyboard[0]
+		b.text = r.FormValue("text")
+		return tgbotapi.Message{MessageID: 10}
+	case "getChat":
+		return tgbotapi.Chat{ID: -100, Type: "supergroup", Permissions: &tgbotapi.ChatPermissions{
+			CanSendMessages: true,
+			CanSendPolls:    true,
+		}}
+	case "restrictChatMember":
+		b.permissions = r.FormValue("permissions")
+	}
+
+	return true
+}
+
+func (b *captchaBot) called() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return strings.Join(b.calls, ",")
+}
+
+func joinUpdate(userID int64) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:           &tgbotapi.Chat{ID: -100, Type: "supergroup"},
+		From:           &tgbotapi.User{ID: userID},
+		NewChatMembers: []tgbotapi.User{{ID: userID, FirstName: "Ann"}},
+	}}
+}
+
+func pressUpdate(userID int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   "q",
+		From: &tgbotapi.User{ID: userID},
+		Data: data,
+	}}
+}
+
+func TestCaptchaButton(t *testing.T) {
+	recorder := &captchaBot{}
+	bot := newTestBot(t, recorder.handle)
+
+	captcha := tgbotapi.NewCaptcha(tgbotapi.CaptchaConfig{Prompt: "100% sure you're human, %s?"})
+	handled := 0
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled++
+	})
+	d.Use(captcha.Middleware())
+
+	ctx := context.Background()
+	d.Dispatch(ctx, joinUpdate(1))
+	if captcha.Pending() != 1 {
+		t.Fatal("no challenge is pending")
+	}
+
+	data := recorder.buttons[0].CallbackData
+	d.Dispatch(ctx, pressUpdate(2, *data))
+	d.Dispatch(ctx, pressUpdate(1, *data))
+
+	expected := "restrictChatMember,sendMessage,answerCallbackQuery,answerCallbackQuery,deleteMessage,getChat,restrictChatMember"
+	if got := recorder.called(); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if recorder.text != "100% sure you're human, Ann?" {
+		t.Errorf("unexpected prompt %q", recorder.text)
+	}
+	if recorder.permissions != `{"can_send_messages":true,"can_send_polls":true}` {
+		t.Errorf("expected the chat's default permissions, got %s", recorder.permissions)
+	}
+	if captcha.Pending() != 0 || handled != 1 {
+		t.Errorf("got %d pending and %d handled", captcha.Pending(), handled)
+	}
+}
+
+func TestCaptchaEmojiWrongAnswer(t *testing.T) {
+	recorder := &captchaBot{}
+	bot := newTestBot(t, recorder.handle)
+
+	captcha := tgbotapi.NewCaptcha(tgbotapi.CaptchaConfig{Kind: tgbotapi.CaptchaEmoji})
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {})
+	d.Use(captcha.Middleware())
+
+	d.Dispatch(context.Background(), joinUpdate(1))
+	if len(recorder.buttons) != 6 {
+		t.Fatalf("expected 6 choices, got %d", len(recorder.buttons))
+	}
+
+	// No choice is numbered -1, so it is always wrong.
+	data := *recorder.buttons[0].CallbackData
+	wrong := data[:strings.LastIndex(data, ":")+1] + "-1"
+	d.Dispatch(context.Background(), pressUpdate(1, wrong))
+
+	expected := "restrictChatMember,sendMessage,answerCallbackQuery,deleteMessage,kickChatMember,unbanChatMember"
+	if got := recorder.called(); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestCaptchaJoinRequestTimeout(t *testing.T) {
+	recorder := &captchaBot{}
+	bot := newTestBot(t, recorder.handle)
+
+	captcha := tgbotapi.NewCaptcha(tgbotapi.CaptchaConfig{Timeout: 10 * time.Millisecond})
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {})
+	d.Use(captcha.Middleware())
+
+	d.Dispatch(context.Background(), tgbotapi.Update{ChatJoinRequest: &tgbotapi.ChatJoinRequest{
+		Chat:       &tgbotapi.Chat{ID: -100, Type: "supergroup"},
+		From:       &tgbotapi.User{ID: 1},
+		UserChatID: 1,
+	}})
+
+	deadline := time.Now().Add(time.Second)
+	for captcha.Pending() != 0 || !strings.HasSuffix(recorder.called(), "declineChatJoinRequest") {
+		if time.Now().After(deadline) {
+			t.Fatalf("join request was not declined: %s", recorder.called())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := recorder.called(); got != "sendMessage,deleteMessage,declineChatJoinRequest" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,259 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// Cassette records requests to the API and their responses to a file,
+// then replays them, so tests of how a bot sends messages can run
+// offline and in CI. Use it as the Transport of the http.Client given to
+// NewBotAPIWithClient.
+//
+// The bot's token is removed from recordings, and requests are matched
+// regardless of the token, so a cassette recorded with a real token can
+// be replayed with any.
+type Cassette struct {
+	path      string
+	transport http.RoundTripper
+	recording bool
+
+	mu           sync.Mutex
+	interactions []*CassetteInteraction
+}
+
+// CassetteInteraction is a recorded request and its response.
+type CassetteInteraction struct {
+	// Path is the path of the request, with the token removed, such as
+	// "/bot/sendMessage".
+	Path string `json:"path"`
+	// Params are the request's parameters. Files uploaded are
+	// summarized, not kept.
+	Params map[string]string `json:"params,omitempty"`
+
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	// Body is the response's body, or Data if it is not text, such as a
+	// downloaded file.
+	Body string `json:"body,omitempty"`
+	Data []byte `json:"data,omitempty"`
+
+	replayed bool
+}
+
+// NewCassette replays the cassette at path if it exists. Otherwise, it
+// records requests made with transport, or http.DefaultTransport if it is
+// nil, which are written to path by Save.
+func NewCassette(path string, transport http.RoundTripper) (*Cassette, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c := &Cassette{path: path, transport: transport}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		c.recording = true
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("cassette %s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+// Recording returns if the cassette is recording, rather than replaying.
+func (c *Cassette) Recording() bool {
+	return c.recording
+}
+
+// Save writes what was recorded to the cassette's file. It does nothing
+// when replaying.
+func (c *Cassette) Save() error {
+	if !c.recording {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path, append(data, '\n'), 0644)
+}
+
+// RoundTrip makes a request, recording it, or replays the first response
+// recorded to a request with the same path and parameters.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, token := cassettePath(req.URL.Path)
+
+	params, err := cassetteParams(req)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range params {
+		params[key] = token.redact(value)
+	}
+
+	if !c.recording {
+		return c.replay(req, path, params)
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	interaction := &CassetteInteraction{
+		Path:        path,
+		Params:      params,
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		interaction.Body = token.redact(string(body))
+	} else {
+		interaction.Data = body
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay returns the recorded response to a request.
+func (c *Cassette) replay(req *http.Request, path string, params map[string]string) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, interaction := range c.interactions {
+		if interaction.replayed || interaction.Path != path || !paramsEqual(interaction.Params, params) {
+			continue
+		}
+		interaction.replayed = true
+
+		body := interaction.Data
+		if body == nil {
+			body = []byte(interaction.Body)
+		}
+
+		header := make(http.Header)
+		if interaction.ContentType != "" {
+			header.Set("Content-Type", interaction.ContentType)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+			StatusCode:    interaction.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette %s: no recorded response to %s %s", c.path, path, describeRequest(paramsToValues(params), nil))
+}
+
+// cassetteTokenPath matches the token in the path of a request to the
+// API or for a file.
+var cassetteTokenPath = regexp.MustCompile(`^(/file)?/bot([^/]*)/`)
+
+// cassettePath returns a request's path without the token, and the token.
+func cassettePath(path string) (string, Token) {
+	m := cassetteTokenPath.FindStringSubmatchIndex(path)
+	if m == nil {
+		return path, ""
+	}
+
+	return path[:m[4]] + path[m[5]:], Token(path[m[4]:m[5]])
+}
+
+// cassetteParams reads the parameters of a request, leaving its body to
+// be read again.
+func cassetteParams(req *http.Request) (map[string]string, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	mediaType, mediaParams, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		return valuesToParams(values), nil
+	}
+
+	params := make(map[string]string)
+	r := multipart.NewReader(bytes.NewReader(body), mediaParams["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			return params, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FileName() != "" {
+			params[part.FormName()] = fmt.Sprintf("<%s, %d bytes>", part.FileName(), len(data))
+		} else {
+			params[part.FormName()] = string(data)
+		}
+	}
+}
+
+func paramsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestCassette(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result interface{} = tgbotapi.User{ID: 1, FirstName: "Test", UserName: "testbot"}
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			result = tgbotapi.Message{MessageID: 7, Text: r.FormValue("text")}
+		}
+
+		data, _ := json.Marshal(result)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: data})
+	}))
+	u, _ := url.Parse(server.URL)
+
+	path := filepath.Join(t.TempDir(), "send.json")
+
+	cassette, err := tgbotapi.NewCassette(path, testTransport{u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cassette.Recording() {
+		t.Fatal("expected a new cassette to record")
+	}
+
+	bot, err := tgbotapi.NewBotAPIWithClient("123:secret", &http.Client{Transport: cassette})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cassette.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected the token removed from the cassette, got %s", data)
+	}
+
+	// The server is gone, so everything must be replayed.
+	cassette, err = tgbotapi.NewCassette(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cassette.Recording() {
+		t.Fatal("expected an existing cassette to replay")
+	}
+
+	bot, err = tgbotapi.NewBotAPIWithClient("456:other", &http.Client{Transport: cassette})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := bot.Send(tgbotapi.NewMessage(ChatID, "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 7 || message.Text != "hello" {
+		t.Errorf("unexpected replayed message %+v", message)
+	}
+
+	if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hello")); err == nil {
+		t.Error("expected an error when the recording has run out")
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "other")); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected an error for a request not recorded, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package tgbotapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Command is a command handled by the bot, shown in the command menu of
+// the users within its scope.
+type Command struct {
+	// Name is the command without the leading /, such as "start".
+	Name        string
+	Description string
+	// Scope is who is shown the command, the default scope if nil.
+	Scope *BotCommandScope
+	// LanguageCode, if set, only shows the command to users with that
+	// language.
+	LanguageCode string
+	Handler      HandlerFunc
+}
+
+// CommandRegistry routes commands to the handlers registered for them,
+// and keeps the bot's command menu in sync with them.
+type CommandRegistry struct {
+	// NotFound, if set, handles updates which are not a registered
+	// command.
+	NotFound HandlerFunc
+
+	bot      *BotAPI
+	mu       sync.RWMutex
+	commands []Command
+}
+
+// NewCommandRegistry creates a CommandRegistry for a bot.
+func NewCommandRegistry(bot *BotAPI) *CommandRegistry {
+	return &CommandRegistry{bot: bot}
+}
+
+// Register adds commands to the registry. A command may be registered
+// more than once, for different scopes or languages; updates are handled
+// by the first registered with a Handler.
+func (r *CommandRegistry) Register(commands ...Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands = append(r.commands, commands...)
+}
+
+// Handle passes an update with a registered command to its handler, and
+// anything else to NotFound. It may be used as a Dispatcher's Handler.
+func (r *CommandRegistry) Handle(ctx context.Context, bot *BotAPI, update Update) {
+	var handler HandlerFunc
+	if update.Message != nil && update.Message.IsCommand() {
+		handler = r.handler(update.Message.Command())
+	}
+	if handler == nil {
+		handler = r.NotFound
+	}
+
+	if handler != nil {
+		handler(ctx, bot, update)
+	}
+}
+
+func (r *CommandRegistry) handler(name string) HandlerFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, command := range r.commands {
+		if command.Name == name && command.Handler != nil {
+			return command.Handler
+		}
+	}
+
+	return nil
+}
+
+// commandMenu is the commands shown for one scope and language.
+type commandMenu struct {
+	scope        *BotCommandScope
+	languageCode string
+	commands     []BotCommand
+}
+
+// SyncCommands updates the bot's command menu for every scope and
+// language with registered commands, calling setMyCommands only where
+// getMyCommands returns something different.
+//
+// Commands with an empty Description are handled but not shown. Menus
+// for scopes which no longer have any registered commands are not
+// found, so they must be removed with DeleteMyCommands.
+func (r *CommandRegistry) SyncCommands(ctx context.Context) error {
+	for _, menu := range r.menus() {
+		config := MyCommandsConfig{
+			Commands:     menu.commands,
+			Scope:        menu.scope,
+			LanguageCode: menu.languageCode,
+		}
+
+		current, err := r.bot.getMyCommands(ctx, config)
+		if err != nil {
+			return err
+		}
+
+		if sameCommands(current, menu.commands) {
+			continue
+		}
+
+		if _, err := r.bot.setMyCommands(ctx, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// menus groups the registered commands by scope and language, in a
+// stable order.
+func (r *CommandRegistry) menus() []commandMenu {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type menuKey struct {
+		scope        BotCommandScope
+		hasScope     bool
+		languageCode string
+	}
+
+	var keys []menuKey
+	menus := make(map[menuKey]*commandMenu)
+
+	for _, command := range r.commands {
+		if command.Description == "" {
+			continue
+		}
+
+		key := menuKey{languageCode: command.LanguageCode}
+		if command.Scope != nil {
+			key.scope, key.hasScope = *command.Scope, true
+		}
+
+		menu, ok := menus[key]
+		if !ok {
+			menu = &commandMenu{scope: command.Scope, languageCode: command.LanguageCode}
+			menus[key] = menu
+			keys = append(keys, key)
+		}
+
+		if !containsCommand(menu.commands, command.Name) {
+			menu.commands = append(menu.commands, BotCommand{
+				Command:     command.Name,
+				Description: command.Description,
+			})
+		}
+	}
+
+	result := make([]commandMenu, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *menus[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].scope == nil && result[j].scope != nil
+	})
+
+	return result
+}
+
+func containsCommand(commands []BotCommand, name string) bool {
+	for _, command := range commands {
+		if command.Command == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameCommands(a, b []BotCommand) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestCommandRegistryHandle(t *testing.T) {
+	var handled []string
+	registry := tgbotapi.NewCommandRegistry(nil)
+	registry.Register(tgbotapi.Command{
+		Name:        "start",
+		Description: "Start the bot",
+		Handler: func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+			handled = append(handled, "start")
+		},
+	})
+	registry.NotFound = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled = append(handled, "not found")
+	}
+
+	for _, text := range []string{"/start", "/start@testbot now", "/help", "hello"} {
+		registry.Handle(context.Background(), nil, tgbotapi.Update{Message: &tgbotapi.Message{Text: text}})
+	}
+
+	expected := []string{"start", "start", "not found", "not found"}
+	if len(handled) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, handled)
+	}
+	for i := range expected {
+		if handled[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, handled)
+		}
+	}
+}
+
+func TestCommandRegistrySyncCommands(t *testing.T) {
+	current := map[string]string{
+		"":                           `[{"command":"start","description":"Start the bot"}]`,
+		`{"type":"all_group_chats"}`: `[{"command":"ban","description":"Old description"}]`,
+	}
+	var set []string
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "getMyCommands":
+			return json.RawMessage(orEmpty(current[r.FormValue("scope")]))
+		case "setMyCommands":
+			set = append(set, r.FormValue("scope")+" "+r.FormValue("commands"))
+			return true
+		}
+		return nil
+	})
+
+	groups := tgbotapi.NewBotCommandScope(tgbotapi.CommandScopeAllGroupChats)
+	registry := tgbotapi.NewCommandRegistry(bot)
+	registry.Register(
+		tgbotapi.Command{Name: "start", Description: "Start the bot"},
+		tgbotapi.Command{Name: "ban", Description: "Ban a user", Scope: &groups},
+		tgbotapi.Command{Name: "debug"},
+	)
+
+	if err := registry.SyncCommands(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(set) != 1 || set[0] != `{"type":"all_group_chats"} [{"command":"ban","description":"Ban a user"}]` {
+		t.Errorf("unexpected setMyCommands calls: %v", set)
+	}
+}
+
+func TestDeleteMyCommandsContext(t *testing.T) {
+	var deleted []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "deleteMyCommands" {
+			deleted = append(deleted, r.FormValue("scope"))
+			return true
+		}
+		return nil
+	})
+
+	groups := tgbotapi.NewBotCommandScope(tgbotapi.CommandScopeAllGroupChats)
+	config := tgbotapi.MyCommandsConfig{Scope: &groups}
+
+	if _, err := bot.DeleteMyCommands(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != `{"type":"all_group_chats"}` {
+		t.Errorf("unexpected deleteMyCommands calls: %v", deleted)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := bot.DeleteMyCommands(ctx, config); err == nil {
+		t.Error("expected an error with a cancelled context")
+	}
+	if len(deleted) != 1 {
+		t.Errorf("expected no request with a cancelled context, got %v", deleted)
+	}
+}
+
+func orEmpty(s string) string {
+	if s == "" {
+		return "[]"
+	}
+	return s
+}
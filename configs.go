@@ -1,10 +1,15 @@
 package tgbotapi
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Telegram constants
@@ -28,29 +33,229 @@ const (
 	ChatFindLocation   = "find_location"
 )
 
+// ChatType is the type of a chat.
+type ChatType string
+
+// Constant values for Chat.Type
+const (
+	ChatTypePrivate    ChatType = "private"
+	ChatTypeGroup      ChatType = "group"
+	ChatTypeSuperGroup ChatType = "supergroup"
+	ChatTypeChannel    ChatType = "channel"
+)
+
+// MemberStatus is the status of a member of a chat.
+type MemberStatus string
+
+// Constant values for ChatMember.Status
+const (
+	MemberStatusCreator       MemberStatus = "creator"
+	MemberStatusAdministrator MemberStatus = "administrator"
+	MemberStatusMember        MemberStatus = "member"
+	MemberStatusRestricted    MemberStatus = "restricted"
+	MemberStatusLeft          MemberStatus = "left"
+	MemberStatusKicked        MemberStatus = "kicked"
+)
+
+// EntityType is the type of a message entity.
+type EntityType string
+
+// Constant values for MessageEntity.Type
+const (
+	EntityTypeMention     EntityType = "mention"
+	EntityTypeHashtag     EntityType = "hashtag"
+	EntityTypeBotCommand  EntityType = "bot_command"
+	EntityTypeURL         EntityType = "url"
+	EntityTypeEmail       EntityType = "email"
+	EntityTypeBold        EntityType = "bold"
+	EntityTypeItalic      EntityType = "italic"
+	EntityTypeCode        EntityType = "code"
+	EntityTypePre         EntityType = "pre"
+	EntityTypeTextLink    EntityType = "text_link"
+	EntityTypeTextMention EntityType = "text_mention"
+)
+
+// UpdateType is a kind of update, as used by allowed_updates.
+type UpdateType string
+
+// Constant values for the kinds of update, as used by allowed_updates
+const (
+	UpdateTypeMessage            UpdateType = "message"
+	UpdateTypeEditedMessage      UpdateType = "edited_message"
+	UpdateTypeChannelPost        UpdateType = "channel_post"
+	UpdateTypeEditedChannelPost  UpdateType = "edited_channel_post"
+	UpdateTypeInlineQuery        UpdateType = "inline_query"
+	UpdateTypeChosenInlineResult UpdateType = "chosen_inline_result"
+	UpdateTypeCallbackQuery      UpdateType = "callback_query"
+	UpdateTypePoll               UpdateType = "poll"
+	UpdateTypePollAnswer         UpdateType = "poll_answer"
+	UpdateTypeShippingQuery      UpdateType = "shipping_query"
+	UpdateTypePreCheckoutQuery   UpdateType = "pre_checkout_query"
+	UpdateTypeChatJoinRequest    UpdateType = "chat_join_request"
+	UpdateTypeMyChatMember       UpdateType = "my_chat_member"
+	UpdateTypeChatMember         UpdateType = "chat_member"
+	UpdateTypeChatBoost          UpdateType = "chat_boost"
+	UpdateTypeRemovedChatBoost   UpdateType = "removed_chat_boost"
+)
+
 // API errors
 const (
 	// ErrAPIForbidden happens when a token is bad
 	ErrAPIForbidden = "forbidden"
+	// ErrMessageNotModified happens when a message is edited to be the
+	// same as it already was
+	ErrMessageNotModified = "message is not modified"
+	// ErrDownloadStalled happens when a download receives no data for
+	// longer than DownloadLimits.StallTimeout
+	ErrDownloadStalled = "download stalled"
 )
 
 // Constant values for ParseMode in MessageConfig
 const (
-	ModeMarkdown = "Markdown"
-	ModeHTML     = "HTML"
+	ModeMarkdown   = "Markdown"
+	ModeMarkdownV2 = "MarkdownV2"
+	ModeHTML       = "HTML"
+	// ModeNone sends text as is, even when the bot has a
+	// DefaultParseMode. It is sent to Telegram as an empty parse mode.
+	ModeNone = "none"
 )
 
+// parseModeParam returns the parse_mode parameter for a parse mode, and
+// if it should be sent.
+func parseModeParam(mode string) (string, bool) {
+	if mode == ModeNone {
+		return "", true
+	}
+
+	return mode, mode != ""
+}
+
+// FileLinkValidity is how long a link to download a file is guaranteed
+// to be valid after calling getFile.
+const FileLinkValidity = time.Hour
+
+// fileLinkMargin is how long before FileLinkValidity ends a cached link
+// is replaced, so links handed out stay usable for a while.
+const fileLinkMargin = 10 * time.Minute
+
+// InlineResultsPerPage is the maximum number of results allowed in a
+// single answer to an inline query.
+const InlineResultsPerPage = 50
+
 // Library errors
 const (
 	// ErrBadFileType happens when you pass an unknown type
 	ErrBadFileType = "bad file type"
 	ErrBadURL      = "bad or empty url"
+	// ErrTooManyInlineResults happens when an InlineBuilder is given more
+	// than InlineResultsPerPage results
+	ErrTooManyInlineResults = "too many inline query results"
+	// ErrDuplicateInlineResultID happens when two results given to an
+	// InlineBuilder have the same ID
+	ErrDuplicateInlineResultID = "duplicate inline query result id"
+	// ErrInvalidHash happens when data signed by Telegram, such as from
+	// the Login Widget, does not match its hash
+	ErrInvalidHash = "data does not match its hash"
+	// ErrAuthExpired happens when data signed by Telegram is older than
+	// allowed
+	ErrAuthExpired = "authorization data has expired"
+	// ErrNoSession happens when Session is used by a handler not run
+	// through the Sessions middleware
+	ErrNoSession = "no session, as the Sessions middleware is not used"
+	// ErrAlbumTooSmall happens when an album has fewer than two items
+	ErrAlbumTooSmall = "albums need at least two items"
+	// ErrAlbumMixedMedia happens when an album mixes audio or documents
+	// with other kinds of media
+	ErrAlbumMixedMedia = "albums may only mix photos and videos"
+	// ErrBadAlbumMedia happens when an album item is not an InputMedia type
+	ErrBadAlbumMedia = "bad album media type"
+	// ErrChecksumNeedsReaderAt happens when DownloadFileParallel is asked
+	// to check a checksum but can't read back what it wrote
+	ErrChecksumNeedsReaderAt = "checksum requires dst to be an io.ReaderAt"
+	// ErrNoInlineKeyboard happens when SendAndWaitCallback is given a
+	// message without an inline keyboard to press
+	ErrNoInlineKeyboard = "message has no inline keyboard"
+	// ErrNoServers happens when a FailoverTransport is given no servers
+	ErrNoServers = "no servers to fail over between"
+	// ErrThumbNotUploaded happens when a thumbnail is a FileID or FileURL,
+	// as Telegram only accepts thumbnails which are uploaded
+	ErrThumbNotUploaded = "thumbnails must be uploaded"
+)
+
+// Request is a request to an API method, such as a Chattable config. Its
+// method and parameters can be read to log or change them, and sent with
+// MethodConfig.
+type Request interface {
+	Method() string
+	Params() (Params, error)
+}
+
+// Every config for a single request is a Request.
+var (
+	_ Request = MessageConfig{}
+	_ Request = ForwardConfig{}
+	_ Request = PhotoConfig{}
+	_ Request = AudioConfig{}
+	_ Request = DocumentConfig{}
+	_ Request = StickerConfig{}
+	_ Request = VideoConfig{}
+	_ Request = VideoNoteConfig{}
+	_ Request = AnimationConfig{}
+	_ Request = VoiceConfig{}
+	_ Request = LocationConfig{}
+	_ Request = VenueConfig{}
+	_ Request = PollConfig{}
+	_ Request = ContactConfig{}
+	_ Request = GameConfig{}
+	_ Request = SetGameScoreConfig{}
+	_ Request = GetGameHighScoresConfig{}
+	_ Request = ChatActionConfig{}
+	_ Request = EditMessageTextConfig{}
+	_ Request = EditMessageCaptionConfig{}
+	_ Request = EditMessageReplyMarkupConfig{}
+	_ Request = EditMessageMediaConfig{}
+	_ Request = MediaGroupConfig{}
+	_ Request = MethodConfig{}
+	_ Request = DeleteMessageConfig{}
+	_ Request = SetMessageReactionConfig{}
+	_ Request = UserProfilePhotosConfig{}
+	_ Request = FileConfig{}
+	_ Request = UpdateConfig{}
+	_ Request = WebhookConfig{}
+	_ Request = InlineConfig{}
+	_ Request = CallbackConfig{}
+	_ Request = ChatMemberConfig{}
+	_ Request = RestrictChatMemberConfig{}
+	_ Request = ChatConfig{}
+	_ Request = ChatConfigWithUser{}
+	_ Request = MyCommandsConfig{}
+	_ Request = InvoiceConfig{}
+	_ Request = ShippingConfig{}
+	_ Request = PreCheckoutConfig{}
 )
 
 // Chattable is any config type that can be sent.
 type Chattable interface {
+	Request
 	values() (url.Values, error)
-	method() string
+}
+
+// multiFileable is any config type that can be sent with any number of
+// files, which are referenced from its parameters with attach://.
+type multiFileable interface {
+	Chattable
+	files() (map[string]string, []RequestFile, error)
+}
+
+// editable is any config type that edits a message.
+type editable interface {
+	message() Message
+}
+
+// thumbnailer is any config type that can include a thumbnail for its
+// file.
+type thumbnailer interface {
+	thumbnail() RequestFileData
 }
 
 // Fileable is any config type that can be sent that includes a file.
@@ -67,38 +272,97 @@ type BaseChat struct {
 	ChatID              int64 // required
 	ChannelUsername     string
 	ReplyToMessageID    int
+	ReplyParameters     *ReplyParameters
 	ReplyMarkup         interface{}
 	DisableNotification bool
 }
 
 // values returns url.Values representation of BaseChat
 func (chat *BaseChat) values() (url.Values, error) {
-	v := url.Values{}
-	if chat.ChannelUsername != "" {
-		v.Add("chat_id", chat.ChannelUsername)
-	} else {
-		v.Add("chat_id", strconv.FormatInt(chat.ChatID, 10))
-	}
+	// Most configs add a few parameters of their own.
+	p := newParamBuilder(8)
+	err := chat.addValues(p)
+
+	return p.v, err
+}
+
+// addValues adds the parameters of BaseChat to p.
+func (chat *BaseChat) addValues(p *paramBuilder) error {
+	p.add("chat_id", chatIDParam(chat.ChatID, chat.ChannelUsername))
 
 	if chat.ReplyToMessageID != 0 {
-		v.Add("reply_to_message_id", strconv.Itoa(chat.ReplyToMessageID))
+		p.add("reply_to_message_id", strconv.Itoa(chat.ReplyToMessageID))
+	}
+
+	if chat.ReplyParameters != nil {
+		data, err := json.Marshal(chat.ReplyParameters)
+		if err != nil {
+			return err
+		}
+
+		p.add("reply_parameters", string(data))
 	}
 
 	if chat.ReplyMarkup != nil {
 		data, err := json.Marshal(chat.ReplyMarkup)
 		if err != nil {
-			return v, err
+			return err
 		}
 
-		v.Add("reply_markup", string(data))
+		p.add("reply_markup", string(data))
 	}
 
-	v.Add("disable_notification", strconv.FormatBool(chat.DisableNotification))
+	p.add("disable_notification", strconv.FormatBool(chat.DisableNotification))
 
-	return v, nil
+	return nil
+}
+
+// paramBuilder builds url.Values for the configs sent most often, with
+// one allocation for all of the values instead of one for each as with
+// url.Values.Add.
+type paramBuilder struct {
+	v      url.Values
+	values []string
+}
+
+// newParamBuilder creates a paramBuilder with room for n parameters.
+func newParamBuilder(n int) *paramBuilder {
+	return &paramBuilder{
+		v:      make(url.Values, n),
+		values: make([]string, 0, n),
+	}
+}
+
+// add adds a value to a parameter.
+func (p *paramBuilder) add(key, value string) {
+	if _, ok := p.v[key]; ok {
+		p.v.Add(key, value)
+		return
+	}
+
+	// Each parameter gets a slice of the shared values with no spare
+	// capacity, so adding to it later copies it instead of overwriting
+	// the next parameter's value.
+	p.values = append(p.values, value)
+	i := len(p.values) - 1
+	p.v[key] = p.values[i : i+1 : i+1]
+}
+
+// chatIDParam returns the chat_id parameter for a chat given by either
+// its ID or its @username. The username is used if it is set, which works
+// for channels and public supergroups.
+func chatIDParam(chatID int64, username string) string {
+	if username != "" {
+		return username
+	}
+
+	return strconv.FormatInt(chatID, 10)
 }
 
 // BaseFile is a base type for all file config types.
+//
+// File should be a RequestFileData. For compatibility, it may also be a
+// string path to a local file or a url.URL.
 type BaseFile struct {
 	BaseChat
 	File        interface{}
@@ -112,16 +376,21 @@ type BaseFile struct {
 func (file BaseFile) params() (map[string]string, error) {
 	params := make(map[string]string)
 
-	if file.ChannelUsername != "" {
-		params["chat_id"] = file.ChannelUsername
-	} else {
-		params["chat_id"] = strconv.FormatInt(file.ChatID, 10)
-	}
+	params["chat_id"] = chatIDParam(file.ChatID, file.ChannelUsername)
 
 	if file.ReplyToMessageID != 0 {
 		params["reply_to_message_id"] = strconv.Itoa(file.ReplyToMessageID)
 	}
 
+	if file.ReplyParameters != nil {
+		data, err := json.Marshal(file.ReplyParameters)
+		if err != nil {
+			return params, err
+		}
+
+		params["reply_parameters"] = string(data)
+	}
+
 	if file.ReplyMarkup != nil {
 		data, err := json.Marshal(file.ReplyMarkup)
 		if err != nil {
@@ -163,15 +432,23 @@ type BaseEdit struct {
 	ReplyMarkup     *InlineKeyboardMarkup
 }
 
+// message returns the message being edited, as far as it is known.
+func (edit BaseEdit) message() Message {
+	if edit.InlineMessageID != "" {
+		return Message{}
+	}
+
+	return Message{
+		MessageID: edit.MessageID,
+		Chat:      &Chat{ID: edit.ChatID, UserName: strings.TrimPrefix(edit.ChannelUsername, "@")},
+	}
+}
+
 func (edit BaseEdit) values() (url.Values, error) {
 	v := url.Values{}
 
 	if edit.InlineMessageID == "" {
-		if edit.ChannelUsername != "" {
-			v.Add("chat_id", edit.ChannelUsername)
-		} else {
-			v.Add("chat_id", strconv.FormatInt(edit.ChatID, 10))
-		}
+		v.Add("chat_id", chatIDParam(edit.ChatID, edit.ChannelUsername))
 		v.Add("message_id", strconv.Itoa(edit.MessageID))
 	} else {
 		v.Add("inline_message_id", edit.InlineMessageID)
@@ -193,29 +470,42 @@ type MessageConfig struct {
 	BaseChat
 	Text                  string
 	ParseMode             string
+	Entities              []MessageEntity
 	DisableWebPagePreview bool
 }
 
 // values returns a url.Values representation of MessageConfig.
 func (config MessageConfig) values() (url.Values, error) {
-	v, err := config.BaseChat.values()
-	if err != nil {
-		return v, err
+	p := newParamBuilder(8)
+	if err := config.BaseChat.addValues(p); err != nil {
+		return p.v, err
 	}
-	v.Add("text", config.Text)
-	v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
-	if config.ParseMode != "" {
-		v.Add("parse_mode", config.ParseMode)
+	p.add("text", config.Text)
+	p.add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
+	if mode, ok := parseModeParam(config.ParseMode); ok {
+		p.add("parse_mode", mode)
+	}
+	if len(config.Entities) > 0 {
+		data, err := json.Marshal(config.Entities)
+		if err != nil {
+			return p.v, err
+		}
+		p.add("entities", string(data))
 	}
 
-	return v, nil
+	return p.v, nil
 }
 
-// method returns Telegram API method name for sending Message.
-func (config MessageConfig) method() string {
+// Method returns Telegram API method name for sending Message.
+func (config MessageConfig) Method() string {
 	return "sendMessage"
 }
 
+// Params returns the parameters of MessageConfig.
+func (config MessageConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // ForwardConfig contains information about a ForwardMessage request.
 type ForwardConfig struct {
 	BaseChat
@@ -230,16 +520,21 @@ func (config ForwardConfig) values() (url.Values, error) {
 	if err != nil {
 		return v, err
 	}
-	v.Add("from_chat_id", strconv.FormatInt(config.FromChatID, 10))
+	v.Add("from_chat_id", chatIDParam(config.FromChatID, config.FromChannelUsername))
 	v.Add("message_id", strconv.Itoa(config.MessageID))
 	return v, nil
 }
 
-// method returns Telegram API method name for sending Forward.
-func (config ForwardConfig) method() string {
+// Method returns Telegram API method name for sending Forward.
+func (config ForwardConfig) Method() string {
 	return "forwardMessage"
 }
 
+// Params returns the parameters of ForwardConfig.
+func (config ForwardConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // PhotoConfig contains information about a SendPhoto request.
 type PhotoConfig struct {
 	BaseFile
@@ -276,11 +571,16 @@ func (config PhotoConfig) name() string {
 	return "photo"
 }
 
-// method returns Telegram API method name for sending Photo.
-func (config PhotoConfig) method() string {
+// Method returns Telegram API method name for sending Photo.
+func (config PhotoConfig) Method() string {
 	return "sendPhoto"
 }
 
+// Params returns the parameters of PhotoConfig.
+func (config PhotoConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
 // AudioConfig contains information about a SendAudio request.
 type AudioConfig struct {
 	BaseFile
@@ -288,6 +588,7 @@ type AudioConfig struct {
 	Duration  int
 	Performer string
 	Title     string
+	Thumb     RequestFileData // optional, must be uploaded
 }
 
 // values returns a url.Values representation of AudioConfig.
@@ -341,14 +642,25 @@ func (config AudioConfig) name() string {
 	return "audio"
 }
 
-// method returns Telegram API method name for sending Audio.
-func (config AudioConfig) method() string {
+// Method returns Telegram API method name for sending Audio.
+func (config AudioConfig) Method() string {
 	return "sendAudio"
 }
 
+// Params returns the parameters of AudioConfig.
+func (config AudioConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
+func (config AudioConfig) thumbnail() RequestFileData {
+	return config.Thumb
+}
+
 // DocumentConfig contains information about a SendDocument request.
 type DocumentConfig struct {
 	BaseFile
+	Caption string
+	Thumb   RequestFileData // optional, must be uploaded
 }
 
 // values returns a url.Values representation of DocumentConfig.
@@ -359,6 +671,9 @@ func (config DocumentConfig) values() (url.Values, error) {
 	}
 
 	v.Add(config.name(), config.FileID)
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
 
 	return v, nil
 }
@@ -367,6 +682,10 @@ func (config DocumentConfig) values() (url.Values, error) {
 func (config DocumentConfig) params() (map[string]string, error) {
 	params, _ := config.BaseFile.params()
 
+	if config.Caption != "" {
+		params["caption"] = config.Caption
+	}
+
 	return params, nil
 }
 
@@ -375,11 +694,20 @@ func (config DocumentConfig) name() string {
 	return "document"
 }
 
-// method returns Telegram API method name for sending Document.
-func (config DocumentConfig) method() string {
+// Method returns Telegram API method name for sending Document.
+func (config DocumentConfig) Method() string {
 	return "sendDocument"
 }
 
+// Params returns the parameters of DocumentConfig.
+func (config DocumentConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
+func (config DocumentConfig) thumbnail() RequestFileData {
+	return config.Thumb
+}
+
 // StickerConfig contains information about a SendSticker request.
 type StickerConfig struct {
 	BaseFile
@@ -409,16 +737,22 @@ func (config StickerConfig) name() string {
 	return "sticker"
 }
 
-// method returns Telegram API method name for sending Sticker.
-func (config StickerConfig) method() string {
+// Method returns Telegram API method name for sending Sticker.
+func (config StickerConfig) Method() string {
 	return "sendSticker"
 }
 
+// Params returns the parameters of StickerConfig.
+func (config StickerConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
 // VideoConfig contains information about a SendVideo request.
 type VideoConfig struct {
 	BaseFile
 	Duration int
 	Caption  string
+	Thumb    RequestFileData // optional, must be uploaded
 }
 
 // values returns a url.Values representation of VideoConfig.
@@ -443,6 +777,13 @@ func (config VideoConfig) values() (url.Values, error) {
 func (config VideoConfig) params() (map[string]string, error) {
 	params, _ := config.BaseFile.params()
 
+	if config.Duration != 0 {
+		params["duration"] = strconv.Itoa(config.Duration)
+	}
+	if config.Caption != "" {
+		params["caption"] = config.Caption
+	}
+
 	return params, nil
 }
 
@@ -451,11 +792,155 @@ func (config VideoConfig) name() string {
 	return "video"
 }
 
-// method returns Telegram API method name for sending Video.
-func (config VideoConfig) method() string {
+// Method returns Telegram API method name for sending Video.
+func (config VideoConfig) Method() string {
 	return "sendVideo"
 }
 
+// Params returns the parameters of VideoConfig.
+func (config VideoConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
+func (config VideoConfig) thumbnail() RequestFileData {
+	return config.Thumb
+}
+
+// VideoNoteConfig contains information about a SendVideoNote request, for
+// a rounded square video of up to a minute.
+type VideoNoteConfig struct {
+	BaseFile
+	Duration int
+	Length   int             // optional, the video's width and height
+	Thumb    RequestFileData // optional, must be uploaded
+}
+
+// values returns a url.Values representation of VideoNoteConfig.
+func (config VideoNoteConfig) values() (url.Values, error) {
+	v, err := config.BaseChat.values()
+	if err != nil {
+		return v, err
+	}
+
+	v.Add(config.name(), config.FileID)
+	if config.Duration != 0 {
+		v.Add("duration", strconv.Itoa(config.Duration))
+	}
+	if config.Length != 0 {
+		v.Add("length", strconv.Itoa(config.Length))
+	}
+
+	return v, nil
+}
+
+// params returns a map[string]string representation of VideoNoteConfig.
+func (config VideoNoteConfig) params() (map[string]string, error) {
+	params, _ := config.BaseFile.params()
+
+	if config.Duration != 0 {
+		params["duration"] = strconv.Itoa(config.Duration)
+	}
+	if config.Length != 0 {
+		params["length"] = strconv.Itoa(config.Length)
+	}
+
+	return params, nil
+}
+
+// name returns the field name for the VideoNote.
+func (config VideoNoteConfig) name() string {
+	return "video_note"
+}
+
+// Method returns Telegram API method name for sending VideoNote.
+func (config VideoNoteConfig) Method() string {
+	return "sendVideoNote"
+}
+
+// Params returns the parameters of VideoNoteConfig.
+func (config VideoNoteConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
+func (config VideoNoteConfig) thumbnail() RequestFileData {
+	return config.Thumb
+}
+
+// AnimationConfig contains information about a SendAnimation request,
+// for a GIF or an H.264/MPEG-4 AVC video without sound.
+type AnimationConfig struct {
+	BaseFile
+	Duration int
+	Width    int
+	Height   int
+	Caption  string
+	Thumb    RequestFileData // optional, must be uploaded
+}
+
+// values returns a url.Values representation of AnimationConfig.
+func (config AnimationConfig) values() (url.Values, error) {
+	v, err := config.BaseChat.values()
+	if err != nil {
+		return v, err
+	}
+
+	v.Add(config.name(), config.FileID)
+	for key, value := range config.extraParams() {
+		v.Add(key, value)
+	}
+
+	return v, nil
+}
+
+// params returns a map[string]string representation of AnimationConfig.
+func (config AnimationConfig) params() (map[string]string, error) {
+	params, _ := config.BaseFile.params()
+
+	for key, value := range config.extraParams() {
+		params[key] = value
+	}
+
+	return params, nil
+}
+
+func (config AnimationConfig) extraParams() map[string]string {
+	params := make(map[string]string)
+
+	if config.Duration != 0 {
+		params["duration"] = strconv.Itoa(config.Duration)
+	}
+	if config.Width != 0 {
+		params["width"] = strconv.Itoa(config.Width)
+	}
+	if config.Height != 0 {
+		params["height"] = strconv.Itoa(config.Height)
+	}
+	if config.Caption != "" {
+		params["caption"] = config.Caption
+	}
+
+	return params
+}
+
+// name returns the field name for the Animation.
+func (config AnimationConfig) name() string {
+	return "animation"
+}
+
+// Method returns Telegram API method name for sending Animation.
+func (config AnimationConfig) Method() string {
+	return "sendAnimation"
+}
+
+// Params returns the parameters of AnimationConfig.
+func (config AnimationConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
+func (config AnimationConfig) thumbnail() RequestFileData {
+	return config.Thumb
+}
+
 // VoiceConfig contains information about a SendVoice request.
 type VoiceConfig struct {
 	BaseFile
@@ -474,6 +959,9 @@ func (config VoiceConfig) values() (url.Values, error) {
 	if config.Duration != 0 {
 		v.Add("duration", strconv.Itoa(config.Duration))
 	}
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
 
 	return v, nil
 }
@@ -485,6 +973,9 @@ func (config VoiceConfig) params() (map[string]string, error) {
 	if config.Duration != 0 {
 		params["duration"] = strconv.Itoa(config.Duration)
 	}
+	if config.Caption != "" {
+		params["caption"] = config.Caption
+	}
 
 	return params, nil
 }
@@ -494,11 +985,16 @@ func (config VoiceConfig) name() string {
 	return "voice"
 }
 
-// method returns Telegram API method name for sending Voice.
-func (config VoiceConfig) method() string {
+// Method returns Telegram API method name for sending Voice.
+func (config VoiceConfig) Method() string {
 	return "sendVoice"
 }
 
+// Params returns the parameters of VoiceConfig.
+func (config VoiceConfig) Params() (Params, error) {
+	return fileableParams(config)
+}
+
 // LocationConfig contains information about a SendLocation request.
 type LocationConfig struct {
 	BaseChat
@@ -519,19 +1015,27 @@ func (config LocationConfig) values() (url.Values, error) {
 	return v, nil
 }
 
-// method returns Telegram API method name for sending Location.
-func (config LocationConfig) method() string {
+// Method returns Telegram API method name for sending Location.
+func (config LocationConfig) Method() string {
 	return "sendLocation"
 }
 
+// Params returns the parameters of LocationConfig.
+func (config LocationConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // VenueConfig contains information about a SendVenue request.
 type VenueConfig struct {
 	BaseChat
-	Latitude     float64 // required
-	Longitude    float64 // required
-	Title        string  // required
-	Address      string  // required
-	FoursquareID string
+	Latitude        float64 // required
+	Longitude       float64 // required
+	Title           string  // required
+	Address         string  // required
+	FoursquareID    string
+	FoursquareType  string
+	GooglePlaceID   string
+	GooglePlaceType string
 }
 
 func (config VenueConfig) values() (url.Values, error) {
@@ -547,20 +1051,85 @@ func (config VenueConfig) values() (url.Values, error) {
 	if config.FoursquareID != "" {
 		v.Add("foursquare_id", config.FoursquareID)
 	}
+	if config.FoursquareType != "" {
+		v.Add("foursquare_type", config.FoursquareType)
+	}
+	if config.GooglePlaceID != "" {
+		v.Add("google_place_id", config.GooglePlaceID)
+	}
+	if config.GooglePlaceType != "" {
+		v.Add("google_place_type", config.GooglePlaceType)
+	}
 
 	return v, nil
 }
 
-func (config VenueConfig) method() string {
+// Method returns the Telegram API method name of VenueConfig.
+func (config VenueConfig) Method() string {
 	return "sendVenue"
 }
 
+// Params returns the parameters of VenueConfig.
+func (config VenueConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
+// PollConfig allows you to send a poll.
+type PollConfig struct {
+	BaseChat
+	Question              string   // required
+	Options               []string // required
+	IsAnonymous           bool
+	Type                  string
+	AllowsMultipleAnswers bool
+	CorrectOptionID       int // required for quizzes
+	IsClosed              bool
+}
+
+func (config PollConfig) values() (url.Values, error) {
+	v, err := config.BaseChat.values()
+	if err != nil {
+		return v, err
+	}
+
+	v.Add("question", config.Question)
+
+	data, err := json.Marshal(config.Options)
+	if err != nil {
+		return v, err
+	}
+	v.Add("options", string(data))
+
+	v.Add("is_anonymous", strconv.FormatBool(config.IsAnonymous))
+	if config.Type != "" {
+		v.Add("type", config.Type)
+	}
+	v.Add("allows_multiple_answers", strconv.FormatBool(config.AllowsMultipleAnswers))
+	if config.Type == "quiz" {
+		v.Add("correct_option_id", strconv.Itoa(config.CorrectOptionID))
+	}
+	v.Add("is_closed", strconv.FormatBool(config.IsClosed))
+
+	return v, nil
+}
+
+// Method returns the Telegram API method name of PollConfig.
+func (config PollConfig) Method() string {
+	return "sendPoll"
+}
+
+// Params returns the parameters of PollConfig.
+func (config PollConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // ContactConfig allows you to send a contact.
 type ContactConfig struct {
 	BaseChat
 	PhoneNumber string
 	FirstName   string
 	LastName    string
+	VCard       string
 }
 
 func (config ContactConfig) values() (url.Values, error) {
@@ -572,14 +1141,23 @@ func (config ContactConfig) values() (url.Values, error) {
 	v.Add("phone_number", config.PhoneNumber)
 	v.Add("first_name", config.FirstName)
 	v.Add("last_name", config.LastName)
+	if config.VCard != "" {
+		v.Add("vcard", config.VCard)
+	}
 
 	return v, nil
 }
 
-func (config ContactConfig) method() string {
+// Method returns the Telegram API method name of ContactConfig.
+func (config ContactConfig) Method() string {
 	return "sendContact"
 }
 
+// Params returns the parameters of ContactConfig.
+func (config ContactConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // GameConfig allows you to send a game.
 type GameConfig struct {
 	BaseChat
@@ -597,17 +1175,23 @@ func (config GameConfig) values() (url.Values, error) {
 	return v, nil
 }
 
-func (config GameConfig) method() string {
+// Method returns the Telegram API method name of GameConfig.
+func (config GameConfig) Method() string {
 	return "sendGame"
 }
 
+// Params returns the parameters of GameConfig.
+func (config GameConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // SetGameScoreConfig allows you to update the game score in a chat.
 type SetGameScoreConfig struct {
-	UserID             int
+	UserID             int64
 	Score              int
 	Force              bool
 	DisableEditMessage bool
-	ChatID             int
+	ChatID             int64
 	ChannelUsername    string
 	MessageID          int
 	InlineMessageID    string
@@ -616,14 +1200,10 @@ type SetGameScoreConfig struct {
 func (config SetGameScoreConfig) values() (url.Values, error) {
 	v := url.Values{}
 
-	v.Add("user_id", strconv.Itoa(config.UserID))
+	v.Add("user_id", strconv.FormatInt(config.UserID, 10))
 	v.Add("score", strconv.Itoa(config.Score))
 	if config.InlineMessageID == "" {
-		if config.ChannelUsername == "" {
-			v.Add("chat_id", strconv.Itoa(config.ChatID))
-		} else {
-			v.Add("chat_id", config.ChannelUsername)
-		}
+		v.Add("chat_id", chatIDParam(config.ChatID, config.ChannelUsername))
 		v.Add("message_id", strconv.Itoa(config.MessageID))
 	} else {
 		v.Add("inline_message_id", config.InlineMessageID)
@@ -633,14 +1213,20 @@ func (config SetGameScoreConfig) values() (url.Values, error) {
 	return v, nil
 }
 
-func (config SetGameScoreConfig) method() string {
+// Method returns the Telegram API method name of SetGameScoreConfig.
+func (config SetGameScoreConfig) Method() string {
 	return "setGameScore"
 }
 
+// Params returns the parameters of SetGameScoreConfig.
+func (config SetGameScoreConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // GetGameHighScoresConfig allows you to fetch the high scores for a game.
 type GetGameHighScoresConfig struct {
-	UserID          int
-	ChatID          int
+	UserID          int64
+	ChatID          int64
 	ChannelUsername string
 	MessageID       int
 	InlineMessageID string
@@ -649,13 +1235,9 @@ type GetGameHighScoresConfig struct {
 func (config GetGameHighScoresConfig) values() (url.Values, error) {
 	v := url.Values{}
 
-	v.Add("user_id", strconv.Itoa(config.UserID))
+	v.Add("user_id", strconv.FormatInt(config.UserID, 10))
 	if config.InlineMessageID == "" {
-		if config.ChannelUsername == "" {
-			v.Add("chat_id", strconv.Itoa(config.ChatID))
-		} else {
-			v.Add("chat_id", config.ChannelUsername)
-		}
+		v.Add("chat_id", chatIDParam(config.ChatID, config.ChannelUsername))
 		v.Add("message_id", strconv.Itoa(config.MessageID))
 	} else {
 		v.Add("inline_message_id", config.InlineMessageID)
@@ -664,31 +1246,53 @@ func (config GetGameHighScoresConfig) values() (url.Values, error) {
 	return v, nil
 }
 
-func (config GetGameHighScoresConfig) method() string {
+// Method returns the Telegram API method name of GetGameHighScoresConfig.
+func (config GetGameHighScoresConfig) Method() string {
 	return "getGameHighScores"
 }
 
+// Params returns the parameters of GetGameHighScoresConfig.
+func (config GetGameHighScoresConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // ChatActionConfig contains information about a SendChatAction request.
+//
+// MessageThreadID shows the action in a forum topic, and
+// BusinessConnectionID in a chat managed through a business connection.
 type ChatActionConfig struct {
 	BaseChat
-	Action string // required
+	Action               string // required
+	MessageThreadID      int
+	BusinessConnectionID string
 }
 
 // values returns a url.Values representation of ChatActionConfig.
 func (config ChatActionConfig) values() (url.Values, error) {
-	v, err := config.BaseChat.values()
-	if err != nil {
-		return v, err
+	p := newParamBuilder(8)
+	if err := config.BaseChat.addValues(p); err != nil {
+		return p.v, err
 	}
-	v.Add("action", config.Action)
-	return v, nil
+	p.add("action", config.Action)
+	if config.MessageThreadID != 0 {
+		p.add("message_thread_id", strconv.Itoa(config.MessageThreadID))
+	}
+	if config.BusinessConnectionID != "" {
+		p.add("business_connection_id", config.BusinessConnectionID)
+	}
+	return p.v, nil
 }
 
-// method returns Telegram API method name for sending ChatAction.
-func (config ChatActionConfig) method() string {
+// Method returns Telegram API method name for sending ChatAction.
+func (config ChatActionConfig) Method() string {
 	return "sendChatAction"
 }
 
+// Params returns the parameters of ChatActionConfig.
+func (config ChatActionConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // EditMessageTextConfig allows you to modify the text in a message.
 type EditMessageTextConfig struct {
 	BaseEdit
@@ -704,16 +1308,24 @@ func (config EditMessageTextConfig) values() (url.Values, error) {
 	}
 
 	v.Add("text", config.Text)
-	v.Add("parse_mode", config.ParseMode)
+	if mode, ok := parseModeParam(config.ParseMode); ok {
+		v.Add("parse_mode", mode)
+	}
 	v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
 
 	return v, nil
 }
 
-func (config EditMessageTextConfig) method() string {
+// Method returns the Telegram API method name of EditMessageTextConfig.
+func (config EditMessageTextConfig) Method() string {
 	return "editMessageText"
 }
 
+// Params returns the parameters of EditMessageTextConfig.
+func (config EditMessageTextConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // EditMessageCaptionConfig allows you to modify the caption of a message.
 type EditMessageCaptionConfig struct {
 	BaseEdit
@@ -728,10 +1340,16 @@ func (config EditMessageCaptionConfig) values() (url.Values, error) {
 	return v, nil
 }
 
-func (config EditMessageCaptionConfig) method() string {
+// Method returns the Telegram API method name of EditMessageCaptionConfig.
+func (config EditMessageCaptionConfig) Method() string {
 	return "editMessageCaption"
 }
 
+// Params returns the parameters of EditMessageCaptionConfig.
+func (config EditMessageCaptionConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // EditMessageReplyMarkupConfig allows you to modify the reply markup
 // of a message.
 type EditMessageReplyMarkupConfig struct {
@@ -742,28 +1360,449 @@ func (config EditMessageReplyMarkupConfig) values() (url.Values, error) {
 	return config.BaseEdit.values()
 }
 
-func (config EditMessageReplyMarkupConfig) method() string {
+// Method returns the Telegram API method name of EditMessageReplyMarkupConfig.
+func (config EditMessageReplyMarkupConfig) Method() string {
 	return "editMessageReplyMarkup"
 }
 
+// Params returns the parameters of EditMessageReplyMarkupConfig.
+func (config EditMessageReplyMarkupConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
+// EditMessageMediaConfig allows you to replace the media of a message.
+//
+// Media should be an InputMediaPhoto, InputMediaVideo, InputMediaAudio
+// or InputMediaDocument.
+type EditMessageMediaConfig struct {
+	BaseEdit
+	Media interface{}
+}
+
+func (config EditMessageMediaConfig) values() (url.Values, error) {
+	params, _, err := config.files()
+
+	return paramsToValues(params), err
+}
+
+func (config EditMessageMediaConfig) files() (map[string]string, []RequestFile, error) {
+	v, err := config.BaseEdit.values()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	media, files, err := prepareInputMedia(config.Media, "file-0")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := json.Marshal(media)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := valuesToParams(v)
+	params["media"] = string(data)
+
+	return params, files, nil
+}
+
+// Method returns the Telegram API method name of EditMessageMediaConfig.
+func (config EditMessageMediaConfig) Method() string {
+	return "editMessageMedia"
+}
+
+// Params returns the parameters of EditMessageMediaConfig.
+func (config EditMessageMediaConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
+// MediaGroupConfig contains information about a SendMediaGroup request.
+//
+// Media should contain InputMediaPhoto and InputMediaVideo. ReplyMarkup
+// is not supported for media groups.
+type MediaGroupConfig struct {
+	BaseChat
+	Media []interface{}
+}
+
+// values returns a url.Values representation of MediaGroupConfig.
+func (config MediaGroupConfig) values() (url.Values, error) {
+	params, _, err := config.files()
+
+	return paramsToValues(params), err
+}
+
+// files returns the parameters of MediaGroupConfig, and the files in it
+// which need to be uploaded.
+func (config MediaGroupConfig) files() (map[string]string, []RequestFile, error) {
+	v, err := config.BaseChat.values()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []RequestFile
+	media := make([]interface{}, len(config.Media))
+	for i, m := range config.Media {
+		var f []RequestFile
+		media[i], f, err = prepareInputMedia(m, "file-"+strconv.Itoa(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, f...)
+	}
+
+	data, err := json.Marshal(media)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := valuesToParams(v)
+	params["media"] = string(data)
+
+	return params, files, nil
+}
+
+// Method returns Telegram API method name for sending a media group.
+func (config MediaGroupConfig) Method() string {
+	return "sendMediaGroup"
+}
+
+// Params returns the parameters of MediaGroupConfig.
+func (config MediaGroupConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
+// prepareInputMedia replaces media which needs uploading with a reference
+// to attach://name, returning the media and the files to upload with it.
+func prepareInputMedia(media interface{}, name string) (interface{}, []RequestFile, error) {
+	switch m := media.(type) {
+	case InputMediaPhoto:
+		files := attachInputMedia(&m.BaseInputMedia, name)
+		return m, files, nil
+	case InputMediaVideo:
+		files := attachInputMedia(&m.BaseInputMedia, name)
+		thumb, err := attachThumb(&m.Thumb, name+"-thumb")
+		return m, append(files, thumb...), err
+	case InputMediaAudio:
+		files := attachInputMedia(&m.BaseInputMedia, name)
+		thumb, err := attachThumb(&m.Thumb, name+"-thumb")
+		return m, append(files, thumb...), err
+	case InputMediaDocument:
+		files := attachInputMedia(&m.BaseInputMedia, name)
+		thumb, err := attachThumb(&m.Thumb, name+"-thumb")
+		return m, append(files, thumb...), err
+	}
+
+	return media, nil, nil
+}
+
+func attachInputMedia(media *BaseInputMedia, name string) []RequestFile {
+	return attachFile(&media.Media, name)
+}
+
+// attachFile replaces a file which needs uploading with a reference to
+// attach://name, returning the file to upload.
+func attachFile(file *RequestFileData, name string) []RequestFile {
+	if *file == nil || !(*file).NeedsUpload() {
+		return nil
+	}
+
+	attached := RequestFile{Name: name, Data: *file}
+	*file = fileAttach("attach://" + name)
+
+	return []RequestFile{attached}
+}
+
+// attachThumb is attachFile for a thumbnail, which can only be uploaded,
+// so one which isn't is an error rather than being sent as is.
+func attachThumb(thumb *RequestFileData, name string) ([]RequestFile, error) {
+	if *thumb != nil && !(*thumb).NeedsUpload() {
+		return nil, errors.New(ErrThumbNotUploaded)
+	}
+
+	return attachFile(thumb, name), nil
+}
+
+// Params are the parameters of a request, by name. Objects such as reply
+// markup are encoded as JSON.
+type Params map[string]string
+
+// chattableParams returns the parameters of a Chattable. Files to upload
+// are not included.
+func chattableParams(c Chattable) (Params, error) {
+	v, err := c.values()
+	if err != nil {
+		return nil, err
+	}
+
+	return valuesToParams(v), nil
+}
+
+// fileableParams returns the parameters of a Fileable as they are sent.
+// A file which isn't uploaded, such as a FileID or FileURL, is a
+// parameter. One which is uploaded is not, as it is sent as a file named
+// after its parameter, but an uploaded thumbnail is referenced from the
+// thumb parameter as attach://file-thumb.
+func fileableParams(config Fileable) (Params, error) {
+	if config.useExistingFile() {
+		return chattableParams(config)
+	}
+
+	params, err := config.params()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := newRequestFileData(config.getFile())
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fileableFiles(config, file, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		if !f.Data.NeedsUpload() {
+			params[f.Name] = f.Data.SendData()
+		}
+	}
+
+	return params, nil
+}
+
+// MethodConfig is a request to any method with the given parameters, such
+// as one not yet supported by this library, or a config's Params after
+// changing them. It can be sent with Send if the method returns a
+// Message, or with CallMethod to decode any other result.
+type MethodConfig struct {
+	Name       string
+	Parameters Params
+}
+
+// NewMethodConfig creates a request to a method with parameters.
+func NewMethodConfig(name string, params Params) MethodConfig {
+	return MethodConfig{
+		Name:       name,
+		Parameters: params,
+	}
+}
+
+func (config MethodConfig) values() (url.Values, error) {
+	return paramsToValues(config.Parameters), nil
+}
+
+// Method returns the name of the method.
+func (config MethodConfig) Method() string {
+	return config.Name
+}
+
+// Params returns a copy of the parameters.
+func (config MethodConfig) Params() (Params, error) {
+	params := make(Params, len(config.Parameters))
+	for key, value := range config.Parameters {
+		params[key] = value
+	}
+
+	return params, nil
+}
+
+func valuesToParams(v url.Values) map[string]string {
+	params := make(map[string]string, len(v))
+	for key := range v {
+		params[key] = v.Get(key)
+	}
+
+	return params
+}
+
+func paramsToValues(params map[string]string) url.Values {
+	v := url.Values{}
+	for key, value := range params {
+		v.Add(key, value)
+	}
+
+	return v
+}
+
+// DeleteMessageConfig contains information about deleting a message.
+type DeleteMessageConfig struct {
+	ChatID          int64
+	ChannelUsername string
+	MessageID       int
+}
+
+func (config DeleteMessageConfig) values() (url.Values, error) {
+	v := url.Values{}
+
+	v.Add("chat_id", chatIDParam(config.ChatID, config.ChannelUsername))
+	v.Add("message_id", strconv.Itoa(config.MessageID))
+
+	return v, nil
+}
+
+// Method returns the Telegram API method name of DeleteMessageConfig.
+func (config DeleteMessageConfig) Method() string {
+	return "deleteMessage"
+}
+
+// Params returns the parameters of DeleteMessageConfig.
+func (config DeleteMessageConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
+// ReactionType is a reaction to a message.
+type ReactionType struct {
+	Type  string `json:"type"` // "emoji"
+	Emoji string `json:"emoji,omitempty"`
+}
+
+// SetMessageReactionConfig contains information about changing the bot's
+// reactions to a message.
+type SetMessageReactionConfig struct {
+	ChatID          int64
+	ChannelUsername string
+	MessageID       int
+	Reaction        []ReactionType // an empty list removes the reactions
+	IsBig           bool
+}
+
+func (config SetMessageReactionConfig) values() (url.Values, error) {
+	v := url.Values{}
+
+	v.Add("chat_id", chatIDParam(config.ChatID, config.ChannelUsername))
+	v.Add("message_id", strconv.Itoa(config.MessageID))
+
+	reaction := config.Reaction
+	if reaction == nil {
+		reaction = []ReactionType{}
+	}
+	data, err := json.Marshal(reaction)
+	if err != nil {
+		return v, err
+	}
+	v.Add("reaction", string(data))
+
+	if config.IsBig {
+		v.Add("is_big", strconv.FormatBool(config.IsBig))
+	}
+
+	return v, nil
+}
+
+// Method returns the Telegram API method name of SetMessageReactionConfig.
+func (config SetMessageReactionConfig) Method() string {
+	return "setMessageReaction"
+}
+
+// Params returns the parameters of SetMessageReactionConfig.
+func (config SetMessageReactionConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
 // UserProfilePhotosConfig contains information about a
 // GetUserProfilePhotos request.
 type UserProfilePhotosConfig struct {
-	UserID int
+	UserID int64
 	Offset int
 	Limit  int
 }
 
+// values returns a url.Values representation of UserProfilePhotosConfig.
+func (config UserProfilePhotosConfig) values() url.Values {
+	v := url.Values{}
+	v.Add("user_id", strconv.FormatInt(config.UserID, 10))
+	if config.Offset != 0 {
+		v.Add("offset", strconv.Itoa(config.Offset))
+	}
+	if config.Limit != 0 {
+		v.Add("limit", strconv.Itoa(config.Limit))
+	}
+
+	return v
+}
+
+// Method returns Telegram API method name for getting profile photos.
+func (config UserProfilePhotosConfig) Method() string {
+	return "getUserProfilePhotos"
+}
+
+// Params returns the parameters of UserProfilePhotosConfig.
+func (config UserProfilePhotosConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
 // FileConfig has information about a file hosted on Telegram.
 type FileConfig struct {
 	FileID string
 }
 
+// values returns a url.Values representation of FileConfig.
+func (config FileConfig) values() url.Values {
+	v := url.Values{}
+	v.Add("file_id", config.FileID)
+
+	return v
+}
+
+// Method returns Telegram API method name for getting a file.
+func (config FileConfig) Method() string {
+	return "getFile"
+}
+
+// Params returns the parameters of FileConfig.
+func (config FileConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
 // UpdateConfig contains information about a GetUpdates request.
 type UpdateConfig struct {
-	Offset  int
-	Limit   int
+	// Offset is the ID of the first update to get. Updates before it are
+	// confirmed, so they won't be returned again.
+	Offset int
+	// Limit is the most updates to get at once, from 1 to 100. If it is
+	// zero, Telegram's default of 100 is used. A lower limit keeps less
+	// in memory when updates have large messages.
+	Limit int
+	// Timeout is how many seconds to wait for updates when there are
+	// none, for long polling.
 	Timeout int
+
+	// AdaptiveTimeout makes GetUpdatesChan shorten Timeout while updates
+	// are arriving, and lengthen it back up to Timeout while idle.
+	AdaptiveTimeout bool
+
+	// OffsetStore, if set, is used by GetUpdatesChan to save the offset
+	// after each batch of updates and to resume from it when started.
+	OffsetStore KeyValueStore
+}
+
+// values returns a url.Values representation of UpdateConfig.
+func (config UpdateConfig) values() url.Values {
+	v := url.Values{}
+	if config.Offset != 0 {
+		v.Add("offset", strconv.Itoa(config.Offset))
+	}
+	if config.Limit > 0 {
+		v.Add("limit", strconv.Itoa(config.Limit))
+	}
+	if config.Timeout > 0 {
+		v.Add("timeout", strconv.Itoa(config.Timeout))
+	}
+
+	return v
+}
+
+// Method returns Telegram API method name for getting updates.
+func (config UpdateConfig) Method() string {
+	return "getUpdates"
+}
+
+// Params returns the parameters of UpdateConfig.
+func (config UpdateConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
 }
 
 // WebhookConfig contains information about a SetWebhook request.
@@ -771,6 +1810,54 @@ type WebhookConfig struct {
 	URL            *url.URL
 	Certificate    interface{}
 	MaxConnections int
+	// SecretToken is sent by Telegram with each update, so the bot can
+	// check they are from Telegram with WebhookSecretToken.
+	SecretToken string
+}
+
+// values returns a url.Values representation of WebhookConfig, without
+// the Certificate.
+func (config WebhookConfig) values() url.Values {
+	v := url.Values{}
+	v.Add("url", config.URL.String())
+	if config.MaxConnections != 0 {
+		v.Add("max_connections", strconv.Itoa(config.MaxConnections))
+	}
+	if config.SecretToken != "" {
+		v.Add("secret_token", config.SecretToken)
+	}
+
+	return v
+}
+
+// Method returns Telegram API method name for setting a webhook.
+func (config WebhookConfig) Method() string {
+	return "setWebhook"
+}
+
+// Params returns the parameters of WebhookConfig. The Certificate is not
+// a parameter, as it is uploaded as a file named certificate.
+func (config WebhookConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
+// RequestFileData is a file to send with a request. It is either
+// uploaded with the request, or referenced by a URL or file_id which is
+// sent as a parameter.
+type RequestFileData interface {
+	// NeedsUpload returns if the file must be uploaded.
+	NeedsUpload() bool
+	// UploadData returns the name and contents of a file to upload.
+	UploadData() (string, io.Reader, error)
+	// SendData returns the parameter value for a file not uploaded.
+	SendData() string
+}
+
+// RequestFile is a file to send with a request, and the name of the
+// parameter it is sent as.
+type RequestFile struct {
+	Name string
+	Data RequestFileData
 }
 
 // FileBytes contains information about a set of bytes to upload
@@ -780,13 +1867,153 @@ type FileBytes struct {
 	Bytes []byte
 }
 
+// NeedsUpload returns true, as bytes must be uploaded.
+func (fb FileBytes) NeedsUpload() bool {
+	return true
+}
+
+// UploadData returns the name and contents of the file.
+func (fb FileBytes) UploadData() (string, io.Reader, error) {
+	return fb.Name, bytes.NewReader(fb.Bytes), nil
+}
+
+// SendData is not used, as FileBytes must be uploaded.
+func (fb FileBytes) SendData() string {
+	panic("FileBytes must be uploaded")
+}
+
 // FileReader contains information about a reader to upload as a File.
-// If Size is -1, it will read the entire Reader into memory to
-// calculate a Size.
+//
+// Size is the number of bytes the Reader contains, or -1 if unknown.
+// ContentType is sent as the file's type, if set.
 type FileReader struct {
-	Name   string
-	Reader io.Reader
-	Size   int64
+	Name        string
+	Reader      io.Reader
+	Size        int64
+	ContentType string
+}
+
+// NeedsUpload returns true, as readers must be uploaded.
+func (fr FileReader) NeedsUpload() bool {
+	return true
+}
+
+// UploadData returns the name and contents of the file.
+func (fr FileReader) UploadData() (string, io.Reader, error) {
+	return fr.Name, fr.Reader, nil
+}
+
+// SendData is not used, as FileReader must be uploaded.
+func (fr FileReader) SendData() string {
+	panic("FileReader must be uploaded")
+}
+
+// FilePath is the path to a local file to upload.
+type FilePath string
+
+// NeedsUpload returns true, as local files must be uploaded.
+func (fp FilePath) NeedsUpload() bool {
+	return true
+}
+
+// UploadData opens the file. It is closed once it has been uploaded.
+func (fp FilePath) UploadData() (string, io.Reader, error) {
+	fileHandle, err := os.Open(string(fp))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fileHandle.Name(), fileHandle, nil
+}
+
+// SendData is not used, as FilePath must be uploaded.
+func (fp FilePath) SendData() string {
+	panic("FilePath must be uploaded")
+}
+
+// FileURL is the URL of a file Telegram should download and send. It
+// must be an http or https URL, as Telegram fetches it itself.
+type FileURL string
+
+// validate checks that the URL is one Telegram is able to fetch.
+func (fu FileURL) validate() error {
+	u, err := url.Parse(string(fu))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New(ErrBadURL)
+	}
+
+	return nil
+}
+
+// NeedsUpload returns false, as Telegram downloads the file itself.
+func (fu FileURL) NeedsUpload() bool {
+	return false
+}
+
+// UploadData is not used, as FileURL is not uploaded.
+func (fu FileURL) UploadData() (string, io.Reader, error) {
+	panic("FileURL cannot be uploaded")
+}
+
+// SendData returns the URL.
+func (fu FileURL) SendData() string {
+	return string(fu)
+}
+
+// FileID is the file_id of a file that already exists on Telegram.
+type FileID string
+
+// NeedsUpload returns false, as the file is already on Telegram.
+func (fi FileID) NeedsUpload() bool {
+	return false
+}
+
+// UploadData is not used, as FileID is not uploaded.
+func (fi FileID) UploadData() (string, io.Reader, error) {
+	panic("FileID cannot be uploaded")
+}
+
+// SendData returns the file_id.
+func (fi FileID) SendData() string {
+	return string(fi)
+}
+
+// fileAttach is a reference to a file uploaded in another part of the
+// same request, such as attach://file-0.
+type fileAttach string
+
+func (fa fileAttach) NeedsUpload() bool {
+	return false
+}
+
+func (fa fileAttach) UploadData() (string, io.Reader, error) {
+	panic("fileAttach cannot be uploaded")
+}
+
+func (fa fileAttach) SendData() string {
+	return string(fa)
+}
+
+// newRequestFileData converts a file given to a config into a
+// RequestFileData. For compatibility, a string is a path to a local
+// file and a url.URL is a FileURL.
+//
+// URLs are checked to be http or https.
+func newRequestFileData(file interface{}) (RequestFileData, error) {
+	switch f := file.(type) {
+	case FileURL:
+		return f, f.validate()
+	case RequestFileData:
+		return f, nil
+	case string:
+		return FilePath(f), nil
+	case url.URL:
+		return newRequestFileData(FileURL(f.String()))
+	case *url.URL:
+		return newRequestFileData(FileURL(f.String()))
+	default:
+		return nil, errors.New(ErrBadFileType)
+	}
 }
 
 // InlineConfig contains information on making an InlineQuery response.
@@ -800,6 +2027,44 @@ type InlineConfig struct {
 	SwitchPMParameter string        `json:"switch_pm_parameter"`
 }
 
+// values returns a url.Values representation of InlineConfig.
+func (config InlineConfig) values() (url.Values, error) {
+	v := url.Values{}
+
+	v.Add("inline_query_id", config.InlineQueryID)
+	v.Add("cache_time", strconv.Itoa(config.CacheTime))
+	v.Add("is_personal", strconv.FormatBool(config.IsPersonal))
+	v.Add("next_offset", config.NextOffset)
+	data, err := json.Marshal(config.Results)
+	if err != nil {
+		return v, err
+	}
+	v.Add("results", string(data))
+	v.Add("switch_pm_text", config.SwitchPMText)
+	v.Add("switch_pm_parameter", config.SwitchPMParameter)
+
+	return v, nil
+}
+
+// Method returns Telegram API method name for answering an inline query.
+func (config InlineConfig) Method() string {
+	return "answerInlineQuery"
+}
+
+// Params returns the parameters of InlineConfig.
+func (config InlineConfig) Params() (Params, error) {
+	v, err := config.values()
+	if err != nil {
+		return nil, err
+	}
+
+	return valuesToParams(v), nil
+}
+
+// InlinePageFunc fetches up to limit inline query results, starting at
+// offset. Returning fewer than limit results ends the pagination.
+type InlinePageFunc func(offset, limit int) ([]interface{}, error)
+
 // CallbackConfig contains information on making a CallbackQuery response.
 type CallbackConfig struct {
 	CallbackQueryID string `json:"callback_query_id"`
@@ -809,24 +2074,413 @@ type CallbackConfig struct {
 	CacheTime       int    `json:"cache_time"`
 }
 
+// values returns a url.Values representation of CallbackConfig.
+func (config CallbackConfig) values() url.Values {
+	p := newParamBuilder(5)
+	p.add("callback_query_id", config.CallbackQueryID)
+	if config.Text != "" {
+		p.add("text", config.Text)
+	}
+	p.add("show_alert", strconv.FormatBool(config.ShowAlert))
+	if config.URL != "" {
+		p.add("url", config.URL)
+	}
+	p.add("cache_time", strconv.Itoa(config.CacheTime))
+
+	return p.v
+}
+
+// Method returns Telegram API method name for answering a callback query.
+func (config CallbackConfig) Method() string {
+	return "answerCallbackQuery"
+}
+
+// Params returns the parameters of CallbackConfig.
+func (config CallbackConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
 // ChatMemberConfig contains information about a user in a chat for use
 // with administrative functions such as kicking or unbanning a user.
+//
+// SuperGroupUsername may be the @username of any public supergroup or
+// channel, and is used instead of ChatID if set.
 type ChatMemberConfig struct {
 	ChatID             int64
 	SuperGroupUsername string
-	UserID             int
+	UserID             int64
+}
+
+// values returns a url.Values representation of ChatMemberConfig.
+func (config ChatMemberConfig) values() url.Values {
+	v := url.Values{}
+	v.Add("chat_id", chatIDParam(config.ChatID, config.SuperGroupUsername))
+	v.Add("user_id", strconv.FormatInt(config.UserID, 10))
+
+	return v
+}
+
+// Method returns Telegram API method name for kicking a member, which is
+// what KickChatMember uses the config for. UnbanChatMember and the
+// methods for join requests take the same parameters.
+func (config ChatMemberConfig) Method() string {
+	return "kickChatMember"
+}
+
+// Params returns the parameters of ChatMemberConfig.
+func (config ChatMemberConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
+// RestrictChatMemberConfig contains the permissions to restrict a user in
+// a supergroup to. Permissions left false are taken away.
+type RestrictChatMemberConfig struct {
+	ChatMemberConfig
+	Permissions ChatPermissions
+	// UntilDate is when the restrictions end, as a Unix time. They never
+	// end if it is zero, or less than 30 seconds or more than 366 days
+	// from now.
+	UntilDate int64
+}
+
+// values returns a url.Values representation of RestrictChatMemberConfig.
+func (config RestrictChatMemberConfig) values() (url.Values, error) {
+	v := config.ChatMemberConfig.values()
+
+	data, err := json.Marshal(config.Permissions)
+	if err != nil {
+		return v, err
+	}
+	v.Add("permissions", string(data))
+
+	if config.UntilDate != 0 {
+		v.Add("until_date", strconv.FormatInt(config.UntilDate, 10))
+	}
+
+	return v, nil
+}
+
+// Method returns Telegram API method name for restricting a member.
+func (config RestrictChatMemberConfig) Method() string {
+	return "restrictChatMember"
+}
+
+// Params returns the parameters of RestrictChatMemberConfig.
+func (config RestrictChatMemberConfig) Params() (Params, error) {
+	v, err := config.values()
+	if err != nil {
+		return nil, err
+	}
+
+	return valuesToParams(v), nil
 }
 
 // ChatConfig contains information about getting information on a chat.
+//
+// SuperGroupUsername may be the @username of any public supergroup or
+// channel, and is used instead of ChatID if set.
 type ChatConfig struct {
 	ChatID             int64
 	SuperGroupUsername string
 }
 
+// values returns a url.Values representation of ChatConfig.
+func (config ChatConfig) values() url.Values {
+	v := url.Values{}
+	v.Add("chat_id", chatIDParam(config.ChatID, config.SuperGroupUsername))
+
+	return v
+}
+
+// Method returns Telegram API method name for getting a chat, which is
+// what GetChat uses the config for. LeaveChat, GetChatAdministrators and
+// GetChatMembersCount take the same parameters.
+func (config ChatConfig) Method() string {
+	return "getChat"
+}
+
+// Params returns the parameters of ChatConfig.
+func (config ChatConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
 // ChatConfigWithUser contains information about getting information on
 // a specific user within a chat.
+//
+// SuperGroupUsername may be the @username of any public supergroup or
+// channel, and is used instead of ChatID if set.
 type ChatConfigWithUser struct {
 	ChatID             int64
 	SuperGroupUsername string
-	UserID             int
+	UserID             int64
+}
+
+// values returns a url.Values representation of ChatConfigWithUser.
+func (config ChatConfigWithUser) values() url.Values {
+	v := url.Values{}
+	v.Add("chat_id", chatIDParam(config.ChatID, config.SuperGroupUsername))
+	v.Add("user_id", strconv.FormatInt(config.UserID, 10))
+
+	return v
+}
+
+// Method returns Telegram API method name for getting a chat member.
+func (config ChatConfigWithUser) Method() string {
+	return "getChatMember"
+}
+
+// Params returns the parameters of ChatConfigWithUser.
+func (config ChatConfigWithUser) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
+}
+
+// Constant values for the Type of a BotCommandScope
+const (
+	CommandScopeDefault               = "default"
+	CommandScopeAllPrivateChats       = "all_private_chats"
+	CommandScopeAllGroupChats         = "all_group_chats"
+	CommandScopeAllChatAdministrators = "all_chat_administrators"
+	CommandScopeChat                  = "chat"
+	CommandScopeChatAdministrators    = "chat_administrators"
+	CommandScopeChatMember            = "chat_member"
+)
+
+// MyCommandsConfig contains information about getting, setting or
+// deleting the list of the bot's commands for a scope and language.
+//
+// A nil Scope is the default scope, and an empty LanguageCode applies to
+// users with no commands for their language.
+type MyCommandsConfig struct {
+	Commands     []BotCommand // only for setting commands
+	Scope        *BotCommandScope
+	LanguageCode string
+}
+
+// values returns a url.Values representation of MyCommandsConfig,
+// including Commands if withCommands is set.
+func (config MyCommandsConfig) values(withCommands bool) (url.Values, error) {
+	v := url.Values{}
+
+	if withCommands {
+		commands := config.Commands
+		if commands == nil {
+			commands = []BotCommand{}
+		}
+
+		data, err := json.Marshal(commands)
+		if err != nil {
+			return v, err
+		}
+		v.Add("commands", string(data))
+	}
+
+	if config.Scope != nil {
+		data, err := json.Marshal(config.Scope)
+		if err != nil {
+			return v, err
+		}
+		v.Add("scope", string(data))
+	}
+
+	if config.LanguageCode != "" {
+		v.Add("language_code", config.LanguageCode)
+	}
+
+	return v, nil
+}
+
+// Method returns Telegram API method name for setting the commands if
+// Commands is set, and otherwise for getting them.
+func (config MyCommandsConfig) Method() string {
+	if config.Commands != nil {
+		return "setMyCommands"
+	}
+
+	return "getMyCommands"
+}
+
+// Params returns the parameters of MyCommandsConfig for its Method.
+func (config MyCommandsConfig) Params() (Params, error) {
+	v, err := config.values(config.Commands != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return valuesToParams(v), nil
+}
+
+// InvoiceConfig contains information about a SendInvoice request.
+//
+// Prices are in the smallest units of Currency, such as cents for USD.
+type InvoiceConfig struct {
+	BaseChat
+	Title               string         // required, 1-32 characters
+	Description         string         // required, 1-255 characters
+	Payload             string         // required, 1-128 bytes, not shown to the user
+	ProviderToken       string         // required
+	StartParameter      string         // optional
+	Currency            string         // required, three-letter ISO 4217 code
+	Prices              []LabeledPrice // required
+	ProviderData        string         // optional, JSON for the payment provider
+	PhotoURL            string
+	PhotoSize           int
+	PhotoWidth          int
+	PhotoHeight         int
+	NeedName            bool
+	NeedPhoneNumber     bool
+	NeedEmail           bool
+	NeedShippingAddress bool
+	// SendPhoneNumberToProvider and SendEmailToProvider pass the user's
+	// phone number or email to the provider, such as for a receipt
+	SendPhoneNumberToProvider bool
+	SendEmailToProvider       bool
+	IsFlexible                bool // if the price depends on the shipping method
+}
+
+// values returns a url.Values representation of InvoiceConfig.
+func (config InvoiceConfig) values() (url.Values, error) {
+	v, err := config.BaseChat.values()
+	if err != nil {
+		return v, err
+	}
+
+	v.Add("title", config.Title)
+	v.Add("description", config.Description)
+	v.Add("payload", config.Payload)
+	v.Add("provider_token", config.ProviderToken)
+	v.Add("currency", config.Currency)
+
+	data, err := json.Marshal(config.Prices)
+	if err != nil {
+		return v, err
+	}
+	v.Add("prices", string(data))
+
+	if config.StartParameter != "" {
+		v.Add("start_parameter", config.StartParameter)
+	}
+	if config.ProviderData != "" {
+		v.Add("provider_data", config.ProviderData)
+	}
+	if config.PhotoURL != "" {
+		v.Add("photo_url", config.PhotoURL)
+	}
+	if config.PhotoSize != 0 {
+		v.Add("photo_size", strconv.Itoa(config.PhotoSize))
+	}
+	if config.PhotoWidth != 0 {
+		v.Add("photo_width", strconv.Itoa(config.PhotoWidth))
+	}
+	if config.PhotoHeight != 0 {
+		v.Add("photo_height", strconv.Itoa(config.PhotoHeight))
+	}
+	if config.NeedName {
+		v.Add("need_name", "true")
+	}
+	if config.NeedPhoneNumber {
+		v.Add("need_phone_number", "true")
+	}
+	if config.NeedEmail {
+		v.Add("need_email", "true")
+	}
+	if config.NeedShippingAddress {
+		v.Add("need_shipping_address", "true")
+	}
+	if config.SendPhoneNumberToProvider {
+		v.Add("send_phone_number_to_provider", "true")
+	}
+	if config.SendEmailToProvider {
+		v.Add("send_email_to_provider", "true")
+	}
+	if config.IsFlexible {
+		v.Add("is_flexible", "true")
+	}
+
+	return v, nil
+}
+
+// Method returns Telegram API method name for sending Invoice.
+func (config InvoiceConfig) Method() string {
+	return "sendInvoice"
+}
+
+// Params returns the parameters of InvoiceConfig.
+func (config InvoiceConfig) Params() (Params, error) {
+	return chattableParams(config)
+}
+
+// ShippingConfig contains information for answering a ShippingQuery,
+// with either the available ShippingOptions or an ErrorMessage.
+type ShippingConfig struct {
+	ShippingQueryID string // required
+	OK              bool   // required
+	ShippingOptions []ShippingOption
+	ErrorMessage    string
+}
+
+// values returns a url.Values representation of ShippingConfig.
+func (config ShippingConfig) values() (url.Values, error) {
+	v := url.Values{}
+
+	v.Add("shipping_query_id", config.ShippingQueryID)
+	v.Add("ok", strconv.FormatBool(config.OK))
+	if config.OK {
+		data, err := json.Marshal(config.ShippingOptions)
+		if err != nil {
+			return v, err
+		}
+		v.Add("shipping_options", string(data))
+	} else {
+		v.Add("error_message", config.ErrorMessage)
+	}
+
+	return v, nil
+}
+
+// Method returns Telegram API method name for answering a shipping query.
+func (config ShippingConfig) Method() string {
+	return "answerShippingQuery"
+}
+
+// Params returns the parameters of ShippingConfig.
+func (config ShippingConfig) Params() (Params, error) {
+	v, err := config.values()
+	if err != nil {
+		return nil, err
+	}
+
+	return valuesToParams(v), nil
+}
+
+// PreCheckoutConfig contains information for answering a
+// PreCheckoutQuery, confirming the order or refusing it with an
+// ErrorMessage.
+type PreCheckoutConfig struct {
+	PreCheckoutQueryID string // required
+	OK                 bool   // required
+	ErrorMessage       string
+}
+
+// values returns a url.Values representation of PreCheckoutConfig.
+func (config PreCheckoutConfig) values() url.Values {
+	v := url.Values{}
+
+	v.Add("pre_checkout_query_id", config.PreCheckoutQueryID)
+	v.Add("ok", strconv.FormatBool(config.OK))
+	if !config.OK {
+		v.Add("error_message", config.ErrorMessage)
+	}
+
+	return v
+}
+
+// Method returns Telegram API method name for answering a pre-checkout
+// query.
+func (config PreCheckoutConfig) Method() string {
+	return "answerPreCheckoutQuery"
+}
+
+// Params returns the parameters of PreCheckoutConfig.
+func (config PreCheckoutConfig) Params() (Params, error) {
+	return valuesToParams(config.values()), nil
 }
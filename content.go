@@ -0,0 +1,355 @@
+package tgbotapi
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+)
+
+// AttachmentKind is the kind of an Attachment.
+type AttachmentKind string
+
+// Kinds of Attachment.
+const (
+	AttachmentImage     AttachmentKind = "image"
+	AttachmentVideo     AttachmentKind = "video"
+	AttachmentAnimation AttachmentKind = "animation"
+	AttachmentAudio     AttachmentKind = "audio"
+	AttachmentVoice     AttachmentKind = "voice"
+	AttachmentSticker   AttachmentKind = "sticker"
+	AttachmentFile      AttachmentKind = "file"
+)
+
+// Attachment is a file attached to Content.
+type Attachment struct {
+	Kind AttachmentKind
+	// ID is the file's ID on Telegram. Attachments from elsewhere leave
+	// it empty and set Fetch.
+	ID       string
+	Name     string
+	MIMEType string
+	Size     int64
+	Width    int
+	Height   int
+	Duration time.Duration
+	// Fetch opens the file's contents. The returned ReadCloser must be
+	// closed.
+	Fetch func(ctx context.Context) (io.ReadCloser, error)
+}
+
+// ContentSender is who sent Content.
+type ContentSender struct {
+	ID       string
+	Name     string
+	Username string
+	IsBot    bool
+}
+
+// Content is a message in a form that doesn't depend on Telegram's
+// types, for bridging Telegram with other chat platforms. IDs are kept
+// as strings, as other platforms may not use numbers.
+type Content struct {
+	ID     string
+	ChatID string
+	// Thread is the forum topic the message is in, if any.
+	Thread string
+	// ReplyTo is the message this is a reply to, if any.
+	ReplyTo     string
+	Sender      ContentSender
+	Time        time.Time
+	Text        string
+	Attachments []Attachment
+}
+
+// NormalizeMessage converts a message into Content. Captions become
+// Text, and each attachment can be fetched through the bot. Contacts,
+// locations, polls and other kinds of message without a file only keep
+// their text, if any.
+func (bot *BotAPI) NormalizeMessage(message *Message) Content {
+	content := Content{
+		ID:   strconv.Itoa(message.MessageID),
+		Time: message.Time(),
+		Text: message.Text,
+	}
+	if content.Text == "" {
+		content.Text = message.Caption
+	}
+
+	if message.Chat != nil {
+		content.ChatID = strconv.FormatInt(message.Chat.ID, 10)
+	}
+	if message.MessageThreadID != 0 {
+		content.Thread = strconv.Itoa(message.MessageThreadID)
+	}
+	if message.ReplyToMessage != nil {
+		content.ReplyTo = strconv.Itoa(message.ReplyToMessage.MessageID)
+	}
+
+	switch {
+	case message.From != nil:
+		content.Sender = ContentSender{
+			ID:       strconv.FormatInt(message.From.ID, 10),
+			Name:     message.From.fullName(),
+			Username: message.From.UserName,
+			IsBot:    message.From.IsBot,
+		}
+	case message.Chat != nil:
+		// Channel posts are sent by the channel itself.
+		content.Sender = ContentSender{
+			ID:       content.ChatID,
+			Name:     message.Chat.Title,
+			Username: message.Chat.UserName,
+		}
+	}
+
+	if attachment, ok := messageAttachment(message); ok {
+		fileID := attachment.ID
+		attachment.Fetch = func(ctx context.Context) (io.ReadCloser, error) {
+			file, err := bot.GetFile(FileConfig{fileID})
+			if err != nil {
+				return nil, err
+			}
+
+			return bot.OpenFileContext(ctx, file)
+		}
+		content.Attachments = []Attachment{attachment}
+	}
+
+	return content
+}
+
+// messageAttachment returns the file attached to a message, if it has
+// one.
+func messageAttachment(message *Message) (Attachment, bool) {
+	seconds := func(s int) time.Duration { return time.Duration(s) * time.Second }
+
+	switch {
+	case message.Photo != nil && len(*message.Photo) > 0:
+		photo := message.BestPhoto()
+		return Attachment{
+			Kind:     AttachmentImage,
+			ID:       photo.FileID,
+			MIMEType: "image/jpeg",
+			Size:     int64(photo.FileSize),
+			Width:    photo.Width,
+			Height:   photo.Height,
+		}, true
+	case message.Animation != nil:
+		a := message.Animation
+		return Attachment{
+			Kind:     AttachmentAnimation,
+			ID:       a.FileID,
+			Name:     a.FileName,
+			MIMEType: a.MimeType,
+			Size:     int64(a.FileSize),
+			Width:    a.Width,
+			Height:   a.Height,
+			Duration: seconds(a.Duration),
+		}, true
+	case message.Video != nil:
+		v := message.Video
+		return Attachment{
+			Kind:     AttachmentVideo,
+			ID:       v.FileID,
+			MIMEType: v.MimeType,
+			Size:     int64(v.FileSize),
+			Width:    v.Width,
+			Height:   v.Height,
+			Duration: seconds(v.Duration),
+		}, true
+	case message.Audio != nil:
+		a := message.Audio
+		return Attachment{
+			Kind:     AttachmentAudio,
+			ID:       a.FileID,
+			MIMEType: a.MimeType,
+			Size:     int64(a.FileSize),
+			Duration: seconds(a.Duration),
+		}, true
+	case message.Voice != nil:
+		v := message.Voice
+		return Attachment{
+			Kind:     AttachmentVoice,
+			ID:       v.FileID,
+			MIMEType: v.MimeType,
+			Size:     int64(v.FileSize),
+			Duration: seconds(v.Duration),
+		}, true
+	case message.Sticker != nil:
+		s := message.Sticker
+		return Attachment{
+			Kind:   AttachmentSticker,
+			ID:     s.FileID,
+			Size:   int64(s.FileSize),
+			Width:  s.Width,
+			Height: s.Height,
+		}, true
+	case message.Document != nil:
+		d := message.Document
+		return Attachment{
+			Kind:     AttachmentFile,
+			ID:       d.FileID,
+			Name:     d.FileName,
+			MIMEType: d.MimeType,
+			Size:     int64(d.FileSize),
+		}, true
+	}
+
+	return Attachment{}, false
+}
+
+// SendContent sends Content to a chat, as a text message, a file with
+// the text as its caption, or an album. Attachments which can't be sent
+// as one album are sent one at a time, with the text on the first.
+//
+// Attachments with an ID are sent by it, and others are fetched and
+// uploaded. Content is sent as a reply to ReplyTo, or else to Thread so
+// it is posted in the forum topic.
+func (bot *BotAPI) SendContent(ctx context.Context, chatID int64, content Content) ([]Message, error) {
+	replyTo, _ := strconv.Atoi(content.ReplyTo)
+	if replyTo == 0 {
+		replyTo, _ = strconv.Atoi(content.Thread)
+	}
+
+	if len(content.Attachments) == 0 {
+		msg := NewMessage(chatID, content.Text)
+		msg.ReplyToMessageID = replyTo
+
+		sent, err := bot.Send(msg)
+		if err != nil {
+			return nil, err
+		}
+		return []Message{sent}, nil
+	}
+
+	files := make([]RequestFileData, len(content.Attachments))
+	for i, attachment := range content.Attachments {
+		file, closer, err := openAttachment(ctx, attachment)
+		if err != nil {
+			return nil, err
+		}
+		if closer != nil {
+			defer closer.Close()
+		}
+		files[i] = file
+	}
+
+	if len(files) > 1 {
+		media := make([]interface{}, len(files))
+		for i, attachment := range content.Attachments {
+			media[i] = attachmentInputMedia(attachment.Kind, files[i])
+		}
+
+		if validateAlbum(media) == nil {
+			album := NewAlbum(chatID, media...)
+			album.Caption = content.Text
+			album.ReplyToMessageID = replyTo
+
+			return bot.SendAlbum(album)
+		}
+	}
+
+	var messages []Message
+	for i, attachment := range content.Attachments {
+		caption := ""
+		if i == 0 {
+			caption = content.Text
+		}
+		if attachment.Kind == AttachmentSticker && caption != "" {
+			// Stickers have no caption, so the text is sent before.
+			msg := NewMessage(chatID, caption)
+			msg.ReplyToMessageID = replyTo
+
+			sent, err := bot.Send(msg)
+			if err != nil {
+				return messages, err
+			}
+			messages = append(messages, sent)
+		}
+
+		sent, err := bot.Send(attachmentConfig(chatID, attachment.Kind, files[i], caption, replyTo))
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, sent)
+	}
+
+	return messages, nil
+}
+
+// openAttachment returns the file to send for an attachment, and what
+// to close once it is sent, if anything.
+func openAttachment(ctx context.Context, attachment Attachment) (RequestFileData, io.Closer, error) {
+	if attachment.ID != "" || attachment.Fetch == nil {
+		return FileID(attachment.ID), nil, nil
+	}
+
+	r, err := attachment.Fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := attachment.Name
+	if name == "" {
+		name = string(attachment.Kind)
+	}
+
+	return FileReader{
+		Name:        name,
+		Reader:      r,
+		Size:        attachment.Size,
+		ContentType: attachment.MIMEType,
+	}, r, nil
+}
+
+// attachmentInputMedia returns an album item for an attachment, or nil
+// for kinds which can't be in albums.
+func attachmentInputMedia(kind AttachmentKind, file RequestFileData) interface{} {
+	switch kind {
+	case AttachmentImage:
+		return NewInputMediaPhoto(file)
+	case AttachmentVideo:
+		return NewInputMediaVideo(file)
+	case AttachmentAudio:
+		return NewInputMediaAudio(file)
+	case AttachmentFile:
+		return NewInputMediaDocument(file)
+	}
+
+	return nil
+}
+
+// attachmentConfig returns the config to send an attachment on its own.
+func attachmentConfig(chatID int64, kind AttachmentKind, file RequestFileData, caption string, replyTo int) Chattable {
+	switch kind {
+	case AttachmentImage:
+		c := NewPhoto(chatID, file)
+		c.Caption, c.ReplyToMessageID = caption, replyTo
+		return c
+	case AttachmentVideo:
+		c := NewVideo(chatID, file)
+		c.Caption, c.ReplyToMessageID = caption, replyTo
+		return c
+	case AttachmentAnimation:
+		c := NewAnimation(chatID, file)
+		c.Caption, c.ReplyToMessageID = caption, replyTo
+		return c
+	case AttachmentAudio:
+		c := NewAudio(chatID, file)
+		c.Caption, c.ReplyToMessageID = caption, replyTo
+		return c
+	case AttachmentVoice:
+		c := NewVoice(chatID, file)
+		c.Caption, c.ReplyToMessageID = caption, replyTo
+		return c
+	case AttachmentSticker:
+		c := NewSticker(chatID, file)
+		c.ReplyToMessageID = replyTo
+		return c
+	}
+
+	c := NewDocument(chatID, file)
+	c.Caption, c.ReplyToMessageID = caption, replyTo
+	return c
+}
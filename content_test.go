@@ -0,0 +1,133 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	var requested []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		requested = append(requested, method)
+		if method == "getFile" {
+			if r.FormValue("file_id") != "large" {
+				t.Errorf("expected the largest photo fetched, got %s", r.FormValue("file_id"))
+			}
+			return tgbotapi.File{FileID: "large", FilePath: "photos/large.jpg"}
+		}
+		return nil
+	})
+	requested = nil
+
+	content := bot.NormalizeMessage(&tgbotapi.Message{
+		MessageID:       10,
+		Date:            1600000000,
+		Chat:            &tgbotapi.Chat{ID: -100, Type: "supergroup"},
+		MessageThreadID: 3,
+		From:            &tgbotapi.User{ID: 5, FirstName: "Ann", LastName: "Lee", UserName: "ann"},
+		ReplyToMessage:  &tgbotapi.Message{MessageID: 9},
+		Caption:         "Look",
+		Photo: &[]tgbotapi.PhotoSize{
+			{FileID: "small", Width: 90, Height: 60},
+			{FileID: "large", Width: 1280, Height: 853, FileSize: 1000},
+		},
+	})
+
+	if content.ID != "10" || content.ChatID != "-100" || content.Thread != "3" || content.ReplyTo != "9" {
+		t.Errorf("bad IDs in %+v", content)
+	}
+	if content.Sender.ID != "5" || content.Sender.Name != "Ann Lee" || content.Sender.Username != "ann" {
+		t.Errorf("bad sender %+v", content.Sender)
+	}
+	if content.Text != "Look" || content.Time.Unix() != 1600000000 {
+		t.Errorf("bad text or time in %+v", content)
+	}
+
+	if len(content.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(content.Attachments))
+	}
+	attachment := content.Attachments[0]
+	if attachment.Kind != tgbotapi.AttachmentImage || attachment.ID != "large" || attachment.Width != 1280 || attachment.Size != 1000 {
+		t.Errorf("bad attachment %+v", attachment)
+	}
+
+	r, err := attachment.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	if len(requested) != 2 || requested[1] != "large.jpg" {
+		t.Errorf("expected the file to be downloaded, got requests %v", requested)
+	}
+}
+
+func TestSendContent(t *testing.T) {
+	var methods []string
+	var media []map[string]interface{}
+	var uploaded string
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		methods = append(methods, method)
+
+		switch method {
+		case "sendMediaGroup":
+			json.Unmarshal([]byte(r.FormValue("media")), &media)
+
+			if f, _, err := r.FormFile("file-1"); err == nil {
+				data, _ := ioutil.ReadAll(f)
+				uploaded = string(data)
+			}
+			return []tgbotapi.Message{{MessageID: 1}, {MessageID: 2}}
+		case "sendSticker", "sendMessage":
+			return tgbotapi.Message{MessageID: 3}
+		}
+		return nil
+	})
+
+	// Photos from Telegram and elsewhere are sent as an album.
+	messages, err := bot.SendContent(context.Background(), ChatID, tgbotapi.Content{
+		Text: "Photos",
+		Attachments: []tgbotapi.Attachment{
+			{Kind: tgbotapi.AttachmentImage, ID: "photo"},
+			{
+				Kind: tgbotapi.AttachmentImage,
+				Name: "other.jpg",
+				Fetch: func(ctx context.Context) (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader("jpeg")), nil
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 2 || len(media) != 2 || media[0]["caption"] != "Photos" || media[0]["media"] != "photo" {
+		t.Errorf("bad album %v", media)
+	}
+	if uploaded != "jpeg" {
+		t.Errorf("expected the fetched photo uploaded, got %q", uploaded)
+	}
+
+	// Stickers can't be in albums or have captions.
+	methods = nil
+	messages, err = bot.SendContent(context.Background(), ChatID, tgbotapi.Content{
+		Text:        "Hi",
+		Attachments: []tgbotapi.Attachment{{Kind: tgbotapi.AttachmentSticker, ID: ExistingStickerFileID}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 2 || len(methods) != 2 || methods[0] != "sendMessage" || methods[1] != "sendSticker" {
+		t.Errorf("expected the text then the sticker, got %v", methods)
+	}
+}
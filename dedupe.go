@@ -0,0 +1,146 @@
+package tgbotapi
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultDedupeSize is how many update IDs an UpdateDeduplicator
+// remembers in memory if no size is given.
+const DefaultDedupeSize = 10000
+
+// DefaultDedupeTTL is how long an UpdateDeduplicator remembers update IDs
+// in a store if no ttl is given. Telegram keeps undelivered updates for
+// 24 hours.
+const DefaultDedupeTTL = 24 * time.Hour
+
+// UpdateDeduplicator drops updates which were already handled, as
+// Telegram delivers webhook updates again if it didn't get a response in
+// time.
+//
+// By default it remembers the most recent update IDs in memory. With a
+// KeyValueStore shared by several instances of a bot, an update
+// delivered to one instance is dropped by the others. If the store is a
+// KeyValueAdder, an update is only ever handled by one instance.
+// Otherwise checking the store is not atomic, so deliveries to two
+// instances at the same moment may both be handled, but Telegram's
+// retries come seconds apart.
+//
+// Update IDs are counted separately for each bot, so bots may share a
+// deduplicator or store.
+type UpdateDeduplicator struct {
+	store KeyValueStore
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	seen  map[seenUpdate]*list.Element
+}
+
+// seenUpdate is an update remembered in memory.
+type seenUpdate struct {
+	botID    int64
+	updateID int
+}
+
+// NewUpdateDeduplicator creates an UpdateDeduplicator remembering the
+// last size update IDs in memory, or DefaultDedupeSize if size is zero.
+func NewUpdateDeduplicator(size int) *UpdateDeduplicator {
+	if size <= 0 {
+		size = DefaultDedupeSize
+	}
+
+	return &UpdateDeduplicator{
+		size:  size,
+		order: list.New(),
+		seen:  make(map[seenUpdate]*list.Element),
+	}
+}
+
+// NewStoreDeduplicator creates an UpdateDeduplicator remembering update
+// IDs in store for ttl, or DefaultDedupeTTL if ttl is zero.
+func NewStoreDeduplicator(store KeyValueStore, ttl time.Duration) *UpdateDeduplicator {
+	if ttl <= 0 {
+		ttl = DefaultDedupeTTL
+	}
+
+	return &UpdateDeduplicator{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Seen remembers an update ID of a bot, returning if it was already
+// seen.
+func (d *UpdateDeduplicator) Seen(botID int64, updateID int) (bool, error) {
+	if d.store != nil {
+		key := fmt.Sprintf("update:%d:%d", botID, updateID)
+
+		if adder, ok := d.store.(KeyValueAdder); ok {
+			added, err := adder.Add(key, []byte{1}, d.ttl)
+			return !added && err == nil, err
+		}
+
+		_, ok, err := d.store.Get(key)
+		if err != nil || ok {
+			return ok, err
+		}
+
+		return false, d.store.Set(key, []byte{1}, d.ttl)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	id := seenUpdate{botID, updateID}
+	if e, ok := d.seen[id]; ok {
+		d.order.MoveToFront(e)
+		return true, nil
+	}
+
+	d.seen[id] = d.order.PushFront(id)
+	if d.order.Len() > d.size {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.seen, oldest.Value.(seenUpdate))
+	}
+
+	return false, nil
+}
+
+// Middleware returns Middleware which drops updates that were already
+// seen. Updates without an ID, such as those made by tests, are always
+// handled, as are updates when the store fails, which is logged.
+func (d *UpdateDeduplicator) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			if update.UpdateID != 0 {
+				var botID int64
+				if bot != nil {
+					self, err := bot.Me(ctx)
+					if err != nil {
+						log.Println(err)
+						next(ctx, bot, update)
+						return
+					}
+					botID = self.ID
+				}
+
+				seen, err := d.Seen(botID, update.UpdateID)
+				if err != nil {
+					log.Println(err)
+				}
+				if seen {
+					return
+				}
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
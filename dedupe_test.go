@@ -0,0 +1,88 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestUpdateDeduplicatorMemory(t *testing.T) {
+	d := tgbotapi.NewUpdateDeduplicator(2)
+
+	for _, step := range []struct {
+		id   int
+		seen bool
+	}{
+		{1, false},
+		{2, false},
+		{1, true},
+		{3, false}, // forgets 2, the least recently seen
+		{1, true},
+		{2, false},
+	} {
+		seen, err := d.Seen(1, step.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen != step.seen {
+			t.Errorf("update %d: expected seen %t, got %t", step.id, step.seen, seen)
+		}
+	}
+}
+
+func TestUpdateDeduplicatorStore(t *testing.T) {
+	store := tgbotapi.NewMemoryStore()
+	first := tgbotapi.NewStoreDeduplicator(store, time.Hour)
+	second := tgbotapi.NewStoreDeduplicator(store, time.Hour)
+
+	if seen, _ := first.Seen(1, 5); seen {
+		t.Error("expected update 5 to be new")
+	}
+	if seen, _ := second.Seen(1, 5); !seen {
+		t.Error("expected update 5 to be seen by another instance")
+	}
+	if seen, _ := second.Seen(2, 5); seen {
+		t.Error("expected update 5 of another bot to be new")
+	}
+}
+
+// getSetStore hides a store's Add, so it is only used with Get and Set.
+type getSetStore struct {
+	tgbotapi.KeyValueStore
+}
+
+func TestUpdateDeduplicatorGetSetStore(t *testing.T) {
+	store := getSetStore{tgbotapi.NewMemoryStore()}
+	d := tgbotapi.NewStoreDeduplicator(store, 0)
+
+	if seen, _ := d.Seen(1, 5); seen {
+		t.Error("expected update 5 to be new")
+	}
+	if seen, _ := d.Seen(1, 5); !seen {
+		t.Error("expected update 5 to be seen")
+	}
+}
+
+func TestUpdateDeduplicatorMiddleware(t *testing.T) {
+	var handled []int
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled = append(handled, update.UpdateID)
+	})
+	d.Use(tgbotapi.NewUpdateDeduplicator(0).Middleware())
+
+	for _, id := range []int{1, 2, 1, 0, 0, 3, 2} {
+		d.Dispatch(context.Background(), tgbotapi.Update{UpdateID: id})
+	}
+
+	expected := []int{1, 2, 0, 0, 3}
+	if len(handled) != len(expected) {
+		t.Fatalf("expected %v handled, got %v", expected, handled)
+	}
+	for i := range expected {
+		if handled[i] != expected[i] {
+			t.Fatalf("expected %v handled, got %v", expected, handled)
+		}
+	}
+}
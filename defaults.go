@@ -0,0 +1,164 @@
+package tgbotapi
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BotOption configures a BotAPI as it is created by NewBotAPI or
+// NewBotAPIWithClient.
+type BotOption func(bot *BotAPI)
+
+// WithDefaultParseMode sets the parse mode used by requests which don't
+// set one, such as ModeHTML. A message with a ParseMode of ModeNone is
+// sent without one, such as to echo text from users which would not
+// parse.
+func WithDefaultParseMode(mode string) BotOption {
+	return func(bot *BotAPI) {
+		bot.DefaultParseMode = mode
+	}
+}
+
+// WithDefaultDisableNotification makes messages be sent silently unless
+// a request says otherwise.
+func WithDefaultDisableNotification(disable bool) BotOption {
+	return func(bot *BotAPI) {
+		bot.DefaultDisableNotification = disable
+	}
+}
+
+// WithUserAgent sets the User-Agent sent with every request.
+func WithUserAgent(userAgent string) BotOption {
+	return func(bot *BotAPI) {
+		bot.UserAgent = userAgent
+	}
+}
+
+// WithHeader adds a header to every request, such as for authentication
+// with a proxy.
+func WithHeader(key, value string) BotOption {
+	return func(bot *BotAPI) {
+		if bot.Header == nil {
+			bot.Header = make(http.Header)
+		}
+		bot.Header.Add(key, value)
+	}
+}
+
+// setHeaders sets the bot's User-Agent and Header on a request.
+func (bot *BotAPI) setHeaders(req *http.Request) {
+	for key, values := range bot.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if bot.UserAgent != "" {
+		req.Header.Set("User-Agent", bot.UserAgent)
+	}
+}
+
+// parseModeMethods are the methods which accept a parse_mode.
+var parseModeMethods = map[string]bool{
+	"sendMessage":        true,
+	"editMessageText":    true,
+	"sendPhoto":          true,
+	"sendAudio":          true,
+	"sendDocument":       true,
+	"sendVideo":          true,
+	"sendAnimation":      true,
+	"sendVoice":          true,
+	"editMessageCaption": true,
+	"copyMessage":        true,
+}
+
+// sendsMessage reports if a method sends a message, so it accepts
+// disable_notification.
+func sendsMessage(method string) bool {
+	switch method {
+	case "forwardMessage", "copyMessage":
+		return true
+	case "sendChatAction":
+		return false
+	}
+
+	return strings.HasPrefix(method, "send")
+}
+
+// defaultParams returns the parameters to add to a request for the bot's
+// defaults, given a way to look up the request's current parameters. It
+// returns nil if there are none, which is the case for most bots.
+//
+// A parse mode is not added when the request has entities, as they are
+// used instead, or has a parse mode, even an empty one from ModeNone.
+func (bot *BotAPI) defaultParams(method string, lookup func(key string) (string, bool)) map[string]string {
+	if bot.DefaultParseMode == "" && !bot.DefaultDisableNotification {
+		return nil
+	}
+
+	get := func(key string) string {
+		value, _ := lookup(key)
+		return value
+	}
+
+	var defaults map[string]string
+
+	if _, ok := lookup("parse_mode"); !ok && bot.DefaultParseMode != "" && parseModeMethods[method] &&
+		get("entities") == "" && get("caption_entities") == "" {
+		defaults = map[string]string{"parse_mode": bot.DefaultParseMode}
+	}
+
+	if bot.DefaultDisableNotification && sendsMessage(method) && get("disable_notification") != "true" {
+		if defaults == nil {
+			defaults = make(map[string]string, 1)
+		}
+		defaults["disable_notification"] = "true"
+	}
+
+	return defaults
+}
+
+// withDefaultValues returns a copy of params with the bot's defaults
+// added, or params itself if there are none to add.
+func (bot *BotAPI) withDefaultValues(method string, params url.Values) url.Values {
+	defaults := bot.defaultParams(method, func(key string) (string, bool) {
+		if values := params[key]; len(values) > 0 {
+			return values[0], true
+		}
+		return "", false
+	})
+	if len(defaults) == 0 {
+		return params
+	}
+
+	v := make(url.Values, len(params)+len(defaults))
+	for key, values := range params {
+		v[key] = values
+	}
+	for key, value := range defaults {
+		v.Set(key, value)
+	}
+
+	return v
+}
+
+// withDefaultParams is withDefaultValues for the parameters of an upload.
+func (bot *BotAPI) withDefaultParams(method string, params map[string]string) map[string]string {
+	defaults := bot.defaultParams(method, func(key string) (string, bool) {
+		value, ok := params[key]
+		return value, ok
+	})
+	if len(defaults) == 0 {
+		return params
+	}
+
+	p := make(map[string]string, len(params)+len(defaults))
+	for key, value := range params {
+		p[key] = value
+	}
+	for key, value := range defaults {
+		p[key] = value
+	}
+
+	return p
+}
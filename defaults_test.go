@@ -0,0 +1,89 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestDefaultSendOptions(t *testing.T) {
+	var params []map[string]string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "getMe" {
+			return nil
+		}
+
+		r.ParseMultipartForm(1 << 20)
+		params = append(params, map[string]string{
+			"method":               method,
+			"parse_mode":           r.FormValue("parse_mode"),
+			"disable_notification": r.FormValue("disable_notification"),
+		})
+
+		return tgbotapi.Message{MessageID: 1}
+	})
+
+	tgbotapi.WithDefaultParseMode(tgbotapi.ModeHTML)(bot)
+	tgbotapi.WithDefaultDisableNotification(true)(bot)
+
+	bot.Send(tgbotapi.NewMessage(ChatID, "<b>hi</b>"))
+
+	msg := tgbotapi.NewMessage(ChatID, "*hi*")
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	bot.Send(msg)
+
+	bot.Send(tgbotapi.NewMessageFromSpans(ChatID, tgbotapi.Bold("hi")))
+
+	plain := tgbotapi.NewMessage(ChatID, "1 < 2")
+	plain.ParseMode = tgbotapi.ModeNone
+	bot.Send(plain)
+
+	bot.Send(tgbotapi.NewPhotoUpload(ChatID, "tests/image.jpg"))
+
+	bot.Send(tgbotapi.NewChatAction(ChatID, tgbotapi.ChatTyping))
+
+	expected := []map[string]string{
+		{"method": "sendMessage", "parse_mode": "HTML", "disable_notification": "true"},
+		{"method": "sendMessage", "parse_mode": "Markdown", "disable_notification": "true"},
+		{"method": "sendMessage", "parse_mode": "", "disable_notification": "true"},
+		{"method": "sendMessage", "parse_mode": "", "disable_notification": "true"},
+		{"method": "sendPhoto", "parse_mode": "HTML", "disable_notification": "true"},
+		{"method": "sendChatAction", "parse_mode": "", "disable_notification": "false"},
+	}
+
+	if len(params) != len(expected) {
+		t.Fatalf("expected %d requests, got %d", len(expected), len(params))
+	}
+	for i := range expected {
+		for key, value := range expected[i] {
+			if params[i][key] != value {
+				t.Errorf("request %d: expected %s %q, got %q", i, key, value, params[i][key])
+			}
+		}
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	headers := make(map[string]http.Header)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		headers[method] = r.Header
+		return nil
+	})
+	tgbotapi.WithUserAgent("mybot/1.0")(bot)
+	tgbotapi.WithHeader("X-Api-Key", "secret")(bot)
+
+	bot.Send(tgbotapi.NewMessage(ChatID, "hello"))
+	bot.Send(tgbotapi.NewPhotoUpload(ChatID, tgbotapi.FileBytes{Name: "image.jpg", Bytes: []byte("jpeg")}))
+
+	for _, method := range []string{"sendMessage", "sendPhoto"} {
+		header := headers[method]
+		if header.Get("User-Agent") != "mybot/1.0" || header.Get("X-Api-Key") != "secret" {
+			t.Errorf("%s: expected the headers to be set, got %v", method, header)
+		}
+	}
+	if !strings.HasPrefix(headers["sendPhoto"].Get("Content-Type"), "multipart/form-data") {
+		t.Errorf("expected the upload's content type to be kept, got %s", headers["sendPhoto"].Get("Content-Type"))
+	}
+}
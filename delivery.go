@@ -0,0 +1,62 @@
+package tgbotapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DeliveryFailure is why messages can no longer be delivered to a chat,
+// so it may be removed from a list of subscribers.
+type DeliveryFailure string
+
+// Reasons messages can't be delivered.
+const (
+	DeliveryBlocked      DeliveryFailure = "blocked"        // the user blocked the bot
+	DeliveryDeactivated  DeliveryFailure = "deactivated"    // the user's account was deleted
+	DeliveryChatNotFound DeliveryFailure = "chat_not_found" // the chat doesn't exist, or the bot never talked to the user
+	DeliveryKicked       DeliveryFailure = "kicked"         // the bot was removed from the group or channel
+)
+
+// UndeliverableReason returns why err means messages can no longer be
+// delivered to the chat they were sent to, if it does.
+func UndeliverableReason(err error) (DeliveryFailure, bool) {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		return "", false
+	}
+
+	description := strings.ToLower(apiErr.Message)
+	switch {
+	case strings.Contains(description, "blocked"):
+		return DeliveryBlocked, true
+	case strings.Contains(description, "deactivated"):
+		return DeliveryDeactivated, true
+	case strings.Contains(description, "chat not found"):
+		return DeliveryChatNotFound, true
+	case strings.Contains(description, "kicked"), strings.Contains(description, "not a member"):
+		return DeliveryKicked, true
+	}
+
+	return "", false
+}
+
+// chatUndeliverable calls OnUndeliverable if err means messages can no
+// longer be delivered to a chat.
+func (bot *BotAPI) chatUndeliverable(err error, chatID string) {
+	if bot.OnUndeliverable == nil {
+		return
+	}
+
+	reason, ok := UndeliverableReason(err)
+	if !ok {
+		return
+	}
+
+	// Chats given by username can't be reported by ID.
+	id, parseErr := strconv.ParseInt(chatID, 10, 64)
+	if parseErr != nil {
+		return
+	}
+
+	bot.OnUndeliverable(id, reason)
+}
@@ -0,0 +1,97 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestUndeliverableReason(t *testing.T) {
+	tests := []struct {
+		description string
+		reason      tgbotapi.DeliveryFailure
+	}{
+		{"Forbidden: bot was blocked by the user", tgbotapi.DeliveryBlocked},
+		{"Forbidden: user is deactivated", tgbotapi.DeliveryDeactivated},
+		{"Bad Request: chat not found", tgbotapi.DeliveryChatNotFound},
+		{"Forbidden: bot was kicked from the supergroup chat", tgbotapi.DeliveryKicked},
+		{"Forbidden: bot is not a member of the channel chat", tgbotapi.DeliveryKicked},
+		{"Bad Request: message text is empty", ""},
+	}
+
+	for _, test := range tests {
+		reason, ok := tgbotapi.UndeliverableReason(&tgbotapi.Error{Code: 403, Message: test.description})
+		if reason != test.reason || ok != (test.reason != "") {
+			t.Errorf("%q: expected %q, got %q", test.description, test.reason, reason)
+		}
+	}
+
+	if _, ok := tgbotapi.UndeliverableReason(errors.New("chat not found")); ok {
+		t.Error("expected only API errors to be undeliverable")
+	}
+}
+
+func TestOnUndeliverable(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch r.FormValue("chat_id") {
+		case "2":
+			return tgbotapi.APIResponse{ErrorCode: 403, Description: "Forbidden: bot was blocked by the user"}
+		case "3":
+			return tgbotapi.APIResponse{ErrorCode: 400, Description: "Bad Request: chat not found"}
+		case "4":
+			return tgbotapi.APIResponse{ErrorCode: 400, Description: "Bad Request: message text is empty"}
+		}
+		return nil
+	})
+
+	reported := map[int64]tgbotapi.DeliveryFailure{}
+	bot.OnUndeliverable = func(chatID int64, reason tgbotapi.DeliveryFailure) {
+		reported[chatID] = reason
+	}
+
+	config := tgbotapi.NewBroadcast("", []int64{2, 3, 4}, tgbotapi.NewMessage(0, "news"))
+	config.Rate = 1000
+
+	report, err := bot.Broadcast(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reported) != 2 || reported[2] != tgbotapi.DeliveryBlocked || reported[3] != tgbotapi.DeliveryChatNotFound {
+		t.Errorf("expected chats 2 and 3 reported, got %v", reported)
+	}
+
+	undeliverable := report.Undeliverable()
+	if len(undeliverable) != 2 || undeliverable[0] != 2 || undeliverable[1] != 3 {
+		t.Errorf("expected chats 2 and 3 undeliverable, got %v", undeliverable)
+	}
+	if len(report.ChatNotFound) != 1 || len(report.Failed) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestOnUndeliverableUpload(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendPhoto" {
+			return tgbotapi.APIResponse{ErrorCode: 403, Description: "Forbidden: user is deactivated"}
+		}
+		return nil
+	})
+
+	var reported int64
+	bot.OnUndeliverable = func(chatID int64, reason tgbotapi.DeliveryFailure) {
+		reported = chatID
+	}
+
+	photo := tgbotapi.NewPhoto(ChatID, tgbotapi.FileBytes{Name: "photo.jpg", Bytes: []byte("jpeg")})
+	if _, err := bot.Send(photo); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if reported != ChatID {
+		t.Errorf("expected chat %d reported, got %d", ChatID, reported)
+	}
+}
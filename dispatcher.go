@@ -0,0 +1,138 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// HandlerFunc handles an Update received by the bot.
+type HandlerFunc func(ctx context.Context, bot *BotAPI, update Update)
+
+// Middleware wraps a HandlerFunc, to run code before or after it or to
+// decide if it should run at all.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Dispatcher passes updates to a handler through a chain of middleware.
+type Dispatcher struct {
+	Bot     *BotAPI
+	Handler HandlerFunc
+
+	middleware []Middleware
+}
+
+// NewDispatcher creates a Dispatcher passing updates to handler.
+func NewDispatcher(bot *BotAPI, handler HandlerFunc) *Dispatcher {
+	return &Dispatcher{
+		Bot:     bot,
+		Handler: handler,
+	}
+}
+
+// Use adds middleware to the Dispatcher. Middleware runs in the order
+// it was added, with the first added seeing each update first.
+func (d *Dispatcher) Use(middleware ...Middleware) {
+	d.middleware = append(d.middleware, middleware...)
+}
+
+// Dispatch handles a single update, returning once the handler has.
+func (d *Dispatcher) Dispatch(ctx context.Context, update Update) {
+	handler := d.Handler
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		handler = d.middleware[i](handler)
+	}
+
+	handler(ctx, d.Bot, update)
+}
+
+// Run handles every update from updates, each in its own goroutine,
+// until updates is closed or ctx is done. It returns once all running
+// handlers have returned.
+func (d *Dispatcher) Run(ctx context.Context, updates UpdatesChannel) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.Dispatch(ctx, update)
+			}()
+		}
+	}
+}
+
+// Recover returns Middleware which recovers from panics in handlers, so
+// a single bad update can't take down the whole bot.
+//
+// The panic is logged with its stack trace and a summary of the update,
+// leaving out message contents. If adminChatID is not zero, a notice is
+// also sent to that chat.
+func Recover(adminChatID int64) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+
+				summary := redactUpdate(update)
+				log.Printf("panic handling %s: %v\n%s", summary, err, debug.Stack())
+
+				if adminChatID != 0 && bot != nil {
+					msg := NewMessage(adminChatID, fmt.Sprintf("Panic handling %s: %v", summary, err))
+					if _, err := bot.Send(msg); err != nil {
+						log.Println(err)
+					}
+				}
+			}()
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// IgnoreOtherBotsCommands returns Middleware which drops commands that
+// are addressed to another bot, such as /start@otherbot in a group with
+// several bots. The bot's own username is found with Me.
+func IgnoreOtherBotsCommands() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			if message := update.EffectiveMessage(); message != nil && update.CallbackQuery == nil {
+				command := message.CommandWithAt()
+				if i := strings.Index(command, "@"); i != -1 {
+					self, err := bot.Me(ctx)
+					if err != nil {
+						log.Println(err)
+					} else if !strings.EqualFold(command[i+1:], self.UserName) {
+						return
+					}
+				}
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// redactUpdate describes an update without including any user content.
+func redactUpdate(update Update) string {
+	kind := update.Kind()
+	if kind == "" {
+		kind = "unknown"
+	}
+
+	return fmt.Sprintf("update %d (%s)", update.UpdateID, kind)
+}
@@ -0,0 +1,92 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestDispatcherMiddlewareOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) tgbotapi.Middleware {
+		return func(next tgbotapi.HandlerFunc) tgbotapi.HandlerFunc {
+			return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+				order = append(order, name)
+				next(ctx, bot, update)
+			}
+		}
+	}
+
+	d := tgbotapi.NewDispatcher(nil, func(context.Context, *tgbotapi.BotAPI, tgbotapi.Update) {
+		order = append(order, "handler")
+	})
+	d.Use(mark("first"), mark("second"))
+	d.Dispatch(context.Background(), tgbotapi.Update{})
+
+	if strings.Join(order, ",") != "first,second,handler" {
+		t.Fatal(order)
+	}
+}
+
+func TestDispatcherRun(t *testing.T) {
+	handled := make(chan int, 2)
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled <- update.UpdateID
+	})
+
+	updates := make(chan tgbotapi.Update, 2)
+	updates <- tgbotapi.Update{UpdateID: 1}
+	updates <- tgbotapi.Update{UpdateID: 2}
+	close(updates)
+
+	d.Run(context.Background(), updates)
+
+	if len(handled) != 2 {
+		t.Fail()
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var notice string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			notice = r.FormValue("text")
+			return tgbotapi.Message{}
+		}
+		return nil
+	})
+
+	d := tgbotapi.NewDispatcher(bot, func(context.Context, *tgbotapi.BotAPI, tgbotapi.Update) {
+		panic("oops")
+	})
+	d.Use(tgbotapi.Recover(ChatID))
+	d.Dispatch(context.Background(), tgbotapi.Update{
+		UpdateID: 5,
+		Message:  &tgbotapi.Message{Text: "secret"},
+	})
+
+	if !strings.Contains(notice, "update 5 (message): oops") || strings.Contains(notice, "secret") {
+		t.Fatal(notice)
+	}
+}
+
+func TestIgnoreOtherBotsCommands(t *testing.T) {
+	bot := newTestBot(t, func(string, *http.Request) interface{} { return nil })
+
+	var handled []string
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled = append(handled, update.Message.Text)
+	})
+	d.Use(tgbotapi.IgnoreOtherBotsCommands())
+
+	for _, text := range []string{"/start", "/start@TestBot", "/start@otherbot", "hello @otherbot"} {
+		d.Dispatch(context.Background(), tgbotapi.Update{Message: &tgbotapi.Message{Text: text}})
+	}
+
+	if strings.Join(handled, ",") != "/start,/start@TestBot,hello @otherbot" {
+		t.Fatal(handled)
+	}
+}
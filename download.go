@@ -0,0 +1,489 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DownloadLimits limits downloads of files, so one slow or unexpectedly
+// large download can't hold up a bot forever.
+type DownloadLimits struct {
+	// Timeout is the longest a download may take, including reading it.
+	Timeout time.Duration
+	// MaxBytes is the largest file which may be downloaded.
+	MaxBytes int64
+	// StallTimeout is the longest a download may go without receiving
+	// any data before it is aborted with ErrDownloadStalled.
+	StallTimeout time.Duration
+}
+
+// ParallelDownloadConfig controls how DownloadFileParallel splits a file.
+type ParallelDownloadConfig struct {
+	// Parallelism is how many ranges are downloaded at once. If it is
+	// zero, 4 are.
+	Parallelism int
+	// ChunkSize is the size of each range. If it is zero, 8MB ranges
+	// are used.
+	ChunkSize int64
+	// Checksum, if set, is checked once the file is downloaded. dst must
+	// then also be an io.ReaderAt, such as an *os.File, to read it back.
+	Checksum *DownloadChecksum
+}
+
+// DownloadChecksum is the hash a downloaded file is expected to have.
+type DownloadChecksum struct {
+	// Hash computes the hash, such as sha256.New(). It is reset before
+	// it is used.
+	Hash hash.Hash
+	// Sum is the expected hash.
+	Sum []byte
+}
+
+// TruncatedDownloadError happens when a download ends before all of the
+// file, or the range of it requested, was received.
+type TruncatedDownloadError struct {
+	Name     string
+	Size     int64
+	Received int64
+}
+
+func (e *TruncatedDownloadError) Error() string {
+	return fmt.Sprintf("%s: download truncated at %d of %d bytes", e.Name, e.Received, e.Size)
+}
+
+// ChecksumMismatchError happens when a downloaded file doesn't have the
+// hash it was expected to.
+type ChecksumMismatchError struct {
+	Name     string
+	Expected []byte
+	Actual   []byte
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s: checksum %s does not match expected %s",
+		e.Name, hex.EncodeToString(e.Actual), hex.EncodeToString(e.Expected))
+}
+
+const (
+	defaultDownloadParallelism = 4
+	defaultDownloadChunkSize   = 8 << 20
+)
+
+// DownloadFileParallel downloads a file returned by GetFile into dst,
+// such as an *os.File, by requesting ranges of it at once. This is much
+// faster for large files from a local Bot API server, which allows files
+// of up to 2GB.
+//
+// The file's FileSize must be set for it to be split. If it isn't, or the
+// server ignores the range requested, it is downloaded as one stream. As
+// with OpenFile, a file with an absolute path is copied from disk if
+// LocalFiles is set.
+//
+// It returns the number of bytes written. If fewer bytes than FileSize
+// are received, it returns a *TruncatedDownloadError.
+func (bot *BotAPI) DownloadFileParallel(ctx context.Context, file File, dst io.WriterAt, config ParallelDownloadConfig) (int64, error) {
+	var src io.ReaderAt
+	if config.Checksum != nil {
+		var ok bool
+		if src, ok = dst.(io.ReaderAt); !ok {
+			return 0, errors.New(ErrChecksumNeedsReaderAt)
+		}
+	}
+
+	n, err := bot.downloadFileParallel(ctx, file, dst, config)
+	if err != nil || config.Checksum == nil {
+		return n, err
+	}
+
+	config.Checksum.Hash.Reset()
+	if _, err := io.Copy(config.Checksum.Hash, io.NewSectionReader(src, 0, n)); err != nil {
+		return n, err
+	}
+
+	return n, config.Checksum.check(file)
+}
+
+func (bot *BotAPI) downloadFileParallel(ctx context.Context, file File, dst io.WriterAt, config ParallelDownloadConfig) (int64, error) {
+	if config.Parallelism <= 0 {
+		config.Parallelism = defaultDownloadParallelism
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = defaultDownloadChunkSize
+	}
+
+	if bot.LocalFiles && filepath.IsAbs(file.FilePath) {
+		f, err := bot.openLocalFile(file)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+
+		return io.Copy(&offsetWriter{w: dst}, f)
+	}
+
+	if err := bot.checkDownloadSize(file, int64(file.FileSize)); err != nil {
+		return 0, err
+	}
+
+	size := int64(file.FileSize)
+	if size <= config.ChunkSize {
+		return bot.downloadWhole(ctx, file, dst)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first range is fetched alone to find out if ranges are
+	// supported, before making any other requests.
+	resp, err := bot.getRange(ctx, file, 0, config.ChunkSize)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		defer resp.Body.Close()
+		return io.Copy(&offsetWriter{w: dst}, resp.Body)
+	}
+	if err := writeRange(resp, file, dst, 0, config.ChunkSize); err != nil {
+		return 0, err
+	}
+
+	offsets := make(chan int64)
+	go func() {
+		defer close(offsets)
+		for offset := config.ChunkSize; offset < size; offset += config.ChunkSize {
+			select {
+			case offsets <- offset:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for i := 0; i < config.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for offset := range offsets {
+				length := config.ChunkSize
+				if offset+length > size {
+					length = size - offset
+				}
+
+				err := bot.downloadRange(ctx, file, dst, offset, length)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// downloadWhole downloads a file into dst as one stream.
+func (bot *BotAPI) downloadWhole(ctx context.Context, file File, dst io.WriterAt) (int64, error) {
+	resp, err := bot.getFile(ctx, file, "")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.New(http.StatusText(resp.StatusCode))
+	}
+
+	return io.Copy(&offsetWriter{w: dst}, resp.Body)
+}
+
+// openLocalFile opens a file on disk, checking it against DownloadLimits.
+func (bot *BotAPI) openLocalFile(file File) (*os.File, error) {
+	f, err := os.Open(file.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if bot.DownloadLimits != nil && bot.DownloadLimits.MaxBytes > 0 {
+		info, err := f.Stat()
+		if err == nil {
+			err = bot.checkDownloadSize(file, info.Size())
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// checkDownloadSize checks the size of a file against DownloadLimits.
+func (bot *BotAPI) checkDownloadSize(file File, size int64) error {
+	if bot.DownloadLimits == nil || bot.DownloadLimits.MaxBytes <= 0 || size <= bot.DownloadLimits.MaxBytes {
+		return nil
+	}
+
+	return &FileTooLargeError{Name: file.FilePath, Size: size, Limit: bot.DownloadLimits.MaxBytes}
+}
+
+// getFile requests a file, or a range of it if rangeHeader is set. The
+// response's body is limited by DownloadLimits.
+func (bot *BotAPI) getFile(ctx context.Context, file File, rangeHeader string) (*http.Response, error) {
+	limits := DownloadLimits{}
+	if bot.DownloadLimits != nil {
+		limits = *bot.DownloadLimits
+	}
+
+	var cancel context.CancelFunc
+	if limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	req, err := http.NewRequest("GET", file.Link(string(bot.Token)), nil)
+	if err != nil {
+		cancel()
+		return nil, bot.redactError(err)
+	}
+	bot.setHeaders(req)
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	body := &limitedBody{file: file, limits: limits, cancel: cancel}
+	if limits.StallTimeout > 0 {
+		body.stall = time.AfterFunc(limits.StallTimeout, body.stalled)
+	}
+
+	resp, err := bot.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		body.stop()
+		if body.isStalled() {
+			return nil, errors.New(ErrDownloadStalled)
+		}
+		return nil, bot.redactError(err)
+	}
+
+	if resp.ContentLength > 0 && rangeHeader == "" {
+		if err := bot.checkDownloadSize(file, resp.ContentLength); err != nil {
+			resp.Body.Close()
+			body.stop()
+			return nil, err
+		}
+	}
+
+	// The whole file was sent, so it should all arrive.
+	if resp.StatusCode == http.StatusOK {
+		body.size = int64(file.FileSize)
+		if body.size == 0 && resp.ContentLength > 0 {
+			body.size = resp.ContentLength
+		}
+	}
+
+	body.body = resp.Body
+	resp.Body = body
+
+	return resp, nil
+}
+
+// limitedBody is the body of a download, limited by DownloadLimits.
+type limitedBody struct {
+	body   io.ReadCloser
+	file   File
+	limits DownloadLimits
+	read   int64
+	// size is how large the body should be, if it is known.
+	size int64
+
+	cancel context.CancelFunc
+	stall  *time.Timer
+
+	mu         sync.Mutex
+	wasStalled bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+
+	if n > 0 && b.stall != nil {
+		b.stall.Reset(b.limits.StallTimeout)
+	}
+
+	if b.limits.MaxBytes > 0 && b.read > b.limits.MaxBytes {
+		return n, &FileTooLargeError{Name: b.file.FilePath, Size: b.read, Limit: b.limits.MaxBytes}
+	}
+
+	if err != nil && err != io.EOF && b.isStalled() {
+		return n, errors.New(ErrDownloadStalled)
+	}
+
+	if (err == io.EOF || err == io.ErrUnexpectedEOF) && b.read < b.size {
+		return n, &TruncatedDownloadError{Name: b.file.FilePath, Size: b.size, Received: b.read}
+	}
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	err := b.body.Close()
+	b.stop()
+
+	return err
+}
+
+// stalled aborts the download when no data has arrived for a while.
+func (b *limitedBody) stalled() {
+	b.mu.Lock()
+	b.wasStalled = true
+	b.mu.Unlock()
+
+	b.cancel()
+}
+
+func (b *limitedBody) isStalled() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.wasStalled
+}
+
+// stop stops the stall timer and releases the request's context.
+func (b *limitedBody) stop() {
+	if b.stall != nil {
+		b.stall.Stop()
+	}
+	b.cancel()
+}
+
+// downloadRange downloads part of a file into the same place in dst.
+func (bot *BotAPI) downloadRange(ctx context.Context, file File, dst io.WriterAt, offset, length int64) error {
+	resp, err := bot.getRange(ctx, file, offset, length)
+	if err != nil {
+		return err
+	}
+
+	return writeRange(resp, file, dst, offset, length)
+}
+
+// getRange requests part of a file. The response is either the range, or
+// the whole file if the server doesn't support ranges.
+func (bot *BotAPI) getRange(ctx context.Context, file File, offset, length int64) (*http.Response, error) {
+	resp, err := bot.getFile(ctx, file, fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.New(http.StatusText(resp.StatusCode))
+	}
+
+	return resp, nil
+}
+
+// writeRange writes a range from a response into dst, checking that all
+// of it was sent.
+func writeRange(resp *http.Response, file File, dst io.WriterAt, offset, length int64) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return errors.New("server does not support ranges")
+	}
+
+	n, err := io.Copy(&offsetWriter{w: dst, offset: offset}, io.LimitReader(resp.Body, length))
+	if err == io.ErrUnexpectedEOF || err == nil && n != length {
+		return &TruncatedDownloadError{Name: file.FilePath, Size: length, Received: n}
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// offsetWriter writes sequentially to an io.WriterAt from an offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.WriteAt(p, w.offset)
+	w.offset += int64(n)
+
+	return n, err
+}
+
+// OpenFileVerified is OpenFileContext, checking the file has the hash
+// given by checksum. Reading the last of the file returns a
+// *ChecksumMismatchError instead of io.EOF if it doesn't, so a partial
+// or corrupted file is never mistaken for a whole one.
+func (bot *BotAPI) OpenFileVerified(ctx context.Context, file File, checksum DownloadChecksum) (io.ReadCloser, error) {
+	r, err := bot.OpenFileContext(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum.Hash.Reset()
+
+	return &checksumReader{ReadCloser: r, file: file, checksum: checksum}, nil
+}
+
+// check compares the hash computed so far with the expected one.
+func (c *DownloadChecksum) check(file File) error {
+	actual := c.Hash.Sum(nil)
+	if !bytes.Equal(actual, c.Sum) {
+		return &ChecksumMismatchError{Name: file.FilePath, Expected: c.Sum, Actual: actual}
+	}
+
+	return nil
+}
+
+// checksumReader hashes a file as it is read, checking it at the end.
+type checksumReader struct {
+	io.ReadCloser
+	file     File
+	checksum DownloadChecksum
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.checksum.Hash.Write(p[:n])
+
+	if err == io.EOF {
+		if mismatch := r.checksum.check(r.file); mismatch != nil {
+			return n, mismatch
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,239 @@
+package tgbotapi_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// memoryFile is an io.WriterAt in memory.
+type memoryFile struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (f *memoryFile) WriteAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if end := int(off) + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+
+	return copy(f.data[off:], p), nil
+}
+
+func (f *memoryFile) ReadAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestDownloadFileParallel(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), 100)
+
+	tests := []struct {
+		name     string
+		ranges   bool
+		size     int
+		requests int32
+	}{
+		{"ranges", true, len(content), 11},
+		{"no ranges", false, len(content), 1},
+		{"unknown size", true, 0, 1},
+	}
+
+	for _, test := range tests {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.URL.Path != "/file/bottoken/videos/file_1.mp4" {
+				http.NotFound(w, r)
+				return
+			}
+			if !test.ranges {
+				r.Header.Del("Range")
+			}
+			http.ServeContent(w, r, "file_1.mp4", time.Time{}, bytes.NewReader(content))
+		}))
+
+		bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+			return nil
+		})
+		u, _ := url.Parse(server.URL)
+		bot.Client = &http.Client{Transport: testTransport{u}}
+
+		dst := &memoryFile{}
+		file := tgbotapi.File{FileID: "file", FilePath: "videos/file_1.mp4", FileSize: test.size}
+
+		n, err := bot.DownloadFileParallel(context.Background(), file, dst, tgbotapi.ParallelDownloadConfig{
+			Parallelism: 3,
+			ChunkSize:   350,
+		})
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if n != int64(len(content)) || !bytes.Equal(dst.data, content) {
+			t.Errorf("%s: downloaded %d bytes which don't match", test.name, n)
+		}
+		if requests != test.requests {
+			t.Errorf("%s: expected %d requests, got %d", test.name, test.requests, requests)
+		}
+	}
+}
+
+func TestDownloadLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file/bottoken/big.mp4":
+			w.Write(make([]byte, 2048))
+		case "/file/bottoken/stalls.mp4":
+			w.Write([]byte("some"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			w.Write([]byte("small"))
+		}
+	}))
+	defer server.Close()
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+	u, _ := url.Parse(server.URL)
+	bot.Client = &http.Client{Transport: testTransport{u}}
+	bot.DownloadLimits = &tgbotapi.DownloadLimits{
+		MaxBytes:     1024,
+		StallTimeout: 50 * time.Millisecond,
+	}
+
+	open := func(path string) error {
+		f, err := bot.OpenFileContext(context.Background(), tgbotapi.File{FilePath: path})
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = ioutil.ReadAll(f)
+		return err
+	}
+
+	if err := open("small.jpg"); err != nil {
+		t.Errorf("small file: %v", err)
+	}
+
+	if _, ok := open("big.mp4").(*tgbotapi.FileTooLargeError); !ok {
+		t.Error("expected big file to be too large")
+	}
+
+	start := time.Now()
+	if err := open("stalls.mp4"); err == nil || err.Error() != tgbotapi.ErrDownloadStalled {
+		t.Errorf("expected download to stall, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("stalled download was not aborted")
+	}
+
+	_, err := bot.DownloadFileParallel(context.Background(), tgbotapi.File{FilePath: "big.mp4", FileSize: 2048}, &memoryFile{}, tgbotapi.ParallelDownloadConfig{})
+	if _, ok := err.(*tgbotapi.FileTooLargeError); !ok {
+		t.Errorf("expected parallel download to be too large, got %v", err)
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file/bottoken/cut.mp4":
+			w.Header().Set("Content-Length", "1000")
+			w.Write(content[:100])
+		default:
+			http.ServeContent(w, r, "file.mp4", time.Time{}, bytes.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+	u, _ := url.Parse(server.URL)
+	bot.Client = &http.Client{Transport: testTransport{u}}
+
+	read := func(file tgbotapi.File, checksum []byte) error {
+		f, err := bot.OpenFileVerified(context.Background(), file, tgbotapi.DownloadChecksum{Hash: sha256.New(), Sum: checksum})
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = ioutil.ReadAll(f)
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	if err := read(tgbotapi.File{FilePath: "file.mp4", FileSize: len(content)}, sum[:]); err != nil {
+		t.Errorf("expected the file to match, got %v", err)
+	}
+
+	err := read(tgbotapi.File{FilePath: "file.mp4"}, []byte("wrong"))
+	if _, ok := err.(*tgbotapi.ChecksumMismatchError); !ok {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+
+	err = read(tgbotapi.File{FilePath: "cut.mp4"}, sum[:])
+	if truncated, ok := err.(*tgbotapi.TruncatedDownloadError); !ok || truncated.Received != 100 || truncated.Size != 1000 {
+		t.Errorf("expected the download to be truncated, got %v", err)
+	}
+
+	err = read(tgbotapi.File{FilePath: "file.mp4", FileSize: 2000}, sum[:])
+	if _, ok := err.(*tgbotapi.TruncatedDownloadError); !ok {
+		t.Errorf("expected a file smaller than FileSize to be truncated, got %v", err)
+	}
+
+	file := tgbotapi.File{FilePath: "file.mp4", FileSize: len(content)}
+	config := tgbotapi.ParallelDownloadConfig{
+		ChunkSize: 300,
+		Checksum:  &tgbotapi.DownloadChecksum{Hash: sha256.New(), Sum: sum[:]},
+	}
+
+	if _, err := bot.DownloadFileParallel(context.Background(), file, &memoryFile{}, config); err != nil {
+		t.Errorf("expected the parallel download to match, got %v", err)
+	}
+
+	config.Checksum.Sum = []byte("wrong")
+	_, err = bot.DownloadFileParallel(context.Background(), file, &memoryFile{}, config)
+	if _, ok := err.(*tgbotapi.ChecksumMismatchError); !ok {
+		t.Errorf("expected a parallel checksum mismatch, got %v", err)
+	}
+
+	_, err = bot.DownloadFileParallel(context.Background(), file, struct{ io.WriterAt }{&memoryFile{}}, config)
+	if err == nil || err.Error() != tgbotapi.ErrChecksumNeedsReaderAt {
+		t.Errorf("expected an error for a dst which can't be read, got %v", err)
+	}
+}
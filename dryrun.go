@@ -0,0 +1,141 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// WithDryRun makes a bot log the requests it would make instead of
+// making them, as with DryRun.
+func WithDryRun() BotOption {
+	return func(bot *BotAPI) {
+		bot.DryRun = true
+	}
+}
+
+// dryRunMessageID numbers the messages sent in dry runs.
+var dryRunMessageID int64
+
+// isDryRun returns if a request to method should be logged instead of
+// made. Methods which only get something are still made.
+func (bot *BotAPI) isDryRun(method string) bool {
+	return bot.DryRun && !strings.HasPrefix(method, "get")
+}
+
+// dryRun logs a request, and returns a result like Telegram's for it,
+// decoding it into result if it is not nil.
+func (bot *BotAPI) dryRun(method string, params url.Values, files []RequestFile, result interface{}) (APIResponse, error) {
+	log.Printf("dry run: %s %s", method, describeRequest(params, files))
+
+	data, err := json.Marshal(bot.dryRunResult(method, params))
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	apiResp := APIResponse{Ok: true, Result: data}
+
+	switch r := result.(type) {
+	case nil:
+		return apiResp, nil
+	case resultStreamer:
+		return APIResponse{Ok: true}, r.streamResult(json.NewDecoder(bytes.NewReader(data)))
+	default:
+		return APIResponse{Ok: true}, json.Unmarshal(data, result)
+	}
+}
+
+// dryRunResult returns what a request would return: a message for those
+// which send or edit one, or true.
+func (bot *BotAPI) dryRunResult(method string, params url.Values) interface{} {
+	if method == "sendMediaGroup" {
+		var media []map[string]interface{}
+		json.Unmarshal([]byte(params.Get("media")), &media)
+
+		messages := make([]Message, len(media))
+		for i, m := range media {
+			messages[i] = bot.dryRunMessage(params)
+			messages[i].Caption, _ = m["caption"].(string)
+		}
+		return messages
+	}
+
+	if sendsMessage(method) || strings.HasPrefix(method, "edit") && params.Get("inline_message_id") == "" {
+		return bot.dryRunMessage(params)
+	}
+
+	return true
+}
+
+// dryRunMessage returns a message as it would be sent with params.
+func (bot *BotAPI) dryRunMessage(params url.Values) Message {
+	self := bot.self()
+	chat := &Chat{Type: ChatTypePrivate}
+	if id, err := strconv.ParseInt(params.Get("chat_id"), 10, 64); err == nil {
+		chat.ID = id
+		if id < 0 {
+			chat.Type = ChatTypeSuperGroup
+		}
+	} else {
+		chat.UserName = strings.TrimPrefix(params.Get("chat_id"), "@")
+		chat.Type = ChatTypeChannel
+	}
+
+	messageID, _ := strconv.Atoi(params.Get("message_id"))
+	if messageID == 0 {
+		messageID = int(atomic.AddInt64(&dryRunMessageID, 1))
+	}
+
+	return Message{
+		MessageID: messageID,
+		From:      &self,
+		Date:      int(time.Now().Unix()),
+		Chat:      chat,
+		Text:      params.Get("text"),
+		Caption:   params.Get("caption"),
+	}
+}
+
+// describeRequest formats the parameters of a request for logging, with
+// files to upload summarized instead of included.
+func describeRequest(params url.Values, files []RequestFile) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+len(files))
+	for _, key := range keys {
+		parts = append(parts, key+"="+strconv.Quote(params.Get(key)))
+	}
+	for _, file := range files {
+		parts = append(parts, file.Name+"="+describeFile(file.Data))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// describeFile summarizes a file to upload.
+func describeFile(file RequestFileData) string {
+	switch f := file.(type) {
+	case FileBytes:
+		return fmt.Sprintf("<%s, %d bytes>", f.Name, len(f.Bytes))
+	case FileReader:
+		if f.Size > 0 {
+			return fmt.Sprintf("<%s, %d bytes>", f.Name, f.Size)
+		}
+		return fmt.Sprintf("<%s>", f.Name)
+	case FilePath:
+		return fmt.Sprintf("<%s>", string(f))
+	}
+
+	return "<file>"
+}
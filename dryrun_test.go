@@ -0,0 +1,62 @@
+package tgbotapi_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestDryRun(t *testing.T) {
+	var requested []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		requested = append(requested, method)
+		if method == "getChat" {
+			return tgbotapi.Chat{ID: ChatID, Type: "private"}
+		}
+		return nil
+	})
+	requested = nil
+	tgbotapi.WithDryRun()(bot)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	message, err := bot.Send(tgbotapi.NewMessage(ChatID, "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Text != "hello" || message.Chat == nil || message.Chat.ID != ChatID || message.MessageID == 0 {
+		t.Errorf("unexpected message %+v", message)
+	}
+
+	photo := tgbotapi.NewPhotoUpload(ChatID, tgbotapi.FileBytes{Name: "image.jpg", Bytes: []byte("jpeg")})
+	photo.Caption = "a photo"
+	if message, err = bot.Send(photo); err != nil {
+		t.Fatal(err)
+	}
+	if message.Caption != "a photo" {
+		t.Errorf("unexpected message %+v", message)
+	}
+
+	if _, err := bot.GetChat(tgbotapi.ChatConfig{ChatID: ChatID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requested) != 1 || requested[0] != "getChat" {
+		t.Errorf("expected only getChat to be requested, got %v", requested)
+	}
+
+	out := logged.String()
+	if !strings.Contains(out, `dry run: sendMessage chat_id="76918703"`) || !strings.Contains(out, `text="hello"`) {
+		t.Errorf("expected the message to be logged, got %q", out)
+	}
+	if !strings.Contains(out, `photo=<image.jpg, 4 bytes>`) {
+		t.Errorf("expected the upload to be summarized, got %q", out)
+	}
+}
@@ -0,0 +1,360 @@
+package tgbotapi
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// EntityText returns the part of the message text an entity covers.
+// Entity offsets count UTF-16 code units, so they can't be used to slice
+// the text directly.
+func (m *Message) EntityText(entity MessageEntity) string {
+	text := utf16.Encode([]rune(m.Text))
+
+	start, end := entity.Offset, entity.Offset+entity.Length
+	if start < 0 || end > len(text) || start > end {
+		return ""
+	}
+
+	return string(utf16.Decode(text[start:end]))
+}
+
+// entitiesOfType returns the message's entities of a type.
+func (m *Message) entitiesOfType(entityType EntityType) []MessageEntity {
+	if m.Entities == nil {
+		return nil
+	}
+
+	var entities []MessageEntity
+	for _, entity := range *m.Entities {
+		if entity.Type == entityType {
+			entities = append(entities, entity)
+		}
+	}
+
+	return entities
+}
+
+// entityTexts returns the text of each of the message's entities of a
+// type.
+func (m *Message) entityTexts(entityType EntityType) []string {
+	var texts []string
+	for _, entity := range m.entitiesOfType(entityType) {
+		texts = append(texts, m.EntityText(entity))
+	}
+
+	return texts
+}
+
+// Mentions returns the @usernames mentioned in the message.
+func (m *Message) Mentions() []string {
+	return m.entityTexts(EntityTypeMention)
+}
+
+// Hashtags returns the #hashtags in the message.
+func (m *Message) Hashtags() []string {
+	return m.entityTexts(EntityTypeHashtag)
+}
+
+// BotCommands returns the /commands in the message, including any
+// @botname they are addressed to.
+func (m *Message) BotCommands() []string {
+	return m.entityTexts(EntityTypeBotCommand)
+}
+
+// URLs returns the URLs in the message, both those written in the text
+// and those behind text links.
+func (m *Message) URLs() []string {
+	if m.Entities == nil {
+		return nil
+	}
+
+	var urls []string
+	for _, entity := range *m.Entities {
+		switch entity.Type {
+		case EntityTypeURL:
+			urls = append(urls, m.EntityText(entity))
+		case EntityTypeTextLink:
+			urls = append(urls, entity.URL)
+		}
+	}
+
+	return urls
+}
+
+// TextMentions returns the users mentioned in the message by name, who
+// don't have a username.
+func (m *Message) TextMentions() []User {
+	var users []User
+	for _, entity := range m.entitiesOfType(EntityTypeTextMention) {
+		if entity.User != nil {
+			users = append(users, *entity.User)
+		}
+	}
+
+	return users
+}
+
+// Span is a piece of message text, which may be formatted by an entity.
+// Messages built from spans need no parse mode, so they are safe for any
+// text, including text from users.
+type Span struct {
+	Text string
+	// Entity is the formatting of the text. Its Offset and Length are set
+	// by BuildText. If Type is empty, the text is not formatted.
+	Entity MessageEntity
+}
+
+// Plain is unformatted text.
+func Plain(text string) Span {
+	return Span{Text: text}
+}
+
+// Bold is bold text.
+func Bold(text string) Span {
+	return Span{Text: text, Entity: MessageEntity{Type: EntityTypeBold}}
+}
+
+// Italic is italic text.
+func Italic(text string) Span {
+	return Span{Text: text, Entity: MessageEntity{Type: EntityTypeItalic}}
+}
+
+// Code is inline monospaced text.
+func Code(text string) Span {
+	return Span{Text: text, Entity: MessageEntity{Type: EntityTypeCode}}
+}
+
+// Pre is a block of monospaced text.
+func Pre(text string) Span {
+	return Span{Text: text, Entity: MessageEntity{Type: EntityTypePre}}
+}
+
+// TextLink is text linking to url.
+func TextLink(text, url string) Span {
+	return Span{Text: text, Entity: MessageEntity{Type: EntityTypeTextLink, URL: url}}
+}
+
+// Mention is a mention of a user by their name, which works for users
+// without a username.
+func Mention(user *User) Span {
+	name, entity := user.MentionEntity(0)
+
+	return Span{Text: name, Entity: entity}
+}
+
+// BuildText joins spans into message text and the entities formatting it,
+// with offsets counted in UTF-16 code units as Telegram requires.
+func BuildText(spans ...Span) (string, []MessageEntity) {
+	var text []rune
+	var entities []MessageEntity
+	offset := 0
+
+	for _, span := range spans {
+		runes := []rune(span.Text)
+		length := len(utf16.Encode(runes))
+
+		if span.Entity.Type != "" && length > 0 {
+			entity := span.Entity
+			entity.Offset = offset
+			entity.Length = length
+			entities = append(entities, entity)
+		}
+
+		text = append(text, runes...)
+		offset += length
+	}
+
+	return string(text), entities
+}
+
+// NewMessageFromSpans creates a new Message from spans of formatted text.
+func NewMessageFromSpans(chatID int64, spans ...Span) MessageConfig {
+	text, entities := BuildText(spans...)
+
+	msg := NewMessage(chatID, text)
+	msg.Entities = entities
+
+	return msg
+}
+
+// NewReplyWithQuote creates a new Message replying to replyTo in the same
+// chat, quoting only quoteText from it. quoteText must appear exactly in
+// the message being replied to.
+func NewReplyWithQuote(chatID int64, replyTo int, quoteText, text string) MessageConfig {
+	msg := NewMessage(chatID, text)
+	msg.ReplyParameters = &ReplyParameters{
+		MessageID: replyTo,
+		Quote:     quoteText,
+	}
+
+	return msg
+}
+
+// QuoteReply returns ReplyParameters for quoting the first occurrence of
+// quote in the message's text, with its position and the entities
+// formatting it, offset from the start of the quote. It returns false if
+// the text does not contain quote.
+func (m *Message) QuoteReply(quote string) (ReplyParameters, bool) {
+	index := strings.Index(m.Text, quote)
+	if quote == "" || index < 0 {
+		return ReplyParameters{}, false
+	}
+
+	start := len(utf16.Encode([]rune(m.Text[:index])))
+	end := start + len(utf16.Encode([]rune(quote)))
+
+	params := ReplyParameters{
+		MessageID:     m.MessageID,
+		Quote:         quote,
+		QuotePosition: start,
+	}
+
+	if m.Entities != nil {
+		for _, entity := range *m.Entities {
+			from, to := entity.Offset, entity.Offset+entity.Length
+			if to <= start || from >= end {
+				continue
+			}
+			if from < start {
+				from = start
+			}
+			if to > end {
+				to = end
+			}
+
+			entity.Offset = from - start
+			entity.Length = to - from
+			params.QuoteEntities = append(params.QuoteEntities, entity)
+		}
+	}
+
+	return params, true
+}
+
+// FormattedText is message text with the entities formatting it, such
+// as from BuildText or a received message. FormattedText values can be
+// joined with ConcatText and FormatText, which keep the entities in
+// place.
+type FormattedText struct {
+	Text     string
+	Entities []MessageEntity
+}
+
+// NewFormattedText creates FormattedText from spans of formatted text.
+func NewFormattedText(spans ...Span) FormattedText {
+	text, entities := BuildText(spans...)
+	return FormattedText{Text: text, Entities: entities}
+}
+
+// FormattedText returns the message's text along with its entities.
+func (m *Message) FormattedText() FormattedText {
+	text := FormattedText{Text: m.Text}
+	if m.Entities != nil {
+		text.Entities = append([]MessageEntity(nil), *m.Entities...)
+	}
+
+	return text
+}
+
+// ConcatText joins pieces of formatted text, moving the entities of each
+// piece to where it ends up.
+func ConcatText(parts ...FormattedText) FormattedText {
+	var b formattedTextBuilder
+	for _, part := range parts {
+		b.add(part)
+	}
+
+	return b.text()
+}
+
+// FormatText replaces each {} in template, which is plain text, with the
+// next of args, moving the entities of args to where they end up.
+// Placeholders without a matching argument are left as they are, and
+// extra arguments are ignored.
+func FormatText(template string, args ...FormattedText) FormattedText {
+	var b formattedTextBuilder
+
+	for len(args) > 0 {
+		i := strings.Index(template, "{}")
+		if i < 0 {
+			break
+		}
+
+		b.add(FormattedText{Text: template[:i]})
+		b.add(args[0])
+
+		template = template[i+2:]
+		args = args[1:]
+	}
+	b.add(FormattedText{Text: template})
+
+	return b.text()
+}
+
+// NewMessageFormatted creates a new Message from formatted text.
+func NewMessageFormatted(chatID int64, text FormattedText) MessageConfig {
+	msg := NewMessage(chatID, text.Text)
+	msg.Entities = text.Entities
+
+	return msg
+}
+
+type formattedTextBuilder struct {
+	buf      strings.Builder
+	entities []MessageEntity
+	offset   int
+}
+
+func (b *formattedTextBuilder) add(part FormattedText) {
+	for _, entity := range part.Entities {
+		entity.Offset += b.offset
+		b.entities = append(b.entities, entity)
+	}
+
+	b.buf.WriteString(part.Text)
+	b.offset += len(utf16.Encode([]rune(part.Text)))
+}
+
+func (b *formattedTextBuilder) text() FormattedText {
+	return FormattedText{Text: b.buf.String(), Entities: b.entities}
+}
+
+// Limits on the length of text, in UTF-16 code units.
+const (
+	MaxMessageTextLength = 4096
+	MaxCaptionLength     = 1024
+)
+
+// TruncateWithEntities shortens text to at most limit UTF-16 code units,
+// such as MaxMessageTextLength, without splitting a character in two.
+// Entities past the end are dropped and those crossing it are shortened,
+// so the result stays consistent.
+func TruncateWithEntities(text string, entities []MessageEntity, limit int) (string, []MessageEntity) {
+	units := utf16.Encode([]rune(text))
+	if len(units) <= limit {
+		return text, entities
+	}
+
+	end := limit
+	if end < 0 {
+		end = 0
+	}
+	// Don't keep the first half of a surrogate pair.
+	if end > 0 && units[end-1] >= 0xd800 && units[end-1] < 0xdc00 {
+		end--
+	}
+
+	var truncated []MessageEntity
+	for _, entity := range entities {
+		if entity.Offset >= end {
+			continue
+		}
+		if entity.Offset+entity.Length > end {
+			entity.Length = end - entity.Offset
+		}
+		truncated = append(truncated, entity)
+	}
+
+	return string(utf16.Decode(units[:end])), truncated
+}
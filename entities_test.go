@@ -0,0 +1,172 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestMessageEntities(t *testing.T) {
+	// The emoji is two UTF-16 code units, shifting every offset after it.
+	message := tgbotapi.Message{
+		Text: "😀 @alice #go /start@bot see https://example.com or docs, Bob",
+		Entities: &[]tgbotapi.MessageEntity{
+			{Type: "mention", Offset: 3, Length: 6},
+			{Type: "hashtag", Offset: 10, Length: 3},
+			{Type: "bot_command", Offset: 14, Length: 10},
+			{Type: "url", Offset: 29, Length: 19},
+			{Type: "text_link", Offset: 52, Length: 4, URL: "https://example.com/docs"},
+			{Type: "text_mention", Offset: 58, Length: 3, User: &tgbotapi.User{ID: 5, FirstName: "Bob"}},
+		},
+	}
+
+	check := func(name string, got []string, expected string) {
+		if strings.Join(got, ",") != expected {
+			t.Errorf("%s: got %q, expected %q", name, got, expected)
+		}
+	}
+
+	check("mentions", message.Mentions(), "@alice")
+	check("hashtags", message.Hashtags(), "#go")
+	check("commands", message.BotCommands(), "/start@bot")
+	check("urls", message.URLs(), "https://example.com,https://example.com/docs")
+
+	if users := message.TextMentions(); len(users) != 1 || users[0].ID != 5 {
+		t.Errorf("got text mentions %v", users)
+	}
+}
+
+func TestBuildText(t *testing.T) {
+	user := &tgbotapi.User{ID: 7, FirstName: "Zoë"}
+
+	text, entities := tgbotapi.BuildText(
+		tgbotapi.Bold("👋 Hi "),
+		tgbotapi.Mention(user),
+		tgbotapi.Plain(", error: "),
+		tgbotapi.Code("<*_>"),
+		tgbotapi.Italic(""),
+	)
+
+	if text != "👋 Hi Zoë, error: <*_>" {
+		t.Errorf("got text %q", text)
+	}
+
+	expected := []tgbotapi.MessageEntity{
+		{Type: "bold", Offset: 0, Length: 6},
+		{Type: "text_mention", Offset: 6, Length: 3, User: user},
+		{Type: "code", Offset: 18, Length: 4},
+	}
+	if len(entities) != len(expected) {
+		t.Fatalf("got entities %+v", entities)
+	}
+	for i, entity := range entities {
+		if entity != expected[i] {
+			t.Errorf("entity %d is %+v, expected %+v", i, entity, expected[i])
+		}
+	}
+
+	message := tgbotapi.Message{Text: text, Entities: &entities}
+	if code := message.EntityText(entities[2]); code != "<*_>" {
+		t.Errorf("code entity covers %q", code)
+	}
+}
+
+func TestTruncateWithEntities(t *testing.T) {
+	text, entities := tgbotapi.BuildText(
+		tgbotapi.Bold("bold"),
+		tgbotapi.Plain(" "),
+		tgbotapi.Italic("italic😀"),
+		tgbotapi.Code("code"),
+	)
+
+	// The limit falls inside the emoji, which must be dropped whole.
+	truncated, kept := tgbotapi.TruncateWithEntities(text, entities, 12)
+
+	if truncated != "bold italic" {
+		t.Errorf("got text %q", truncated)
+	}
+	if len(kept) != 2 || kept[1].Type != "italic" || kept[1].Offset != 5 || kept[1].Length != 6 {
+		t.Errorf("got entities %+v", kept)
+	}
+
+	if same, _ := tgbotapi.TruncateWithEntities(text, entities, tgbotapi.MaxCaptionLength); same != text {
+		t.Error("text within the limit was changed")
+	}
+}
+
+func TestQuoteReply(t *testing.T) {
+	text, entities := tgbotapi.BuildText(
+		tgbotapi.Plain("😀 first. "),
+		tgbotapi.Bold("second sentence"),
+		tgbotapi.Plain(" ends."),
+	)
+	message := tgbotapi.Message{MessageID: 7, Text: text, Entities: &entities}
+
+	params, ok := message.QuoteReply("sentence ends")
+	if !ok {
+		t.Fatal("quote not found")
+	}
+
+	// The emoji is two UTF-16 code units.
+	if params.MessageID != 7 || params.QuotePosition != 17 {
+		t.Errorf("got %+v", params)
+	}
+	if len(params.QuoteEntities) != 1 || params.QuoteEntities[0].Offset != 0 || params.QuoteEntities[0].Length != 8 {
+		t.Errorf("got entities %+v", params.QuoteEntities)
+	}
+
+	if _, ok := message.QuoteReply("missing"); ok {
+		t.Error("found a missing quote")
+	}
+}
+
+func TestNewReplyWithQuote(t *testing.T) {
+	var reply string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			reply = r.FormValue("reply_parameters")
+		}
+		return nil
+	})
+
+	bot.Send(tgbotapi.NewReplyWithQuote(ChatID, 5, "second", "indeed"))
+
+	if reply != `{"message_id":5,"quote":"second"}` {
+		t.Errorf("got reply_parameters %s", reply)
+	}
+}
+
+func TestConcatText(t *testing.T) {
+	header := tgbotapi.NewFormattedText(tgbotapi.Bold("😀 Header"), tgbotapi.Plain("\n"))
+	body := tgbotapi.NewFormattedText(tgbotapi.Plain("see "), tgbotapi.TextLink("here", "https://example.com"))
+
+	text := tgbotapi.ConcatText(header, body)
+
+	if text.Text != "😀 Header\nsee here" {
+		t.Errorf("got text %q", text.Text)
+	}
+	// The emoji is two UTF-16 code units.
+	if len(text.Entities) != 2 || text.Entities[0].Length != 9 || text.Entities[1].Offset != 14 || text.Entities[1].Length != 4 {
+		t.Errorf("got entities %+v", text.Entities)
+	}
+
+	if header.Entities[0].Offset != 0 || body.Entities[0].Offset != 4 {
+		t.Error("parts were changed")
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	name := tgbotapi.NewFormattedText(tgbotapi.Bold("Bob"))
+	count := tgbotapi.NewFormattedText(tgbotapi.Code("3"))
+
+	text := tgbotapi.FormatText("Hi {}, you have {} messages {}", name, count)
+
+	if text.Text != "Hi Bob, you have 3 messages {}" {
+		t.Errorf("got text %q", text.Text)
+	}
+	if len(text.Entities) != 2 || text.Entities[0].Offset != 3 || text.Entities[1].Offset != 17 {
+		t.Errorf("got entities %+v", text.Entities)
+	}
+}
@@ -0,0 +1,215 @@
+package tgbotapi
+
+import (
+	"context"
+	"time"
+)
+
+// ChatEventKind is a kind of ChatEvent.
+type ChatEventKind string
+
+// Kinds of ChatEvent.
+const (
+	ChatEventMemberJoined  ChatEventKind = "member_joined"
+	ChatEventMemberLeft    ChatEventKind = "member_left"
+	ChatEventMemberUpdated ChatEventKind = "member_updated" // promoted, restricted, and so on
+	ChatEventTitleChanged  ChatEventKind = "title_changed"
+	ChatEventPhotoChanged  ChatEventKind = "photo_changed"
+	ChatEventPhotoDeleted  ChatEventKind = "photo_deleted"
+	ChatEventChatCreated   ChatEventKind = "chat_created"
+	ChatEventMessagePinned ChatEventKind = "message_pinned"
+	ChatEventMigrated      ChatEventKind = "migrated"
+
+	ChatEventVideoChatScheduled ChatEventKind = "video_chat_scheduled"
+	ChatEventVideoChatStarted   ChatEventKind = "video_chat_started"
+	ChatEventVideoChatEnded     ChatEventKind = "video_chat_ended"
+	ChatEventVideoChatInvited   ChatEventKind = "video_chat_invited"
+
+	ChatEventBoosted      ChatEventKind = "boosted"
+	ChatEventBoostRemoved ChatEventKind = "boost_removed"
+)
+
+// ChatEvent is something that happened to a chat, from a service message
+// or a member or boost update. Only the fields for its Kind are set.
+type ChatEvent struct {
+	Kind ChatEventKind
+	Chat *Chat
+	Date time.Time
+	// Actor is who caused the event, if known. It is the member themself
+	// when they joined or left by their own choice.
+	Actor *User
+	// Member is who joined, left, was updated, or boosted the chat.
+	Member *User
+	// OldStatus and NewStatus are the member's status before and after
+	// the event, for events from member updates.
+	OldStatus *ChatMember
+	NewStatus *ChatMember
+
+	Title         string      // ChatEventTitleChanged
+	Photo         []PhotoSize // ChatEventPhotoChanged
+	PinnedMessage *Message    // ChatEventMessagePinned
+	// MigrateToChatID is the supergroup a group became, and
+	// MigrateFromChatID the group a supergroup was, for ChatEventMigrated.
+	MigrateToChatID   int64
+	MigrateFromChatID int64
+
+	VideoChatStart    time.Time     // ChatEventVideoChatScheduled
+	VideoChatDuration time.Duration // ChatEventVideoChatEnded
+	Invited           []User        // ChatEventVideoChatInvited
+
+	// BoostID is the boost added or removed, and BoostCount how many
+	// times a user boosted the chat, if it is known.
+	BoostID    string
+	BoostCount int
+
+	// Update is the update the event came from.
+	Update *Update
+}
+
+// ChatEventHandler handles a ChatEvent.
+type ChatEventHandler func(ctx context.Context, bot *BotAPI, event ChatEvent)
+
+// OnChatEvent returns Middleware which calls handler with the ChatEvents
+// of each update, before passing it on.
+//
+// Joins and leaves in groups come both as service messages and, if the
+// bot is an administrator getting chat_member updates, as member updates,
+// so each is seen twice. Bots getting chat_member updates may want to
+// ignore events where Update.Message is set for those kinds.
+func OnChatEvent(handler ChatEventHandler) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			for _, event := range ParseChatEvents(&update) {
+				handler(ctx, bot, event)
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// ParseChatEvents returns the ChatEvents of an update, or none if it is
+// not a service message or a member or boost update. A message may have
+// several, such as one for each member added.
+func ParseChatEvents(update *Update) []ChatEvent {
+	switch {
+	case update.Message != nil:
+		return messageChatEvents(update, update.Message)
+	case update.ChannelPost != nil:
+		return messageChatEvents(update, update.ChannelPost)
+	case update.MyChatMember != nil:
+		return memberChatEvents(update, update.MyChatMember)
+	case update.ChatMember != nil:
+		return memberChatEvents(update, update.ChatMember)
+	case update.ChatBoost != nil:
+		boost := update.ChatBoost
+		return []ChatEvent{{
+			Kind:    ChatEventBoosted,
+			Chat:    boost.Chat,
+			Date:    unixTime(int64(boost.Boost.AddDate)),
+			Member:  boost.Boost.Source.booster(),
+			BoostID: boost.Boost.BoostID,
+			Update:  update,
+		}}
+	case update.RemovedChatBoost != nil:
+		boost := update.RemovedChatBoost
+		return []ChatEvent{{
+			Kind:    ChatEventBoostRemoved,
+			Chat:    boost.Chat,
+			Date:    unixTime(int64(boost.RemoveDate)),
+			Member:  boost.Source.booster(),
+			BoostID: boost.BoostID,
+			Update:  update,
+		}}
+	}
+
+	return nil
+}
+
+// messageChatEvents returns the ChatEvents of a service message.
+func messageChatEvents(update *Update, message *Message) []ChatEvent {
+	var events []ChatEvent
+	add := func(event ChatEvent) {
+		event.Chat = message.Chat
+		event.Date = message.Time()
+		event.Update = update
+		if event.Actor == nil {
+			event.Actor = message.From
+		}
+		events = append(events, event)
+	}
+
+	members := message.NewChatMembers
+	if len(members) == 0 && message.NewChatMember != nil {
+		members = []User{*message.NewChatMember}
+	}
+	for i := range members {
+		add(ChatEvent{Kind: ChatEventMemberJoined, Member: &members[i]})
+	}
+
+	switch {
+	case message.LeftChatMember != nil:
+		add(ChatEvent{Kind: ChatEventMemberLeft, Member: message.LeftChatMember})
+	case message.NewChatTitle != "":
+		add(ChatEvent{Kind: ChatEventTitleChanged, Title: message.NewChatTitle})
+	case message.NewChatPhoto != nil:
+		add(ChatEvent{Kind: ChatEventPhotoChanged, Photo: *message.NewChatPhoto})
+	case message.DeleteChatPhoto:
+		add(ChatEvent{Kind: ChatEventPhotoDeleted})
+	case message.GroupChatCreated, message.SuperGroupChatCreated, message.ChannelChatCreated:
+		add(ChatEvent{Kind: ChatEventChatCreated})
+	case message.PinnedMessage != nil:
+		add(ChatEvent{Kind: ChatEventMessagePinned, PinnedMessage: message.PinnedMessage})
+	case message.MigrateToChatID != 0 || message.MigrateFromChatID != 0:
+		add(ChatEvent{
+			Kind:              ChatEventMigrated,
+			MigrateToChatID:   message.MigrateToChatID,
+			MigrateFromChatID: message.MigrateFromChatID,
+		})
+	case message.VideoChatScheduled != nil:
+		add(ChatEvent{
+			Kind:           ChatEventVideoChatScheduled,
+			VideoChatStart: unixTime(int64(message.VideoChatScheduled.StartDate)),
+		})
+	case message.VideoChatStarted != nil:
+		add(ChatEvent{Kind: ChatEventVideoChatStarted})
+	case message.VideoChatEnded != nil:
+		add(ChatEvent{
+			Kind:              ChatEventVideoChatEnded,
+			VideoChatDuration: time.Duration(message.VideoChatEnded.Duration) * time.Second,
+		})
+	case message.VideoChatParticipantsInvited != nil:
+		add(ChatEvent{Kind: ChatEventVideoChatInvited, Invited: message.VideoChatParticipantsInvited.Users})
+	case message.BoostAdded != nil:
+		add(ChatEvent{
+			Kind:       ChatEventBoosted,
+			Member:     message.From,
+			BoostCount: message.BoostAdded.BoostCount,
+		})
+	}
+
+	return events
+}
+
+// memberChatEvents returns the ChatEvent of a change to a member's
+// status.
+func memberChatEvents(update *Update, change *ChatMemberUpdated) []ChatEvent {
+	kind := ChatEventMemberUpdated
+	switch wasIn, isIn := change.OldChatMember.IsPresent(), change.NewChatMember.IsPresent(); {
+	case !wasIn && isIn:
+		kind = ChatEventMemberJoined
+	case wasIn && !isIn:
+		kind = ChatEventMemberLeft
+	}
+
+	return []ChatEvent{{
+		Kind:      kind,
+		Chat:      change.Chat,
+		Date:      unixTime(int64(change.Date)),
+		Actor:     change.From,
+		Member:    change.NewChatMember.User,
+		OldStatus: &change.OldChatMember,
+		NewStatus: &change.NewChatMember,
+		Update:    update,
+	}}
+}
@@ -0,0 +1,215 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestParseChatEventsServiceMessages(t *testing.T) {
+	chat := &tgbotapi.Chat{ID: -100, Type: "supergroup"}
+	admin := &tgbotapi.User{ID: 1, FirstName: "Admin"}
+
+	tests := []struct {
+		name    string
+		message tgbotapi.Message
+		kinds   []tgbotapi.ChatEventKind
+	}{
+		{
+			"Joined",
+			tgbotapi.Message{NewChatMembers: []tgbotapi.User{{ID: 2}, {ID: 3}}},
+			[]tgbotapi.ChatEventKind{tgbotapi.ChatEventMemberJoined, tgbotapi.ChatEventMemberJoined},
+		},
+		{
+			"Left",
+			tgbotapi.Message{LeftChatMember: &tgbotapi.User{ID: 2}},
+			[]tgbotapi.ChatEventKind{tgbotapi.ChatEventMemberLeft},
+		},
+		{
+			"Title",
+			tgbotapi.Message{NewChatTitle: "New title"},
+			[]tgbotapi.ChatEventKind{tgbotapi.ChatEventTitleChanged},
+		},
+		{
+			"Pinned",
+			tgbotapi.Message{PinnedMessage: &tgbotapi.Message{MessageID: 7}},
+			[]tgbotapi.ChatEventKind{tgbotapi.ChatEventMessagePinned},
+		},
+		{
+			"Migrated",
+			tgbotapi.Message{MigrateToChatID: -1001},
+			[]tgbotapi.ChatEventKind{tgbotapi.ChatEventMigrated},
+		},
+		{
+			"VideoChatEnded",
+			tgbotapi.Message{VideoChatEnded: &tgbotapi.VideoChatEnded{Duration: 90}},
+			[]tgbotapi.ChatEventKind{tgbotapi.ChatEventVideoChatEnded},
+		},
+		{
+			"Text",
+			tgbotapi.Message{Text: "hello"},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.message.Chat = chat
+			test.message.From = admin
+			test.message.Date = 1600000000
+
+			events := tgbotapi.ParseChatEvents(&tgbotapi.Update{Message: &test.message})
+			if len(events) != len(test.kinds) {
+				t.Fatalf("expected %d events, got %d", len(test.kinds), len(events))
+			}
+
+			for i, event := range events {
+				if event.Kind != test.kinds[i] {
+					t.Errorf("expected %s, got %s", test.kinds[i], event.Kind)
+				}
+				if event.Chat != chat || event.Actor != admin || !event.Date.Equal(time.Unix(1600000000, 0)) {
+					t.Errorf("bad event %+v", event)
+				}
+			}
+		})
+	}
+}
+
+func TestParseChatEventsDetails(t *testing.T) {
+	events := tgbotapi.ParseChatEvents(&tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:           &tgbotapi.Chat{ID: -100},
+		NewChatMembers: []tgbotapi.User{{ID: 2}, {ID: 3}},
+	}})
+	if events[0].Member.ID != 2 || events[1].Member.ID != 3 {
+		t.Errorf("expected members 2 and 3, got %d and %d", events[0].Member.ID, events[1].Member.ID)
+	}
+
+	events = tgbotapi.ParseChatEvents(&tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:           &tgbotapi.Chat{ID: -100},
+		VideoChatEnded: &tgbotapi.VideoChatEnded{Duration: 90},
+	}})
+	if events[0].VideoChatDuration != 90*time.Second {
+		t.Errorf("expected 90s video chat, got %s", events[0].VideoChatDuration)
+	}
+}
+
+func TestParseChatEventsMemberUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new tgbotapi.ChatMember
+		kind     tgbotapi.ChatEventKind
+	}{
+		{
+			"Joined",
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusLeft},
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusMember},
+			tgbotapi.ChatEventMemberJoined,
+		},
+		{
+			"Kicked",
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusMember},
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusKicked},
+			tgbotapi.ChatEventMemberLeft,
+		},
+		{
+			"Promoted",
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusMember},
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusAdministrator},
+			tgbotapi.ChatEventMemberUpdated,
+		},
+		{
+			"Restricted",
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusMember},
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusRestricted, InChat: true},
+			tgbotapi.ChatEventMemberUpdated,
+		},
+		{
+			"RestrictedLeft",
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusRestricted, InChat: true},
+			tgbotapi.ChatMember{Status: tgbotapi.MemberStatusRestricted},
+			tgbotapi.ChatEventMemberLeft,
+		},
+	}
+
+	member := &tgbotapi.User{ID: 2}
+	admin := &tgbotapi.User{ID: 1}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.new.User = member
+			update := tgbotapi.Update{ChatMember: &tgbotapi.ChatMemberUpdated{
+				Chat:          &tgbotapi.Chat{ID: -100},
+				From:          admin,
+				OldChatMember: test.old,
+				NewChatMember: test.new,
+			}}
+
+			events := tgbotapi.ParseChatEvents(&update)
+			if len(events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(events))
+			}
+			if events[0].Kind != test.kind {
+				t.Errorf("expected %s, got %s", test.kind, events[0].Kind)
+			}
+			if events[0].Member != member || events[0].Actor != admin {
+				t.Errorf("bad member or actor in %+v", events[0])
+			}
+		})
+	}
+}
+
+func TestParseChatEventsBoost(t *testing.T) {
+	var update tgbotapi.Update
+	err := json.Unmarshal([]byte(`{"update_id":1,"chat_boost":{"chat":{"id":-100,"type":"channel"},
+		"boost":{"boost_id":"b1","add_date":1600000000,"expiration_date":1700000000,
+		"source":{"source":"premium","user":{"id":5,"first_name":"Booster"}}}}}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if update.Kind() != tgbotapi.UpdateTypeChatBoost {
+		t.Errorf("expected chat_boost kind, got %s", update.Kind())
+	}
+	if update.FromChat() == nil || update.SentFrom() == nil || update.SentFrom().ID != 5 {
+		t.Error("expected the boost's chat and user")
+	}
+
+	events := tgbotapi.ParseChatEvents(&update)
+	if len(events) != 1 || events[0].Kind != tgbotapi.ChatEventBoosted {
+		t.Fatalf("expected a boost event, got %+v", events)
+	}
+	if events[0].BoostID != "b1" || events[0].Member.ID != 5 || events[0].Chat.ID != -100 {
+		t.Errorf("bad boost event %+v", events[0])
+	}
+}
+
+func TestOnChatEvent(t *testing.T) {
+	var kinds []tgbotapi.ChatEventKind
+	handled := 0
+
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled++
+	})
+	d.Use(tgbotapi.OnChatEvent(func(ctx context.Context, bot *tgbotapi.BotAPI, event tgbotapi.ChatEvent) {
+		kinds = append(kinds, event.Kind)
+	}))
+
+	d.Dispatch(context.Background(), tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:           &tgbotapi.Chat{ID: -100},
+		NewChatMembers: []tgbotapi.User{{ID: 2}},
+	}})
+	d.Dispatch(context.Background(), tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat: &tgbotapi.Chat{ID: -100},
+		Text: "hello",
+	}})
+
+	if len(kinds) != 1 || kinds[0] != tgbotapi.ChatEventMemberJoined {
+		t.Errorf("expected one join event, got %v", kinds)
+	}
+	if handled != 2 {
+		t.Errorf("expected both updates passed on, got %d", handled)
+	}
+}
@@ -0,0 +1,278 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Defaults for FailoverTransport.
+const (
+	DefaultFailoverThreshold     = 3
+	DefaultFailoverRetryInterval = 30 * time.Second
+)
+
+// maxFailoverBody is the largest request body which is kept to be sent
+// again to another server if a request fails. Larger uploads aren't
+// retried, but still make the next request go to another server.
+const maxFailoverBody = 1 << 20
+
+// FailoverTransport sends requests to the first healthy server of a list
+// of Bot API servers, such as two local Bot API server replicas and then
+// api.telegram.org, so a server restarting doesn't take the bot down.
+// Use it as the Transport of the http.Client given to
+// NewBotAPIWithClient.
+//
+// A server is skipped once requests to it fail Threshold times in a row,
+// by not connecting or with a 502, 503 or 504 response. After
+// RetryInterval, or once CheckHealth finds it is back, requests go to it
+// again, so the bot fails back to the servers listed first.
+//
+// A failed request is sent to the next server straight away. As with any
+// retry, a request which timed out at a gateway may still have been made
+// by the first.
+type FailoverTransport struct {
+	// Transport makes the requests. If it is nil, http.DefaultTransport
+	// is used.
+	Transport http.RoundTripper
+	// Threshold is how many requests to a server must fail in a row for
+	// it to be skipped. If it is zero, DefaultFailoverThreshold is used.
+	Threshold int
+	// RetryInterval is how long a server is skipped for. If it is zero,
+	// DefaultFailoverRetryInterval is used.
+	RetryInterval time.Duration
+
+	servers []*url.URL
+
+	mu    sync.Mutex
+	state []failoverState
+}
+
+// failoverState is the health of a server.
+type failoverState struct {
+	failures  int
+	downUntil time.Time
+}
+
+// NewFailoverTransport creates a FailoverTransport for servers, given as
+// base URLs such as "http://localhost:8081", in order of preference.
+func NewFailoverTransport(servers ...string) (*FailoverTransport, error) {
+	if len(servers) == 0 {
+		return nil, errors.New(ErrNoServers)
+	}
+
+	t := &FailoverTransport{
+		servers: make([]*url.URL, len(servers)),
+		state:   make([]failoverState, len(servers)),
+	}
+	for i, server := range servers {
+		u, err := url.Parse(server)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("server %q is not an absolute URL", server)
+		}
+		t.servers[i] = u
+	}
+
+	return t, nil
+}
+
+// RoundTrip sends a request to the first healthy server. If it fails in a
+// way that means the server is down, it is sent to the next one if its
+// body can be sent again.
+func (t *FailoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := bufferFailoverBody(req); err != nil {
+		return nil, err
+	}
+
+	order := t.order(time.Now())
+	for n, i := range order {
+		if n > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.transport().RoundTrip(t.rewrite(req, i))
+		if !serverFailed(req.Context(), resp, err) {
+			t.succeeded(i)
+			return resp, nil
+		}
+		t.failed(i, time.Now())
+
+		if n == len(order)-1 || req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	return nil, errors.New(ErrNoServers)
+}
+
+// Current returns the base URL requests are sent to first.
+func (t *FailoverTransport) Current() string {
+	return t.servers[t.order(time.Now())[0]].String()
+}
+
+// CheckHealth makes a request to each server being skipped, so requests
+// go to those which respond again without waiting for RetryInterval.
+// Any response other than a 502, 503 or 504 means a server is back.
+func (t *FailoverTransport) CheckHealth(ctx context.Context) {
+	now := time.Now()
+
+	for i, server := range t.servers {
+		t.mu.Lock()
+		down := t.state[i].downUntil.After(now)
+		t.mu.Unlock()
+		if !down {
+			continue
+		}
+
+		req, err := http.NewRequest("GET", server.String(), nil)
+		if err != nil {
+			continue
+		}
+
+		resp, err := t.transport().RoundTrip(req.WithContext(ctx))
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !serverFailed(ctx, resp, err) {
+			t.succeeded(i)
+		}
+	}
+}
+
+// StartHealthChecks runs CheckHealth every interval until ctx is done.
+func (t *FailoverTransport) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.CheckHealth(ctx)
+			}
+		}
+	}()
+}
+
+func (t *FailoverTransport) transport() http.RoundTripper {
+	if t.Transport == nil {
+		return http.DefaultTransport
+	}
+	return t.Transport
+}
+
+// order returns the servers to try, healthy ones first, each in order of
+// preference.
+func (t *FailoverTransport) order(now time.Time) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	order := make([]int, 0, len(t.servers))
+	var down []int
+	for i := range t.servers {
+		if t.state[i].downUntil.After(now) {
+			down = append(down, i)
+		} else {
+			order = append(order, i)
+		}
+	}
+
+	return append(order, down...)
+}
+
+func (t *FailoverTransport) succeeded(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.state[i] = failoverState{}
+}
+
+func (t *FailoverTransport) failed(i int, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	threshold := t.Threshold
+	if threshold <= 0 {
+		threshold = DefaultFailoverThreshold
+	}
+	retry := t.RetryInterval
+	if retry <= 0 {
+		retry = DefaultFailoverRetryInterval
+	}
+
+	t.state[i].failures++
+	if t.state[i].failures >= threshold {
+		t.state[i].downUntil = now.Add(retry)
+	}
+}
+
+// rewrite returns a request to the server at i, keeping the path, such
+// as /bot<token>/getMe, under the server's base path.
+func (t *FailoverTransport) rewrite(req *http.Request, i int) *http.Request {
+	server := t.servers[i]
+
+	r := req.Clone(req.Context())
+	r.URL.Scheme = server.Scheme
+	r.URL.Host = server.Host
+	r.URL.Path = server.Path + req.URL.Path
+	r.URL.RawPath = ""
+	r.Host = ""
+
+	return r
+}
+
+// serverFailed returns if a request failed because the server is down,
+// rather than because it was cancelled or rejected.
+func serverFailed(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// bufferFailoverBody reads a small request body into memory, if it can't
+// already be read again, so it can be sent to another server.
+func bufferFailoverBody(req *http.Request) error {
+	if req.Body == nil || req.GetBody != nil || req.ContentLength <= 0 || req.ContentLength > maxFailoverBody {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return nil
+}
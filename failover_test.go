@@ -0,0 +1,116 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestFailoverTransport(t *testing.T) {
+	var primaryDown int32
+	var primaryRequests, secondaryRequests int32
+
+	respond := func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("text") != "hello" && r.URL.Path != "/bottoken/getMe" {
+			t.Errorf("expected the request body to be sent, got %v", r.Form)
+		}
+		data, _ := json.Marshal(tgbotapi.Message{MessageID: 1})
+		json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: data})
+	}
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&primaryDown) == 1 {
+			http.Error(w, "restarting", http.StatusServiceUnavailable)
+			return
+		}
+		atomic.AddInt32(&primaryRequests, 1)
+		respond(w, r)
+	}))
+	defer primary.Close()
+
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondaryRequests, 1)
+		respond(w, r)
+	}))
+	defer secondary.Close()
+
+	transport, err := tgbotapi.NewFailoverTransport(primary.URL, secondary.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.Threshold = 2
+	transport.RetryInterval = 100 * time.Millisecond
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", &http.Client{Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func() {
+		if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hello")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	atomic.StoreInt32(&primaryDown, 1)
+	for i := 0; i < 3; i++ {
+		send()
+	}
+
+	if n := atomic.LoadInt32(&secondaryRequests); n != 3 {
+		t.Errorf("expected every message sent through the secondary, got %d", n)
+	}
+	if transport.Current() != secondary.URL {
+		t.Errorf("expected the primary to be skipped, current is %s", transport.Current())
+	}
+
+	// Once the primary is back, requests fail back to it.
+	atomic.StoreInt32(&primaryDown, 0)
+	time.Sleep(150 * time.Millisecond)
+	before := atomic.LoadInt32(&primaryRequests)
+	send()
+
+	if atomic.LoadInt32(&primaryRequests) != before+1 {
+		t.Error("expected the request to fail back to the primary")
+	}
+}
+
+func TestFailoverHealthCheck(t *testing.T) {
+	var down int32 = 1
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer primary.Close()
+
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer secondary.Close()
+
+	transport, err := tgbotapi.NewFailoverTransport(primary.URL, secondary.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.Threshold = 1
+	transport.RetryInterval = time.Hour
+
+	req, _ := http.NewRequest("GET", "https://api.telegram.org/bottoken/getMe", nil)
+	if resp, err := transport.RoundTrip(req); err == nil {
+		resp.Body.Close()
+	}
+	if transport.Current() == primary.URL {
+		t.Fatal("expected the primary to be skipped")
+	}
+
+	atomic.StoreInt32(&down, 0)
+	transport.CheckHealth(req.Context())
+
+	if transport.Current() != primary.URL {
+		t.Errorf("expected the health check to bring back the primary, current is %s", transport.Current())
+	}
+}
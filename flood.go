@@ -0,0 +1,194 @@
+package tgbotapi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// FloodAction is what AntiFlood does with updates over the limit.
+type FloodAction int
+
+// Actions AntiFlood may take.
+const (
+	// FloodDrop ignores updates over the limit.
+	FloodDrop FloodAction = iota
+	// FloodDelay holds updates over the limit until the window allows
+	// them to be handled.
+	FloodDelay
+	// FloodWarn ignores updates over the limit, sending Warning to the
+	// chat the first time in each window.
+	FloodWarn
+	// FloodMute ignores updates over the limit, and mutes the user for
+	// MuteFor if it is a group. Warning is sent when they are muted.
+	FloodMute
+)
+
+// FloodConfig configures AntiFlood.
+type FloodConfig struct {
+	// Limit is how many updates are handled in each Window. If it is
+	// zero, 20 are.
+	Limit int
+	// Window is how long updates are counted for. If it is zero, a
+	// minute is used.
+	Window time.Duration
+	// PerChat counts updates for each chat, instead of for each user.
+	PerChat bool
+	Action  FloodAction
+	// Warning is sent to the chat by FloodWarn and FloodMute, if set.
+	Warning string
+	// MuteFor is how long FloodMute mutes users for. Telegram mutes for
+	// at least 30 seconds. If it is zero, 5 minutes is used.
+	MuteFor time.Duration
+}
+
+// Defaults for FloodConfig.
+const (
+	defaultFloodLimit  = 20
+	defaultFloodWindow = time.Minute
+	defaultFloodMute   = 5 * time.Minute
+)
+
+// floodCounter counts the updates for a user or chat in a window.
+type floodCounter struct {
+	start  time.Time
+	count  int
+	warned bool
+}
+
+// floodLimiter counts updates for AntiFlood.
+type floodLimiter struct {
+	config FloodConfig
+
+	mu        sync.Mutex
+	counters  map[int64]*floodCounter
+	lastSweep time.Time
+}
+
+// allow counts an update for key, returning if it may be handled. If
+// not, it returns how long until it may be, and if this is the first
+// update over the limit in the window.
+func (l *floodLimiter) allow(key int64, now time.Time) (bool, time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > l.config.Window {
+		for k, c := range l.counters {
+			if now.Sub(c.start) >= l.config.Window {
+				delete(l.counters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c := l.counters[key]
+	if c == nil || now.Sub(c.start) >= l.config.Window {
+		c = &floodCounter{start: now}
+		l.counters[key] = c
+	}
+
+	c.count++
+	if c.count <= l.config.Limit {
+		return true, 0, false
+	}
+
+	first := !c.warned
+	c.warned = true
+
+	return false, c.start.Add(l.config.Window).Sub(now), first
+}
+
+// AntiFlood returns Middleware which limits how many updates from each
+// user, or each chat, are handled in a window of time, to protect
+// handlers from spam. Updates without a user or chat are not limited.
+func AntiFlood(config FloodConfig) Middleware {
+	if config.Limit <= 0 {
+		config.Limit = defaultFloodLimit
+	}
+	if config.Window <= 0 {
+		config.Window = defaultFloodWindow
+	}
+	if config.MuteFor == 0 {
+		config.MuteFor = defaultFloodMute
+	}
+
+	limiter := &floodLimiter{
+		config:   config,
+		counters: make(map[int64]*floodCounter),
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			chat, user := update.FromChat(), update.SentFrom()
+
+			var key int64
+			if config.PerChat && chat != nil {
+				key = chat.ID
+			} else if !config.PerChat && user != nil {
+				key = user.ID
+			}
+			if key == 0 {
+				next(ctx, bot, update)
+				return
+			}
+
+			for {
+				ok, wait, first := limiter.allow(key, time.Now())
+				if ok {
+					break
+				}
+
+				switch config.Action {
+				case FloodDelay:
+					if err := sleepContext(ctx, wait); err != nil {
+						return
+					}
+					continue
+				case FloodWarn:
+					if first {
+						floodWarn(ctx, bot, chat, config.Warning)
+					}
+				case FloodMute:
+					if first {
+						floodMute(ctx, bot, chat, user, config.MuteFor)
+						floodWarn(ctx, bot, chat, config.Warning)
+					}
+				}
+
+				return
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+func floodWarn(ctx context.Context, bot *BotAPI, chat *Chat, warning string) {
+	if bot == nil || chat == nil || warning == "" {
+		return
+	}
+
+	if _, err := bot.SendContext(ctx, NewMessage(chat.ID, warning)); err != nil {
+		log.Println(err)
+	}
+}
+
+func floodMute(ctx context.Context, bot *BotAPI, chat *Chat, user *User, muteFor time.Duration) {
+	if bot == nil || chat == nil || user == nil || !(chat.IsGroup() || chat.IsSuperGroup()) {
+		return
+	}
+
+	config := RestrictChatMemberConfig{
+		ChatMemberConfig: ChatMemberConfig{ChatID: chat.ID, UserID: user.ID},
+		UntilDate:        time.Now().Add(muteFor).Unix(),
+	}
+
+	v, err := config.values()
+	if err == nil {
+		_, err = bot.makeRequestContext(ctx, config.Method(), v)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+}
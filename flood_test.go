@@ -0,0 +1,135 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func floodUpdate(userID int64) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Text: "spam",
+		Chat: &tgbotapi.Chat{ID: -100, Type: "supergroup"},
+		From: &tgbotapi.User{ID: userID},
+	}}
+}
+
+func TestAntiFloodMute(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	var permissions string
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls[method]++
+		switch method {
+		case "restrictChatMember":
+			permissions = r.FormValue("permissions")
+			return true
+		case "sendMessage":
+			return tgbotapi.Message{MessageID: 1}
+		}
+		return nil
+	})
+
+	handled := 0
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled++
+	})
+	d.Use(tgbotapi.AntiFlood(tgbotapi.FloodConfig{
+		Limit:   2,
+		Window:  time.Minute,
+		Action:  tgbotapi.FloodMute,
+		Warning: "Slow down",
+	}))
+
+	for i := 0; i < 5; i++ {
+		d.Dispatch(context.Background(), floodUpdate(1))
+	}
+	d.Dispatch(context.Background(), floodUpdate(2))
+
+	if handled != 3 {
+		t.Errorf("expected 3 updates handled, got %d", handled)
+	}
+	if calls["restrictChatMember"] != 1 || calls["sendMessage"] != 1 {
+		t.Errorf("expected one mute and warning, got %v", calls)
+	}
+	if permissions != "{}" {
+		t.Errorf("expected all permissions removed, got %s", permissions)
+	}
+}
+
+func TestAntiFloodDelay(t *testing.T) {
+	handled := 0
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled++
+	})
+	d.Use(tgbotapi.AntiFlood(tgbotapi.FloodConfig{
+		Limit:   1,
+		Window:  50 * time.Millisecond,
+		PerChat: true,
+		Action:  tgbotapi.FloodDelay,
+	}))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		d.Dispatch(context.Background(), floodUpdate(int64(i+1)))
+	}
+
+	if handled != 3 {
+		t.Errorf("expected every update to be handled, got %d", handled)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("updates were not delayed, took %s", elapsed)
+	}
+}
+
+func TestAntiFloodDefaults(t *testing.T) {
+	handled := 0
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled++
+	})
+	d.Use(tgbotapi.AntiFlood(tgbotapi.FloodConfig{}))
+
+	for i := 0; i < 25; i++ {
+		d.Dispatch(context.Background(), floodUpdate(1))
+	}
+
+	if handled != 20 {
+		t.Errorf("expected 20 updates handled in the default window, got %d", handled)
+	}
+}
+
+func TestAntiFloodMuteCancelled(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls[method]++
+		return true
+	})
+
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {})
+	d.Use(tgbotapi.AntiFlood(tgbotapi.FloodConfig{Limit: 1, Action: tgbotapi.FloodMute}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 2; i++ {
+		d.Dispatch(ctx, floodUpdate(1))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls["restrictChatMember"] != 0 {
+		t.Errorf("expected no mute once the handler's context is done, got %v", calls)
+	}
+}
@@ -1,8 +1,11 @@
 package tgbotapi
 
 import (
+	"io"
 	"log"
 	"net/url"
+	"strconv"
+	"strings"
 )
 
 // NewMessage creates a new Message.
@@ -194,6 +197,38 @@ func NewVideoShare(chatID int64, fileID string) VideoConfig {
 	}
 }
 
+// NewVideoNoteUpload creates a new video note uploader.
+//
+// chatID is where to send it, length is the width and height of the
+// video, file is a string path to the file, FileReader, or FileBytes.
+func NewVideoNoteUpload(chatID int64, length int, file interface{}) VideoNoteConfig {
+	return VideoNoteConfig{
+		BaseFile: BaseFile{
+			BaseChat:    BaseChat{ChatID: chatID},
+			File:        file,
+			UseExisting: false,
+		},
+		Length: length,
+	}
+}
+
+// NewVideoNoteShare shares an existing video note.
+// You may use this to reshare an existing video note without reuploading
+// it.
+//
+// chatID is where to send it, length is the width and height of the
+// video, fileID is the ID of the video note already uploaded.
+func NewVideoNoteShare(chatID int64, length int, fileID string) VideoNoteConfig {
+	return VideoNoteConfig{
+		BaseFile: BaseFile{
+			BaseChat:    BaseChat{ChatID: chatID},
+			FileID:      fileID,
+			UseExisting: true,
+		},
+		Length: length,
+	}
+}
+
 // NewVoiceUpload creates a new voice uploader.
 //
 // chatID is where to send it, file is a string path to the file,
@@ -223,6 +258,161 @@ func NewVoiceShare(chatID int64, fileID string) VoiceConfig {
 	}
 }
 
+// NewPhoto creates a new photo to send from any RequestFileData, such as
+// a FilePath, FileBytes, FileReader, FileURL or FileID.
+//
+// chatID is where to send it, file is the photo to send.
+func NewPhoto(chatID int64, file RequestFileData) PhotoConfig {
+	return PhotoConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewAudio creates a new audio file to send from any RequestFileData.
+//
+// chatID is where to send it, file is the audio to send.
+func NewAudio(chatID int64, file RequestFileData) AudioConfig {
+	return AudioConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewDocument creates a new document to send from any RequestFileData.
+//
+// chatID is where to send it, file is the document to send.
+func NewDocument(chatID int64, file RequestFileData) DocumentConfig {
+	return DocumentConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewPhotoUploadFromReader creates a new photo uploader which streams the
+// photo from a reader, such as an HTTP response body, as it is sent.
+//
+// chatID is where to send it, reader is the photo's contents, name is its
+// filename and contentType its MIME type, if known.
+func NewPhotoUploadFromReader(chatID int64, reader io.Reader, name, contentType string) PhotoConfig {
+	return NewPhoto(chatID, FileReader{
+		Name:        name,
+		Reader:      reader,
+		Size:        -1,
+		ContentType: contentType,
+	})
+}
+
+// NewDocumentUploadFromReader creates a new document uploader which
+// streams the document from a reader, such as an HTTP response body, as
+// it is sent.
+//
+// chatID is where to send it, reader is the document's contents, name is
+// its filename and contentType its MIME type, if known.
+func NewDocumentUploadFromReader(chatID int64, reader io.Reader, name, contentType string) DocumentConfig {
+	return NewDocument(chatID, FileReader{
+		Name:        name,
+		Reader:      reader,
+		Size:        -1,
+		ContentType: contentType,
+	})
+}
+
+// NewSticker creates a new sticker to send from any RequestFileData.
+//
+// chatID is where to send it, file is the sticker to send.
+func NewSticker(chatID int64, file RequestFileData) StickerConfig {
+	return StickerConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewVideo creates a new video to send from any RequestFileData.
+//
+// chatID is where to send it, file is the video to send.
+func NewVideo(chatID int64, file RequestFileData) VideoConfig {
+	return VideoConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewVoice creates a new voice message to send from any RequestFileData.
+//
+// chatID is where to send it, file is the voice message to send.
+func NewVoice(chatID int64, file RequestFileData) VoiceConfig {
+	return VoiceConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewAnimation creates a new animation, a GIF or a video without sound,
+// to send from any RequestFileData. Like every other file, it may be a
+// FileURL for Telegram to download.
+//
+// chatID is where to send it, file is the animation to send.
+func NewAnimation(chatID int64, file RequestFileData) AnimationConfig {
+	return AnimationConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewMediaGroup creates a new media group to send as an album.
+//
+// chatID is where to send it, media is a list of InputMediaPhoto or
+// InputMediaVideo.
+func NewMediaGroup(chatID int64, media []interface{}) MediaGroupConfig {
+	return MediaGroupConfig{
+		BaseChat: BaseChat{ChatID: chatID},
+		Media:    media,
+	}
+}
+
+// NewInputMediaPhoto creates a new photo for a media group.
+func NewInputMediaPhoto(media RequestFileData) InputMediaPhoto {
+	return InputMediaPhoto{
+		BaseInputMedia{Type: "photo", Media: media},
+	}
+}
+
+// NewInputMediaVideo creates a new video for a media group.
+func NewInputMediaVideo(media RequestFileData) InputMediaVideo {
+	return InputMediaVideo{
+		BaseInputMedia: BaseInputMedia{Type: "video", Media: media},
+	}
+}
+
+// NewInputMediaAudio creates a new audio file to edit a message with.
+func NewInputMediaAudio(media RequestFileData) InputMediaAudio {
+	return InputMediaAudio{
+		BaseInputMedia: BaseInputMedia{Type: "audio", Media: media},
+	}
+}
+
+// NewInputMediaDocument creates a new document to edit a message with.
+func NewInputMediaDocument(media RequestFileData) InputMediaDocument {
+	return InputMediaDocument{
+		BaseInputMedia: BaseInputMedia{Type: "document", Media: media},
+	}
+}
+
 // NewContact allows you to send a shared contact.
 func NewContact(chatID int64, phoneNumber, firstName string) ContactConfig {
 	return ContactConfig{
@@ -234,6 +424,15 @@ func NewContact(chatID int64, phoneNumber, firstName string) ContactConfig {
 	}
 }
 
+// NewContactWithVCard allows you to send a shared contact with more
+// details about them in a vCard.
+func NewContactWithVCard(chatID int64, phoneNumber, firstName, vCard string) ContactConfig {
+	contact := NewContact(chatID, phoneNumber, firstName)
+	contact.VCard = vCard
+
+	return contact
+}
+
 // NewLocation shares your location.
 //
 // chatID is where to send it, latitude and longitude are coordinates.
@@ -260,6 +459,38 @@ func NewVenue(chatID int64, title, address string, latitude, longitude float64)
 	}
 }
 
+// NewPoll allows you to send an anonymous poll.
+func NewPoll(chatID int64, question string, options ...string) PollConfig {
+	return PollConfig{
+		BaseChat: BaseChat{
+			ChatID: chatID,
+		},
+		Question:    question,
+		Options:     options,
+		IsAnonymous: true,
+	}
+}
+
+// NewVenueWithFoursquare allows you to send a venue identified on
+// Foursquare, such as with type "arts_entertainment/aquarium".
+func NewVenueWithFoursquare(chatID int64, title, address string, latitude, longitude float64, foursquareID, foursquareType string) VenueConfig {
+	venue := NewVenue(chatID, title, address, latitude, longitude)
+	venue.FoursquareID = foursquareID
+	venue.FoursquareType = foursquareType
+
+	return venue
+}
+
+// NewVenueWithGooglePlace allows you to send a venue identified on Google
+// Places, such as with type "aquarium".
+func NewVenueWithGooglePlace(chatID int64, title, address string, latitude, longitude float64, placeID, placeType string) VenueConfig {
+	venue := NewVenue(chatID, title, address, latitude, longitude)
+	venue.GooglePlaceID = placeID
+	venue.GooglePlaceType = placeType
+
+	return venue
+}
+
 // NewChatAction sets a chat action.
 // Actions last for 5 seconds, or until your next action.
 //
@@ -271,10 +502,19 @@ func NewChatAction(chatID int64, action string) ChatActionConfig {
 	}
 }
 
+// NewChatActionInThread sets a chat action in a forum topic, so it is
+// shown there instead of in the general topic.
+func NewChatActionInThread(chatID int64, messageThreadID int, action string) ChatActionConfig {
+	config := NewChatAction(chatID, action)
+	config.MessageThreadID = messageThreadID
+
+	return config
+}
+
 // NewUserProfilePhotos gets user profile photos.
 //
 // userID is the ID of the user you wish to get profile photos from.
-func NewUserProfilePhotos(userID int) UserProfilePhotosConfig {
+func NewUserProfilePhotos(userID int64) UserProfilePhotosConfig {
 	return UserProfilePhotosConfig{
 		UserID: userID,
 		Offset: 0,
@@ -444,6 +684,43 @@ func NewInlineQueryResultLocation(id, title string, latitude, longitude float64)
 	}
 }
 
+// NewInlineQueryPage creates an answer to an inline query containing the
+// page of results requested by the query's offset.
+//
+// results is the full set of results, which is split into pages of
+// InlineResultsPerPage. NextOffset is set so Telegram requests the next
+// page when the user scrolls, and is empty on the last page.
+func NewInlineQueryPage(query InlineQuery, results []interface{}) InlineConfig {
+	start := parseInlineOffset(query.Offset)
+	if start > len(results) {
+		start = len(results)
+	}
+
+	end := start + InlineResultsPerPage
+	nextOffset := strconv.Itoa(end)
+	if end >= len(results) {
+		end = len(results)
+		nextOffset = ""
+	}
+
+	return InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results[start:end],
+		NextOffset:    nextOffset,
+	}
+}
+
+// parseInlineOffset parses an offset previously set by NewInlineQueryPage,
+// treating anything else as the first page.
+func parseInlineOffset(offset string) int {
+	start, err := strconv.Atoi(offset)
+	if err != nil || start < 0 {
+		return 0
+	}
+
+	return start
+}
+
 // NewEditMessageText allows you to edit the text of a message.
 func NewEditMessageText(chatID int64, messageID int, text string) EditMessageTextConfig {
 	return EditMessageTextConfig{
@@ -478,6 +755,34 @@ func NewEditMessageReplyMarkup(chatID int64, messageID int, replyMarkup InlineKe
 	}
 }
 
+// NewEditMessageMedia allows you to replace the media of a message.
+func NewEditMessageMedia(chatID int64, messageID int, media interface{}) EditMessageMediaConfig {
+	return EditMessageMediaConfig{
+		BaseEdit: BaseEdit{
+			ChatID:    chatID,
+			MessageID: messageID,
+		},
+		Media: media,
+	}
+}
+
+// NewDeleteMessage allows you to delete a message.
+func NewDeleteMessage(chatID int64, messageID int) DeleteMessageConfig {
+	return DeleteMessageConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+}
+
+// NewReaction sets the bot's reaction to a message to a single emoji.
+func NewReaction(chatID int64, messageID int, emoji string) SetMessageReactionConfig {
+	return SetMessageReactionConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Reaction:  []ReactionType{{Type: "emoji", Emoji: emoji}},
+	}
+}
+
 // NewHideKeyboard hides the keyboard, with the option for being selective
 // or hiding for everyone.
 func NewHideKeyboard(selective bool) ReplyKeyboardHide {
@@ -571,6 +876,51 @@ func NewInlineKeyboardButtonSwitch(text, sw string) InlineKeyboardButton {
 	}
 }
 
+// NewInlineKeyboardButtonSwitchCurrentChat creates an inline keyboard
+// button with text which starts an inline query in the current chat.
+func NewInlineKeyboardButtonSwitchCurrentChat(text, sw string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:                         text,
+		SwitchInlineQueryCurrentChat: &sw,
+	}
+}
+
+// NewInlineKeyboardButtonGame creates an inline keyboard button with text
+// which starts the game sent with it. It must be the first button.
+func NewInlineKeyboardButtonGame(text string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:         text,
+		CallbackGame: &CallbackGame{},
+	}
+}
+
+// NewInlineKeyboardButtonPay creates an inline keyboard button with text
+// which pays an invoice. It must be the first button.
+func NewInlineKeyboardButtonPay(text string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text: text,
+		Pay:  true,
+	}
+}
+
+// NewInlineKeyboardButtonWebApp creates an inline keyboard button with
+// text which opens a Web App.
+func NewInlineKeyboardButtonWebApp(text, url string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:   text,
+		WebApp: &WebAppInfo{URL: url},
+	}
+}
+
+// NewInlineKeyboardButtonLoginURL creates an inline keyboard button with
+// text which logs the user in to a website.
+func NewInlineKeyboardButtonLoginURL(text string, loginURL LoginURL) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:     text,
+		LoginURL: &loginURL,
+	}
+}
+
 // NewInlineKeyboardRow creates an inline keyboard row with buttons.
 func NewInlineKeyboardRow(buttons ...InlineKeyboardButton) []InlineKeyboardButton {
 	var row []InlineKeyboardButton
@@ -591,6 +941,20 @@ func NewInlineKeyboardMarkup(rows ...[]InlineKeyboardButton) InlineKeyboardMarku
 	}
 }
 
+// NewInlineKeyboardColumn creates a new inline keyboard with each button
+// in its own row.
+func NewInlineKeyboardColumn(buttons ...InlineKeyboardButton) InlineKeyboardMarkup {
+	var keyboard [][]InlineKeyboardButton
+
+	for _, button := range buttons {
+		keyboard = append(keyboard, NewInlineKeyboardRow(button))
+	}
+
+	return InlineKeyboardMarkup{
+		InlineKeyboard: keyboard,
+	}
+}
+
 // NewCallback creates a new callback message.
 func NewCallback(id, text string) CallbackConfig {
 	return CallbackConfig{
@@ -609,3 +973,76 @@ func NewCallbackWithAlert(id, text string) CallbackConfig {
 		ShowAlert:       true,
 	}
 }
+
+// EscapeText escapes text so it shows as is when sent with parseMode,
+// one of ModeHTML, ModeMarkdown or ModeMarkdownV2. Text for any other
+// parse mode is returned unchanged.
+func EscapeText(parseMode string, text string) string {
+	var replacer *strings.Replacer
+
+	switch parseMode {
+	case ModeHTML:
+		replacer = strings.NewReplacer("<", "&lt;", ">", "&gt;", "&", "&amp;", `"`, "&quot;")
+	case ModeMarkdown:
+		replacer = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+	case ModeMarkdownV2:
+		replacer = strings.NewReplacer(
+			"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]",
+			"(", "\\(", ")", "\\)", "~", "\\~", "`", "\\`", ">", "\\>",
+			"#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=", "|", "\\|",
+			"{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+		)
+	default:
+		return text
+	}
+
+	return replacer.Replace(text)
+}
+
+// NewBotCommandScope creates a scope of commands without a chat, such as
+// CommandScopeAllPrivateChats.
+func NewBotCommandScope(scopeType string) BotCommandScope {
+	return BotCommandScope{Type: scopeType}
+}
+
+// NewBotCommandScopeChat creates a scope of commands for all members of
+// a chat.
+func NewBotCommandScopeChat(chatID int64) BotCommandScope {
+	return BotCommandScope{Type: CommandScopeChat, ChatID: chatID}
+}
+
+// NewBotCommandScopeChatAdministrators creates a scope of commands for
+// the administrators of a chat.
+func NewBotCommandScopeChatAdministrators(chatID int64) BotCommandScope {
+	return BotCommandScope{Type: CommandScopeChatAdministrators, ChatID: chatID}
+}
+
+// NewBotCommandScopeChatMember creates a scope of commands for a single
+// member of a chat.
+func NewBotCommandScopeChatMember(chatID, userID int64) BotCommandScope {
+	return BotCommandScope{Type: CommandScopeChatMember, ChatID: chatID, UserID: userID}
+}
+
+// NewInvoice creates a new invoice, to which prices must be added.
+//
+// chatID is where to send it, payload is an identifier of the order not
+// shown to the user, and currency is a three-letter ISO 4217 code.
+func NewInvoice(chatID int64, title, description, payload, providerToken, currency string) InvoiceConfig {
+	return InvoiceConfig{
+		BaseChat:      BaseChat{ChatID: chatID},
+		Title:         title,
+		Description:   description,
+		Payload:       payload,
+		ProviderToken: providerToken,
+		Currency:      currency,
+	}
+}
+
+// NewShippingOption creates a way of shipping goods, with its prices.
+func NewShippingOption(id, title string, prices ...LabeledPrice) ShippingOption {
+	return ShippingOption{
+		ID:     id,
+		Title:  title,
+		Prices: prices,
+	}
+}
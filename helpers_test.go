@@ -1,7 +1,11 @@
 package tgbotapi_test
 
 import (
+	"encoding/json"
 	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -175,3 +179,129 @@ func TestNewEditMessageReplyMarkup(t *testing.T) {
 	}
 
 }
+
+func TestNewInlineQueryPage(t *testing.T) {
+	results := make([]interface{}, 120)
+	for i := range results {
+		results[i] = tgbotapi.NewInlineQueryResultArticle(strconv.Itoa(i), "title", "message")
+	}
+
+	query := tgbotapi.InlineQuery{ID: "id"}
+	offsets := []string{}
+	for {
+		page := tgbotapi.NewInlineQueryPage(query, results)
+		if page.InlineQueryID != "id" || len(page.Results) > tgbotapi.InlineResultsPerPage {
+			t.Fatal(page)
+		}
+
+		offsets = append(offsets, page.NextOffset)
+		if page.NextOffset == "" {
+			if len(page.Results) != 20 {
+				t.Fatal(len(page.Results))
+			}
+			break
+		}
+		query.Offset = page.NextOffset
+	}
+
+	if strings.Join(offsets, ",") != "50,100," {
+		t.Fail()
+	}
+}
+
+func TestNewInlineKeyboardColumn(t *testing.T) {
+	markup := tgbotapi.NewInlineKeyboardColumn(
+		tgbotapi.NewInlineKeyboardButtonPay("Pay"),
+		tgbotapi.NewInlineKeyboardButtonWebApp("Open", "https://example.com/app"),
+		tgbotapi.NewInlineKeyboardButtonSwitchCurrentChat("Search", "query"),
+	)
+
+	data, err := json.Marshal(markup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"inline_keyboard":[[{"text":"Pay","pay":true}],` +
+		`[{"text":"Open","web_app":{"url":"https://example.com/app"}}],` +
+		`[{"text":"Search","switch_inline_query_current_chat":"query"}]]}`
+	if string(data) != expected {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestNewVenueWithGooglePlace(t *testing.T) {
+	venue := tgbotapi.NewVenueWithGooglePlace(ChatID, "Aquarium", "1 Sea Rd", 1.5, 2.5, "place-id", "aquarium")
+
+	if venue.GooglePlaceID != "place-id" || venue.GooglePlaceType != "aquarium" || venue.Title != "Aquarium" {
+		t.Errorf("got %+v", venue)
+	}
+}
+
+func TestNewChatActionInThread(t *testing.T) {
+	var threadID string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendChatAction" {
+			threadID = r.FormValue("message_thread_id")
+			return true
+		}
+		return nil
+	})
+
+	if _, err := bot.Send(tgbotapi.NewChatActionInThread(ChatID, 12, tgbotapi.ChatTyping)); err != nil {
+		t.Fatal(err)
+	}
+
+	if threadID != "12" {
+		t.Errorf("expected message_thread_id 12, got %q", threadID)
+	}
+}
+
+func TestSendByURL(t *testing.T) {
+	var sent []string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getMe" {
+			sent = append(sent, method+" "+r.FormValue(strings.TrimPrefix(strings.ToLower(method), "send"))+" "+r.FormValue("caption"))
+			return tgbotapi.Message{MessageID: 1}
+		}
+		return nil
+	})
+
+	file := tgbotapi.FileURL("https://example.com/file")
+
+	animation := tgbotapi.NewAnimation(ChatID, file)
+	animation.Caption = "gif"
+	video := tgbotapi.NewVideo(ChatID, file)
+	video.Caption = "video"
+
+	configs := []tgbotapi.Chattable{
+		tgbotapi.NewPhoto(ChatID, file),
+		tgbotapi.NewAudio(ChatID, file),
+		tgbotapi.NewDocument(ChatID, file),
+		tgbotapi.NewSticker(ChatID, file),
+		video,
+		tgbotapi.NewVoice(ChatID, file),
+		animation,
+	}
+	for _, config := range configs {
+		if _, err := bot.Send(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		"sendPhoto https://example.com/file ",
+		"sendAudio https://example.com/file ",
+		"sendDocument https://example.com/file ",
+		"sendSticker https://example.com/file ",
+		"sendVideo https://example.com/file video",
+		"sendVoice https://example.com/file ",
+		"sendAnimation https://example.com/file gif",
+	}
+	if strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got requests:\n%s", strings.Join(sent, "\n"))
+	}
+
+	if _, err := bot.Send(tgbotapi.NewPhoto(ChatID, tgbotapi.FileURL("ftp://example.com/a.jpg"))); err == nil || err.Error() != tgbotapi.ErrBadURL {
+		t.Errorf("expected bad URL error, got %v", err)
+	}
+}
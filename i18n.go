@@ -0,0 +1,311 @@
+package tgbotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Args are the values of the {placeholders} in a translated message.
+// The "count" argument, if set to an integer, chooses the plural form.
+type Args map[string]interface{}
+
+// PluralRule returns the CLDR plural category of a count in a language,
+// such as "one", "few", "many" or "other".
+type PluralRule func(n int) string
+
+// pluralRules are the plural rules of common languages. Languages not
+// listed use "one" for 1 and "other" for anything else.
+var pluralRules = map[string]PluralRule{
+	"ru": slavicPlural,
+	"uk": slavicPlural,
+	"be": slavicPlural,
+	"pl": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	},
+	"fr": func(n int) string {
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"ja": otherPlural,
+	"ko": otherPlural,
+	"zh": otherPlural,
+	"id": otherPlural,
+	"vi": otherPlural,
+}
+
+func slavicPlural(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+func otherPlural(n int) string {
+	return "other"
+}
+
+func defaultPlural(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// Translations holds a bot's messages in each language it speaks, and
+// picks the right one for each user.
+type Translations struct {
+	// Fallback is the language used for users whose language has no
+	// catalog, or a message missing from their language's catalog.
+	Fallback string
+	// ParseMode is the parse mode messages are sent with, which
+	// arguments are escaped for.
+	ParseMode string
+
+	mu       sync.RWMutex
+	catalogs map[string]map[string]translation
+	plurals  map[string]PluralRule
+}
+
+// translation is a message, which is either a single string or one for
+// each plural category.
+type translation struct {
+	text   string
+	plural map[string]string
+}
+
+func (t *translation) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.text); err == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, &t.plural)
+}
+
+// NewTranslations creates Translations falling back to a language, with
+// messages sent in a parse mode.
+func NewTranslations(fallback string, parseMode string) *Translations {
+	return &Translations{
+		Fallback:  normalizeLanguage(fallback),
+		ParseMode: parseMode,
+	}
+}
+
+// LoadCatalog loads the messages of a language from JSON, adding to any
+// already loaded. Each message is either a string, or an object with a
+// string for each plural category:
+//
+//	{
+//		"greeting": "Hello, {name}!",
+//		"apples": {"one": "{count} apple", "other": "{count} apples"}
+//	}
+func (t *Translations) LoadCatalog(language string, r io.Reader) error {
+	var catalog map[string]translation
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return fmt.Errorf("loading %s catalog: %v", language, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.catalogs == nil {
+		t.catalogs = make(map[string]map[string]translation)
+	}
+
+	language = normalizeLanguage(language)
+	if t.catalogs[language] == nil {
+		t.catalogs[language] = make(map[string]translation)
+	}
+	for key, message := range catalog {
+		t.catalogs[language][key] = message
+	}
+
+	return nil
+}
+
+// SetPluralRule sets the plural rule of a language, for languages not
+// built in.
+func (t *Translations) SetPluralRule(language string, rule PluralRule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.plurals == nil {
+		t.plurals = make(map[string]PluralRule)
+	}
+	t.plurals[normalizeLanguage(language)] = rule
+}
+
+// Resolve returns the language with a catalog which best matches a
+// language code, such as a user's LanguageCode. "pt-BR" uses "pt-br" if
+// it is loaded, then "pt", then Fallback.
+func (t *Translations) Resolve(languageCode string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	language := normalizeLanguage(languageCode)
+	if _, ok := t.catalogs[language]; ok {
+		return language
+	}
+
+	if i := strings.IndexByte(language, '-'); i != -1 {
+		if _, ok := t.catalogs[language[:i]]; ok {
+			return language[:i]
+		}
+	}
+
+	return t.Fallback
+}
+
+// Translate returns a message in a language, with its placeholders
+// replaced by args escaped for ParseMode. If the message is missing from
+// both the language and Fallback, its key is returned.
+func (t *Translations) Translate(language string, key string, args Args) string {
+	language = t.Resolve(language)
+
+	t.mu.RLock()
+	message, ok := t.catalogs[language][key]
+	if !ok {
+		language = t.Fallback
+		message, ok = t.catalogs[language][key]
+	}
+	rule := t.plurals[language]
+	t.mu.RUnlock()
+
+	if !ok {
+		return key
+	}
+
+	text := message.text
+	if message.plural != nil {
+		text = message.plural[t.pluralCategory(language, rule, args)]
+		if text == "" {
+			text = message.plural["other"]
+		}
+	}
+
+	return t.format(text, args)
+}
+
+// pluralCategory returns the plural category for the count in args.
+func (t *Translations) pluralCategory(language string, rule PluralRule, args Args) string {
+	count, ok := args["count"].(int)
+	if !ok {
+		return "other"
+	}
+	if count < 0 {
+		count = -count
+	}
+
+	if rule == nil {
+		if i := strings.IndexByte(language, '-'); i != -1 {
+			language = language[:i]
+		}
+		if rule = pluralRules[language]; rule == nil {
+			rule = defaultPlural
+		}
+	}
+
+	return rule(count)
+}
+
+// format replaces {placeholders} in text with args. Unknown placeholders
+// are left as they are.
+func (t *Translations) format(text string, args Args) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(text, '{')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end == -1 {
+			break
+		}
+		end += start
+
+		b.WriteString(text[:start])
+		if value, ok := args[text[start+1:end]]; ok {
+			b.WriteString(EscapeText(t.ParseMode, fmt.Sprint(value)))
+		} else {
+			b.WriteString(text[start : end+1])
+		}
+		text = text[end+1:]
+	}
+	b.WriteString(text)
+
+	return b.String()
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.Replace(language, "_", "-", -1))
+}
+
+// translatorKey is the context key for the translator of an update.
+type translatorKey struct{}
+
+// translator is Translations for the language of an update's user.
+type translator struct {
+	translations *Translations
+	language     string
+}
+
+// Middleware returns Middleware which makes the language of the user who
+// sent each update available to handlers through Translate.
+func (t *Translations) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			language := ""
+			if user := update.SentFrom(); user != nil {
+				language = user.LanguageCode
+			}
+
+			ctx = context.WithValue(ctx, translatorKey{}, translator{
+				translations: t,
+				language:     t.Resolve(language),
+			})
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// Translate returns a message in the language of the user whose update
+// is being handled, as chosen by Translations.Middleware. Outside of it,
+// the key is returned.
+//
+//	t := tgbotapi.Translate
+//	msg := tgbotapi.NewMessage(chatID, t(ctx, "greeting", tgbotapi.Args{"name": user.FirstName}))
+func Translate(ctx context.Context, key string, args Args) string {
+	tr, ok := ctx.Value(translatorKey{}).(translator)
+	if !ok {
+		return key
+	}
+
+	return tr.translations.Translate(tr.language, key, args)
+}
+
+// LanguageFromContext returns the language chosen for the user whose
+// update is being handled by Translations.Middleware.
+func LanguageFromContext(ctx context.Context) string {
+	tr, _ := ctx.Value(translatorKey{}).(translator)
+
+	return tr.language
+}
@@ -0,0 +1,79 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func newTestTranslations(t *testing.T) *tgbotapi.Translations {
+	tr := tgbotapi.NewTranslations("en", tgbotapi.ModeHTML)
+
+	catalogs := map[string]string{
+		"en": `{"greeting": "Hello, <b>{name}</b>!", "apples": {"one": "{count} apple", "other": "{count} apples"}, "bye": "Bye"}`,
+		"ru": `{"greeting": "Привет, <b>{name}</b>!", "apples": {"one": "{count} яблоко", "few": "{count} яблока", "many": "{count} яблок"}}`,
+	}
+	for language, catalog := range catalogs {
+		if err := tr.LoadCatalog(language, strings.NewReader(catalog)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tr
+}
+
+func TestTranslations(t *testing.T) {
+	tr := newTestTranslations(t)
+
+	tests := []struct {
+		language string
+		key      string
+		args     tgbotapi.Args
+		expected string
+	}{
+		{"en", "greeting", tgbotapi.Args{"name": "<Ann>"}, "Hello, <b>&lt;Ann&gt;</b>!"},
+		{"ru-RU", "greeting", tgbotapi.Args{"name": "Аня"}, "Привет, <b>Аня</b>!"},
+		{"de", "greeting", tgbotapi.Args{"name": "Anna"}, "Hello, <b>Anna</b>!"},
+		{"ru", "bye", nil, "Bye"},
+		{"en", "missing", nil, "missing"},
+		{"en", "apples", tgbotapi.Args{"count": 1}, "1 apple"},
+		{"en", "apples", tgbotapi.Args{"count": 5}, "5 apples"},
+		{"ru", "apples", tgbotapi.Args{"count": 21}, "21 яблоко"},
+		{"ru", "apples", tgbotapi.Args{"count": 3}, "3 яблока"},
+		{"ru", "apples", tgbotapi.Args{"count": 11}, "11 яблок"},
+	}
+
+	for _, test := range tests {
+		if got := tr.Translate(test.language, test.key, test.args); got != test.expected {
+			t.Errorf("%s %s: expected %q, got %q", test.language, test.key, test.expected, got)
+		}
+	}
+}
+
+func TestTranslationsMiddleware(t *testing.T) {
+	tr := newTestTranslations(t)
+
+	var got, language string
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		got = tgbotapi.Translate(ctx, "greeting", tgbotapi.Args{"name": "Аня"})
+		language = tgbotapi.LanguageFromContext(ctx)
+	})
+	d.Use(tr.Middleware())
+
+	d.Dispatch(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 1, LanguageCode: "ru"},
+			Chat: &tgbotapi.Chat{ID: 1},
+		},
+	})
+
+	if got != "Привет, <b>Аня</b>!" || language != "ru" {
+		t.Errorf("got %q in %q", got, language)
+	}
+
+	if got := tgbotapi.Translate(context.Background(), "greeting", nil); got != "greeting" {
+		t.Errorf("expected the key outside of the middleware, got %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+package tgbotapi
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// InlineBuilder assembles an InlineConfig from results of any type,
+// giving results without an ID one which is unique within the answer.
+//
+// Errors are kept until Build, so calls may be chained:
+//
+//	config, err := tgbotapi.NewInlineBuilder(query.ID).
+//		Add(article, photo).
+//		CacheTime(60).
+//		Personal().
+//		Build()
+type InlineBuilder struct {
+	config InlineConfig
+	ids    map[string]bool
+	auto   []int
+	err    error
+}
+
+// NewInlineBuilder creates an InlineBuilder answering an inline query.
+func NewInlineBuilder(inlineQueryID string) *InlineBuilder {
+	return &InlineBuilder{
+		config: InlineConfig{InlineQueryID: inlineQueryID},
+		ids:    make(map[string]bool),
+	}
+}
+
+// Add adds results, such as an InlineQueryResultArticle or a pointer to
+// one. Results with an empty ID field are given their position in the
+// answer as an ID, or the next number not used by another result. The
+// ID is set on a copy, so results passed by pointer are not changed.
+//
+// At most InlineResultsPerPage results may be added.
+func (b *InlineBuilder) Add(results ...interface{}) *InlineBuilder {
+	for _, result := range results {
+		if b.err != nil {
+			return b
+		}
+
+		if len(b.config.Results) >= InlineResultsPerPage {
+			b.err = errors.New(ErrTooManyInlineResults)
+			return b
+		}
+
+		id, ok := resultID(result)
+		switch {
+		case ok && id == "":
+			b.auto = append(b.auto, len(b.config.Results))
+		case ok && b.ids[id]:
+			b.err = errors.New(ErrDuplicateInlineResultID)
+			return b
+		case ok:
+			b.ids[id] = true
+		}
+
+		b.config.Results = append(b.config.Results, result)
+	}
+
+	return b
+}
+
+// CacheTime sets how many seconds Telegram may cache the results for.
+func (b *InlineBuilder) CacheTime(seconds int) *InlineBuilder {
+	b.config.CacheTime = seconds
+	return b
+}
+
+// Personal makes the results be cached only for the user who sent the
+// query.
+func (b *InlineBuilder) Personal() *InlineBuilder {
+	b.config.IsPersonal = true
+	return b
+}
+
+// NextOffset sets the offset Telegram sends when the user wants more
+// results.
+func (b *InlineBuilder) NextOffset(offset string) *InlineBuilder {
+	b.config.NextOffset = offset
+	return b
+}
+
+// SwitchPM shows a button above the results which opens a private chat
+// with the bot, sending /start with parameter.
+func (b *InlineBuilder) SwitchPM(text, parameter string) *InlineBuilder {
+	b.config.SwitchPMText = text
+	b.config.SwitchPMParameter = parameter
+	return b
+}
+
+// Build returns the InlineConfig, or the first error from adding
+// results.
+func (b *InlineBuilder) Build() (InlineConfig, error) {
+	if b.err != nil {
+		return InlineConfig{}, b.err
+	}
+
+	config := b.config
+	config.Results = append([]interface{}(nil), b.config.Results...)
+
+	used := make(map[string]bool, len(b.ids)+len(b.auto))
+	for id := range b.ids {
+		used[id] = true
+	}
+	for _, i := range b.auto {
+		next := i
+		for used[strconv.Itoa(next)] {
+			next++
+		}
+
+		id := strconv.Itoa(next)
+		used[id] = true
+		config.Results[i] = withResultID(config.Results[i], id)
+	}
+
+	return config, nil
+}
+
+// resultID returns the ID field of result, and whether it has one.
+func resultID(result interface{}) (string, bool) {
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	field := v.FieldByName("ID")
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return "", false
+	}
+
+	return field.String(), true
+}
+
+// withResultID returns a copy of result with its ID field set to id. A
+// pointer result is returned as a pointer to a copy.
+func withResultID(result interface{}, id string) interface{} {
+	v := reflect.ValueOf(result)
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		v = v.Elem()
+	}
+
+	copied := reflect.New(v.Type())
+	copied.Elem().Set(v)
+	copied.Elem().FieldByName("ID").SetString(id)
+
+	if isPtr {
+		return copied.Interface()
+	}
+
+	return copied.Elem().Interface()
+}
@@ -0,0 +1,166 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InlineCache keeps the results of inline queries in a KeyValueStore, so
+// repeats of a query can be answered without searching again. This helps
+// inline bots backed by slow searches answer within Telegram's deadline.
+//
+// Queries are matched ignoring case and extra whitespace. While a query
+// is being fetched, the same query from other users waits for it rather
+// than fetching it again.
+type InlineCache struct {
+	Store KeyValueStore
+	// TTL is how long results are kept.
+	TTL time.Duration
+	// PerUser keeps separate results for each user, for results which
+	// depend on who is asking.
+	PerUser bool
+	// PerLanguage keeps separate results for each user's language.
+	PerLanguage bool
+
+	mu       sync.Mutex
+	inFlight map[string]*inlineFetch
+}
+
+// inlineFetch is a fetch of results which others may be waiting for.
+type inlineFetch struct {
+	done    chan struct{}
+	results cachedInlineResults
+	err     error
+}
+
+// cachedInlineResults is what is kept for a query.
+type cachedInlineResults struct {
+	Results    []json.RawMessage `json:"results"`
+	NextOffset string            `json:"next_offset,omitempty"`
+}
+
+// InlineFetchFunc fetches the results for an inline query, and the
+// offset to request more with, if there are any.
+type InlineFetchFunc func(query *InlineQuery) (results []interface{}, nextOffset string, err error)
+
+// NewInlineCache creates an InlineCache keeping results in store for
+// ttl.
+func NewInlineCache(store KeyValueStore, ttl time.Duration) *InlineCache {
+	return &InlineCache{
+		Store: store,
+		TTL:   ttl,
+	}
+}
+
+// Answer answers an inline query with cached results if there are any,
+// or with results from fetch, which are then cached. config is the rest
+// of the answer, such as CacheTime.
+func (c *InlineCache) Answer(bot *BotAPI, query *InlineQuery, config InlineConfig, fetch InlineFetchFunc) (APIResponse, error) {
+	cached, err := c.results(query, fetch)
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	config.InlineQueryID = query.ID
+	config.NextOffset = cached.NextOffset
+	config.Results = make([]interface{}, len(cached.Results))
+	for i, result := range cached.Results {
+		config.Results[i] = result
+	}
+
+	return bot.AnswerInlineQuery(config)
+}
+
+// results returns the results for a query from the store, or fetches
+// them.
+func (c *InlineCache) results(query *InlineQuery, fetch InlineFetchFunc) (cachedInlineResults, error) {
+	key := c.key(query)
+
+	var cached cachedInlineResults
+	data, ok, err := c.Store.Get(key)
+	if err != nil {
+		return cached, err
+	}
+	if ok && json.Unmarshal(data, &cached) == nil {
+		return cached, nil
+	}
+
+	c.mu.Lock()
+	if f, ok := c.inFlight[key]; ok {
+		c.mu.Unlock()
+		<-f.done
+		return f.results, f.err
+	}
+
+	f := &inlineFetch{done: make(chan struct{})}
+	if c.inFlight == nil {
+		c.inFlight = make(map[string]*inlineFetch)
+	}
+	c.inFlight[key] = f
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inFlight, key)
+		c.mu.Unlock()
+		close(f.done)
+	}()
+
+	f.results, f.err = c.fetch(key, query, fetch)
+
+	return f.results, f.err
+}
+
+// fetch fetches the results for a query and stores them.
+func (c *InlineCache) fetch(key string, query *InlineQuery, fetch InlineFetchFunc) (cachedInlineResults, error) {
+	results, nextOffset, err := fetch(query)
+	if err != nil {
+		return cachedInlineResults{}, err
+	}
+
+	cached := cachedInlineResults{
+		Results:    make([]json.RawMessage, len(results)),
+		NextOffset: nextOffset,
+	}
+	for i, result := range results {
+		if cached.Results[i], err = json.Marshal(result); err != nil {
+			return cachedInlineResults{}, err
+		}
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return cachedInlineResults{}, err
+	}
+
+	return cached, c.Store.Set(key, data, c.TTL)
+}
+
+// key returns the key results for a query are kept under.
+func (c *InlineCache) key(query *InlineQuery) string {
+	var b strings.Builder
+	b.WriteString("inline:")
+
+	if c.PerUser && query.From != nil {
+		b.WriteString(strconv.FormatInt(query.From.ID, 10))
+	}
+	b.WriteByte(':')
+	if c.PerLanguage && query.From != nil {
+		b.WriteString(strings.ToLower(query.From.LanguageCode))
+	}
+	b.WriteByte(':')
+	b.WriteString(query.Offset)
+	b.WriteByte(':')
+	b.WriteString(NormalizeInlineQuery(query.Query))
+
+	return b.String()
+}
+
+// NormalizeInlineQuery returns the form of a query used to match it to
+// others, lowercased and with whitespace collapsed.
+func NormalizeInlineQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
@@ -0,0 +1,69 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestInlineCache(t *testing.T) {
+	var mu sync.Mutex
+	var answers []string
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "answerInlineQuery" {
+			mu.Lock()
+			answers = append(answers, r.FormValue("results")+" "+r.FormValue("next_offset"))
+			mu.Unlock()
+			return true
+		}
+		return nil
+	})
+
+	cache := tgbotapi.NewInlineCache(tgbotapi.NewMemoryStore(), time.Minute)
+
+	fetches := 0
+	fetch := func(query *tgbotapi.InlineQuery) ([]interface{}, string, error) {
+		fetches++
+		return []interface{}{tgbotapi.NewInlineQueryResultArticle("1", query.Query, "text")}, "50", nil
+	}
+
+	queries := []*tgbotapi.InlineQuery{
+		{ID: "a", Query: "Cats", From: &tgbotapi.User{ID: 1}},
+		{ID: "b", Query: "  cats ", From: &tgbotapi.User{ID: 2}},
+		{ID: "c", Query: "dogs", From: &tgbotapi.User{ID: 1}},
+	}
+	for _, query := range queries {
+		if _, err := cache.Answer(bot, query, tgbotapi.InlineConfig{}, fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetches)
+	}
+	if len(answers) != 3 || answers[0] != answers[1] || answers[0] == answers[2] {
+		t.Fatalf("got answers %q", answers)
+	}
+
+	var results []map[string]interface{}
+	if err := json.Unmarshal([]byte(answers[1][:len(answers[1])-3]), &results); err != nil || results[0]["title"] != "Cats" {
+		t.Errorf("got results %s, %v", answers[1], err)
+	}
+
+	cache.PerUser = true
+	cache.Answer(bot, queries[1], tgbotapi.InlineConfig{}, fetch)
+	if fetches != 3 {
+		t.Errorf("expected results to be fetched for another user, got %d fetches", fetches)
+	}
+}
+
+func TestNormalizeInlineQuery(t *testing.T) {
+	if got := tgbotapi.NormalizeInlineQuery("  Hello \t  World "); got != "hello world" {
+		t.Errorf("got %q", got)
+	}
+}
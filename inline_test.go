@@ -0,0 +1,86 @@
+package tgbotapi_test
+
+import (
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestInlineBuilder(t *testing.T) {
+	article := tgbotapi.NewInlineQueryResultArticle("", "title", "message")
+	photo := tgbotapi.NewInlineQueryResultPhoto("photo", "https://example.com/a.jpg")
+	location := tgbotapi.NewInlineQueryResultLocation("", "here", 1, 2)
+
+	config, err := tgbotapi.NewInlineBuilder("query").
+		Add(article, photo, &location).
+		CacheTime(60).
+		Personal().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.InlineQueryID != "query" || config.CacheTime != 60 || !config.IsPersonal {
+		t.Errorf("got %+v", config)
+	}
+	if len(config.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(config.Results))
+	}
+	if id := config.Results[0].(tgbotapi.InlineQueryResultArticle).ID; id != "0" {
+		t.Errorf("expected article ID 0, got %q", id)
+	}
+	if id := config.Results[1].(tgbotapi.InlineQueryResultPhoto).ID; id != "photo" {
+		t.Errorf("expected photo ID to be kept, got %q", id)
+	}
+	if id := config.Results[2].(*tgbotapi.InlineQueryResultLocation).ID; id != "2" {
+		t.Errorf("expected location ID 2, got %q", id)
+	}
+	if article.ID != "" {
+		t.Error("article passed by value was changed")
+	}
+	if location.ID != "" {
+		t.Error("location passed by pointer was changed")
+	}
+}
+
+func TestInlineBuilderAutoIDs(t *testing.T) {
+	config, err := tgbotapi.NewInlineBuilder("query").
+		Add(tgbotapi.NewInlineQueryResultArticle("", "a", "a")).
+		Add(tgbotapi.NewInlineQueryResultArticle("", "b", "b")).
+		Add(tgbotapi.NewInlineQueryResultArticle("0", "c", "c")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, result := range config.Results {
+		ids = append(ids, result.(tgbotapi.InlineQueryResultArticle).ID)
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[1] != "2" || ids[2] != "0" {
+		t.Errorf("expected IDs [1 2 0], got %v", ids)
+	}
+}
+
+func TestInlineBuilderLimits(t *testing.T) {
+	builder := tgbotapi.NewInlineBuilder("query")
+	for i := 0; i < tgbotapi.InlineResultsPerPage; i++ {
+		builder.Add(tgbotapi.NewInlineQueryResultArticle("", "title", "message"))
+	}
+	if _, err := builder.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	builder.Add(tgbotapi.NewInlineQueryResultArticle("", "title", "message"))
+	if _, err := builder.Build(); err == nil || err.Error() != tgbotapi.ErrTooManyInlineResults {
+		t.Errorf("expected too many results error, got %v", err)
+	}
+
+	_, err := tgbotapi.NewInlineBuilder("query").
+		Add(tgbotapi.NewInlineQueryResultArticle("same", "a", "a")).
+		Add(tgbotapi.NewInlineQueryResultArticle("same", "b", "b")).
+		Build()
+	if err == nil || err.Error() != tgbotapi.ErrDuplicateInlineResultID {
+		t.Errorf("expected duplicate ID error, got %v", err)
+	}
+}
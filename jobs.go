@@ -0,0 +1,165 @@
+package tgbotapi
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JobFunc is a recurring job, run with the context given to RunJobs.
+type JobFunc func(ctx context.Context, bot *BotAPI)
+
+// Job is a recurring job added with Every.
+type Job struct {
+	// Name is the key the job's next run is kept under in JobStore. It
+	// is the schedule, with a number added if another job has the same
+	// one, and should be set to something stable before RunJobs if jobs
+	// may be added in a different order.
+	Name string
+	// Location is the time zone the schedule is in, or time.Local if it
+	// is nil.
+	Location *time.Location
+
+	schedule *Schedule
+	fn       JobFunc
+}
+
+// Every adds a job which runs on a cron schedule, such as "0 9 * * *"
+// for 9:00 each day. See ParseSchedule for the syntax. Jobs run once
+// RunJobs is called.
+//
+// Messages sent by jobs are limited by the bot's RateLimiter like any
+// others, so a large digest doesn't break Telegram's limits.
+func (bot *BotAPI) Every(spec string, fn JobFunc) (*Job, error) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	bot.jobsMu.Lock()
+	defer bot.jobsMu.Unlock()
+
+	name := spec
+	for n := 2; bot.hasJob(name); n++ {
+		name = spec + "#" + strconv.Itoa(n)
+	}
+
+	job := &Job{
+		Name:     name,
+		schedule: schedule,
+		fn:       fn,
+	}
+	bot.jobs = append(bot.jobs, job)
+
+	return job, nil
+}
+
+func (bot *BotAPI) hasJob(name string) bool {
+	for _, job := range bot.jobs {
+		if job.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RunJobs runs the jobs added with Every until ctx is done, then waits
+// for any which are running to return. Each job runs at most once at a
+// time; a run that would start while the last is still going is
+// skipped.
+//
+// If JobStore is set, a job whose run was missed while the bot was
+// stopped runs as soon as RunJobs starts.
+func (bot *BotAPI) RunJobs(ctx context.Context) {
+	bot.jobsMu.Lock()
+	jobs := append([]*Job(nil), bot.jobs...)
+	bot.jobsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			bot.runJob(ctx, job)
+		}(job)
+	}
+
+	wg.Wait()
+}
+
+// runJob runs a job each time its schedule says until ctx is done.
+func (bot *BotAPI) runJob(ctx context.Context, job *Job) {
+	loc := job.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	key := "job:" + job.Name
+
+	next, ok := bot.storedJobRun(key)
+	if !ok {
+		next = job.schedule.Next(time.Now().In(loc))
+		bot.storeJobRun(key, next)
+	}
+
+	for !next.IsZero() {
+		if err := sleepContext(ctx, time.Until(next)); err != nil {
+			return
+		}
+
+		job.run(ctx, bot)
+
+		next = job.schedule.Next(time.Now().In(loc))
+		bot.storeJobRun(key, next)
+	}
+}
+
+// storeJobRun keeps when a job should next run in JobStore, if it is
+// set.
+func (bot *BotAPI) storeJobRun(key string, next time.Time) {
+	if bot.JobStore == nil || next.IsZero() {
+		return
+	}
+
+	value := []byte(strconv.FormatInt(next.Unix(), 10))
+	if err := bot.JobStore.Set(key, value, 0); err != nil {
+		log.Println(err)
+	}
+}
+
+// storedJobRun returns when a job should next run, as kept in JobStore.
+func (bot *BotAPI) storedJobRun(key string) (time.Time, bool) {
+	if bot.JobStore == nil {
+		return time.Time{}, false
+	}
+
+	value, ok, err := bot.JobStore.Get(key)
+	if err != nil {
+		log.Println(err)
+		return time.Time{}, false
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(sec, 0), true
+}
+
+// run runs the job, logging it if it panics.
+func (job *Job) run(ctx context.Context, bot *BotAPI) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("panic running job %s: %v\n%s", job.Name, err, debug.Stack())
+		}
+	}()
+
+	job.fn(ctx, bot)
+}
@@ -0,0 +1,65 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestEveryNames(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} { return nil })
+
+	first, err := bot.Every("0 9 * * *", func(ctx context.Context, bot *tgbotapi.BotAPI) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := bot.Every("0 9 * * *", func(ctx context.Context, bot *tgbotapi.BotAPI) {})
+
+	if first.Name != "0 9 * * *" || second.Name != "0 9 * * *#2" {
+		t.Errorf("unexpected names %q and %q", first.Name, second.Name)
+	}
+
+	if _, err := bot.Every("0 25 * * *", func(ctx context.Context, bot *tgbotapi.BotAPI) {}); err == nil {
+		t.Error("expected an error for a bad schedule")
+	}
+}
+
+func TestRunJobsMissedRun(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} { return nil })
+
+	bot.JobStore = tgbotapi.NewMemoryStore()
+	past := time.Now().Add(-time.Hour).Unix()
+	bot.JobStore.Set("job:digest", []byte(strconv.FormatInt(past, 10)), 0)
+
+	ran := make(chan struct{}, 1)
+	job, _ := bot.Every("0 9 * * *", func(ctx context.Context, bot *tgbotapi.BotAPI) {
+		ran <- struct{}{}
+	})
+	job.Name = "digest"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.RunJobs(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("expected the missed run to run")
+	}
+
+	cancel()
+	<-done
+
+	value, ok, _ := bot.JobStore.Get("job:digest")
+	next, _ := strconv.ParseInt(string(value), 10, 64)
+	if !ok || next <= time.Now().Unix() {
+		t.Errorf("expected the next run to be stored, got %s", value)
+	}
+}
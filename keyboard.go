@@ -0,0 +1,122 @@
+package tgbotapi
+
+// Clone returns a copy of the keyboard which can be changed without
+// changing the original, such as the keyboard of a received message.
+func (markup InlineKeyboardMarkup) Clone() InlineKeyboardMarkup {
+	clone := InlineKeyboardMarkup{
+		InlineKeyboard: make([][]InlineKeyboardButton, len(markup.InlineKeyboard)),
+	}
+
+	for i, row := range markup.InlineKeyboard {
+		clone.InlineKeyboard[i] = make([]InlineKeyboardButton, len(row))
+		for j, button := range row {
+			clone.InlineKeyboard[i][j] = button.clone()
+		}
+	}
+
+	return clone
+}
+
+func (button InlineKeyboardButton) clone() InlineKeyboardButton {
+	copyString := func(s *string) *string {
+		if s == nil {
+			return nil
+		}
+		c := *s
+		return &c
+	}
+
+	button.URL = copyString(button.URL)
+	button.CallbackData = copyString(button.CallbackData)
+	button.SwitchInlineQuery = copyString(button.SwitchInlineQuery)
+	button.SwitchInlineQueryCurrentChat = copyString(button.SwitchInlineQueryCurrentChat)
+	if button.CallbackGame != nil {
+		game := *button.CallbackGame
+		button.CallbackGame = &game
+	}
+	if button.WebApp != nil {
+		webApp := *button.WebApp
+		button.WebApp = &webApp
+	}
+	if button.LoginURL != nil {
+		loginURL := *button.LoginURL
+		button.LoginURL = &loginURL
+	}
+
+	return button
+}
+
+// Button returns the button with callbackData, or nil if there is none.
+// Changing the button changes the keyboard.
+func (markup *InlineKeyboardMarkup) Button(callbackData string) *InlineKeyboardButton {
+	for i := range markup.InlineKeyboard {
+		for j := range markup.InlineKeyboard[i] {
+			button := &markup.InlineKeyboard[i][j]
+			if button.CallbackData != nil && *button.CallbackData == callbackData {
+				return button
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetButtonText changes the text of the button with callbackData. It
+// returns false if there is no such button.
+func (markup *InlineKeyboardMarkup) SetButtonText(callbackData, text string) bool {
+	button := markup.Button(callbackData)
+	if button == nil {
+		return false
+	}
+
+	button.Text = text
+	return true
+}
+
+// SetButtonData changes the callback data of the button with
+// callbackData. It returns false if there is no such button.
+func (markup *InlineKeyboardMarkup) SetButtonData(callbackData, newData string) bool {
+	button := markup.Button(callbackData)
+	if button == nil {
+		return false
+	}
+
+	button.CallbackData = &newData
+	return true
+}
+
+// ReplaceButton replaces the button with callbackData. It returns false
+// if there is no such button.
+func (markup *InlineKeyboardMarkup) ReplaceButton(callbackData string, replacement InlineKeyboardButton) bool {
+	button := markup.Button(callbackData)
+	if button == nil {
+		return false
+	}
+
+	*button = replacement
+	return true
+}
+
+// ToggleButton switches the text of the button with callbackData between
+// on and off, such as "☑ Option" and "☐ Option", returning if it is now
+// on. A button with any other text is turned on.
+func (markup *InlineKeyboardMarkup) ToggleButton(callbackData, on, off string) bool {
+	button := markup.Button(callbackData)
+	if button == nil {
+		return false
+	}
+
+	if button.Text == on {
+		button.Text = off
+		return false
+	}
+
+	button.Text = on
+	return true
+}
+
+// EditKeyboard creates an edit replacing the keyboard of a message, such
+// as after changing a Clone of the message's ReplyMarkup.
+func (m *Message) EditKeyboard(markup InlineKeyboardMarkup) EditMessageReplyMarkupConfig {
+	return NewEditMessageReplyMarkup(m.Chat.ID, m.MessageID, markup)
+}
@@ -0,0 +1,44 @@
+package tgbotapi_test
+
+import (
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestToggleKeyboard(t *testing.T) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("☐ Cheese", "cheese"),
+			tgbotapi.NewInlineKeyboardButtonData("☐ Ham", "ham"),
+		),
+	)
+	message := tgbotapi.Message{MessageID: 3, Chat: &tgbotapi.Chat{ID: ChatID}, ReplyMarkup: &keyboard}
+
+	markup := message.ReplyMarkup.Clone()
+	if !markup.ToggleButton("ham", "☑ Ham", "☐ Ham") {
+		t.Error("expected the button to be on")
+	}
+	if !markup.SetButtonData("cheese", "cheese:1") {
+		t.Error("button not found")
+	}
+	if markup.SetButtonText("missing", "text") {
+		t.Error("found a missing button")
+	}
+
+	if text := markup.InlineKeyboard[0][1].Text; text != "☑ Ham" {
+		t.Errorf("got text %q", text)
+	}
+	if data := *markup.InlineKeyboard[0][0].CallbackData; data != "cheese:1" {
+		t.Errorf("got callback data %q", data)
+	}
+
+	if keyboard.InlineKeyboard[0][1].Text != "☐ Ham" || *keyboard.InlineKeyboard[0][0].CallbackData != "cheese" {
+		t.Error("the original keyboard was changed")
+	}
+
+	edit := message.EditKeyboard(markup)
+	if edit.ChatID != ChatID || edit.MessageID != 3 || edit.ReplyMarkup.InlineKeyboard[0][1].Text != "☑ Ham" {
+		t.Errorf("got edit %+v", edit)
+	}
+}
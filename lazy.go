@@ -0,0 +1,161 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// LazyUpdate is an update received by a webhook which is only fully
+// decoded when Update is called, so bots which ignore most kinds of
+// update don't pay for decoding them.
+type LazyUpdate struct {
+	UpdateID int
+	// Raw is the JSON of the update.
+	Raw json.RawMessage
+
+	kind UpdateType
+
+	once   sync.Once
+	update Update
+	err    error
+}
+
+// NewLazyUpdate reads the ID and kind of an update from its JSON,
+// without decoding the rest of it. The JSON is kept, not copied.
+func NewLazyUpdate(raw []byte) (*LazyUpdate, error) {
+	var envelope updateEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, err
+	}
+
+	return &LazyUpdate{
+		UpdateID: envelope.UpdateID,
+		Raw:      raw,
+		kind:     envelope.kind(),
+	}, nil
+}
+
+// Kind returns the kind of update, or an empty UpdateType if it is one
+// not supported by this library.
+func (u *LazyUpdate) Kind() UpdateType {
+	return u.kind
+}
+
+// Update decodes the update the first time it is called.
+func (u *LazyUpdate) Update() (Update, error) {
+	u.once.Do(func() {
+		u.err = json.Unmarshal(u.Raw, &u.update)
+	})
+
+	return u.update, u.err
+}
+
+// present is a JSON value whose contents are skipped, only noting that
+// it was there.
+type present bool
+
+func (p *present) UnmarshalJSON(data []byte) error {
+	*p = present(!bytes.Equal(data, []byte("null")))
+	return nil
+}
+
+// updateEnvelope is the fields of an update needed to tell its kind.
+type updateEnvelope struct {
+	UpdateID           int     `json:"update_id"`
+	Message            present `json:"message"`
+	EditedMessage      present `json:"edited_message"`
+	ChannelPost        present `json:"channel_post"`
+	EditedChannelPost  present `json:"edited_channel_post"`
+	InlineQuery        present `json:"inline_query"`
+	ChosenInlineResult present `json:"chosen_inline_result"`
+	CallbackQuery      present `json:"callback_query"`
+	Poll               present `json:"poll"`
+	PollAnswer         present `json:"poll_answer"`
+	ShippingQuery      present `json:"shipping_query"`
+	PreCheckoutQuery   present `json:"pre_checkout_query"`
+	ChatJoinRequest    present `json:"chat_join_request"`
+	MyChatMember       present `json:"my_chat_member"`
+	ChatMember         present `json:"chat_member"`
+	ChatBoost          present `json:"chat_boost"`
+	RemovedChatBoost   present `json:"removed_chat_boost"`
+}
+
+func (e *updateEnvelope) kind() UpdateType {
+	switch {
+	case bool(e.Message):
+		return UpdateTypeMessage
+	case bool(e.EditedMessage):
+		return UpdateTypeEditedMessage
+	case bool(e.ChannelPost):
+		return UpdateTypeChannelPost
+	case bool(e.EditedChannelPost):
+		return UpdateTypeEditedChannelPost
+	case bool(e.InlineQuery):
+		return UpdateTypeInlineQuery
+	case bool(e.ChosenInlineResult):
+		return UpdateTypeChosenInlineResult
+	case bool(e.CallbackQuery):
+		return UpdateTypeCallbackQuery
+	case bool(e.Poll):
+		return UpdateTypePoll
+	case bool(e.PollAnswer):
+		return UpdateTypePollAnswer
+	case bool(e.ShippingQuery):
+		return UpdateTypeShippingQuery
+	case bool(e.PreCheckoutQuery):
+		return UpdateTypePreCheckoutQuery
+	case bool(e.ChatJoinRequest):
+		return UpdateTypeChatJoinRequest
+	case bool(e.MyChatMember):
+		return UpdateTypeMyChatMember
+	case bool(e.ChatMember):
+		return UpdateTypeChatMember
+	case bool(e.ChatBoost):
+		return UpdateTypeChatBoost
+	case bool(e.RemovedChatBoost):
+		return UpdateTypeRemovedChatBoost
+	}
+
+	return ""
+}
+
+// LazyUpdatesChannel is the channel for getting lazily decoded updates.
+type LazyUpdatesChannel <-chan *LazyUpdate
+
+// LazyWebhookHandler is WebhookHandler for LazyUpdates. Messages and
+// callback queries are still decoded while Ask or SendAndWaitCallback is
+// waiting for an answer, to check if they are one.
+func (bot *BotAPI) LazyWebhookHandler() (http.Handler, LazyUpdatesChannel) {
+	ch := make(chan *LazyUpdate, bot.Buffer)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if !bot.readWebhookBody(w, r, &buf) {
+			return
+		}
+
+		update, err := NewLazyUpdate(buf.Bytes())
+		if err != nil {
+			return
+		}
+
+		// Reporting unknown fields needs the whole update decoded.
+		if bot.OnUnknownFields != nil {
+			if decoded, err := update.Update(); err == nil {
+				bot.reportUnknownFields(&decoded)
+			}
+		}
+
+		if kind := update.Kind(); (kind == UpdateTypeMessage || kind == UpdateTypeCallbackQuery) && bot.hasWaiters() {
+			if decoded, err := update.Update(); err == nil && bot.answer(&decoded) {
+				return
+			}
+		}
+
+		ch <- update
+	})
+
+	return handler, ch
+}
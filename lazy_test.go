@@ -0,0 +1,78 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestNewLazyUpdate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		kind tgbotapi.UpdateType
+	}{
+		{`{"update_id":1,"message":{"message_id":1,"text":"hi","chat":{"id":1}}}`, tgbotapi.UpdateTypeMessage},
+		{`{"update_id":2,"callback_query":{"id":"q","data":"yes"}}`, tgbotapi.UpdateTypeCallbackQuery},
+		{`{"update_id":3,"message":null,"poll":{"id":"p"}}`, tgbotapi.UpdateTypePoll},
+		{`{"update_id":4,"business_message":{"message_id":1}}`, ""},
+	}
+
+	for _, test := range tests {
+		update, err := tgbotapi.NewLazyUpdate([]byte(test.raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if update.Kind() != test.kind {
+			t.Errorf("%s: expected kind %q, got %q", test.raw, test.kind, update.Kind())
+		}
+
+		decoded, err := update.Update()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.UpdateID != update.UpdateID || decoded.Kind() != test.kind {
+			t.Errorf("%s: decoded %+v", test.raw, decoded)
+		}
+	}
+
+	if _, err := tgbotapi.NewLazyUpdate([]byte(`{"update_id":`)); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
+
+func TestLazyWebhookHandler(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	handler, updates := bot.LazyWebhookHandler()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/",
+		strings.NewReader(`{"update_id":5,"callback_query":{"id":"q","data":"yes"}}`)))
+
+	update := <-updates
+	if update.UpdateID != 5 || update.Kind() != tgbotapi.UpdateTypeCallbackQuery {
+		t.Fatalf("got %+v", update)
+	}
+
+	decoded, err := update.Update()
+	if err != nil || decoded.CallbackQuery.Data != "yes" {
+		t.Errorf("decoded %+v, %v", decoded, err)
+	}
+}
+
+func BenchmarkLazyUpdate(b *testing.B) {
+	raw := []byte(`{"update_id":1,"message":{"message_id":1,"date":1600000000,"text":"A message with some text in it",` +
+		`"chat":{"id":76918703,"type":"private"},"from":{"id":76918703,"first_name":"Test"}}}`)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		update, _ := tgbotapi.NewLazyUpdate(raw)
+		if update.Kind() == tgbotapi.UpdateTypeCallbackQuery {
+			update.Update()
+		}
+	}
+}
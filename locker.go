@@ -0,0 +1,97 @@
+package tgbotapi
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyedLocker is a set of mutexes identified by keys such as a chat or
+// user ID, for serializing work per chat or per user.
+//
+// Mutexes are created when first locked and removed once nothing holds
+// or waits on them. The zero value is ready to use.
+type KeyedLocker struct {
+	mu    sync.Mutex
+	locks map[int64]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewKeyedLocker creates a new KeyedLocker.
+func NewKeyedLocker() *KeyedLocker {
+	return &KeyedLocker{}
+}
+
+// Lock locks the mutex for key, waiting until it is available.
+func (l *KeyedLocker) Lock(key int64) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int64]*keyedLock)
+	}
+
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+}
+
+// Unlock unlocks the mutex for key. It panics if key is not locked.
+func (l *KeyedLocker) Unlock(key int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, ok := l.locks[key]
+	if !ok {
+		panic("tgbotapi: unlock of unlocked key")
+	}
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, key)
+	}
+
+	lock.mu.Unlock()
+}
+
+// LockChat returns Middleware which handles only one update at a time
+// for each chat, using locker. Updates without a chat are not locked.
+func LockChat(locker *KeyedLocker) Middleware {
+	return lockBy(locker, func(update Update) int64 {
+		if chat := update.FromChat(); chat != nil {
+			return chat.ID
+		}
+		return 0
+	})
+}
+
+// LockUser returns Middleware which handles only one update at a time
+// from each user, using locker. Updates without a user are not locked.
+func LockUser(locker *KeyedLocker) Middleware {
+	return lockBy(locker, func(update Update) int64 {
+		if user := update.SentFrom(); user != nil {
+			return user.ID
+		}
+		return 0
+	})
+}
+
+func lockBy(locker *KeyedLocker, key func(Update) int64) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			if k := key(update); k != 0 {
+				locker.Lock(k)
+				defer locker.Unlock(k)
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
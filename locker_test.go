@@ -0,0 +1,68 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestKeyedLocker(t *testing.T) {
+	locker := tgbotapi.NewKeyedLocker()
+
+	var running, max int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			locker.Lock(1)
+			defer locker.Unlock(1)
+
+			if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&max) {
+				atomic.StoreInt32(&max, n)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+
+	if max != 1 {
+		t.Fail()
+	}
+
+	// Other keys must not be blocked.
+	locker.Lock(1)
+	locker.Lock(2)
+	locker.Unlock(2)
+	locker.Unlock(1)
+}
+
+func TestLockChat(t *testing.T) {
+	var running, max int32
+	d := tgbotapi.NewDispatcher(nil, func(context.Context, *tgbotapi.BotAPI, tgbotapi.Update) {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&max) {
+			atomic.StoreInt32(&max, n)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+	d.Use(tgbotapi.LockChat(tgbotapi.NewKeyedLocker()))
+
+	updates := make(chan tgbotapi.Update, 5)
+	for i := 0; i < 5; i++ {
+		updates <- tgbotapi.Update{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: ChatID}}}
+	}
+	close(updates)
+
+	d.Run(context.Background(), updates)
+
+	if max != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,121 @@
+package tgbotapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoginData is the information about a user sent to a website by the
+// Telegram Login Widget.
+type LoginData struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	UserName  string `json:"username,omitempty"`
+	PhotoURL  string `json:"photo_url,omitempty"`
+	AuthDate  int64  `json:"auth_date"`
+	Hash      string `json:"hash"`
+}
+
+// User returns the user who logged in.
+func (data LoginData) User() User {
+	return User{
+		ID:        data.ID,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+		UserName:  data.UserName,
+	}
+}
+
+// AuthTime returns when the user logged in.
+func (data LoginData) AuthTime() time.Time {
+	return time.Unix(data.AuthDate, 0)
+}
+
+// CheckLoginData checks that data from the Telegram Login Widget, such
+// as the query of the URL it redirected to, was signed for the bot with
+// token, and was signed within maxAge if it is more than zero.
+//
+// It returns an error with ErrInvalidHash or ErrAuthExpired if the data
+// can't be trusted.
+func CheckLoginData(values url.Values, token string, maxAge time.Duration) (LoginData, error) {
+	secret := sha256.Sum256([]byte(token))
+	if !checkDataHash(values, secret[:]) {
+		return LoginData{}, errors.New(ErrInvalidHash)
+	}
+
+	authDate, err := checkAuthDate(values, maxAge)
+	if err != nil {
+		return LoginData{}, err
+	}
+
+	id, err := strconv.ParseInt(values.Get("id"), 10, 64)
+	if err != nil {
+		return LoginData{}, err
+	}
+
+	return LoginData{
+		ID:        id,
+		FirstName: values.Get("first_name"),
+		LastName:  values.Get("last_name"),
+		UserName:  values.Get("username"),
+		PhotoURL:  values.Get("photo_url"),
+		AuthDate:  authDate,
+		Hash:      values.Get("hash"),
+	}, nil
+}
+
+// checkDataHash checks the hash of data signed by Telegram, which is the
+// HMAC-SHA256 of every other field as sorted key=value lines.
+func checkDataHash(values url.Values, secret []byte) bool {
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(dataCheckString(values)))
+
+	return hmac.Equal(mac.Sum(nil), hash)
+}
+
+// dataCheckString is the string Telegram signs for data: every field but
+// the hash, as key=value lines sorted by key.
+func dataCheckString(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + values.Get(key)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkAuthDate returns the auth_date of signed data, checking it is no
+// older than maxAge if it is more than zero.
+func checkAuthDate(values url.Values, maxAge time.Duration) (int64, error) {
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if maxAge > 0 && time.Since(time.Unix(authDate, 0)) > maxAge {
+		return 0, errors.New(ErrAuthExpired)
+	}
+
+	return authDate, nil
+}
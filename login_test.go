@@ -0,0 +1,63 @@
+package tgbotapi_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// signLogin signs values as the Login Widget does.
+func signLogin(values url.Values, token string) {
+	var lines []string
+	for key := range values {
+		lines = append(lines, key+"="+values.Get(key))
+	}
+	sort.Strings(lines)
+
+	secret := sha256.Sum256([]byte(token))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestCheckLoginData(t *testing.T) {
+	authDate := time.Now().Add(-time.Minute).Unix()
+
+	values := url.Values{
+		"id":         {"42"},
+		"first_name": {"Ann"},
+		"username":   {"ann"},
+		"auth_date":  {strconv.FormatInt(authDate, 10)},
+	}
+	signLogin(values, "token")
+
+	data, err := tgbotapi.CheckLoginData(values, "token", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.ID != 42 || data.FirstName != "Ann" || data.User().UserName != "ann" || data.AuthDate != authDate {
+		t.Errorf("got %+v", data)
+	}
+
+	if _, err := tgbotapi.CheckLoginData(values, "other token", time.Hour); err == nil || err.Error() != tgbotapi.ErrInvalidHash {
+		t.Errorf("expected invalid hash for another bot, got %v", err)
+	}
+
+	if _, err := tgbotapi.CheckLoginData(values, "token", time.Second); err == nil || err.Error() != tgbotapi.ErrAuthExpired {
+		t.Errorf("expected expired data, got %v", err)
+	}
+
+	values.Set("id", "43")
+	if _, err := tgbotapi.CheckLoginData(values, "token", 0); err == nil || err.Error() != tgbotapi.ErrInvalidHash {
+		t.Errorf("expected invalid hash for changed data, got %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+package tgbotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultInvoiceStoreTTL is how long invoices are kept in InvoiceStore
+// if InvoiceStoreTTL is zero.
+const DefaultInvoiceStoreTTL = 7 * 24 * time.Hour
+
+// InvoiceError happens when an invoice would be rejected by Telegram.
+type InvoiceError struct {
+	Field  string
+	Reason string
+}
+
+func (e *InvoiceError) Error() string {
+	return fmt.Sprintf("invalid invoice %s: %s", e.Field, e.Reason)
+}
+
+// ValidateInvoice checks an invoice against Telegram's requirements,
+// returning an InvoiceError if it would be rejected.
+func ValidateInvoice(config InvoiceConfig) error {
+	if n := utf8.RuneCountInString(config.Title); n < 1 || n > 32 {
+		return &InvoiceError{Field: "title", Reason: "must be 1-32 characters"}
+	}
+	if n := utf8.RuneCountInString(config.Description); n < 1 || n > 255 {
+		return &InvoiceError{Field: "description", Reason: "must be 1-255 characters"}
+	}
+	if n := len(config.Payload); n < 1 || n > 128 {
+		return &InvoiceError{Field: "payload", Reason: "must be 1-128 bytes"}
+	}
+	if !isCurrencyCode(config.Currency) {
+		return &InvoiceError{Field: "currency", Reason: "must be a three-letter ISO 4217 code"}
+	}
+	if len(config.Prices) == 0 {
+		return &InvoiceError{Field: "prices", Reason: "must not be empty"}
+	}
+
+	total := 0
+	for _, price := range config.Prices {
+		if price.Label == "" {
+			return &InvoiceError{Field: "prices", Reason: "every price must have a label"}
+		}
+		total += price.Amount
+	}
+	if total <= 0 {
+		return &InvoiceError{Field: "prices", Reason: "total must be more than zero"}
+	}
+
+	return nil
+}
+
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// currencyExponents are the currencies which don't have two digits after
+// the decimal point, by how many they have.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0,
+	"KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0,
+	"VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits converts a decimal amount of a currency, such as "12.99",
+// into its smallest units, such as 1299 for USD or 13 for JPY, as prices
+// must be given in. It fails if amount has more decimal places than the
+// currency allows.
+func MinorUnits(currency string, amount string) (int, error) {
+	exponent, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		exponent = 2
+	}
+
+	whole, fraction := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		whole, fraction = amount[:i], amount[i+1:]
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return 0, &InvoiceError{Field: "amount", Reason: fmt.Sprintf("%s has at most %d decimal places", currency, exponent)}
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	units, err := strconv.Atoi(whole + fraction)
+	if err != nil {
+		return 0, &InvoiceError{Field: "amount", Reason: fmt.Sprintf("%q is not a number", amount)}
+	}
+
+	return units, nil
+}
+
+// formatMinorUnits formats an amount in the smallest units of a currency
+// as a decimal, the reverse of MinorUnits.
+func formatMinorUnits(currency string, units int) string {
+	exponent, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		exponent = 2
+	}
+
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+
+	digits := fmt.Sprintf("%0*d", exponent+1, units)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// InvoiceBuilder assembles an InvoiceConfig, validating it when built.
+//
+//	config, err := tgbotapi.NewInvoiceBuilder(chatID, "Coffee", "A cup of coffee", "order-42", token, "EUR").
+//		Price("Coffee", 350).
+//		Price("Tip", 50).
+//		Build()
+type InvoiceBuilder struct {
+	config       InvoiceConfig
+	providerData ProviderData
+}
+
+// NewInvoiceBuilder creates an InvoiceBuilder for an invoice sent to a
+// chat.
+func NewInvoiceBuilder(chatID int64, title, description, payload, providerToken, currency string) *InvoiceBuilder {
+	return &InvoiceBuilder{config: NewInvoice(chatID, title, description, payload, providerToken, currency)}
+}
+
+// Price adds a portion of the price, in the smallest units of the
+// currency.
+func (b *InvoiceBuilder) Price(label string, amount int) *InvoiceBuilder {
+	b.config.Prices = append(b.config.Prices, LabeledPrice{Label: label, Amount: amount})
+	return b
+}
+
+// Photo shows a photo of the goods with the invoice.
+func (b *InvoiceBuilder) Photo(url string, width, height int) *InvoiceBuilder {
+	b.config.PhotoURL = url
+	b.config.PhotoWidth = width
+	b.config.PhotoHeight = height
+	return b
+}
+
+// StartParameter sets the deep-linking parameter for the invoice.
+func (b *InvoiceBuilder) StartParameter(parameter string) *InvoiceBuilder {
+	b.config.StartParameter = parameter
+	return b
+}
+
+// NeedContact asks the user for their name, phone number and email.
+func (b *InvoiceBuilder) NeedContact(name, phoneNumber, email bool) *InvoiceBuilder {
+	b.config.NeedName = name
+	b.config.NeedPhoneNumber = phoneNumber
+	b.config.NeedEmail = email
+	return b
+}
+
+// Shipping asks the user for a shipping address. If flexible, the price
+// depends on the shipping method, and a ShippingQuery must be answered.
+func (b *InvoiceBuilder) Shipping(flexible bool) *InvoiceBuilder {
+	b.config.NeedShippingAddress = true
+	b.config.IsFlexible = flexible
+	return b
+}
+
+// Build returns the InvoiceConfig, or an InvoiceError if it is not
+// valid.
+func (b *InvoiceBuilder) Build() (InvoiceConfig, error) {
+	config := b.config
+	config.Prices = append([]LabeledPrice(nil), b.config.Prices...)
+
+	if err := ValidateInvoice(config); err != nil {
+		return InvoiceConfig{}, err
+	}
+
+	if b.providerData != nil {
+		data, err := b.providerData.ProviderData(config)
+		if err != nil {
+			return InvoiceConfig{}, err
+		}
+		config.ProviderData = data
+	}
+
+	return config, nil
+}
+
+// AnswerShippingWithOptions answers a shipping query with the ways the
+// goods may be shipped to the user's address.
+func (bot *BotAPI) AnswerShippingWithOptions(query *ShippingQuery, options ...ShippingOption) error {
+	_, err := bot.AnswerShippingQuery(ShippingConfig{
+		ShippingQueryID: query.ID,
+		OK:              true,
+		ShippingOptions: options,
+	})
+
+	return err
+}
+
+// AnswerShippingError answers a shipping query when the goods can't be
+// shipped to the user's address, explaining why.
+func (bot *BotAPI) AnswerShippingError(query *ShippingQuery, message string) error {
+	_, err := bot.AnswerShippingQuery(ShippingConfig{
+		ShippingQueryID: query.ID,
+		ErrorMessage:    message,
+	})
+
+	return err
+}
+
+// AnswerPreCheckoutOK confirms that the order in a pre-checkout query
+// can be fulfilled, so the user is charged.
+func (bot *BotAPI) AnswerPreCheckoutOK(query *PreCheckoutQuery) error {
+	_, err := bot.AnswerPreCheckoutQuery(PreCheckoutConfig{
+		PreCheckoutQueryID: query.ID,
+		OK:                 true,
+	})
+
+	return err
+}
+
+// AnswerPreCheckoutError refuses the order in a pre-checkout query,
+// explaining why to the user.
+func (bot *BotAPI) AnswerPreCheckoutError(query *PreCheckoutQuery, message string) error {
+	_, err := bot.AnswerPreCheckoutQuery(PreCheckoutConfig{
+		PreCheckoutQueryID: query.ID,
+		ErrorMessage:       message,
+	})
+
+	return err
+}
+
+// SentInvoice is what is kept in InvoiceStore about an invoice sent by
+// the bot. The provider token is not kept.
+type SentInvoice struct {
+	ChatID      int64          `json:"chat_id"`
+	MessageID   int            `json:"message_id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Payload     string         `json:"payload"`
+	Currency    string         `json:"currency"`
+	Prices      []LabeledPrice `json:"prices"`
+}
+
+// TotalAmount returns the sum of the invoice's prices.
+func (invoice SentInvoice) TotalAmount() int {
+	total := 0
+	for _, price := range invoice.Prices {
+		total += price.Amount
+	}
+
+	return total
+}
+
+// sendInvoice sends an invoice, keeping it in InvoiceStore if it is set.
+func (bot *BotAPI) sendInvoice(ctx context.Context, config InvoiceConfig) (Message, error) {
+	message, err := bot.sendChattable(ctx, config)
+	if err != nil || bot.InvoiceStore == nil {
+		return message, err
+	}
+
+	invoice := SentInvoice{
+		ChatID:      config.ChatID,
+		MessageID:   message.MessageID,
+		Title:       config.Title,
+		Description: config.Description,
+		Payload:     config.Payload,
+		Currency:    config.Currency,
+		Prices:      config.Prices,
+	}
+	if message.Chat != nil {
+		invoice.ChatID = message.Chat.ID
+	}
+
+	ttl := bot.InvoiceStoreTTL
+	if ttl == 0 {
+		ttl = DefaultInvoiceStoreTTL
+	}
+
+	data, err := json.Marshal(invoice)
+	if err != nil {
+		return message, err
+	}
+
+	return message, bot.InvoiceStore.Set(invoiceKey(invoice.ChatID, invoice.Payload), data, ttl)
+}
+
+// InvoiceForPayment finds the invoice a successful payment in a chat
+// was for in InvoiceStore, by the chat and the payload. It returns false
+// if there is no InvoiceStore or the invoice is not in it.
+func (bot *BotAPI) InvoiceForPayment(chatID int64, payment *SuccessfulPayment) (SentInvoice, bool, error) {
+	if bot.InvoiceStore == nil {
+		return SentInvoice{}, false, nil
+	}
+
+	data, ok, err := bot.InvoiceStore.Get(invoiceKey(chatID, payment.InvoicePayload))
+	if err != nil || !ok {
+		return SentInvoice{}, false, err
+	}
+
+	var invoice SentInvoice
+	if err := json.Unmarshal(data, &invoice); err != nil {
+		return SentInvoice{}, false, err
+	}
+
+	return invoice, true, nil
+}
+
+// invoiceKey is the InvoiceStore key of an invoice sent to a chat.
+func invoiceKey(chatID int64, payload string) string {
+	return "invoice:" + strconv.FormatInt(chatID, 10) + ":" + payload
+}
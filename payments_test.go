@@ -0,0 +1,141 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestInvoiceBuilder(t *testing.T) {
+	config, err := tgbotapi.NewInvoiceBuilder(ChatID, "Coffee", "A cup of coffee", "order-1", "token", "EUR").
+		Price("Coffee", 350).
+		Price("Discount", -50).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Prices) != 2 || config.Currency != "EUR" {
+		t.Errorf("got %+v", config)
+	}
+
+	tests := []struct {
+		builder *tgbotapi.InvoiceBuilder
+		field   string
+	}{
+		{tgbotapi.NewInvoiceBuilder(ChatID, "", "d", "p", "t", "EUR").Price("a", 1), "title"},
+		{tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "euro").Price("a", 1), "currency"},
+		{tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "EUR"), "prices"},
+		{tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "EUR").Price("a", 0), "prices"},
+	}
+	for _, test := range tests {
+		_, err := test.builder.Build()
+		if invoiceErr, ok := err.(*tgbotapi.InvoiceError); !ok || invoiceErr.Field != test.field {
+			t.Errorf("expected an invalid %s, got %v", test.field, err)
+		}
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency, amount string
+		units            int
+		ok               bool
+	}{
+		{"USD", "12.99", 1299, true},
+		{"USD", "12.5", 1250, true},
+		{"USD", "12", 1200, true},
+		{"JPY", "500", 500, true},
+		{"JPY", "500.50", 0, false},
+		{"KWD", "1.234", 1234, true},
+		{"USD", "1.234", 0, false},
+		{"USD", "abc", 0, false},
+	}
+
+	for _, test := range tests {
+		units, err := tgbotapi.MinorUnits(test.currency, test.amount)
+		if (err == nil) != test.ok || units != test.units {
+			t.Errorf("%s %s: expected %d, %v, got %d, %v", test.amount, test.currency, test.units, test.ok, units, err)
+		}
+	}
+}
+
+// ttlStore records the ttl of every value set in a store.
+type ttlStore struct {
+	tgbotapi.KeyValueStore
+	ttls []time.Duration
+}
+
+func (s *ttlStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.ttls = append(s.ttls, ttl)
+	return s.KeyValueStore.Set(key, value, ttl)
+}
+
+func TestInvoiceForPayment(t *testing.T) {
+	var answered string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "sendInvoice":
+			chatID, _ := strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+			return tgbotapi.Message{MessageID: 9, Chat: &tgbotapi.Chat{ID: chatID}}
+		case "answerPreCheckoutQuery":
+			answered = r.FormValue("pre_checkout_query_id") + " " + r.FormValue("ok")
+			return true
+		}
+		return nil
+	})
+	store := &ttlStore{KeyValueStore: tgbotapi.NewMemoryStore()}
+	bot.InvoiceStore = store
+
+	invoice := tgbotapi.NewInvoice(ChatID, "Coffee", "A cup of coffee", "order-2", "token", "EUR")
+	invoice.Prices = []tgbotapi.LabeledPrice{{Label: "Coffee", Amount: 350}}
+	if _, err := bot.Send(invoice); err != nil {
+		t.Fatal(err)
+	}
+
+	other := tgbotapi.NewInvoice(ChatID+1, "Tea", "A cup of tea", "order-2", "token", "EUR")
+	other.Prices = []tgbotapi.LabeledPrice{{Label: "Tea", Amount: 250}}
+	if _, err := bot.Send(other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bot.AnswerPreCheckoutOK(&tgbotapi.PreCheckoutQuery{ID: "q"}); err != nil || answered != "q true" {
+		t.Errorf("unexpected answer %q, %v", answered, err)
+	}
+
+	sent, ok, err := bot.InvoiceForPayment(ChatID, &tgbotapi.SuccessfulPayment{InvoicePayload: "order-2", TotalAmount: 350})
+	if err != nil || !ok {
+		t.Fatalf("invoice not found: %v", err)
+	}
+	if sent.MessageID != 9 || sent.Title != "Coffee" || sent.TotalAmount() != 350 {
+		t.Errorf("got %+v", sent)
+	}
+
+	sent, ok, err = bot.InvoiceForPayment(ChatID+1, &tgbotapi.SuccessfulPayment{InvoicePayload: "order-2", TotalAmount: 250})
+	if err != nil || !ok {
+		t.Fatalf("invoice in other chat not found: %v", err)
+	}
+	if sent.Title != "Tea" || sent.TotalAmount() != 250 {
+		t.Errorf("invoice with the same payload was overwritten, got %+v", sent)
+	}
+
+	if _, ok, _ := bot.InvoiceForPayment(ChatID, &tgbotapi.SuccessfulPayment{InvoicePayload: "other"}); ok {
+		t.Error("found an invoice which was not sent")
+	}
+
+	for _, ttl := range store.ttls {
+		if ttl != tgbotapi.DefaultInvoiceStoreTTL {
+			t.Errorf("expected invoices kept for %s, got %s", tgbotapi.DefaultInvoiceStoreTTL, ttl)
+		}
+	}
+
+	bot.InvoiceStoreTTL = time.Hour
+	if _, err := bot.Send(invoice); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := store.ttls[len(store.ttls)-1]; ttl != time.Hour {
+		t.Errorf("expected InvoiceStoreTTL to be used, got %s", ttl)
+	}
+}
@@ -0,0 +1,174 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ChatPermission is a right a chat member may have, named like the
+// field of ChatMember holding it.
+type ChatPermission string
+
+// Constant values for ChatPermission
+const (
+	PermissionManageChat       ChatPermission = "can_manage_chat"
+	PermissionChangeInfo       ChatPermission = "can_change_info"
+	PermissionPostMessages     ChatPermission = "can_post_messages"
+	PermissionEditMessages     ChatPermission = "can_edit_messages"
+	PermissionDeleteMessages   ChatPermission = "can_delete_messages"
+	PermissionInviteUsers      ChatPermission = "can_invite_users"
+	PermissionRestrictMembers  ChatPermission = "can_restrict_members"
+	PermissionPinMessages      ChatPermission = "can_pin_messages"
+	PermissionPromoteMembers   ChatPermission = "can_promote_members"
+	PermissionManageVideoChats ChatPermission = "can_manage_video_chats"
+
+	PermissionSendMessages       ChatPermission = "can_send_messages"
+	PermissionSendMediaMessages  ChatPermission = "can_send_media_messages"
+	PermissionSendPolls          ChatPermission = "can_send_polls"
+	PermissionSendOtherMessages  ChatPermission = "can_send_other_messages"
+	PermissionAddWebPagePreviews ChatPermission = "can_add_web_page_previews"
+)
+
+// DefaultChatMemberCacheTTL is how long members are kept in
+// ChatMemberCache if ChatMemberCacheTTL is zero.
+const DefaultChatMemberCacheTTL = 5 * time.Minute
+
+// IsAdmin returns if the ChatMember is the creator or an administrator
+// of the chat.
+func (chat ChatMember) IsAdmin() bool {
+	return chat.IsCreator() || chat.IsAdministrator()
+}
+
+// HasPermission returns if the ChatMember has a permission.
+//
+// The creator has every permission, and administrators have the rights
+// they were given along with the permissions to send messages. Members
+// which are not restricted are assumed to be allowed to send messages,
+// though the chat's default permissions may forbid it.
+func (chat ChatMember) HasPermission(perm ChatPermission) bool {
+	switch chat.Status {
+	case MemberStatusCreator:
+		return true
+	case MemberStatusAdministrator:
+		if isSendPermission(perm) {
+			return true
+		}
+		return chat.permission(perm)
+	case MemberStatusMember:
+		return isSendPermission(perm)
+	case MemberStatusRestricted:
+		return chat.permission(perm)
+	}
+
+	return false
+}
+
+func isSendPermission(perm ChatPermission) bool {
+	switch perm {
+	case PermissionSendMessages, PermissionSendMediaMessages, PermissionSendPolls,
+		PermissionSendOtherMessages, PermissionAddWebPagePreviews:
+		return true
+	}
+
+	return false
+}
+
+func (chat ChatMember) permission(perm ChatPermission) bool {
+	switch perm {
+	case PermissionManageChat:
+		return chat.CanManageChat
+	case PermissionChangeInfo:
+		return chat.CanChangeInfo
+	case PermissionPostMessages:
+		return chat.CanPostMessages
+	case PermissionEditMessages:
+		return chat.CanEditMessages
+	case PermissionDeleteMessages:
+		return chat.CanDeleteMessages
+	case PermissionInviteUsers:
+		return chat.CanInviteUsers
+	case PermissionRestrictMembers:
+		return chat.CanRestrictMembers
+	case PermissionPinMessages:
+		return chat.CanPinMessages
+	case PermissionPromoteMembers:
+		return chat.CanPromoteMembers
+	case PermissionManageVideoChats:
+		return chat.CanManageVideoChats
+	case PermissionSendMessages:
+		return chat.CanSendMessages
+	case PermissionSendMediaMessages:
+		return chat.CanSendMediaMessages
+	case PermissionSendPolls:
+		return chat.CanSendPolls
+	case PermissionSendOtherMessages:
+		return chat.CanSendOtherMessages
+	case PermissionAddWebPagePreviews:
+		return chat.CanAddWebPagePreviews
+	}
+
+	return false
+}
+
+// IsChatAdmin returns if a user is the creator or an administrator of a
+// chat.
+func (bot *BotAPI) IsChatAdmin(chatID, userID int64) (bool, error) {
+	member, err := bot.cachedChatMember(chatID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return member.IsAdmin(), nil
+}
+
+// MemberHasPermission returns if a user has a permission in a chat, as
+// reported by ChatMember.HasPermission.
+func (bot *BotAPI) MemberHasPermission(chatID, userID int64, perm ChatPermission) (bool, error) {
+	member, err := bot.cachedChatMember(chatID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return member.HasPermission(perm), nil
+}
+
+// cachedChatMember gets a chat member, from ChatMemberCache if it is set
+// and has the member.
+func (bot *BotAPI) cachedChatMember(chatID, userID int64) (ChatMember, error) {
+	key := "member:" + strconv.FormatInt(chatID, 10) + ":" + strconv.FormatInt(userID, 10)
+
+	if bot.ChatMemberCache != nil {
+		data, ok, err := bot.ChatMemberCache.Get(key)
+		if err != nil {
+			return ChatMember{}, err
+		}
+
+		var member ChatMember
+		if ok && json.Unmarshal(data, &member) == nil {
+			return member, nil
+		}
+	}
+
+	member, err := bot.GetChatMember(ChatConfigWithUser{ChatID: chatID, UserID: userID})
+	if err != nil {
+		return ChatMember{}, err
+	}
+
+	if bot.ChatMemberCache != nil {
+		ttl := bot.ChatMemberCacheTTL
+		if ttl == 0 {
+			ttl = DefaultChatMemberCacheTTL
+		}
+
+		data, err := json.Marshal(member)
+		if err != nil {
+			return member, err
+		}
+		if err := bot.ChatMemberCache.Set(key, data, ttl); err != nil {
+			return member, err
+		}
+	}
+
+	return member, nil
+}
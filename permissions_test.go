@@ -0,0 +1,60 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestChatMemberHasPermission(t *testing.T) {
+	tests := []struct {
+		member   tgbotapi.ChatMember
+		perm     tgbotapi.ChatPermission
+		expected bool
+	}{
+		{tgbotapi.ChatMember{Status: "creator"}, tgbotapi.PermissionPromoteMembers, true},
+		{tgbotapi.ChatMember{Status: "administrator", CanDeleteMessages: true}, tgbotapi.PermissionDeleteMessages, true},
+		{tgbotapi.ChatMember{Status: "administrator"}, tgbotapi.PermissionRestrictMembers, false},
+		{tgbotapi.ChatMember{Status: "administrator"}, tgbotapi.PermissionSendMessages, true},
+		{tgbotapi.ChatMember{Status: "member"}, tgbotapi.PermissionSendPolls, true},
+		{tgbotapi.ChatMember{Status: "member"}, tgbotapi.PermissionPinMessages, false},
+		{tgbotapi.ChatMember{Status: "restricted", CanPinMessages: true}, tgbotapi.PermissionPinMessages, true},
+		{tgbotapi.ChatMember{Status: "restricted"}, tgbotapi.PermissionSendMessages, false},
+		{tgbotapi.ChatMember{Status: "kicked"}, tgbotapi.PermissionSendMessages, false},
+	}
+
+	for _, test := range tests {
+		if actual := test.member.HasPermission(test.perm); actual != test.expected {
+			t.Errorf("%s with %s: expected %v, got %v", test.member.Status, test.perm, test.expected, actual)
+		}
+	}
+}
+
+func TestIsChatAdminCached(t *testing.T) {
+	calls := 0
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "getChatMember" {
+			calls++
+			return tgbotapi.ChatMember{Status: "administrator", CanRestrictMembers: true}
+		}
+		return nil
+	})
+	bot.ChatMemberCache = tgbotapi.NewMemoryStore()
+
+	for i := 0; i < 2; i++ {
+		admin, err := bot.IsChatAdmin(ChatID, 5)
+		if err != nil || !admin {
+			t.Errorf("expected an admin, got %v, %v", admin, err)
+		}
+	}
+
+	allowed, err := bot.MemberHasPermission(ChatID, 5, tgbotapi.PermissionRestrictMembers)
+	if err != nil || !allowed {
+		t.Errorf("expected permission, got %v, %v", allowed, err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 call to getChatMember, got %d", calls)
+	}
+}
@@ -0,0 +1,166 @@
+package tgbotapi
+
+import (
+	"context"
+	"sync"
+)
+
+// PollTracker keeps the current results of polls sent by the bot, using
+// the Poll and PollAnswer updates Telegram sends about them.
+//
+// Closed polls are no longer tracked once OnClose has been called.
+type PollTracker struct {
+	// OnClose, if set, is called with the final results of a tracked
+	// poll when it closes.
+	OnClose func(poll Poll)
+
+	mu    sync.Mutex
+	polls map[string]*trackedPoll
+}
+
+type trackedPoll struct {
+	poll    Poll
+	answers map[int64][]int // option IDs chosen by each user
+}
+
+// NewPollTracker creates a new PollTracker.
+func NewPollTracker() *PollTracker {
+	return &PollTracker{polls: make(map[string]*trackedPoll)}
+}
+
+// Track starts tracking the poll in a message returned from sending a
+// PollConfig. Messages without a poll are ignored.
+func (t *PollTracker) Track(message Message) {
+	if message.Poll == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.polls[message.Poll.ID] = &trackedPoll{
+		poll:    clonePoll(*message.Poll),
+		answers: make(map[int64][]int),
+	}
+}
+
+// Forget stops tracking a poll.
+func (t *PollTracker) Forget(pollID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.polls, pollID)
+}
+
+// Poll returns the current results of a tracked poll.
+func (t *PollTracker) Poll(pollID string) (Poll, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.polls[pollID]
+	if !ok {
+		return Poll{}, false
+	}
+
+	return clonePoll(tracked.poll), true
+}
+
+// Update updates the results of a tracked poll from a Poll or PollAnswer
+// update. It returns true if the update was about a tracked poll.
+func (t *PollTracker) Update(update Update) bool {
+	switch {
+	case update.Poll != nil:
+		return t.updatePoll(*update.Poll)
+	case update.PollAnswer != nil:
+		return t.updateAnswer(*update.PollAnswer)
+	}
+
+	return false
+}
+
+// Middleware returns Middleware which updates the tracker from every
+// update before passing it on.
+func (t *PollTracker) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			t.Update(update)
+			next(ctx, bot, update)
+		}
+	}
+}
+
+func (t *PollTracker) updatePoll(poll Poll) bool {
+	t.mu.Lock()
+
+	tracked, ok := t.polls[poll.ID]
+	if !ok {
+		t.mu.Unlock()
+		return false
+	}
+
+	// Poll updates have the full results, so they replace ours.
+	tracked.poll = clonePoll(poll)
+
+	if !poll.IsClosed {
+		t.mu.Unlock()
+		return true
+	}
+
+	delete(t.polls, poll.ID)
+	t.mu.Unlock()
+
+	if t.OnClose != nil {
+		t.OnClose(clonePoll(poll))
+	}
+
+	return true
+}
+
+func (t *PollTracker) updateAnswer(answer PollAnswer) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.polls[answer.PollID]
+	if !ok || answer.User == nil {
+		return ok
+	}
+
+	poll := &tracked.poll
+	previous := tracked.answers[answer.User.ID]
+
+	// A changed or retracted vote replaces the user's previous one, so
+	// the options they chose before are taken off first.
+	for _, id := range previous {
+		if id >= 0 && id < len(poll.Options) && poll.Options[id].VoterCount > 0 {
+			poll.Options[id].VoterCount--
+		}
+	}
+	for _, id := range answer.OptionIDs {
+		if id >= 0 && id < len(poll.Options) {
+			poll.Options[id].VoterCount++
+		}
+	}
+
+	switch {
+	case len(previous) == 0 && len(answer.OptionIDs) > 0:
+		poll.TotalVoterCount++
+	case len(previous) > 0 && len(answer.OptionIDs) == 0 && poll.TotalVoterCount > 0:
+		poll.TotalVoterCount--
+	}
+
+	// The options are copied, as the update's slice may be reused.
+	if len(answer.OptionIDs) == 0 {
+		delete(tracked.answers, answer.User.ID)
+	} else {
+		tracked.answers[answer.User.ID] = append([]int(nil), answer.OptionIDs...)
+	}
+
+	return true
+}
+
+// clonePoll copies a poll so its options aren't shared.
+func clonePoll(poll Poll) Poll {
+	poll.Options = append([]PollOption(nil), poll.Options...)
+
+	return poll
+}
@@ -0,0 +1,103 @@
+package tgbotapi_test
+
+import (
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestPollTracker(t *testing.T) {
+	tracker := tgbotapi.NewPollTracker()
+
+	var closed *tgbotapi.Poll
+	tracker.OnClose = func(poll tgbotapi.Poll) {
+		closed = &poll
+	}
+
+	tracker.Track(tgbotapi.Message{Poll: &tgbotapi.Poll{
+		ID:      "poll",
+		Options: []tgbotapi.PollOption{{Text: "yes"}, {Text: "no"}},
+	}})
+
+	answer := func(userID int64, options ...int) {
+		tracker.Update(tgbotapi.Update{PollAnswer: &tgbotapi.PollAnswer{
+			PollID:    "poll",
+			User:      &tgbotapi.User{ID: userID},
+			OptionIDs: options,
+		}})
+	}
+	answer(1, 0)
+	answer(2, 0)
+	answer(2, 1) // changed their mind
+	answer(3, 1)
+	answer(3) // retracted
+
+	poll, ok := tracker.Poll("poll")
+	if !ok {
+		t.Fatal("poll is not tracked")
+	}
+	if poll.TotalVoterCount != 2 || poll.Options[0].VoterCount != 1 || poll.Options[1].VoterCount != 1 {
+		t.Errorf("got results %+v", poll)
+	}
+
+	if tracker.Update(tgbotapi.Update{PollAnswer: &tgbotapi.PollAnswer{PollID: "other"}}) {
+		t.Error("untracked poll was handled")
+	}
+
+	poll.IsClosed = true
+	tracker.Update(tgbotapi.Update{Poll: &poll})
+
+	if closed == nil || closed.TotalVoterCount != 2 {
+		t.Fatalf("OnClose got %v", closed)
+	}
+	if _, ok := tracker.Poll("poll"); ok {
+		t.Error("closed poll is still tracked")
+	}
+}
+
+func TestPollTrackerChangedVotes(t *testing.T) {
+	tracker := tgbotapi.NewPollTracker()
+	tracker.Track(tgbotapi.Message{Poll: &tgbotapi.Poll{
+		ID:      "poll",
+		Options: []tgbotapi.PollOption{{Text: "a"}, {Text: "b"}, {Text: "c"}},
+	}})
+
+	// The same slice is reused for every answer, as a caller decoding
+	// updates into one buffer might.
+	options := make([]int, 0, 2)
+	answer := func(userID int64, ids ...int) {
+		options = append(options[:0], ids...)
+		tracker.Update(tgbotapi.Update{PollAnswer: &tgbotapi.PollAnswer{
+			PollID:    "poll",
+			User:      &tgbotapi.User{ID: userID},
+			OptionIDs: options,
+		}})
+	}
+
+	for _, step := range []struct {
+		userID int64
+		ids    []int
+		counts [3]int
+		total  int
+	}{
+		{1, []int{0, 1}, [3]int{1, 1, 0}, 1},
+		{2, []int{2}, [3]int{1, 1, 1}, 2},
+		{1, []int{2}, [3]int{0, 0, 2}, 2}, // changed
+		{2, nil, [3]int{0, 0, 1}, 1},      // retracted
+		{2, nil, [3]int{0, 0, 1}, 1},      // retracted again
+		{1, nil, [3]int{0, 0, 0}, 0},
+	} {
+		answer(step.userID, step.ids...)
+
+		poll, _ := tracker.Poll("poll")
+		for i, count := range step.counts {
+			if poll.Options[i].VoterCount != count {
+				t.Errorf("user %d voting %v: expected counts %v, got %+v", step.userID, step.ids, step.counts, poll.Options)
+				break
+			}
+		}
+		if poll.TotalVoterCount != step.total {
+			t.Errorf("user %d voting %v: expected %d voters, got %d", step.userID, step.ids, step.total, poll.TotalVoterCount)
+		}
+	}
+}
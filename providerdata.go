@@ -0,0 +1,170 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ProviderData builds the provider_data of an invoice for a particular
+// payment provider, checking it against the invoice.
+type ProviderData interface {
+	ProviderData(invoice InvoiceConfig) (string, error)
+}
+
+// Values of VATCode for YooKassa receipt items.
+const (
+	YooKassaVATNone = 1 // no VAT
+	YooKassaVAT0    = 2 // 0%
+	YooKassaVAT10   = 3 // 10%
+	YooKassaVAT20   = 4 // 20%
+	YooKassaVAT110  = 5 // 10/110
+	YooKassaVAT120  = 6 // 20/120
+)
+
+// YooKassaReceipt is the receipt YooKassa needs to register a payment
+// with the tax authorities. Its items must add up to the invoice's total.
+//
+//	receipt := &tgbotapi.YooKassaReceipt{}
+//	receipt.AddItem("Coffee", 1, 35000, tgbotapi.YooKassaVAT20)
+//	config.SendEmailToProvider, config.NeedEmail = true, true
+//	config.ProviderData, err = receipt.ProviderData(config)
+type YooKassaReceipt struct {
+	Items []YooKassaReceiptItem
+	// Customer is who the receipt is sent to. If it is nil, the invoice
+	// must send the user's email or phone number to the provider.
+	Customer *YooKassaCustomer
+	// TaxSystemCode is the seller's tax system, if they have more than
+	// one.
+	TaxSystemCode int
+}
+
+// YooKassaReceiptItem is a line of a YooKassaReceipt.
+type YooKassaReceiptItem struct {
+	Description    string  // required, 1-128 characters
+	Quantity       float64 // required
+	Price          int     // price of one unit, in the smallest units of the currency
+	VATCode        int     // required, one of the YooKassaVAT constants
+	PaymentMode    string  // optional, such as "full_payment"
+	PaymentSubject string  // optional, such as "commodity"
+}
+
+// YooKassaCustomer is who a YooKassaReceipt is sent to.
+type YooKassaCustomer struct {
+	FullName string `json:"full_name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// yooKassaReceipt is how YooKassa expects a receipt to be encoded.
+type yooKassaReceipt struct {
+	Items         []yooKassaItem    `json:"items"`
+	Customer      *YooKassaCustomer `json:"customer,omitempty"`
+	TaxSystemCode int               `json:"tax_system_code,omitempty"`
+}
+
+type yooKassaItem struct {
+	Description string `json:"description"`
+	Quantity    string `json:"quantity"`
+	Amount      struct {
+		Value    string `json:"value"`
+		Currency string `json:"currency"`
+	} `json:"amount"`
+	VATCode        int    `json:"vat_code"`
+	PaymentMode    string `json:"payment_mode,omitempty"`
+	PaymentSubject string `json:"payment_subject,omitempty"`
+}
+
+// AddItem adds a line to the receipt, with the price of one unit in the
+// smallest units of the invoice's currency.
+func (r *YooKassaReceipt) AddItem(description string, quantity float64, price int, vatCode int) *YooKassaReceipt {
+	r.Items = append(r.Items, YooKassaReceiptItem{
+		Description: description,
+		Quantity:    quantity,
+		Price:       price,
+		VATCode:     vatCode,
+	})
+
+	return r
+}
+
+// ProviderData checks the receipt against an invoice, returning an
+// InvoiceError if YooKassa would reject it, and encodes it.
+func (r *YooKassaReceipt) ProviderData(invoice InvoiceConfig) (string, error) {
+	if len(r.Items) == 0 {
+		return "", &InvoiceError{Field: "receipt", Reason: "must have at least one item"}
+	}
+
+	if r.Customer == nil || (r.Customer.Email == "" && r.Customer.Phone == "") {
+		sendsEmail := invoice.NeedEmail && invoice.SendEmailToProvider
+		sendsPhone := invoice.NeedPhoneNumber && invoice.SendPhoneNumberToProvider
+		if !sendsEmail && !sendsPhone {
+			return "", &InvoiceError{Field: "receipt", Reason: "needs a customer email or phone, or the invoice must send one to the provider"}
+		}
+	}
+
+	receipt := yooKassaReceipt{
+		Items:         make([]yooKassaItem, len(r.Items)),
+		Customer:      r.Customer,
+		TaxSystemCode: r.TaxSystemCode,
+	}
+
+	total := 0
+	for i, item := range r.Items {
+		if n := utf8.RuneCountInString(item.Description); n < 1 || n > 128 {
+			return "", &InvoiceError{Field: "receipt", Reason: "item descriptions must be 1-128 characters"}
+		}
+		if item.VATCode < YooKassaVATNone || item.VATCode > YooKassaVAT120 {
+			return "", &InvoiceError{Field: "receipt", Reason: "item VAT codes must be 1-6"}
+		}
+		if item.Quantity <= 0 || item.Price <= 0 {
+			return "", &InvoiceError{Field: "receipt", Reason: "item quantities and prices must be more than zero"}
+		}
+
+		total += int(math.Round(float64(item.Price) * item.Quantity))
+
+		encoded := &receipt.Items[i]
+		encoded.Description = item.Description
+		encoded.Quantity = strconv.FormatFloat(item.Quantity, 'f', -1, 64)
+		encoded.Amount.Value = formatMinorUnits(invoice.Currency, item.Price)
+		encoded.Amount.Currency = invoice.Currency
+		encoded.VATCode = item.VATCode
+		encoded.PaymentMode = item.PaymentMode
+		encoded.PaymentSubject = item.PaymentSubject
+	}
+
+	invoiceTotal := 0
+	for _, price := range invoice.Prices {
+		invoiceTotal += price.Amount
+	}
+	if total != invoiceTotal {
+		return "", &InvoiceError{Field: "receipt", Reason: "items must add up to the invoice's total"}
+	}
+
+	data, err := json.Marshal(struct {
+		Receipt yooKassaReceipt `json:"receipt"`
+	}{receipt})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// ProviderData sets the invoice's provider_data, checking it when the
+// invoice is built.
+func (b *InvoiceBuilder) ProviderData(data ProviderData) *InvoiceBuilder {
+	b.providerData = data
+	return b
+}
+
+// SendToProvider passes the user's email or phone number to the
+// provider, asking the user for them.
+func (b *InvoiceBuilder) SendToProvider(email, phoneNumber bool) *InvoiceBuilder {
+	b.config.NeedEmail = b.config.NeedEmail || email
+	b.config.SendEmailToProvider = email
+	b.config.NeedPhoneNumber = b.config.NeedPhoneNumber || phoneNumber
+	b.config.SendPhoneNumberToProvider = phoneNumber
+	return b
+}
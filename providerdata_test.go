@@ -0,0 +1,63 @@
+package tgbotapi_test
+
+import (
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestYooKassaReceipt(t *testing.T) {
+	receipt := &tgbotapi.YooKassaReceipt{}
+	receipt.AddItem("Coffee", 2, 17500, tgbotapi.YooKassaVAT20)
+
+	config, err := tgbotapi.NewInvoiceBuilder(ChatID, "Coffee", "Two cups of coffee", "order-3", "token", "RUB").
+		Price("Coffee", 35000).
+		SendToProvider(true, false).
+		ProviderData(receipt).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"receipt":{"items":[{"description":"Coffee","quantity":"2","amount":{"value":"175.00","currency":"RUB"},"vat_code":4}]}}`
+	if config.ProviderData != expected {
+		t.Errorf("expected %s, got %s", expected, config.ProviderData)
+	}
+	if !config.NeedEmail || !config.SendEmailToProvider {
+		t.Error("email was not sent to the provider")
+	}
+
+	tests := []struct {
+		name    string
+		receipt *tgbotapi.YooKassaReceipt
+		builder *tgbotapi.InvoiceBuilder
+	}{
+		{
+			"wrong total",
+			(&tgbotapi.YooKassaReceipt{}).AddItem("Coffee", 1, 17500, tgbotapi.YooKassaVAT20),
+			tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "RUB").Price("a", 35000).SendToProvider(true, false),
+		},
+		{
+			"no customer",
+			(&tgbotapi.YooKassaReceipt{}).AddItem("Coffee", 1, 35000, tgbotapi.YooKassaVAT20),
+			tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "RUB").Price("a", 35000),
+		},
+		{
+			"bad VAT code",
+			(&tgbotapi.YooKassaReceipt{Customer: &tgbotapi.YooKassaCustomer{Email: "a@example.com"}}).AddItem("Coffee", 1, 35000, 9),
+			tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "RUB").Price("a", 35000),
+		},
+		{
+			"no items",
+			&tgbotapi.YooKassaReceipt{Customer: &tgbotapi.YooKassaCustomer{Email: "a@example.com"}},
+			tgbotapi.NewInvoiceBuilder(ChatID, "t", "d", "p", "t", "RUB").Price("a", 35000),
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.builder.ProviderData(test.receipt).Build()
+		if invoiceErr, ok := err.(*tgbotapi.InvoiceError); !ok || invoiceErr.Field != "receipt" {
+			t.Errorf("%s: expected a receipt error, got %v", test.name, err)
+		}
+	}
+}
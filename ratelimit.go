@@ -0,0 +1,423 @@
+package tgbotapi
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Telegram's limits on sending messages.
+const (
+	// DefaultGlobalRate is how many messages per second a bot may send
+	// to all chats.
+	DefaultGlobalRate = 30
+	// DefaultChatRate is how many messages per second a bot may send to
+	// a single private chat.
+	DefaultChatRate = 1
+	// DefaultGroupRate is how many messages per minute a bot may send to
+	// a single group.
+	DefaultGroupRate = 20
+)
+
+// RateLimitConfig configures a RateLimiter. Zero values use Telegram's
+// limits.
+type RateLimitConfig struct {
+	// GlobalRate is how many messages per second may be sent to all
+	// chats, which may be sent in a burst of as many.
+	GlobalRate float64
+	// ChatRate is how many messages per second may be sent to each
+	// private chat.
+	ChatRate float64
+	// GroupRate is how many messages per minute may be sent to each
+	// group, supergroup or channel.
+	GroupRate float64
+}
+
+// RateLimiter delays requests which send messages so they stay within
+// Telegram's limits, instead of failing with "Too Many Requests". When
+// Telegram asks to wait anyway, requests to that chat, or all requests
+// if no chat was given, wait until it says.
+//
+// Set it as a BotAPI's RateLimiter for it to be used.
+type RateLimiter struct {
+	config RateLimitConfig
+
+	mu     sync.Mutex
+	global rateBucket
+	chats  map[string]*rateBucket
+	// floodUntil is when requests to each chat may be sent again after
+	// Telegram asked to wait, with "" for all chats.
+	floodUntil map[string]time.Time
+	// waiting is how many requests to each chat are waiting, with "" for
+	// requests not to a chat.
+	waiting map[string]int
+	// waitingPriority is how many requests of each priority are waiting.
+	waitingPriority map[Priority]int
+	lastSweep       time.Time
+}
+
+// Priority is how urgent a request is, for a RateLimiter. Set it with
+// WithPriority.
+type Priority int
+
+// Priorities of requests.
+const (
+	// PriorityLow is for bulk messages, such as broadcasts, which only
+	// use capacity no other request is waiting for.
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of requests which weren't given
+	// one.
+	PriorityNormal Priority = 0
+	// PriorityHigh is for messages a user is waiting for, such as
+	// replies to commands.
+	PriorityHigh Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context which gives requests made with it, such
+// as with SendContext, a priority.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// contextPriority returns the priority given to ctx by WithPriority.
+func contextPriority(ctx context.Context) Priority {
+	priority, _ := ctx.Value(priorityKey{}).(Priority)
+	return priority
+}
+
+// rateBucket allows requests at an interval, with bursts of up to burst.
+type rateBucket struct {
+	interval time.Duration
+	burst    int
+	// next is when the bucket is empty, if no requests are made before.
+	next time.Time
+}
+
+// reserve takes a request from the bucket, returning how long until it
+// may be made.
+func (b *rateBucket) reserve(now time.Time) time.Duration {
+	if b.next.Before(now) {
+		b.next = now
+	}
+
+	wait := b.next.Sub(now) - time.Duration(b.burst-1)*b.interval
+	b.next = b.next.Add(b.interval)
+
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// tokens returns how many requests may be made from the bucket now
+// without waiting.
+func (b *rateBucket) tokens(now time.Time) int {
+	if !b.next.After(now) {
+		return b.burst
+	}
+
+	used := int((b.next.Sub(now) + b.interval - 1) / b.interval)
+	if used >= b.burst {
+		return 0
+	}
+	return b.burst - used
+}
+
+// NewRateLimiter creates a RateLimiter.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.GlobalRate <= 0 {
+		config.GlobalRate = DefaultGlobalRate
+	}
+	if config.ChatRate <= 0 {
+		config.ChatRate = DefaultChatRate
+	}
+	if config.GroupRate <= 0 {
+		config.GroupRate = DefaultGroupRate
+	}
+
+	// Rates under one a second still allow a message at a time.
+	burst := int(config.GlobalRate)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		config: config,
+		global: rateBucket{
+			interval: rateInterval(config.GlobalRate, time.Second),
+			burst:    burst,
+		},
+		chats:           make(map[string]*rateBucket),
+		floodUntil:      make(map[string]time.Time),
+		waiting:         make(map[string]int),
+		waitingPriority: make(map[Priority]int),
+	}
+}
+
+func rateInterval(rate float64, per time.Duration) time.Duration {
+	return time.Duration(float64(per) / rate)
+}
+
+// Wait waits until a request to a method may be made to a chat, given as
+// its chat_id parameter. Methods which don't send messages don't wait,
+// unless Telegram asked to wait for all requests.
+//
+// When messages have to wait for the limit on all chats, those with a
+// higher priority, given to ctx with WithPriority, are sent first.
+// Messages of PriorityLow only use capacity that is free right away, so
+// a large broadcast doesn't hold up replies to users.
+func (l *RateLimiter) Wait(ctx context.Context, method string, chatID string) error {
+	priority := contextPriority(ctx)
+
+	wait, reserved := l.reserve(method, chatID, priority, time.Now())
+	if reserved && wait <= 0 {
+		return nil
+	}
+
+	l.addWaiting(chatID, priority, 1)
+	defer l.addWaiting(chatID, priority, -1)
+
+	for !reserved {
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+
+		wait, reserved = l.reserve(method, chatID, priority, time.Now())
+	}
+
+	return sleepContext(ctx, wait)
+}
+
+// addWaiting counts requests to a chat starting or finishing waiting.
+func (l *RateLimiter) addWaiting(chatID string, priority Priority, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.waiting[chatID] += n
+	if l.waiting[chatID] <= 0 {
+		delete(l.waiting, chatID)
+	}
+
+	l.waitingPriority[priority] += n
+	if l.waitingPriority[priority] <= 0 {
+		delete(l.waitingPriority, priority)
+	}
+}
+
+// reserve takes a request to a chat, returning how long until it may be
+// made. If it has to give way to requests of a higher priority, nothing
+// is taken, and it returns false with how long to wait before trying
+// again.
+func (l *RateLimiter) reserve(method string, chatID string, priority Priority, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	wait := l.floodUntil[""].Sub(now)
+	if !sendsMessage(method) {
+		return wait, true
+	}
+
+	var bucket *rateBucket
+	if chatID != "" {
+		if flood := l.floodUntil[chatID].Sub(now); flood > wait {
+			wait = flood
+		}
+
+		bucket = l.chats[chatID]
+		if bucket == nil {
+			bucket = l.newChatBucket(chatID)
+			l.chats[chatID] = bucket
+		}
+	}
+
+	// Requests only queue up for capacity that isn't free yet if none of
+	// a higher priority are waiting, or it would go to them first. Those
+	// of PriorityLow never do, so they don't hold up any sent later.
+	empty := l.global.tokens(now) == 0
+	yield := empty && l.higherWaiting(priority)
+	if priority < PriorityNormal {
+		yield = yield || empty || wait > 0 || bucket != nil && bucket.tokens(now) == 0
+	}
+	if yield {
+		if wait < l.global.interval {
+			wait = l.global.interval
+		}
+		return wait, false
+	}
+
+	if bucket != nil {
+		if chatWait := bucket.reserve(now); chatWait > wait {
+			wait = chatWait
+		}
+	}
+
+	if globalWait := l.global.reserve(now); globalWait > wait {
+		wait = globalWait
+	}
+
+	return wait, true
+}
+
+// higherWaiting returns if any request of a higher priority is waiting.
+func (l *RateLimiter) higherWaiting(priority Priority) bool {
+	for p, n := range l.waitingPriority {
+		if p > priority && n > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newChatBucket creates the bucket for a chat. Private chats have
+// positive IDs, and groups and channels negative IDs or usernames.
+func (l *RateLimiter) newChatBucket(chatID string) *rateBucket {
+	if strings.HasPrefix(chatID, "-") || strings.HasPrefix(chatID, "@") {
+		return &rateBucket{
+			interval: rateInterval(l.config.GroupRate, time.Minute),
+			burst:    1,
+		}
+	}
+
+	return &rateBucket{
+		interval: rateInterval(l.config.ChatRate, time.Second),
+		burst:    1,
+	}
+}
+
+// sweep forgets chats with nothing waiting, once a minute.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for chatID, bucket := range l.chats {
+		if bucket.next.Before(now) {
+			delete(l.chats, chatID)
+		}
+	}
+	for chatID, until := range l.floodUntil {
+		if until.Before(now) {
+			delete(l.floodUntil, chatID)
+		}
+	}
+}
+
+// FloodWait makes requests to a chat, or all requests if chatID is
+// empty, wait for d, as Telegram asks with retry_after. Requests made
+// by a BotAPI with this RateLimiter call it for it.
+func (l *RateLimiter) FloodWait(chatID string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(l.floodUntil[chatID]) {
+		l.floodUntil[chatID] = until
+	}
+}
+
+// RateLimiterStats is what a RateLimiter is doing at a moment, such as
+// to show on a dashboard why messages are delayed.
+type RateLimiterStats struct {
+	// GlobalTokens is how many messages may be sent to all chats now
+	// without waiting.
+	GlobalTokens int
+	// Waiting is how many requests are waiting in total.
+	Waiting int
+	// WaitingByPriority is how many requests of each priority are
+	// waiting.
+	WaitingByPriority map[Priority]int
+	// FloodWaitUntil is when requests may be made again after Telegram
+	// asked all requests to wait, or the zero Time if it didn't.
+	FloodWaitUntil time.Time
+	// Chats are the chats messages were recently sent to, or requests
+	// are waiting for, by chat_id.
+	Chats map[string]ChatRateStats
+}
+
+// ChatRateStats is what a RateLimiter is doing for a chat.
+type ChatRateStats struct {
+	// Tokens is how many messages may be sent to the chat now without
+	// waiting for its limit.
+	Tokens int
+	// Waiting is how many requests to the chat are waiting.
+	Waiting int
+	// FloodWaitUntil is when requests to the chat may be made again
+	// after Telegram asked to wait, or the zero Time if it didn't.
+	FloodWaitUntil time.Time
+}
+
+// Stats returns what the limiter is doing now.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stats := RateLimiterStats{
+		GlobalTokens:      l.global.tokens(now),
+		WaitingByPriority: make(map[Priority]int, len(l.waitingPriority)),
+		Chats:             make(map[string]ChatRateStats),
+	}
+	for priority, n := range l.waitingPriority {
+		stats.WaitingByPriority[priority] = n
+	}
+	if until := l.floodUntil[""]; until.After(now) {
+		stats.FloodWaitUntil = until
+	}
+
+	for chatID, until := range l.floodUntil {
+		if chatID == "" || !until.After(now) {
+			continue
+		}
+		chat := stats.Chats[chatID]
+		chat.FloodWaitUntil = until
+		stats.Chats[chatID] = chat
+	}
+	for chatID, n := range l.waiting {
+		stats.Waiting += n
+		if chatID == "" {
+			continue
+		}
+		chat := stats.Chats[chatID]
+		chat.Waiting = n
+		stats.Chats[chatID] = chat
+	}
+
+	// Chats with no messages sent recently have all of their tokens.
+	for chatID, chat := range stats.Chats {
+		chat.Tokens = 1
+		stats.Chats[chatID] = chat
+	}
+	for chatID, bucket := range l.chats {
+		chat := stats.Chats[chatID]
+		chat.Tokens = bucket.tokens(now)
+		stats.Chats[chatID] = chat
+	}
+
+	return stats
+}
+
+// rateLimit waits for the bot's RateLimiter, if it has one.
+func (bot *BotAPI) rateLimit(ctx context.Context, method string, chatID string) error {
+	if bot.RateLimiter == nil {
+		return nil
+	}
+
+	return bot.RateLimiter.Wait(ctx, method, chatID)
+}
+
+// rateLimited tells the bot's RateLimiter if err asks to wait.
+func (bot *BotAPI) rateLimited(err error, chatID string) {
+	apiErr, ok := err.(*Error)
+	if bot.RateLimiter == nil || !ok || apiErr.RetryAfter <= 0 {
+		return
+	}
+
+	bot.RateLimiter.FloodWait(chatID, time.Duration(apiErr.RetryAfter)*time.Second)
+}
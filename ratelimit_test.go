@@ -0,0 +1,175 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestRateLimiterChat(t *testing.T) {
+	limiter := tgbotapi.NewRateLimiter(tgbotapi.RateLimitConfig{ChatRate: 20})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "sendMessage", "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected 3 messages to one chat to take 100ms, took %s", elapsed)
+	}
+
+	// Other chats and methods which don't send messages don't wait.
+	start = time.Now()
+	limiter.Wait(ctx, "sendMessage", "2")
+	limiter.Wait(ctx, "getChat", "1")
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("expected no wait, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterFloodWait(t *testing.T) {
+	limiter := tgbotapi.NewRateLimiter(tgbotapi.RateLimitConfig{})
+	limiter.FloodWait("", 50*time.Millisecond)
+
+	start := time.Now()
+	limiter.Wait(context.Background(), "getChat", "1")
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected to wait for the flood wait, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.FloodWait("1", time.Hour)
+	if err := limiter.Wait(ctx, "sendMessage", "1"); err != context.Canceled {
+		t.Errorf("expected the context's error, got %v", err)
+	}
+}
+
+func TestBotRateLimiter(t *testing.T) {
+	flooded := false
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" && !flooded {
+			flooded = true
+			return tgbotapi.APIResponse{
+				ErrorCode:   429,
+				Description: "Too Many Requests: retry after 1",
+				Parameters:  &tgbotapi.ResponseParameters{RetryAfter: 1},
+			}
+		}
+		return nil
+	})
+	bot.RateLimiter = tgbotapi.NewRateLimiter(tgbotapi.RateLimitConfig{})
+
+	if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hi")); err == nil {
+		t.Fatal("expected a flood error")
+	}
+
+	start := time.Now()
+	bot.Send(tgbotapi.NewMessage(ChatID, "hi"))
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("expected to wait for retry_after, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterStats(t *testing.T) {
+	limiter := tgbotapi.NewRateLimiter(tgbotapi.RateLimitConfig{GlobalRate: 10, ChatRate: 1})
+	ctx := context.Background()
+
+	limiter.Wait(ctx, "sendMessage", "1")
+	limiter.FloodWait("-100", time.Minute)
+
+	waiting := make(chan struct{})
+	go func() {
+		limiter.Wait(ctx, "sendMessage", "1")
+		close(waiting)
+	}()
+
+	var stats tgbotapi.RateLimiterStats
+	for i := 0; i < 100; i++ {
+		if stats = limiter.Stats(); stats.Waiting == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats.Waiting != 1 || stats.GlobalTokens >= 10 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if chat := stats.Chats["1"]; chat.Waiting != 1 || chat.Tokens != 0 {
+		t.Errorf("unexpected stats for a chat with a request waiting %+v", chat)
+	}
+	if chat := stats.Chats["-100"]; chat.FloodWaitUntil.IsZero() || chat.Tokens != 1 {
+		t.Errorf("unexpected stats for a flooded chat %+v", chat)
+	}
+	if !stats.FloodWaitUntil.IsZero() {
+		t.Errorf("expected no global flood wait, got %s", stats.FloodWaitUntil)
+	}
+
+	<-waiting
+	if stats := limiter.Stats(); stats.Waiting != 0 {
+		t.Errorf("expected nothing waiting, got %d", stats.Waiting)
+	}
+}
+
+func TestRateLimiterPriority(t *testing.T) {
+	limiter := tgbotapi.NewRateLimiter(tgbotapi.RateLimitConfig{GlobalRate: 10, ChatRate: 100})
+	low := tgbotapi.WithPriority(context.Background(), tgbotapi.PriorityLow)
+
+	// Use up the burst, so low priority messages have to wait.
+	for i := 0; i < 10; i++ {
+		limiter.Wait(low, "sendMessage", strconv.Itoa(i))
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			limiter.Wait(low, "sendMessage", strconv.Itoa(100+i))
+
+			mu.Lock()
+			order = append(order, "low")
+			mu.Unlock()
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	limiter.Wait(context.Background(), "sendMessage", "200")
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the normal message to take the next slot, took %s", elapsed)
+	}
+
+	mu.Lock()
+	order = append(order, "normal")
+	mu.Unlock()
+
+	wg.Wait()
+
+	if order[0] != "normal" {
+		t.Errorf("expected the normal message sent before low ones, got %v", order)
+	}
+}
+
+func TestRateLimiterFractionalRate(t *testing.T) {
+	limiter := tgbotapi.NewRateLimiter(tgbotapi.RateLimitConfig{GlobalRate: 0.5})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	low := tgbotapi.WithPriority(ctx, tgbotapi.PriorityLow)
+	if err := limiter.Wait(low, "sendMessage", "1"); err != nil {
+		t.Errorf("expected the first message sent right away, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package tgbotapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// RecordedUpdate is an Update along with the time it was received.
+type RecordedUpdate struct {
+	Time   time.Time `json:"time"`
+	Update Update    `json:"update"`
+}
+
+// UpdateRecorder writes updates as JSON Lines, one RecordedUpdate per line,
+// so they may later be replayed with ReplayUpdates.
+type UpdateRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewUpdateRecorder creates an UpdateRecorder writing to w.
+func NewUpdateRecorder(w io.Writer) *UpdateRecorder {
+	return &UpdateRecorder{enc: json.NewEncoder(w)}
+}
+
+// Record writes an update, timestamped with the current time.
+func (r *UpdateRecorder) Record(update Update) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.enc.Encode(RecordedUpdate{
+		Time:   time.Now(),
+		Update: update,
+	})
+}
+
+// Tee records every update from updates, and passes it on to the
+// returned channel. Errors while recording are logged.
+func (r *UpdateRecorder) Tee(updates UpdatesChannel) UpdatesChannel {
+	ch := make(chan Update, cap(updates))
+
+	go func() {
+		defer close(ch)
+
+		for update := range updates {
+			if err := r.Record(update); err != nil {
+				log.Println(err)
+			}
+
+			ch <- update
+		}
+	}()
+
+	return ch
+}
+
+// ReplayUpdates reads updates written by an UpdateRecorder and calls
+// handler with each of them in order.
+//
+// The time between updates is kept as when they were recorded, divided
+// by speed. If speed is zero, updates are replayed without waiting.
+func ReplayUpdates(r io.Reader, speed float64, handler func(Update)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	var last time.Time
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var recorded RecordedUpdate
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return err
+		}
+
+		if speed > 0 && !last.IsZero() {
+			time.Sleep(time.Duration(float64(recorded.Time.Sub(last)) / speed))
+		}
+		last = recorded.Time
+
+		handler(recorded.Update)
+	}
+
+	return scanner.Err()
+}
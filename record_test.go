@@ -0,0 +1,32 @@
+package tgbotapi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestRecordAndReplayUpdates(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := tgbotapi.NewUpdateRecorder(&buf)
+
+	updates := make(chan tgbotapi.Update, 2)
+	updates <- tgbotapi.Update{UpdateID: 1, Message: &tgbotapi.Message{Text: "first"}}
+	updates <- tgbotapi.Update{UpdateID: 2, Message: &tgbotapi.Message{Text: "second"}}
+	close(updates)
+
+	for range recorder.Tee(updates) {
+	}
+
+	var replayed []tgbotapi.Update
+	err := tgbotapi.ReplayUpdates(&buf, 0, func(update tgbotapi.Update) {
+		replayed = append(replayed, update)
+	})
+
+	if err != nil || len(replayed) != 2 ||
+		replayed[0].UpdateID != 1 ||
+		replayed[1].Message.Text != "second" {
+		t.Fatal(err, replayed)
+	}
+}
@@ -0,0 +1,181 @@
+package tgbotapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is when a recurring job runs, parsed from a cron expression.
+type Schedule struct {
+	minute, hour, day, month, weekday uint64
+	// anyDay and anyWeekday are whether the day of the month or week
+	// started with *, as a job runs on days matching either if neither
+	// did.
+	anyDay, anyWeekday bool
+}
+
+// scheduleField is the range of values of a field of a cron expression.
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = []scheduleField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// scheduleDescriptors are shorthands for common schedules.
+var scheduleDescriptors = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression of five fields: minute, hour,
+// day of month, month and day of week, where Sunday is 0 or 7. Fields
+// may be *, numbers, ranges such as 1-5, lists such as 1,15, and steps
+// such as */15 or 9-17/2. @hourly, @daily, @weekly, @monthly and
+// @yearly are also accepted.
+func ParseSchedule(spec string) (*Schedule, error) {
+	if expanded, ok := scheduleDescriptors[strings.TrimSpace(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("schedule %q: expected %d fields, got %d", spec, len(scheduleFields), len(fields))
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseScheduleField(field, scheduleFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %v", spec, err)
+		}
+		bits[i] = b
+	}
+
+	// Sunday may be 7 as well as 0.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Schedule{
+		minute:     bits[0],
+		hour:       bits[1],
+		day:        bits[2],
+		month:      bits[3],
+		weekday:    bits[4],
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseScheduleField parses a field of a cron expression into a bit for
+// each value it matches.
+func parseScheduleField(field string, f scheduleField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(part, '/'); i != -1 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step in %s %q", f.name, part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		start, end := f.min, f.max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("bad %s %q", f.name, part)
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("bad %s %q", f.name, part)
+				}
+			} else if step != 1 {
+				// As in cron, 5/10 means from 5 to the end in steps of 10.
+				end = f.max
+			}
+		}
+
+		if start < f.min || end > f.max || start > end {
+			return 0, fmt.Errorf("%s %q out of range %d-%d", f.name, part, f.min, f.max)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first time after t the schedule matches, in t's
+// location, or the zero Time if it never does, such as for February 30.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		if !hasBit(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !hasBit(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !hasBit(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns if the schedule runs on t's day. When both the day
+// of the month and of the week are restricted, either may match.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := hasBit(s.day, t.Day())
+	weekday := hasBit(s.weekday, int(t.Weekday()))
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+
+	return day || weekday
+}
+
+func hasBit(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
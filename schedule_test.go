@@ -0,0 +1,62 @@
+package tgbotapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Thursday.
+	start := time.Date(2020, time.January, 2, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2020, time.January, 2, 10, 31, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2020, time.January, 3, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, time.January, 2, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2020, time.January, 2, 13, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2020, time.January, 3, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		// Either the day of the month or of the week may match.
+		{"0 0 20 * 6", time.Date(2020, time.January, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		schedule, err := tgbotapi.ParseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+
+		if next := schedule.Next(start); !next.Equal(test.next) {
+			t.Errorf("%s: expected %s, got %s", test.spec, test.next, next)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		if _, err := tgbotapi.ParseSchedule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// UserSession holds small bits of state for a user in a chat, such as
+// the last page they viewed, without needing a full state machine.
+type UserSession struct {
+	store  KeyValueStore
+	prefix string
+	ttl    time.Duration
+}
+
+// Get returns the value of a key, or an empty string if it is not set.
+func (s *UserSession) Get(key string) (string, error) {
+	if s == nil {
+		return "", errors.New(ErrNoSession)
+	}
+
+	value, _, err := s.store.Get(s.prefix + key)
+
+	return string(value), err
+}
+
+// Set sets the value of a key.
+func (s *UserSession) Set(key string, value string) error {
+	if s == nil {
+		return errors.New(ErrNoSession)
+	}
+
+	return s.store.Set(s.prefix+key, []byte(value), s.ttl)
+}
+
+// Delete removes a key.
+func (s *UserSession) Delete(key string) error {
+	if s == nil {
+		return errors.New(ErrNoSession)
+	}
+
+	return s.store.Delete(s.prefix + key)
+}
+
+// sessionKey is the context key for the session of an update.
+type sessionKey struct{}
+
+// Sessions returns Middleware which gives handlers a UserSession for the
+// user and chat of each update, kept in store. Values expire after ttl
+// if it is more than zero, counted from when they were last set.
+//
+// Sessions of different bots sharing a store are kept apart.
+func Sessions(store KeyValueStore, ttl time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			var botID, chatID, userID int64
+			if bot != nil {
+				self, err := bot.Me(ctx)
+				if err != nil {
+					log.Println(err)
+					next(ctx, bot, update)
+					return
+				}
+				botID = self.ID
+			}
+			if chat := update.FromChat(); chat != nil {
+				chatID = chat.ID
+			}
+			if user := update.SentFrom(); user != nil {
+				userID = user.ID
+			}
+
+			ctx = context.WithValue(ctx, sessionKey{}, &UserSession{
+				store:  store,
+				prefix: fmt.Sprintf("session:%d:%d:%d:", botID, chatID, userID),
+				ttl:    ttl,
+			})
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// Session returns the session of the user whose update is being handled,
+// as given by the Sessions middleware. Outside of it, every method of the
+// session returns an error with ErrNoSession.
+func Session(ctx context.Context) *UserSession {
+	session, _ := ctx.Value(sessionKey{}).(*UserSession)
+
+	return session
+}
@@ -0,0 +1,56 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestSessions(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	var got []string
+	d := tgbotapi.NewDispatcher(bot, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		session := tgbotapi.Session(ctx)
+
+		page, err := session.Get("page")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page)
+
+		if err := session.Set("page", update.Message.Text); err != nil {
+			t.Fatal(err)
+		}
+	})
+	d.Use(tgbotapi.Sessions(tgbotapi.NewMemoryStore(), 0))
+
+	message := func(chatID, userID int64, text string) tgbotapi.Update {
+		return tgbotapi.Update{Message: &tgbotapi.Message{
+			Text: text,
+			Chat: &tgbotapi.Chat{ID: chatID},
+			From: &tgbotapi.User{ID: userID},
+		}}
+	}
+
+	ctx := context.Background()
+	d.Dispatch(ctx, message(1, 1, "first"))
+	d.Dispatch(ctx, message(1, 2, "other user"))
+	d.Dispatch(ctx, message(2, 1, "other chat"))
+	d.Dispatch(ctx, message(1, 1, "second"))
+
+	expected := []string{"", "", "", "first"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+
+	if err := tgbotapi.Session(ctx).Set("page", "x"); err == nil || err.Error() != tgbotapi.ErrNoSession {
+		t.Errorf("expected ErrNoSession outside the middleware, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// UpdateSource is somewhere updates are received from other than
+// Telegram, such as a NATS, Kafka or SQS queue that a webhook frontend
+// made with PublishingWebhookHandler pushes them to. This lets updates be
+// handled by many workers, each reading its own part of the queue.
+type UpdateSource interface {
+	// Receive blocks until the next update is available, returning its
+	// JSON, or until ctx is done, returning its error.
+	Receive(ctx context.Context) ([]byte, error)
+}
+
+// UpdatePublisher is somewhere updates can be sent, such as a message
+// queue, to be received by an UpdateSource.
+type UpdatePublisher interface {
+	// Publish sends the JSON of an update. Updates with the same key must
+	// be received in the order they were published, such as by using the
+	// key to pick a Kafka partition or as an SQS message group ID.
+	Publish(ctx context.Context, key string, data []byte) error
+}
+
+// UpdatePartitionKey returns the key an update should be published with
+// so that updates from the same chat stay in order. Updates without a
+// chat use the user who caused them, and any others the update ID.
+func UpdatePartitionKey(update *Update) string {
+	if chat := update.FromChat(); chat != nil {
+		return strconv.FormatInt(chat.ID, 10)
+	}
+	if user := update.SentFrom(); user != nil {
+		return "user:" + strconv.FormatInt(user.ID, 10)
+	}
+
+	return "update:" + strconv.Itoa(update.UpdateID)
+}
+
+// PublishingWebhookHandler returns a http.Handler for a webhook which
+// publishes each update instead of handling it. If publishing fails, the
+// request fails so Telegram sends the update again later.
+func (bot *BotAPI) PublishingWebhookHandler(publisher UpdatePublisher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if !bot.readWebhookBody(w, r, &buf) {
+			return
+		}
+
+		var update Update
+		if err := json.Unmarshal(buf.Bytes(), &update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := publisher.Publish(r.Context(), UpdatePartitionKey(&update), buf.Bytes()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	})
+}
+
+// GetUpdatesFromSource starts receiving updates from an UpdateSource in
+// the background, in the order it gives them, until ctx is done. As with
+// polling, answers to Ask are not sent to the channel, and failures to
+// receive are logged and retried with a backoff.
+func (bot *BotAPI) GetUpdatesFromSource(ctx context.Context, source UpdateSource) UpdatesChannel {
+	ch := make(chan Update, bot.Buffer)
+
+	go func() {
+		defer close(ch)
+
+		failures := 0
+		for ctx.Err() == nil {
+			data, err := source.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				failures++
+				delay := retryDelay(failures)
+
+				log.Println(err)
+				log.Printf("Failed to receive updates, retrying in %s...", delay)
+
+				if err := sleepContext(ctx, delay); err != nil {
+					return
+				}
+
+				continue
+			}
+			failures = 0
+
+			var update Update
+			if err := json.Unmarshal(data, &update); err != nil {
+				log.Println(err)
+				continue
+			}
+
+			bot.reportUnknownFields(&update)
+
+			if bot.answer(&update) {
+				continue
+			}
+
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
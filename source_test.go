@@ -0,0 +1,79 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// queue is an UpdatePublisher and UpdateSource connected by a channel.
+type queue struct {
+	messages chan []byte
+	keys     []string
+}
+
+func (q *queue) Publish(ctx context.Context, key string, data []byte) error {
+	q.keys = append(q.keys, key)
+	q.messages <- append([]byte(nil), data...)
+	return nil
+}
+
+func (q *queue) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-q.messages:
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestUpdateSource(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	q := &queue{messages: make(chan []byte, 10)}
+	handler := bot.PublishingWebhookHandler(q)
+
+	bodies := []string{
+		`{"update_id":1,"message":{"message_id":1,"text":"first","chat":{"id":5}}}`,
+		`{"update_id":2,"inline_query":{"id":"a","from":{"id":7}}}`,
+		`{"update_id":3,"message":{"message_id":2,"text":"second","chat":{"id":5}}}`,
+	}
+	for _, body := range bodies {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d", w.Code)
+		}
+	}
+
+	if strings.Join(q.keys, ",") != "5,user:7,5" {
+		t.Errorf("got keys %v", q.keys)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := bot.GetUpdatesFromSource(ctx, q)
+
+	for _, id := range []int{1, 2, 3} {
+		if update := <-updates; update.UpdateID != id {
+			t.Errorf("expected update %d, got %d", id, update.UpdateID)
+		}
+	}
+
+	cancel()
+	if _, ok := <-updates; ok {
+		t.Error("channel was not closed")
+	}
+}
+
+func TestUpdatePartitionKey(t *testing.T) {
+	update := &tgbotapi.Update{UpdateID: 9}
+	if key := tgbotapi.UpdatePartitionKey(update); key != "update:9" {
+		t.Errorf("got key %q", key)
+	}
+}
@@ -0,0 +1,434 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+)
+
+// StickerFormat is the format of the stickers in a sticker set.
+type StickerFormat string
+
+// Formats of stickers.
+const (
+	StickerFormatStatic   StickerFormat = "static"   // PNG or WEBP images
+	StickerFormatAnimated StickerFormat = "animated" // TGS animations
+	StickerFormatVideo    StickerFormat = "video"    // WEBM videos
+)
+
+// Limits on the files stickers in sets are created from.
+const (
+	StickerDimension       = 512 // one side must be this, the other at most
+	MaxStaticStickerSize   = 512 << 10
+	MaxAnimatedStickerSize = 64 << 10
+	MaxVideoStickerSize    = 256 << 10
+	MaxStickerDuration     = 3 * time.Second
+)
+
+// StickerAssetError happens when a file can't be used as a sticker in a
+// set, explaining what must be changed.
+type StickerAssetError struct {
+	Name   string
+	Reason string
+}
+
+func (e *StickerAssetError) Error() string {
+	return fmt.Sprintf("%s can't be used as a sticker: %s", e.Name, e.Reason)
+}
+
+// ValidateStickerFile checks a file against Telegram's requirements for
+// stickers in sets of a format, before it is uploaded to one. It returns
+// a FileTooLargeError, StickerFormatError or StickerAssetError saying
+// what is wrong, instead of the server's vaguer errors.
+//
+// Files which are not uploaded are not checked, and a FileReader is only
+// checked by its Size, if known.
+func ValidateStickerFile(file RequestFileData, format StickerFormat) error {
+	if !file.NeedsUpload() {
+		return nil
+	}
+
+	var limit int64
+	switch format {
+	case StickerFormatStatic:
+		limit = MaxStaticStickerSize
+	case StickerFormatAnimated:
+		limit = MaxAnimatedStickerSize
+	case StickerFormatVideo:
+		limit = MaxVideoStickerSize
+	default:
+		return fmt.Errorf("unknown sticker format %q", format)
+	}
+
+	name, size, content, err := readStickerFile(file, limit)
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		return &FileTooLargeError{Name: name, Size: size, Limit: limit}
+	}
+	if content == nil {
+		return nil
+	}
+
+	switch format {
+	case StickerFormatStatic:
+		return validateStaticSticker(name, content)
+	case StickerFormatAnimated:
+		return validateAnimatedSticker(name, content)
+	default:
+		return validateVideoSticker(name, content)
+	}
+}
+
+// readStickerFile returns the name and size of a file, and its contents
+// if they can be read without consuming the file and are within limit.
+// Size is -1 if unknown.
+func readStickerFile(file RequestFileData, limit int64) (string, int64, []byte, error) {
+	switch f := file.(type) {
+	case FileBytes:
+		return f.Name, int64(len(f.Bytes)), f.Bytes, nil
+	case FileReader:
+		return f.Name, f.Size, nil, nil
+	case FilePath:
+		info, err := os.Stat(string(f))
+		if err != nil {
+			return "", 0, nil, err
+		}
+		if info.Size() > limit {
+			return string(f), info.Size(), nil, nil
+		}
+
+		content, err := ioutil.ReadFile(string(f))
+		if err != nil {
+			return "", 0, nil, err
+		}
+
+		return string(f), int64(len(content)), content, nil
+	}
+
+	return "", -1, nil, nil
+}
+
+// checkStickerDimensions checks that one side of a sticker is exactly
+// StickerDimension and the other is no larger.
+func checkStickerDimensions(name string, width, height int) error {
+	if (width == StickerDimension && height <= StickerDimension) ||
+		(height == StickerDimension && width <= StickerDimension) {
+		return nil
+	}
+
+	return &StickerAssetError{
+		Name:   name,
+		Reason: fmt.Sprintf("it is %dx%d, but one side must be %d pixels and the other at most %d", width, height, StickerDimension, StickerDimension),
+	}
+}
+
+func validateStaticSticker(name string, content []byte) error {
+	if isWebP(content) {
+		width, height, ok := webpDimensions(content)
+		if !ok {
+			return &StickerAssetError{Name: name, Reason: "its WEBP header can't be read"}
+		}
+
+		return checkStickerDimensions(name, width, height)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || format != "png" {
+		return &StickerFormatError{Name: name}
+	}
+
+	return checkStickerDimensions(name, config.Width, config.Height)
+}
+
+func isWebP(content []byte) bool {
+	return len(content) >= 12 && string(content[:4]) == "RIFF" && string(content[8:12]) == "WEBP"
+}
+
+// webpDimensions reads the dimensions of a WEBP image from its header.
+func webpDimensions(content []byte) (int, int, bool) {
+	if len(content) < 30 {
+		return 0, 0, false
+	}
+
+	switch string(content[12:16]) {
+	case "VP8 ":
+		if !bytes.Equal(content[23:26], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0, false
+		}
+		width := binary.LittleEndian.Uint16(content[26:]) & 0x3fff
+		height := binary.LittleEndian.Uint16(content[28:]) & 0x3fff
+		return int(width), int(height), true
+	case "VP8L":
+		if content[20] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(content[21:])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, true
+	case "VP8X":
+		width := uint32(content[24]) | uint32(content[25])<<8 | uint32(content[26])<<16
+		height := uint32(content[27]) | uint32(content[28])<<8 | uint32(content[29])<<16
+		return int(width) + 1, int(height) + 1, true
+	}
+
+	return 0, 0, false
+}
+
+// lottieHeader is the part of a Lottie animation, which a TGS file is
+// gzipped, describing its size and length.
+type lottieHeader struct {
+	Width     int     `json:"w"`
+	Height    int     `json:"h"`
+	FrameRate float64 `json:"fr"`
+	InPoint   float64 `json:"ip"`
+	OutPoint  float64 `json:"op"`
+}
+
+// maxLottieSize is the most decompressed data read from a TGS file.
+const maxLottieSize = 16 << 20
+
+func validateAnimatedSticker(name string, content []byte) error {
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return &StickerFormatError{Name: name}
+	}
+	defer zr.Close()
+
+	var header lottieHeader
+	if err := json.NewDecoder(io.LimitReader(zr, maxLottieSize)).Decode(&header); err != nil {
+		return &StickerAssetError{Name: name, Reason: "it is not a Lottie animation"}
+	}
+
+	if header.Width != StickerDimension || header.Height != StickerDimension {
+		return &StickerAssetError{
+			Name:   name,
+			Reason: fmt.Sprintf("its canvas is %dx%d, but must be %dx%d", header.Width, header.Height, StickerDimension, StickerDimension),
+		}
+	}
+	if header.FrameRate != 30 && header.FrameRate != 60 {
+		return &StickerAssetError{Name: name, Reason: fmt.Sprintf("it is %g fps, but must be 30 or 60", header.FrameRate)}
+	}
+
+	duration := time.Duration((header.OutPoint - header.InPoint) / header.FrameRate * float64(time.Second))
+	if duration > MaxStickerDuration {
+		return &StickerAssetError{Name: name, Reason: fmt.Sprintf("it lasts %s, but must be at most %s", duration, MaxStickerDuration)}
+	}
+
+	return nil
+}
+
+// webmInfo is what is needed from a WEBM file to check it as a sticker.
+type webmInfo struct {
+	timecodeScale uint64
+	duration      float64
+	codec         string
+	width         int
+	height        int
+	hasAudio      bool
+}
+
+// EBML element IDs used in WEBM files.
+const (
+	ebmlHeaderID    = 0x1a45dfa3
+	ebmlSegmentID   = 0x18538067
+	ebmlInfoID      = 0x1549a966
+	ebmlTimecodeID  = 0x2ad7b1
+	ebmlDurationID  = 0x4489
+	ebmlTracksID    = 0x1654ae6b
+	ebmlTrackID     = 0xae
+	ebmlTrackTypeID = 0x83
+	ebmlCodecID     = 0x86
+	ebmlVideoID     = 0xe0
+	ebmlWidthID     = 0xb0
+	ebmlHeightID    = 0xba
+	ebmlClusterID   = 0x1f43b675
+)
+
+func validateVideoSticker(name string, content []byte) error {
+	if len(content) < 4 || binary.BigEndian.Uint32(content) != ebmlHeaderID {
+		return &StickerFormatError{Name: name}
+	}
+
+	info := webmInfo{timecodeScale: 1000000}
+	if !readWebM(content, &info, nil) {
+		return &StickerAssetError{Name: name, Reason: "it is not a valid WEBM file"}
+	}
+
+	if info.codec != "V_VP9" {
+		return &StickerAssetError{Name: name, Reason: fmt.Sprintf("it is encoded with %q, but must be VP9", info.codec)}
+	}
+	if info.hasAudio {
+		return &StickerAssetError{Name: name, Reason: "it has an audio track, which must be removed"}
+	}
+	if err := checkStickerDimensions(name, info.width, info.height); err != nil {
+		return err
+	}
+
+	duration := time.Duration(info.duration * float64(info.timecodeScale))
+	if duration > MaxStickerDuration {
+		return &StickerAssetError{Name: name, Reason: fmt.Sprintf("it lasts %s, but must be at most %s", duration, MaxStickerDuration)}
+	}
+
+	return nil
+}
+
+// readWebM reads the elements of an EBML document into info, descending
+// into those needed. track is the track being read, if any. It returns
+// false if the document is malformed.
+func readWebM(data []byte, info *webmInfo, track *webmTrack) bool {
+	for len(data) > 0 {
+		id, n := readEBMLID(data)
+		if n == 0 {
+			return false
+		}
+		data = data[n:]
+
+		size, n := readEBMLSize(data)
+		if n == 0 {
+			return false
+		}
+		data = data[n:]
+		if size < 0 || size > int64(len(data)) {
+			size = int64(len(data))
+		}
+		body := data[:size]
+		data = data[size:]
+
+		switch id {
+		case ebmlSegmentID, ebmlInfoID, ebmlTracksID:
+			if !readWebM(body, info, nil) {
+				return false
+			}
+		case ebmlTrackID:
+			t := &webmTrack{}
+			if !readWebM(body, info, t) {
+				return false
+			}
+			switch t.kind {
+			case 1:
+				info.codec, info.width, info.height = t.codec, t.width, t.height
+			case 2:
+				info.hasAudio = true
+			}
+		case ebmlVideoID:
+			if track == nil || !readWebM(body, info, track) {
+				return false
+			}
+		case ebmlTimecodeID:
+			info.timecodeScale = ebmlUint(body)
+		case ebmlDurationID:
+			switch len(body) {
+			case 4:
+				info.duration = float64(math.Float32frombits(binary.BigEndian.Uint32(body)))
+			case 8:
+				info.duration = math.Float64frombits(binary.BigEndian.Uint64(body))
+			}
+		case ebmlTrackTypeID, ebmlCodecID, ebmlWidthID, ebmlHeightID:
+			if track != nil {
+				track.set(id, body)
+			}
+		case ebmlClusterID:
+			// Everything needed comes before the frames.
+			return true
+		}
+	}
+
+	return true
+}
+
+// webmTrack is a track of a WEBM file.
+type webmTrack struct {
+	kind   uint64
+	codec  string
+	width  int
+	height int
+}
+
+func (t *webmTrack) set(id uint64, body []byte) {
+	switch id {
+	case ebmlTrackTypeID:
+		t.kind = ebmlUint(body)
+	case ebmlCodecID:
+		t.codec = string(bytes.TrimRight(body, "\x00"))
+	case ebmlWidthID:
+		t.width = int(ebmlUint(body))
+	case ebmlHeightID:
+		t.height = int(ebmlUint(body))
+	}
+}
+
+// readEBMLID reads an element ID, which keeps its length marker. It
+// returns a length of zero if it is malformed.
+func readEBMLID(data []byte) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+
+	n := ebmlLength(data[0])
+	if n == 0 || n > 4 || n > len(data) {
+		return 0, 0
+	}
+
+	var id uint64
+	for _, b := range data[:n] {
+		id = id<<8 | uint64(b)
+	}
+
+	return id, n
+}
+
+// readEBMLSize reads an element size, which is -1 if unknown. It returns
+// a length of zero if it is malformed.
+func readEBMLSize(data []byte) (int64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+
+	n := ebmlLength(data[0])
+	if n == 0 || n > len(data) {
+		return 0, 0
+	}
+
+	size := uint64(data[0]) & (0xff >> uint(n))
+	unknown := size == 0xff>>uint(n)
+	for _, b := range data[1:n] {
+		size = size<<8 | uint64(b)
+		unknown = unknown && b == 0xff
+	}
+
+	if unknown || size > math.MaxInt64 {
+		return -1, n
+	}
+
+	return int64(size), n
+}
+
+// ebmlLength returns the length of a variable length integer from its
+// first byte, or zero if it is invalid.
+func ebmlLength(first byte) int {
+	for n := 1; n <= 8; n++ {
+		if first&(0x80>>uint(n-1)) != 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+func ebmlUint(body []byte) uint64 {
+	var v uint64
+	for _, b := range body {
+		v = v<<8 | uint64(b)
+	}
+
+	return v
+}
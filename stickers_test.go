@@ -0,0 +1,121 @@
+package tgbotapi_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// ebml encodes an EBML element with an 8 byte size.
+func ebml(id uint32, body ...[]byte) []byte {
+	var buf bytes.Buffer
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> uint(shift)); b != 0 || buf.Len() > 0 {
+			buf.WriteByte(b)
+		}
+	}
+
+	content := bytes.Join(body, nil)
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(content)))
+	size[0] = 0x01
+	buf.Write(size)
+	buf.Write(content)
+
+	return buf.Bytes()
+}
+
+func ebmlUint(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func webm(codec string, width, height uint64, seconds float64, audio bool) []byte {
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(seconds*1000))
+
+	tracks := [][]byte{ebml(0xae,
+		ebml(0x83, ebmlUint(1)),
+		ebml(0x86, []byte(codec)),
+		ebml(0xe0, ebml(0xb0, ebmlUint(width)), ebml(0xba, ebmlUint(height))),
+	)}
+	if audio {
+		tracks = append(tracks, ebml(0xae, ebml(0x83, ebmlUint(2)), ebml(0x86, []byte("A_OPUS"))))
+	}
+
+	return append(ebml(0x1a45dfa3, ebml(0x4282, []byte("webm"))), ebml(0x18538067,
+		ebml(0x1549a966, ebml(0x2ad7b1, ebmlUint(1000000)), ebml(0x4489, duration)),
+		ebml(0x1654ae6b, tracks...),
+		ebml(0x1f43b675),
+	)...)
+}
+
+func tgs(width, height int, frameRate, frames float64) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	fmt.Fprintf(zw, `{"v":"5.5.2","w":%d,"h":%d,"fr":%g,"ip":0,"op":%g,"layers":[]}`, width, height, frameRate, frames)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func pngOf(width, height int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+func webpOf(width, height uint32) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+	bits := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bits, (width-1)|(height-1)<<14)
+	return append(append(data, bits...), make([]byte, 8)...)
+}
+
+func TestValidateStickerFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		format tgbotapi.StickerFormat
+		file   []byte
+		err    string
+	}{
+		{"png", tgbotapi.StickerFormatStatic, pngOf(512, 300), ""},
+		{"small png", tgbotapi.StickerFormatStatic, pngOf(256, 256), "*tgbotapi.StickerAssetError"},
+		{"webp", tgbotapi.StickerFormatStatic, webpOf(200, 512), ""},
+		{"large webp", tgbotapi.StickerFormatStatic, webpOf(1024, 512), "*tgbotapi.StickerAssetError"},
+		{"not an image", tgbotapi.StickerFormatStatic, []byte("text"), "*tgbotapi.StickerFormatError"},
+		{"heavy png", tgbotapi.StickerFormatStatic, make([]byte, tgbotapi.MaxStaticStickerSize+1), "*tgbotapi.FileTooLargeError"},
+		{"tgs", tgbotapi.StickerFormatAnimated, tgs(512, 512, 60, 180), ""},
+		{"long tgs", tgbotapi.StickerFormatAnimated, tgs(512, 512, 30, 120), "*tgbotapi.StickerAssetError"},
+		{"small tgs", tgbotapi.StickerFormatAnimated, tgs(256, 256, 30, 30), "*tgbotapi.StickerAssetError"},
+		{"webm", tgbotapi.StickerFormatVideo, webm("V_VP9", 512, 512, 2.5, false), ""},
+		{"vp8 webm", tgbotapi.StickerFormatVideo, webm("V_VP8", 512, 512, 2.5, false), "*tgbotapi.StickerAssetError"},
+		{"long webm", tgbotapi.StickerFormatVideo, webm("V_VP9", 512, 400, 3.5, false), "*tgbotapi.StickerAssetError"},
+		{"loud webm", tgbotapi.StickerFormatVideo, webm("V_VP9", 512, 512, 1, true), "*tgbotapi.StickerAssetError"},
+		{"not a webm", tgbotapi.StickerFormatVideo, pngOf(512, 512), "*tgbotapi.StickerFormatError"},
+	}
+
+	for _, test := range tests {
+		err := tgbotapi.ValidateStickerFile(tgbotapi.FileBytes{Name: test.name, Bytes: test.file}, test.format)
+
+		got := ""
+		if err != nil {
+			got = fmt.Sprintf("%T", err)
+		}
+
+		if got != test.err {
+			t.Errorf("%s: got %s (%v), expected %s", test.name, got, err, test.err)
+		}
+	}
+
+	if err := tgbotapi.ValidateStickerFile(tgbotapi.FileID(ExistingStickerFileID), tgbotapi.StickerFormatVideo); err != nil {
+		t.Errorf("existing file was checked: %v", err)
+	}
+}
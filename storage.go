@@ -0,0 +1,175 @@
+package tgbotapi
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KeyValueStore persists small values by key, such as the update offset,
+// conversation state or cached results.
+//
+// Implementations must be safe for concurrent use. Adapters for Redis,
+// bbolt and database/sql are available in the storage directory.
+type KeyValueStore interface {
+	// Get returns the value for a key, and false if it does not exist
+	// or has expired.
+	Get(key string) ([]byte, bool, error)
+	// Set stores a value for a key. If ttl is more than zero, the value
+	// expires after that long.
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes a key, if it exists.
+	Delete(key string) error
+}
+
+// KeyValueAdder is a KeyValueStore which can also set a value only if
+// its key is missing, in one step, so callers sharing the store can't
+// both set it. The MemoryStore and the Redis and bbolt adapters are
+// KeyValueAdders.
+type KeyValueAdder interface {
+	KeyValueStore
+	// Add stores a value for a key as Set does, unless the key exists
+	// and has not expired. It returns if the value was stored.
+	Add(key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+// StateStore persists the conversation state of a user within a chat.
+type StateStore interface {
+	// GetState returns the current state, or an empty string if none
+	// was set.
+	GetState(chatID int64, userID int64) (string, error)
+	// SetState sets the current state. Setting an empty state clears it.
+	SetState(chatID int64, userID int64, state string) error
+}
+
+// MemoryStore is a KeyValueStore that keeps values in memory.
+//
+// It is useful for tests and for bots that don't need to keep anything
+// between restarts.
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string]memoryValue
+}
+
+type memoryValue struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStore creates a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: make(map[string]memoryValue)}
+}
+
+// Get returns the value for a key, and false if it does not exist
+// or has expired.
+func (store *MemoryStore) Get(key string) ([]byte, bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	v, ok := store.values[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !v.expires.IsZero() && time.Now().After(v.expires) {
+		delete(store.values, key)
+		return nil, false, nil
+	}
+
+	return v.value, true, nil
+}
+
+// Set stores a value for a key, expiring it after ttl if it is
+// more than zero.
+func (store *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	v := memoryValue{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		v.expires = time.Now().Add(ttl)
+	}
+
+	store.values[key] = v
+
+	return nil
+}
+
+// Add stores a value for a key unless it exists and has not expired,
+// returning if it was stored.
+func (store *MemoryStore) Add(key string, value []byte, ttl time.Duration) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if v, ok := store.values[key]; ok && (v.expires.IsZero() || time.Now().Before(v.expires)) {
+		return false, nil
+	}
+
+	v := memoryValue{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		v.expires = time.Now().Add(ttl)
+	}
+
+	store.values[key] = v
+
+	return true, nil
+}
+
+// Delete removes a key, if it exists.
+func (store *MemoryStore) Delete(key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.values, key)
+
+	return nil
+}
+
+// kvStateStore is a StateStore kept within a KeyValueStore.
+type kvStateStore struct {
+	store KeyValueStore
+}
+
+// NewStateStore creates a StateStore which keeps states in a KeyValueStore.
+func NewStateStore(store KeyValueStore) StateStore {
+	return kvStateStore{store}
+}
+
+func stateKey(chatID int64, userID int64) string {
+	return fmt.Sprintf("state:%d:%d", chatID, userID)
+}
+
+func (s kvStateStore) GetState(chatID int64, userID int64) (string, error) {
+	state, _, err := s.store.Get(stateKey(chatID, userID))
+
+	return string(state), err
+}
+
+func (s kvStateStore) SetState(chatID int64, userID int64, state string) error {
+	if state == "" {
+		return s.store.Delete(stateKey(chatID, userID))
+	}
+
+	return s.store.Set(stateKey(chatID, userID), []byte(state), 0)
+}
+
+// offsetKey is the key the update offset is stored as.
+const offsetKey = "offset"
+
+// loadOffset returns the update offset saved in a KeyValueStore, or 0
+// if none has been saved.
+func loadOffset(store KeyValueStore) (int, error) {
+	value, ok, err := store.Get(offsetKey)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return strconv.Atoi(string(value))
+}
+
+// saveOffset saves the update offset in a KeyValueStore.
+func saveOffset(store KeyValueStore, offset int) error {
+	return store.Set(offsetKey, []byte(strconv.Itoa(offset)), 0)
+}
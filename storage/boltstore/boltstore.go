@@ -0,0 +1,144 @@
+// Package boltstore implements tgbotapi.KeyValueStore on top of bbolt.
+package boltstore
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBucket is the bucket values are kept in if none is specified.
+const DefaultBucket = "tgbotapi"
+
+var _ tgbotapi.KeyValueAdder = (*Store)(nil)
+
+// Store is a tgbotapi.KeyValueStore backed by a bbolt database.
+//
+// Each value is stored with its expiry time, as bbolt has no support for
+// expiring keys. Expired values are removed when they are next read.
+type Store struct {
+	DB     *bolt.DB
+	Bucket []byte
+}
+
+// New creates a new Store keeping values in bucket, creating the
+// bucket if it does not exist.
+func New(db *bolt.DB, bucket string) (*Store, error) {
+	if bucket == "" {
+		bucket = DefaultBucket
+	}
+
+	s := &Store{
+		DB:     db,
+		Bucket: []byte(bucket),
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.Bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get returns the value for a key, and false if it does not exist
+// or has expired.
+func (s *Store) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	var expired bool
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(s.Bucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+
+		var expires int64
+		value, expires = decode(data)
+		expired = expires != 0 && time.Now().UnixNano() > expires
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if expired {
+		return nil, false, s.Delete(key)
+	}
+
+	return value, value != nil, nil
+}
+
+// Set stores a value for a key, expiring it after ttl if it is
+// more than zero.
+func (s *Store) Set(key string, value []byte, ttl time.Duration) error {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.Bucket).Put([]byte(key), encode(value, expires))
+	})
+}
+
+// Add stores a value for a key unless it exists and has not expired,
+// returning if it was stored.
+func (s *Store) Add(key string, value []byte, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	var expires int64
+	if ttl > 0 {
+		expires = now.Add(ttl).UnixNano()
+	}
+
+	added := false
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(s.Bucket)
+
+		if data := bucket.Get([]byte(key)); data != nil {
+			if _, expires := decode(data); expires == 0 || now.UnixNano() <= expires {
+				return nil
+			}
+		}
+
+		added = true
+		return bucket.Put([]byte(key), encode(value, expires))
+	})
+
+	return added && err == nil, err
+}
+
+// Delete removes a key, if it exists.
+func (s *Store) Delete(key string) error {
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.Bucket).Delete([]byte(key))
+	})
+}
+
+// encode prefixes a value with its expiry time.
+func encode(value []byte, expires int64) []byte {
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expires))
+	copy(data[8:], value)
+
+	return data
+}
+
+// decode splits stored data into its value and expiry time. The value
+// is copied, as data is only valid within the transaction.
+func decode(data []byte) ([]byte, int64) {
+	if len(data) < 8 {
+		return []byte{}, 0
+	}
+
+	value := append([]byte{}, data[8:]...)
+
+	return value, int64(binary.BigEndian.Uint64(data))
+}
@@ -0,0 +1,25 @@
+package boltstore_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/boltstore"
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/storetest"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "bot.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := boltstore.New(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, store)
+}
@@ -0,0 +1,56 @@
+// Package redisstore implements tgbotapi.KeyValueStore on top of Redis.
+package redisstore
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var _ tgbotapi.KeyValueAdder = (*Store)(nil)
+
+// Store is a tgbotapi.KeyValueStore backed by Redis.
+type Store struct {
+	Client *redis.Client
+	Prefix string // prepended to every key, to share a database
+}
+
+// New creates a new Store using client, with keys prefixed by prefix.
+func New(client *redis.Client, prefix string) *Store {
+	return &Store{
+		Client: client,
+		Prefix: prefix,
+	}
+}
+
+// Get returns the value for a key, and false if it does not exist
+// or has expired.
+func (s *Store) Get(key string) ([]byte, bool, error) {
+	value, err := s.Client.Get(s.Prefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set stores a value for a key, expiring it after ttl if it is
+// more than zero.
+func (s *Store) Set(key string, value []byte, ttl time.Duration) error {
+	return s.Client.Set(s.Prefix+key, value, ttl).Err()
+}
+
+// Add stores a value for a key unless it exists, returning if it was
+// stored.
+func (s *Store) Add(key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.Client.SetNX(s.Prefix+key, value, ttl).Result()
+}
+
+// Delete removes a key, if it exists.
+func (s *Store) Delete(key string) error {
+	return s.Client.Del(s.Prefix + key).Err()
+}
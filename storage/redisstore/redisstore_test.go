@@ -0,0 +1,24 @@
+package redisstore_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-redis/redis"
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/redisstore"
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/storetest"
+)
+
+// TestStore runs against the Redis server at TGBOTAPI_TEST_REDIS, such as
+// localhost:6379, if it is set.
+func TestStore(t *testing.T) {
+	addr := os.Getenv("TGBOTAPI_TEST_REDIS")
+	if addr == "" {
+		t.Skip("TGBOTAPI_TEST_REDIS is not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+
+	storetest.Run(t, redisstore.New(client, "tgbotapi-test:"))
+}
@@ -0,0 +1,70 @@
+//go:build postgres
+
+package sqlstore_test
+
+import (
+	"database/sql"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/sqlstore"
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/storetest"
+	_ "github.com/lib/pq"
+)
+
+// TestPostgres runs against the PostgreSQL database at
+// TGBOTAPI_TEST_POSTGRES, such as postgres://localhost/test, if it is set.
+// It needs the postgres build tag, so the driver is only required here.
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TGBOTAPI_TEST_POSTGRES")
+	if dsn == "" {
+		t.Skip("TGBOTAPI_TEST_POSTGRES is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS tgbotapi_test (
+		name    VARCHAR(255) PRIMARY KEY,
+		value   BYTEA NOT NULL,
+		expires BIGINT NOT NULL
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("DROP TABLE tgbotapi_test")
+
+	store := sqlstore.NewPostgres(db, "tgbotapi_test")
+	storetest.Run(t, store)
+
+	t.Run("ConcurrentSet", func(t *testing.T) {
+		key := "storetest:concurrent"
+		defer store.Delete(key)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- store.Set(key, []byte(strconv.Itoa(i)), 0)
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Errorf("setting a new key concurrently failed: %v", err)
+			}
+		}
+		if _, ok, err := store.Get(key); !ok || err != nil {
+			t.Errorf("expected the key set, got %v, %v", ok, err)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+// Package sqlstore implements tgbotapi.KeyValueStore on top of
+// database/sql.
+//
+// The table must be created before use, for example:
+//
+//	CREATE TABLE tgbotapi (
+//		name    VARCHAR(255) PRIMARY KEY,
+//		value   BLOB NOT NULL,
+//		expires BIGINT NOT NULL
+//	);
+//
+// PostgreSQL has no BLOB type, so value should be a BYTEA there, and the
+// Store created with NewPostgres. MySQL Stores are created with NewMySQL.
+package sqlstore
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// DefaultTable is the table values are kept in if none is specified.
+const DefaultTable = "tgbotapi"
+
+var _ tgbotapi.KeyValueStore = (*Store)(nil)
+
+// Placeholder is the style of parameter placeholder a database uses.
+type Placeholder int
+
+const (
+	// Question uses ? placeholders, as MySQL and SQLite do.
+	Question Placeholder = iota
+	// Dollar uses numbered $1 placeholders, as PostgreSQL does.
+	Dollar
+)
+
+// Dialect is the SQL dialect of a database, for the statements which
+// differ between them.
+type Dialect int
+
+const (
+	// SQLite is the dialect of SQLite.
+	SQLite Dialect = iota
+	// MySQL is the dialect of MySQL and MariaDB.
+	MySQL
+	// PostgreSQL is the dialect of PostgreSQL.
+	PostgreSQL
+)
+
+// Store is a tgbotapi.KeyValueStore backed by a SQL database.
+//
+// Expired values are removed when they are next read.
+type Store struct {
+	DB          *sql.DB
+	Table       string
+	Placeholder Placeholder
+	Dialect     Dialect
+}
+
+// New creates a new Store keeping values in table in a SQLite database.
+func New(db *sql.DB, table string) *Store {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	return &Store{
+		DB:    db,
+		Table: table,
+	}
+}
+
+// NewPostgres creates a new Store keeping values in table in a
+// PostgreSQL database.
+func NewPostgres(db *sql.DB, table string) *Store {
+	s := New(db, table)
+	s.Placeholder = Dollar
+	s.Dialect = PostgreSQL
+
+	return s
+}
+
+// NewMySQL creates a new Store keeping values in table in a MySQL
+// database.
+func NewMySQL(db *sql.DB, table string) *Store {
+	s := New(db, table)
+	s.Dialect = MySQL
+
+	return s
+}
+
+// query rewrites a query written with ? placeholders for the database.
+func (s *Store) query(query string) string {
+	if s.Placeholder != Dollar {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c != '?' {
+			b.WriteRune(c)
+			continue
+		}
+
+		n++
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// Get returns the value for a key, and false if it does not exist
+// or has expired.
+func (s *Store) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	var expires int64
+
+	err := s.DB.QueryRow(s.query("SELECT value, expires FROM "+s.Table+" WHERE name = ?"), key).Scan(&value, &expires)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if expires != 0 && time.Now().UnixNano() > expires {
+		return nil, false, s.Delete(key)
+	}
+
+	return value, true, nil
+}
+
+// Set stores a value for a key, expiring it after ttl if it is
+// more than zero.
+func (s *Store) Set(key string, value []byte, ttl time.Duration) error {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	_, err := s.DB.Exec(s.query(s.upsert()), key, value, expires)
+
+	return err
+}
+
+// upsert returns the statement which inserts a value, or replaces the
+// value already stored, in one step so concurrent Sets don't conflict.
+func (s *Store) upsert() string {
+	insert := "INSERT INTO " + s.Table + " (name, value, expires) VALUES (?, ?, ?)"
+
+	if s.Dialect == MySQL {
+		return insert + " ON DUPLICATE KEY UPDATE value = VALUES(value), expires = VALUES(expires)"
+	}
+
+	return insert + " ON CONFLICT (name) DO UPDATE SET value = excluded.value, expires = excluded.expires"
+}
+
+// Delete removes a key, if it exists.
+func (s *Store) Delete(key string) error {
+	_, err := s.DB.Exec(s.query("DELETE FROM "+s.Table+" WHERE name = ?"), key)
+
+	return err
+}
@@ -0,0 +1,178 @@
+// Package storetest checks that an implementation of
+// tgbotapi.KeyValueStore behaves as the library expects, so every
+// adapter can be tested the same way.
+package storetest
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Run tests a store. It should be empty, and is left with test values
+// under the "storetest:" prefix removed.
+func Run(t *testing.T, store tgbotapi.KeyValueStore) {
+	t.Run("Missing", func(t *testing.T) {
+		value, ok, err := store.Get("storetest:missing")
+		if err != nil || ok || value != nil {
+			t.Errorf("got %q, %v, %v for a missing key", value, ok, err)
+		}
+	})
+
+	t.Run("SetGetDelete", func(t *testing.T) {
+		key := "storetest:value"
+		defer store.Delete(key)
+
+		for _, value := range [][]byte{[]byte("first"), []byte("second"), {0, 1, 2, 255}} {
+			if err := store.Set(key, value, 0); err != nil {
+				t.Fatal(err)
+			}
+			expectValue(t, store, key, value)
+		}
+
+		if err := store.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := store.Get(key); ok || err != nil {
+			t.Errorf("key still exists after being deleted: %v", err)
+		}
+
+		if err := store.Delete(key); err != nil {
+			t.Errorf("deleting a missing key failed: %v", err)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		key := "storetest:empty"
+		defer store.Delete(key)
+
+		if err := store.Set(key, []byte{}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if value, ok, err := store.Get(key); !ok || err != nil || len(value) != 0 {
+			t.Errorf("got %q, %v, %v for an empty value", value, ok, err)
+		}
+	})
+
+	t.Run("Copied", func(t *testing.T) {
+		key := "storetest:copied"
+		defer store.Delete(key)
+
+		value := []byte("original")
+		if err := store.Set(key, value, 0); err != nil {
+			t.Fatal(err)
+		}
+		copy(value, "modified")
+
+		expectValue(t, store, key, []byte("original"))
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		key := "storetest:ttl"
+		defer store.Delete(key)
+
+		// Some stores only expire keys to the second.
+		if err := store.Set(key, []byte("expires"), time.Second); err != nil {
+			t.Fatal(err)
+		}
+		expectValue(t, store, key, []byte("expires"))
+
+		time.Sleep(1500 * time.Millisecond)
+
+		if _, ok, err := store.Get(key); ok || err != nil {
+			t.Errorf("key did not expire: %v", err)
+		}
+
+		if err := store.Set(key, []byte("kept"), 0); err != nil {
+			t.Fatal(err)
+		}
+		expectValue(t, store, key, []byte("kept"))
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				key := fmt.Sprintf("storetest:concurrent:%d", i)
+				defer store.Delete(key)
+
+				if err := store.Set(key, []byte(key), 0); err != nil {
+					errs <- err
+					return
+				}
+				value, ok, err := store.Get(key)
+				if err == nil && (!ok || string(value) != key) {
+					err = fmt.Errorf("got %q for %s", value, key)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}(i)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Error(err)
+		}
+	})
+
+	if adder, ok := store.(tgbotapi.KeyValueAdder); ok {
+		t.Run("Add", func(t *testing.T) {
+			key := "storetest:add"
+			defer store.Delete(key)
+
+			for i, want := range []bool{true, false, false} {
+				added, err := adder.Add(key, []byte(fmt.Sprint(i)), 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if added != want {
+					t.Errorf("add %d: expected added %t, got %t", i, want, added)
+				}
+			}
+			expectValue(t, store, key, []byte("0"))
+
+			if err := store.Delete(key); err != nil {
+				t.Fatal(err)
+			}
+			if added, err := adder.Add(key, []byte("again"), 0); !added || err != nil {
+				t.Errorf("expected a deleted key added again, got %t, %v", added, err)
+			}
+		})
+	}
+
+	t.Run("StateStore", func(t *testing.T) {
+		states := tgbotapi.NewStateStore(store)
+		defer states.SetState(-1, -1, "")
+
+		if err := states.SetState(-1, -1, "asking"); err != nil {
+			t.Fatal(err)
+		}
+		if state, err := states.GetState(-1, -1); state != "asking" || err != nil {
+			t.Errorf("got state %q, %v", state, err)
+		}
+	})
+}
+
+func expectValue(t *testing.T, store tgbotapi.KeyValueStore, key string, want []byte) {
+	t.Helper()
+
+	value, ok, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !bytes.Equal(value, want) {
+		t.Errorf("expected %q, got %q, %v", want, value, ok)
+	}
+}
@@ -0,0 +1,93 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/go-telegram-bot-api/telegram-bot-api/storage/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := tgbotapi.NewMemoryStore()
+
+	if _, ok, _ := store.Get("key"); ok {
+		t.Fail()
+	}
+
+	store.Set("key", []byte("value"), 0)
+	if value, ok, _ := store.Get("key"); !ok || string(value) != "value" {
+		t.Fail()
+	}
+
+	store.Delete("key")
+	if _, ok, _ := store.Get("key"); ok {
+		t.Fail()
+	}
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	store := tgbotapi.NewMemoryStore()
+
+	store.Set("key", []byte("value"), time.Millisecond)
+	time.Sleep(time.Millisecond * 5)
+
+	if _, ok, _ := store.Get("key"); ok {
+		t.Fail()
+	}
+}
+
+func TestStateStore(t *testing.T) {
+	states := tgbotapi.NewStateStore(tgbotapi.NewMemoryStore())
+
+	states.SetState(ChatID, 1, "waiting")
+	if state, _ := states.GetState(ChatID, 1); state != "waiting" {
+		t.Fail()
+	}
+	if state, _ := states.GetState(ChatID, 2); state != "" {
+		t.Fail()
+	}
+
+	states.SetState(ChatID, 1, "")
+	if state, _ := states.GetState(ChatID, 1); state != "" {
+		t.Fail()
+	}
+}
+
+func TestUpdatesChanOffsetStore(t *testing.T) {
+	store := tgbotapi.NewMemoryStore()
+	store.Set("offset", []byte("10"), 0)
+
+	offsets := make(chan string, 10)
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method != "getUpdates" {
+			return nil
+		}
+
+		offsets <- r.FormValue("offset")
+		if r.FormValue("offset") == "10" {
+			return []tgbotapi.Update{{UpdateID: 10}}
+		}
+		return []tgbotapi.Update{}
+	})
+
+	config := tgbotapi.NewUpdate(0)
+	config.OffsetStore = store
+	updates, err := bot.GetUpdatesChan(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if <-offsets != "10" || (<-updates).UpdateID != 10 || <-offsets != "11" {
+		t.Fail()
+	}
+
+	if value, _, _ := store.Get("offset"); string(value) != "11" {
+		t.Fail()
+	}
+}
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.Run(t, tgbotapi.NewMemoryStore())
+}
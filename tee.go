@@ -0,0 +1,154 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// UpdateTee forwards a copy of the JSON of every update it is given to
+// other consumers, such as an analytics pipeline or a new version of the
+// bot being tested against live traffic.
+//
+// Forwarding never holds up handling. Each consumer has its own queue,
+// and updates are dropped for a consumer which falls behind.
+type UpdateTee struct {
+	// Endpoints are URLs each update is POSTed to as JSON.
+	Endpoints []string
+	// Channels are sent each update's JSON.
+	Channels []chan<- json.RawMessage
+	// Client makes the requests to Endpoints. If it is nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+	// AfterHandling makes Middleware forward updates once they have been
+	// handled, rather than before.
+	AfterHandling bool
+	// QueueSize is how many updates may wait to be sent to each endpoint.
+	// If it is zero, 100 may.
+	QueueSize int
+
+	once    sync.Once
+	queues  []chan json.RawMessage
+	wg      sync.WaitGroup
+	dropped int64
+}
+
+const defaultTeeQueueSize = 100
+
+// NewUpdateTee creates an UpdateTee forwarding updates to endpoints.
+func NewUpdateTee(endpoints ...string) *UpdateTee {
+	return &UpdateTee{Endpoints: endpoints}
+}
+
+// start starts sending to each endpoint.
+func (t *UpdateTee) start() {
+	size := t.QueueSize
+	if size <= 0 {
+		size = defaultTeeQueueSize
+	}
+
+	for _, endpoint := range t.Endpoints {
+		queue := make(chan json.RawMessage, size)
+		t.queues = append(t.queues, queue)
+
+		t.wg.Add(1)
+		go func(endpoint string) {
+			defer t.wg.Done()
+
+			for data := range queue {
+				if err := t.post(endpoint, data); err != nil {
+					log.Println(err)
+				}
+			}
+		}(endpoint)
+	}
+}
+
+// post sends an update to an endpoint.
+func (t *UpdateTee) post(endpoint string, data []byte) error {
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("forwarding update to %s: %s", endpoint, resp.Status)
+	}
+
+	return nil
+}
+
+// Forward queues an update to be sent to every consumer, without
+// waiting for it to be sent.
+func (t *UpdateTee) Forward(update *Update) {
+	t.once.Do(t.start)
+
+	data := update.Raw()
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(update); err != nil {
+			return
+		}
+	}
+
+	for _, queue := range t.queues {
+		select {
+		case queue <- data:
+		default:
+			atomic.AddInt64(&t.dropped, 1)
+		}
+	}
+
+	for _, ch := range t.Channels {
+		select {
+		case ch <- data:
+		default:
+			atomic.AddInt64(&t.dropped, 1)
+		}
+	}
+}
+
+// Dropped returns how many times an update was not forwarded to a
+// consumer because it had fallen behind.
+func (t *UpdateTee) Dropped() int64 {
+	return atomic.LoadInt64(&t.dropped)
+}
+
+// Close sends any updates still queued for endpoints and stops
+// forwarding. Forward must not be called after Close.
+func (t *UpdateTee) Close() {
+	t.once.Do(t.start)
+
+	for _, queue := range t.queues {
+		close(queue)
+	}
+
+	t.wg.Wait()
+}
+
+// Middleware returns Middleware which forwards every update a Dispatcher
+// handles.
+func (t *UpdateTee) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *BotAPI, update Update) {
+			if !t.AfterHandling {
+				t.Forward(&update)
+			} else {
+				defer t.Forward(&update)
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestUpdateTee(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer server.Close()
+
+	ch := make(chan json.RawMessage, 1)
+	tee := tgbotapi.NewUpdateTee(server.URL)
+	tee.Channels = []chan<- json.RawMessage{ch}
+	tee.AfterHandling = true
+
+	raw := `{"update_id":4,"message":{"message_id":1,"text":"hi","chat":{"id":1},"new_field":true}}`
+	var update tgbotapi.Update
+	if err := json.Unmarshal([]byte(raw), &update); err != nil {
+		t.Fatal(err)
+	}
+
+	handled := false
+	d := tgbotapi.NewDispatcher(nil, func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+		handled = true
+		if len(ch) != 0 {
+			t.Error("update was forwarded before it was handled")
+		}
+	})
+	d.Use(tee.Middleware())
+	d.Dispatch(context.Background(), update)
+	tee.Close()
+
+	if !handled {
+		t.Error("update was not handled")
+	}
+	if got := string(<-ch); got != raw {
+		t.Errorf("channel got %s", got)
+	}
+	if got := <-received; got != raw {
+		t.Errorf("endpoint got %s", got)
+	}
+
+	// A full channel drops the update rather than blocking.
+	full := make(chan json.RawMessage)
+	tee = tgbotapi.NewUpdateTee()
+	tee.Channels = []chan<- json.RawMessage{full}
+	tee.Forward(&update)
+
+	if tee.Dropped() != 1 {
+		t.Errorf("expected 1 dropped update, got %d", tee.Dropped())
+	}
+}
@@ -0,0 +1,199 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// MessageTemplates renders messages from text/template templates, so
+// message layouts can be kept out of code.
+//
+// Every value a template prints is escaped for ParseMode, so user input
+// can't break or inject formatting. Formatting in the template itself is
+// left alone, and a value already formatted can be printed with raw.
+//
+// Templates may also add inline keyboard buttons to the message, with
+// button, urlButton and row:
+//
+//	Hello, <b>{{.Name}}</b>!
+//	{{- button "Settings" "settings" -}}
+//	{{- urlButton "Website" .URL -}}
+//	{{- row -}}
+//	{{- button "Close" "close" -}}
+type MessageTemplates struct {
+	ParseMode string
+
+	mu      sync.Mutex
+	tmpl    *template.Template
+	escaped map[*parse.Tree]bool
+}
+
+// RenderedMessage is a message rendered by MessageTemplates.
+type RenderedMessage struct {
+	Text      string
+	ParseMode string
+	// Keyboard is the inline keyboard added by the template, or nil if
+	// it didn't add one.
+	Keyboard *InlineKeyboardMarkup
+}
+
+// NewMessage creates a message to send the rendered message to a chat.
+func (m RenderedMessage) NewMessage(chatID int64) MessageConfig {
+	msg := NewMessage(chatID, m.Text)
+	msg.ParseMode = m.ParseMode
+	if m.Keyboard != nil {
+		msg.ReplyMarkup = *m.Keyboard
+	}
+
+	return msg
+}
+
+// rawText is text which is printed without being escaped.
+type rawText string
+
+// NewMessageTemplates creates MessageTemplates for messages sent in a
+// parse mode.
+func NewMessageTemplates(parseMode string) *MessageTemplates {
+	t := &MessageTemplates{
+		ParseMode: parseMode,
+		escaped:   make(map[*parse.Tree]bool),
+	}
+
+	t.tmpl = template.New("").Funcs(template.FuncMap{
+		"escapeValue": t.escapeValue,
+		"raw":         func(s string) rawText { return rawText(s) },
+		// The keyboard functions are replaced for each message rendered.
+		"button":    func(text, data string) string { return "" },
+		"urlButton": func(text, url string) string { return "" },
+		"row":       func() string { return "" },
+	})
+
+	return t
+}
+
+// Parse adds a named template, along with any templates it defines.
+func (t *MessageTemplates) Parse(name, text string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.tmpl.New(name).Parse(text); err != nil {
+		return err
+	}
+
+	for _, tmpl := range t.tmpl.Templates() {
+		if tmpl.Tree == nil || t.escaped[tmpl.Tree] {
+			continue
+		}
+
+		escapeNode(tmpl.Tree.Root)
+		t.escaped[tmpl.Tree] = true
+	}
+
+	return nil
+}
+
+// Render renders a named template with data.
+func (t *MessageTemplates) Render(name string, data interface{}) (RenderedMessage, error) {
+	t.mu.Lock()
+	tmpl, err := t.tmpl.Clone()
+	t.mu.Unlock()
+	if err != nil {
+		return RenderedMessage{}, err
+	}
+
+	var keyboard [][]InlineKeyboardButton
+	addButton := func(button InlineKeyboardButton) string {
+		if len(keyboard) == 0 {
+			keyboard = append(keyboard, nil)
+		}
+		keyboard[len(keyboard)-1] = append(keyboard[len(keyboard)-1], button)
+
+		return ""
+	}
+
+	tmpl.Funcs(template.FuncMap{
+		"button": func(text, data string) string {
+			return addButton(NewInlineKeyboardButtonData(text, data))
+		},
+		"urlButton": func(text, url string) string {
+			return addButton(NewInlineKeyboardButtonURL(text, url))
+		},
+		"row": func() string {
+			if len(keyboard) > 0 && len(keyboard[len(keyboard)-1]) > 0 {
+				keyboard = append(keyboard, nil)
+			}
+			return ""
+		},
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return RenderedMessage{}, err
+	}
+
+	msg := RenderedMessage{
+		Text:      strings.TrimSpace(buf.String()),
+		ParseMode: t.ParseMode,
+	}
+	if msg.Text == "" {
+		return RenderedMessage{}, errors.New("template " + name + " rendered an empty message")
+	}
+
+	if len(keyboard) > 0 && len(keyboard[len(keyboard)-1]) == 0 {
+		keyboard = keyboard[:len(keyboard)-1]
+	}
+	if len(keyboard) > 0 {
+		markup := NewInlineKeyboardMarkup(keyboard...)
+		msg.Keyboard = &markup
+	}
+
+	return msg, nil
+}
+
+// escapeValue escapes a value printed by a template.
+func (t *MessageTemplates) escapeValue(value interface{}) string {
+	if raw, ok := value.(rawText); ok {
+		return string(raw)
+	}
+
+	return EscapeText(t.ParseMode, fmt.Sprint(value))
+}
+
+// escapeNode adds escapeValue to the end of every pipeline in a template
+// which prints a value.
+func escapeNode(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeNode(child)
+		}
+	case *parse.ActionNode:
+		// Declarations such as {{$x := .Y}} don't print anything.
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier("escapeValue").SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeNode(n.List)
+		escapeNode(n.ElseList)
+	case *parse.RangeNode:
+		escapeNode(n.List)
+		escapeNode(n.ElseList)
+	case *parse.WithNode:
+		escapeNode(n.List)
+		escapeNode(n.ElseList)
+	}
+}
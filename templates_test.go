@@ -0,0 +1,72 @@
+package tgbotapi_test
+
+import (
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestMessageTemplates(t *testing.T) {
+	templates := tgbotapi.NewMessageTemplates(tgbotapi.ModeHTML)
+
+	err := templates.Parse("order", `
+{{define "total"}}<i>{{.}}</i>{{end -}}
+Order for <b>{{.Name}}</b>:
+{{range .Items}}- {{.}}
+{{end -}}
+Total: {{template "total" .Total}}{{$note := .Note}} {{raw $note}}
+{{- button "Pay" "pay:1" -}}
+{{- button "Cancel" "cancel:1" -}}
+{{- row -}}
+{{- urlButton "Help" "https://example.com/help" -}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := templates.Render("order", map[string]interface{}{
+		"Name":  "<script>",
+		"Items": []string{"Tea & cake", "Coffee"},
+		"Total": "<5>",
+		"Note":  "<u>fast</u>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Order for <b>&lt;script&gt;</b>:\n- Tea &amp; cake\n- Coffee\nTotal: <i>&lt;5&gt;</i> <u>fast</u>"
+	if msg.Text != expected {
+		t.Errorf("expected %q, got %q", expected, msg.Text)
+	}
+
+	keyboard := msg.Keyboard
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 2 || len(keyboard.InlineKeyboard[0]) != 2 || *keyboard.InlineKeyboard[1][0].URL != "https://example.com/help" {
+		t.Fatalf("got keyboard %+v", keyboard)
+	}
+
+	config := msg.NewMessage(ChatID)
+	if config.ParseMode != tgbotapi.ModeHTML || config.ReplyMarkup == nil {
+		t.Errorf("got config %+v", config)
+	}
+
+	// Rendering again doesn't keep the previous keyboard.
+	if err := templates.Parse("plain", "Hi {{.}}"); err != nil {
+		t.Fatal(err)
+	}
+	msg, err = templates.Render("plain", "*you*")
+	if err != nil || msg.Keyboard != nil || msg.Text != "Hi *you*" {
+		t.Errorf("got %+v, %v", msg, err)
+	}
+}
+
+func TestMessageTemplatesMarkdownV2(t *testing.T) {
+	templates := tgbotapi.NewMessageTemplates(tgbotapi.ModeMarkdownV2)
+	if err := templates.Parse("hi", "*Hi* {{.}}"); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := templates.Render("hi", "a_b.c")
+	if err != nil || msg.Text != `*Hi* a\_b\.c` {
+		t.Errorf("got %q, %v", msg.Text, err)
+	}
+}
@@ -0,0 +1,105 @@
+package tgbotapi
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestTiming is how long each part of a request to the API took, so
+// slowness at Telegram's end can be told apart from the local network.
+//
+// DNS, Connect, and TLS are zero when an existing connection was reused.
+type RequestTiming struct {
+	Method string
+
+	DNS     time.Duration // looking up the API's address
+	Connect time.Duration // opening a TCP connection
+	TLS     time.Duration // the TLS handshake
+	// TTFB is the time to the first byte of the response, from when the
+	// request was written, which is mostly the time Telegram took.
+	TTFB  time.Duration
+	Total time.Duration // the whole request, including reading the response
+
+	ReusedConn bool
+	// Err is the error making the request, if there was one. It is not
+	// set for errors returned by the API.
+	Err error
+}
+
+// requestTimer records the timing of a request with httptrace. Its
+// hooks may be called from other goroutines, such as one dialing a new
+// connection, so it is locked.
+type requestTimer struct {
+	mu     sync.Mutex
+	timing RequestTiming
+
+	start, dnsStart, connectStart, tlsStart, wrote time.Time
+}
+
+func (t *requestTimer) locked(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f()
+}
+
+// traceRequest adds tracing to a request if OnRequestTiming is set. The
+// returned function must be called once the response has been read.
+func (bot *BotAPI) traceRequest(endpoint string, req *http.Request) (*http.Request, func(err error)) {
+	if bot.OnRequestTiming == nil {
+		return req, func(error) {}
+	}
+
+	t := &requestTimer{start: time.Now()}
+	t.timing.Method = endpoint
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.locked(func() { t.timing.ReusedConn = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.locked(func() { t.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.locked(func() { t.timing.DNS = time.Since(t.dnsStart) })
+		},
+		ConnectStart: func(network, addr string) {
+			t.locked(func() { t.connectStart = time.Now() })
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.locked(func() { t.timing.Connect = time.Since(t.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			t.locked(func() { t.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.locked(func() { t.timing.TLS = time.Since(t.tlsStart) })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.locked(func() { t.wrote = time.Now() })
+		},
+		GotFirstResponseByte: func() {
+			t.locked(func() {
+				if !t.wrote.IsZero() {
+					t.timing.TTFB = time.Since(t.wrote)
+				}
+			})
+		},
+	}
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	return req, func(err error) {
+		var timing RequestTiming
+		t.locked(func() {
+			t.timing.Total = time.Since(t.start)
+			t.timing.Err = err
+			timing = t.timing
+		})
+
+		bot.OnRequestTiming(timing)
+	}
+}
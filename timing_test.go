@@ -0,0 +1,44 @@
+package tgbotapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestOnRequestTiming(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "sendMessage" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return nil
+	})
+
+	var timings []tgbotapi.RequestTiming
+	bot.OnRequestTiming = func(timing tgbotapi.RequestTiming) {
+		timings = append(timings, timing)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := bot.Send(tgbotapi.NewMessage(ChatID, "hi")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(timings) != 2 {
+		t.Fatalf("got %d timings", len(timings))
+	}
+
+	timing := timings[1]
+	if timing.Method != "sendMessage" || timing.Err != nil {
+		t.Errorf("got timing %+v", timing)
+	}
+	if timing.TTFB < 20*time.Millisecond || timing.Total < timing.TTFB {
+		t.Errorf("unexpected TTFB %s of %s", timing.TTFB, timing.Total)
+	}
+	if !timing.ReusedConn || timing.Connect != 0 {
+		t.Errorf("expected the connection to be reused, got %+v", timing)
+	}
+}
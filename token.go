@@ -0,0 +1,80 @@
+package tgbotapi
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Token is a bot's token. It is printed redacted, keeping only the bot's
+// ID, so logging a BotAPI or a request doesn't leak it. Convert it to a
+// string for the token itself.
+type Token string
+
+// redactedToken replaces the secret part of a token.
+const redactedToken = "[redacted]"
+
+// String returns the token redacted, such as "123456:[redacted]".
+func (t Token) String() string {
+	if i := strings.IndexByte(string(t), ':'); i != -1 {
+		return string(t[:i+1]) + redactedToken
+	}
+
+	return redactedToken
+}
+
+// Format writes the token redacted for every verb, so it isn't leaked by
+// %q, %x or %#v either.
+func (t Token) Format(f fmt.State, verb rune) {
+	io.WriteString(f, t.String())
+}
+
+// redact replaces the token in s.
+func (t Token) redact(s string) string {
+	if t == "" {
+		return s
+	}
+
+	return strings.Replace(s, string(t), t.String(), -1)
+}
+
+// redactError removes the bot's token from an error, such as one from
+// the HTTP client, which includes the URL of the request.
+func (bot *BotAPI) redactError(err error) error {
+	if err == nil || bot.Token == "" || !strings.Contains(err.Error(), string(bot.Token)) {
+		return err
+	}
+
+	if urlErr, ok := err.(*url.Error); ok {
+		return &url.Error{
+			Op:  urlErr.Op,
+			URL: bot.Token.redact(urlErr.URL),
+			Err: bot.redactError(urlErr.Err),
+		}
+	}
+
+	return &redactedError{err: err, message: bot.Token.redact(err.Error())}
+}
+
+// redactedError is an error with a token removed from its message.
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the original error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// secretsEqual compares secrets in constant time, so how long it takes
+// doesn't tell how much of a guess was right.
+func secretsEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,110 @@
+package tgbotapi_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestTokenRedacted(t *testing.T) {
+	token := tgbotapi.Token(TestToken)
+
+	for _, format := range []string{"%s", "%v", "%q", "%x", "%#v", "%+v"} {
+		printed := fmt.Sprintf(format, token)
+		if strings.Contains(printed, "AAHl") {
+			t.Errorf("%s leaked the token: %s", format, printed)
+		}
+	}
+
+	if token.String() != "153667468:[redacted]" {
+		t.Errorf("expected the bot ID kept, got %s", token)
+	}
+
+	printed := fmt.Sprintf("%+v", tgbotapi.BotAPI{Token: token})
+	if strings.Contains(printed, "AAHl") {
+		t.Errorf("printing a BotAPI leaked the token: %s", printed)
+	}
+}
+
+// failingTransport fails every request.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRequestErrorRedacted(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} { return nil })
+	bot.Token = TestToken
+	bot.Client = &http.Client{Transport: failingTransport{}}
+
+	_, err := bot.Send(tgbotapi.NewMessage(ChatID, "hi"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if strings.Contains(err.Error(), "AAHl") {
+		t.Errorf("error leaked the token: %v", err)
+	}
+	if !strings.Contains(err.Error(), "153667468:[redacted]/sendMessage") {
+		t.Errorf("expected the redacted URL in %v", err)
+	}
+}
+
+func TestWebhookSecretToken(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} { return nil })
+	bot.WebhookSecretToken = "secret"
+
+	handler, updates := bot.WebhookHandler()
+
+	for _, test := range []struct {
+		secret string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+		{"secret", http.StatusOK},
+	} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"update_id":1}`))
+		if test.secret != "" {
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", test.secret)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != test.status {
+			t.Errorf("secret %q: expected status %d, got %d", test.secret, test.status, w.Code)
+		}
+	}
+
+	if len(updates) != 1 {
+		t.Errorf("expected only the update with the secret delivered, got %d", len(updates))
+	}
+}
+
+func TestSetWebhookSecretToken(t *testing.T) {
+	var secret string
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		if method == "setWebhook" {
+			secret = r.FormValue("secret_token")
+			return true
+		}
+		return nil
+	})
+
+	config := tgbotapi.NewWebhook("https://example.com/hook")
+	config.SecretToken = "secret"
+
+	if _, err := bot.SetWebhook(config); err != nil {
+		t.Fatal(err)
+	}
+	if secret != "secret" {
+		t.Errorf("expected the secret token sent, got %q", secret)
+	}
+}
@@ -0,0 +1,126 @@
+package tgbotapi
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the connections made to the Bot API.
+//
+// A bot makes many small requests to the same host, so keeping enough
+// idle connections open avoids paying for a TLS handshake on each one.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is how many idle connections are kept open to
+	// the API. Go's default of 2 causes connections to be closed and
+	// opened again as soon as a few requests run at once.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits how many connections are made to the API.
+	// If it is zero, there is no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout limits how long a TLS handshake may take.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits how long to wait for a response after
+	// sending a request. It must be longer than the timeout of long
+	// polling with GetUpdates, so it is not set by default.
+	ResponseHeaderTimeout time.Duration
+	// DisableHTTP2 makes requests use HTTP/1.1, which the API also
+	// supports.
+	DisableHTTP2 bool
+	// TLSConfig configures TLS, such as a minimum version, RootCAs for a
+	// private CA or TLS-intercepting proxy in front of a local Bot API
+	// server, or Certificates to present as a client. It is cloned, and
+	// Go's defaults are used if it is nil.
+	TLSConfig *tls.Config
+}
+
+// DefaultTransportConfig is used by NewBotAPI, and for any fields of a
+// TransportConfig that are zero.
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConnsPerHost: 32,
+	IdleConnTimeout:     90 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
+
+// NewTransport creates an http.Transport for the API from Go's default
+// transport, so proxies from the environment are still used.
+func NewTransport(config TransportConfig) *http.Transport {
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = DefaultTransportConfig.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = DefaultTransportConfig.IdleConnTimeout
+	}
+	if config.TLSHandshakeTimeout == 0 {
+		config.TLSHandshakeTimeout = DefaultTransportConfig.TLSHandshakeTimeout
+	}
+	if config.ResponseHeaderTimeout == 0 {
+		config.ResponseHeaderTimeout = DefaultTransportConfig.ResponseHeaderTimeout
+	}
+	if config.MaxConnsPerHost == 0 {
+		config.MaxConnsPerHost = DefaultTransportConfig.MaxConnsPerHost
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	if transport.MaxIdleConns < config.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = config.MaxIdleConnsPerHost
+	}
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = config.IdleConnTimeout
+	transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+	if config.TLSConfig != nil {
+		transport.TLSClientConfig = config.TLSConfig.Clone()
+	} else if DefaultTransportConfig.TLSConfig != nil {
+		transport.TLSClientConfig = DefaultTransportConfig.TLSConfig.Clone()
+	}
+
+	transport.ForceAttemptHTTP2 = !config.DisableHTTP2 && !DefaultTransportConfig.DisableHTTP2
+	if !transport.ForceAttemptHTTP2 {
+		// A non-nil, empty map is how HTTP/2 is turned off.
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport
+}
+
+// NewHTTPClient creates an http.Client for the API using NewTransport.
+//
+// Pass it to NewBotAPIWithClient to tune the connections a bot makes.
+func NewHTTPClient(config TransportConfig) *http.Client {
+	return &http.Client{Transport: NewTransport(config)}
+}
+
+// WithTLSConfig makes a bot's requests use a TLS config, as described
+// for TransportConfig.TLSConfig. The bot is given a copy of its client
+// with a copy of its transport, so a client shared with other code is
+// left alone.
+//
+// It only applies to clients whose Transport is an *http.Transport, or
+// nil for Go's default; build the transport with NewTransport
+// otherwise.
+func WithTLSConfig(config *tls.Config) BotOption {
+	return func(bot *BotAPI) {
+		client := http.Client{}
+		if bot.Client != nil {
+			client = *bot.Client
+		}
+
+		var transport *http.Transport
+		switch t := client.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return
+		}
+		transport.TLSClientConfig = config.Clone()
+
+		client.Transport = transport
+		bot.Client = &client
+	}
+}
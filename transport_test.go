@@ -0,0 +1,78 @@
+package tgbotapi_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport := tgbotapi.NewTransport(tgbotapi.TransportConfig{})
+
+	if transport.MaxIdleConnsPerHost != tgbotapi.DefaultTransportConfig.MaxIdleConnsPerHost {
+		t.Errorf("expected %d idle connections, got %d", tgbotapi.DefaultTransportConfig.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != tgbotapi.DefaultTransportConfig.IdleConnTimeout {
+		t.Errorf("unexpected idle timeout %s", transport.IdleConnTimeout)
+	}
+	if !transport.ForceAttemptHTTP2 || transport.TLSNextProto != nil {
+		t.Error("expected HTTP/2 to be enabled")
+	}
+	if transport.Proxy == nil {
+		t.Error("expected proxies from the environment to be used")
+	}
+
+	transport = tgbotapi.NewTransport(tgbotapi.TransportConfig{
+		MaxIdleConnsPerHost: 200,
+		IdleConnTimeout:     time.Minute,
+		DisableHTTP2:        true,
+	})
+
+	if transport.MaxIdleConnsPerHost != 200 || transport.MaxIdleConns < 200 {
+		t.Errorf("unexpected idle connections %d, %d", transport.MaxIdleConnsPerHost, transport.MaxIdleConns)
+	}
+	if transport.IdleConnTimeout != time.Minute {
+		t.Errorf("unexpected idle timeout %s", transport.IdleConnTimeout)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+}
+
+func TestTransportTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := tgbotapi.NewHTTPClient(tgbotapi.TransportConfig{}).Get(server.URL); err == nil {
+		t.Error("expected an untrusted certificate to fail")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	config := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+	resp, err := tgbotapi.NewHTTPClient(tgbotapi.TransportConfig{TLSConfig: config}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	client := tgbotapi.NewHTTPClient(tgbotapi.TransportConfig{})
+	bot := &tgbotapi.BotAPI{Client: client}
+	tgbotapi.WithTLSConfig(config)(bot)
+
+	if bot.Client == client || bot.Client.Transport == client.Transport {
+		t.Error("expected the client passed in to be left alone")
+	}
+
+	resp, err = bot.Client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
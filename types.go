@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf16"
 )
 
 // APIResponse is a response from the Telegram API with the result
@@ -25,6 +29,51 @@ type ResponseParameters struct {
 	RetryAfter      int   `json:"retry_after"`        // optional
 }
 
+// RawResponse is a response from the Telegram API before it is decoded.
+//
+// Params does not include uploaded files.
+type RawResponse struct {
+	Method     string
+	Params     url.Values
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Error is an error returned by the Telegram API, with any parameters
+// describing how it may be resolved.
+type Error struct {
+	Code    int
+	Message string
+	ResponseParameters
+}
+
+// Error returns the description of the error given by Telegram.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsMessageNotModified returns if err is an Error from editing a message
+// without changing it.
+func IsMessageNotModified(err error) bool {
+	apiErr, ok := err.(*Error)
+
+	return ok && strings.Contains(apiErr.Message, ErrMessageNotModified)
+}
+
+// newError creates an Error from an unsuccessful APIResponse.
+func newError(resp APIResponse) *Error {
+	err := &Error{
+		Code:    resp.ErrorCode,
+		Message: resp.Description,
+	}
+	if resp.Parameters != nil {
+		err.ResponseParameters = *resp.Parameters
+	}
+
+	return err
+}
+
 // This object represents an incoming update.
 // Only one of the optional parameters can be present in any given update.
 type Update struct {
@@ -34,13 +83,254 @@ type Update struct {
 	// 	This ID becomes especially handy if you’re using Webhooks,
 	// 	since it allows you to ignore repeated updates or to restore
 	// 	the correct update sequence, should they get out of order.
-	Message            *Message            `json:"message"` // Optional. New incoming message of any kind — text, photo, sticker, etc.
-	EditedMessage      *Message            `json:"edited_message"`
-	ChannelPost        *Message            `json:"channel_post"`
-	EditedChannelPost  *Message            `json:"edited_channel_post"`
-	InlineQuery        *InlineQuery        `json:"inline_query"`         // Optional. New incoming inline query
-	ChosenInlineResult *ChosenInlineResult `json:"chosen_inline_result"` // Optional. The result of an inline query that was chosen by a user and sent to their chat partner.
-	CallbackQuery      *CallbackQuery      `json:"callback_query"`       // Optional. New incoming callback query
+	Message            *Message            `json:"message,omitempty"` // Optional. New incoming message of any kind — text, photo, sticker, etc.
+	EditedMessage      *Message            `json:"edited_message,omitempty"`
+	ChannelPost        *Message            `json:"channel_post,omitempty"`
+	EditedChannelPost  *Message            `json:"edited_channel_post,omitempty"`
+	InlineQuery        *InlineQuery        `json:"inline_query,omitempty"`         // Optional. New incoming inline query
+	ChosenInlineResult *ChosenInlineResult `json:"chosen_inline_result,omitempty"` // Optional. The result of an inline query that was chosen by a user and sent to their chat partner.
+	CallbackQuery      *CallbackQuery      `json:"callback_query,omitempty"`       // Optional. New incoming callback query
+	Poll               *Poll               `json:"poll,omitempty"`                 // Optional. New poll state, for polls sent by the bot or stopped
+	PollAnswer         *PollAnswer         `json:"poll_answer,omitempty"`          // Optional. A user changed their answer in a non-anonymous poll
+	ShippingQuery      *ShippingQuery      `json:"shipping_query,omitempty"`       // Optional. New incoming shipping query, only for invoices with flexible price
+	PreCheckoutQuery   *PreCheckoutQuery   `json:"pre_checkout_query,omitempty"`   // Optional. New incoming pre-checkout query, with full information about checkout
+	ChatJoinRequest    *ChatJoinRequest    `json:"chat_join_request,omitempty"`    // Optional. A request to join a chat the bot can approve
+	MyChatMember       *ChatMemberUpdated  `json:"my_chat_member,omitempty"`       // Optional. The bot's status in a chat was changed
+	ChatMember         *ChatMemberUpdated  `json:"chat_member,omitempty"`          // Optional. A member's status in a chat was changed, if the bot is an administrator and asked for chat_member updates
+	ChatBoost          *ChatBoostUpdated   `json:"chat_boost,omitempty"`           // Optional. A chat was boosted or a boost was changed, if the bot is an administrator
+	RemovedChatBoost   *ChatBoostRemoved   `json:"removed_chat_boost,omitempty"`   // Optional. A boost was removed from a chat, if the bot is an administrator
+
+	raw json.RawMessage
+}
+
+// Raw returns the JSON the update was decoded from, so fields not yet
+// supported by this library can be read. It is nil if the update was not
+// decoded from JSON.
+func (u *Update) Raw() json.RawMessage {
+	return u.raw
+}
+
+// UnmarshalJSON decodes an update, keeping a copy of its JSON for Raw.
+func (u *Update) UnmarshalJSON(data []byte) error {
+	type update Update
+	if err := json.Unmarshal(data, (*update)(u)); err != nil {
+		return err
+	}
+
+	u.raw = append(json.RawMessage(nil), data...)
+
+	return nil
+}
+
+// MarshalJSON encodes an update. Fields of the JSON it was decoded from
+// which are not supported by this library are kept, so a received
+// update encodes back to the same payload. Unsupported fields of nested
+// objects other than messages are lost.
+func (u Update) MarshalJSON() ([]byte, error) {
+	type update Update
+	return marshalWithRaw(update(u), u.raw)
+}
+
+// marshalWithRaw encodes v, a struct, adding any fields from raw which v
+// has no field for.
+func marshalWithRaw(v interface{}, raw json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || raw == nil {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return data, nil
+	}
+
+	t := reflect.TypeOf(v)
+	for key := range fields {
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if name != "" && strings.EqualFold(name, key) {
+				delete(fields, key)
+				break
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+// Kind returns which kind of update this is, or an empty string if it
+// has no field known to this library.
+func (u Update) Kind() UpdateType {
+	switch {
+	case u.Message != nil:
+		return UpdateTypeMessage
+	case u.EditedMessage != nil:
+		return UpdateTypeEditedMessage
+	case u.ChannelPost != nil:
+		return UpdateTypeChannelPost
+	case u.EditedChannelPost != nil:
+		return UpdateTypeEditedChannelPost
+	case u.InlineQuery != nil:
+		return UpdateTypeInlineQuery
+	case u.ChosenInlineResult != nil:
+		return UpdateTypeChosenInlineResult
+	case u.CallbackQuery != nil:
+		return UpdateTypeCallbackQuery
+	case u.Poll != nil:
+		return UpdateTypePoll
+	case u.PollAnswer != nil:
+		return UpdateTypePollAnswer
+	case u.ShippingQuery != nil:
+		return UpdateTypeShippingQuery
+	case u.PreCheckoutQuery != nil:
+		return UpdateTypePreCheckoutQuery
+	case u.ChatJoinRequest != nil:
+		return UpdateTypeChatJoinRequest
+	case u.MyChatMember != nil:
+		return UpdateTypeMyChatMember
+	case u.ChatMember != nil:
+		return UpdateTypeChatMember
+	case u.ChatBoost != nil:
+		return UpdateTypeChatBoost
+	case u.RemovedChatBoost != nil:
+		return UpdateTypeRemovedChatBoost
+	}
+
+	return ""
+}
+
+// updateStringTextLength is how many characters of text String includes
+// before truncating it.
+const updateStringTextLength = 32
+
+// String summarizes an update for logging, with its kind, chat, user and
+// the start of any text, such as
+// `update 42 (message) chat=123 user=bob text="hello"`.
+func (u Update) String() string {
+	kind := u.Kind()
+	if kind == "" {
+		kind = "unknown"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "update %d (%s)", u.UpdateID, kind)
+
+	if chat := u.FromChat(); chat != nil {
+		fmt.Fprintf(&b, " chat=%d", chat.ID)
+	}
+	if user := u.SentFrom(); user != nil {
+		fmt.Fprintf(&b, " user=%s", user.String())
+	}
+
+	var text string
+	switch {
+	case u.CallbackQuery != nil:
+		text = u.CallbackQuery.Data
+	case u.InlineQuery != nil:
+		text = u.InlineQuery.Query
+	case u.ChosenInlineResult != nil:
+		text = u.ChosenInlineResult.Query
+	case u.Poll != nil:
+		text = u.Poll.Question
+	case u.EffectiveMessage() != nil:
+		text = u.EffectiveMessage().Text
+		if text == "" {
+			text = u.EffectiveMessage().Caption
+		}
+	}
+	if text != "" {
+		if runes := []rune(text); len(runes) > updateStringTextLength {
+			text = string(runes[:updateStringTextLength]) + "…"
+		}
+		fmt.Fprintf(&b, " text=%q", text)
+	}
+
+	return b.String()
+}
+
+// EffectiveMessage returns the message the update is about, whichever
+// field it is in, or nil if there is none. For callback queries, it is
+// the message with the button that was pressed, if there is one.
+func (u *Update) EffectiveMessage() *Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.Message
+	}
+
+	return nil
+}
+
+// SentFrom returns the user who caused the update, or nil if there is
+// none, such as for channel posts.
+func (u *Update) SentFrom() *User {
+	switch {
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From
+	case u.ChosenInlineResult != nil:
+		return u.ChosenInlineResult.From
+	case u.PollAnswer != nil:
+		return u.PollAnswer.User
+	case u.ShippingQuery != nil:
+		return u.ShippingQuery.From
+	case u.PreCheckoutQuery != nil:
+		return u.PreCheckoutQuery.From
+	case u.ChatJoinRequest != nil:
+		return u.ChatJoinRequest.From
+	case u.MyChatMember != nil:
+		return u.MyChatMember.From
+	case u.ChatMember != nil:
+		return u.ChatMember.From
+	case u.ChatBoost != nil:
+		return u.ChatBoost.Boost.Source.booster()
+	case u.RemovedChatBoost != nil:
+		return u.RemovedChatBoost.Source.booster()
+	}
+
+	if message := u.EffectiveMessage(); message != nil {
+		return message.From
+	}
+
+	return nil
+}
+
+// FromChat returns the chat the update came from, or nil if there is
+// none, such as for inline queries.
+func (u *Update) FromChat() *Chat {
+	if message := u.EffectiveMessage(); message != nil {
+		return message.Chat
+	}
+	switch {
+	case u.ChatJoinRequest != nil:
+		return u.ChatJoinRequest.Chat
+	case u.MyChatMember != nil:
+		return u.MyChatMember.Chat
+	case u.ChatMember != nil:
+		return u.ChatMember.Chat
+	case u.ChatBoost != nil:
+		return u.ChatBoost.Chat
+	case u.RemovedChatBoost != nil:
+		return u.RemovedChatBoost.Chat
+	}
+
+	return nil
 }
 
 // UpdatesChannel is the channel for getting updates.
@@ -53,12 +343,24 @@ func (ch UpdatesChannel) Clear() {
 	}
 }
 
+// UpdatesPtrChannel is the channel for getting pointers to updates.
+type UpdatesPtrChannel <-chan *Update
+
+// Clear discards all unprocessed incoming updates.
+func (ch UpdatesPtrChannel) Clear() {
+	for len(ch) != 0 {
+		<-ch
+	}
+}
+
 // User is a user on Telegram.
 type User struct {
-	ID        int    `json:"id"`         // Unique identifier for this user or bot
-	FirstName string `json:"first_name"` // User‘s or bot’s first name
-	LastName  string `json:"last_name"`  // Optional. User‘s or bot’s last name
-	UserName  string `json:"username"`   // Optional. User‘s or bot’s username
+	ID           int64  `json:"id"`                      // Unique identifier for this user or bot
+	FirstName    string `json:"first_name"`              // User‘s or bot’s first name
+	LastName     string `json:"last_name,omitempty"`     // Optional. User‘s or bot’s last name
+	UserName     string `json:"username,omitempty"`      // Optional. User‘s or bot’s username
+	IsBot        bool   `json:"is_bot"`                  // True, if this user is a bot
+	LanguageCode string `json:"language_code,omitempty"` // Optional. IETF language tag of the user's language
 }
 
 // String displays a simple text version of a user.
@@ -70,6 +372,10 @@ func (u *User) String() string {
 		return u.UserName
 	}
 
+	return u.fullName()
+}
+
+func (u *User) fullName() string {
 	name := u.FirstName
 	if u.LastName != "" {
 		name += " " + u.LastName
@@ -78,41 +384,80 @@ func (u *User) String() string {
 	return name
 }
 
+// MentionHTML returns a clickable mention of the user for ModeHTML. It is
+// their @username if they have one, or else their name linking to them.
+func (u *User) MentionHTML() string {
+	if u.UserName != "" {
+		return "@" + EscapeText(ModeHTML, u.UserName)
+	}
+
+	return fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, u.ID, EscapeText(ModeHTML, u.fullName()))
+}
+
+// MentionMarkdownV2 returns a clickable mention of the user for
+// ModeMarkdownV2. It is their @username if they have one, or else their
+// name linking to them.
+func (u *User) MentionMarkdownV2() string {
+	if u.UserName != "" {
+		return "@" + EscapeText(ModeMarkdownV2, u.UserName)
+	}
+
+	return fmt.Sprintf("[%s](tg://user?id=%d)", EscapeText(ModeMarkdownV2, u.fullName()), u.ID)
+}
+
+// MentionEntity returns the user's name and a text_mention entity making
+// it a mention when the name is placed at offset in a message's text.
+// Offset is in UTF-16 code units, as are all entity offsets.
+//
+// Unlike the other mentions, this needs no escaping and works with no
+// parse mode set.
+func (u *User) MentionEntity(offset int) (string, MessageEntity) {
+	name := u.fullName()
+
+	return name, MessageEntity{
+		Type:   EntityTypeTextMention,
+		Offset: offset,
+		Length: len(utf16.Encode([]rune(name))),
+		User:   u,
+	}
+}
+
 // GroupChat is a group chat.
 type GroupChat struct {
-	ID    int    `json:"id"`
+	ID    int64  `json:"id"`
 	Title string `json:"title"`
 }
 
 // This object represents a chat.
 type Chat struct {
-	ID                  int64  `json:"id"`                             // Unique identifier for this chat, not exceeding 1e13 by absolute value
-	Type                string `json:"type"`                           // Type of chat, can be either “private”, “group”, “supergroup” or “channel”
-	Title               string `json:"title"`                          // Optional. Title, for channels and group chats
-	UserName            string `json:"username"`                       // Optional. Username, for private chats and channels if available
-	FirstName           string `json:"first_name"`                     // Optional. First name of the other party in a private chat
-	LastName            string `json:"last_name"`                      // Optional. Last name of the other party in a private chat
-	AllMembersAreAdmins bool   `json:"all_members_are_administrators"` // optional
+	ID                  int64            `json:"id"`                                       // Unique identifier for this chat, not exceeding 1e13 by absolute value
+	Type                ChatType         `json:"type"`                                     // Type of chat, can be either “private”, “group”, “supergroup” or “channel”
+	Title               string           `json:"title,omitempty"`                          // Optional. Title, for channels and group chats
+	UserName            string           `json:"username,omitempty"`                       // Optional. Username, for private chats and channels if available
+	FirstName           string           `json:"first_name,omitempty"`                     // Optional. First name of the other party in a private chat
+	LastName            string           `json:"last_name,omitempty"`                      // Optional. Last name of the other party in a private chat
+	AllMembersAreAdmins bool             `json:"all_members_are_administrators,omitempty"` // optional
+	Permissions         *ChatPermissions `json:"permissions,omitempty"`                    // Optional. Default permissions of members of groups and supergroups, returned only by getChat
 }
 
 // IsPrivate returns if the Chat is a private conversation.
 func (c Chat) IsPrivate() bool {
-	return c.Type == "private"
+	return c.Type == ChatTypePrivate
 }
 
 // IsGroup returns if the Chat is a group.
 func (c Chat) IsGroup() bool {
-	return c.Type == "group"
+	return c.Type == ChatTypeGroup
 }
 
 // IsSuperGroup returns if the Chat is a supergroup.
 func (c Chat) IsSuperGroup() bool {
-	return c.Type == "supergroup"
+	return c.Type == ChatTypeSuperGroup
 }
 
 // IsChannel returns if the Chat is a channel.
 func (c Chat) IsChannel() bool {
-	return c.Type == "channel"
+	return c.Type == ChatTypeChannel
 }
 
 // ChatConfig returns a ChatConfig struct for chat related methods.
@@ -123,46 +468,91 @@ func (c Chat) ChatConfig() ChatConfig {
 // Message is returned by almost every request, and contains data about
 // almost anything.
 type Message struct {
-	MessageID            int      `json:"message_id"`              // Unique message identifier
-	From                 *User    `json:"from"`                    // Optional. Sender, can be empty for messages sent to channels
-	Date                 int      `json:"date"`                    // Date the message was sent in Unix time
-	Chat                 *Chat    `json:"chat"`                    // Conversation the message belongs to
-	ForwardFrom          *User    `json:"forward_from"`            // Optional. For forwarded messages, sender of the original message
-	ForwardFromChat      *Chat    `json:"forward_from_chat"`       // optional
-	ForwardFromMessageID int      `json:"forward_from_message_id"` // optional
-	ForwardDate          int      `json:"forward_date"`            // Optional. For forwarded messages, date the original message was sent in Unix time
-	ReplyToMessage       *Message `json:"reply_to_message"`        // Optional. For replies, the original message.
+	MessageID            int      `json:"message_id"`                        // Unique message identifier
+	From                 *User    `json:"from,omitempty"`                    // Optional. Sender, can be empty for messages sent to channels
+	Date                 int      `json:"date"`                              // Date the message was sent in Unix time
+	Chat                 *Chat    `json:"chat"`                              // Conversation the message belongs to
+	MessageThreadID      int      `json:"message_thread_id,omitempty"`       // Optional. The forum topic the message belongs to
+	IsTopicMessage       bool     `json:"is_topic_message,omitempty"`        // Optional. True, if the message is sent to a forum topic
+	ForwardFrom          *User    `json:"forward_from,omitempty"`            // Optional. For forwarded messages, sender of the original message
+	ForwardFromChat      *Chat    `json:"forward_from_chat,omitempty"`       // optional
+	ForwardFromMessageID int      `json:"forward_from_message_id,omitempty"` // optional
+	ForwardDate          int      `json:"forward_date,omitempty"`            // Optional. For forwarded messages, date the original message was sent in Unix time
+	ReplyToMessage       *Message `json:"reply_to_message,omitempty"`        // Optional. For replies, the original message.
 	// 	Note that the Message object in this field
 	// 	will not contain further reply_to_message fields
 	// 	even if it itself is a reply.
-	EditDate              int              `json:"edit_date"`               // optional
-	Text                  string           `json:"text"`                    // Optional. For text messages, the actual UTF-8 text of the message, 0-4096 characters.
-	Entities              *[]MessageEntity `json:"entities"`                // Optional. For text messages, special entities like usernames, URLs, bot commands, etc. that appear in the text
-	Audio                 *Audio           `json:"audio"`                   // Optional. Message is an audio file, information about the file
-	Document              *Document        `json:"document"`                // Optional. Message is a general file, information about the file
-	Game                  *Game            `json:"game"`                    // optional
-	Photo                 *[]PhotoSize     `json:"photo"`                   // Optional. Message is a photo, available sizes of the photo
-	Sticker               *Sticker         `json:"sticker"`                 // Optional. Message is a sticker, information about the sticker
-	Video                 *Video           `json:"video"`                   // Optional. Message is a video, information about the video
-	Voice                 *Voice           `json:"voice"`                   // Optional. Message is a voice message, information about the file
-	Caption               string           `json:"caption"`                 // Optional. Caption for the document, photo or video, 0-200 characters
-	Contact               *Contact         `json:"contact"`                 // Optional. Message is a shared contact, information about the contact
-	Location              *Location        `json:"location"`                // Optional. Message is a shared location, information about the location
-	Venue                 *Venue           `json:"venue"`                   // Optional. Message is a venue, information about the venue
-	NewChatMember         *User            `json:"new_chat_member"`         // Optional. A new member was added to the group, information about them (this member may be the bot itself)
-	LeftChatMember        *User            `json:"left_chat_member"`        // Optional. A member was removed from the group, information about them (this member may be the bot itself)
-	NewChatTitle          string           `json:"new_chat_title"`          // Optional. A chat title was changed to this value
-	NewChatPhoto          *[]PhotoSize     `json:"new_chat_photo"`          // Optional. A chat photo was change to this value
-	DeleteChatPhoto       bool             `json:"delete_chat_photo"`       // Optional. Service message: the chat photo was deleted
-	GroupChatCreated      bool             `json:"group_chat_created"`      // Optional. Service message: the group has been created
-	SuperGroupChatCreated bool             `json:"supergroup_chat_created"` // Optional. Service message: the supergroup has been created
-	ChannelChatCreated    bool             `json:"channel_chat_created"`    // Optional. Service message: the channel has been created
-	MigrateToChatID       int64            `json:"migrate_to_chat_id"`      // Optional. The group has been migrated to a supergroup with the specified
+	EditDate              int              `json:"edit_date,omitempty"`               // optional
+	Text                  string           `json:"text,omitempty"`                    // Optional. For text messages, the actual UTF-8 text of the message, 0-4096 characters.
+	Entities              *[]MessageEntity `json:"entities,omitempty"`                // Optional. For text messages, special entities like usernames, URLs, bot commands, etc. that appear in the text
+	Audio                 *Audio           `json:"audio,omitempty"`                   // Optional. Message is an audio file, information about the file
+	Document              *Document        `json:"document,omitempty"`                // Optional. Message is a general file, information about the file
+	Game                  *Game            `json:"game,omitempty"`                    // optional
+	Animation             *Animation       `json:"animation,omitempty"`               // Optional. Message is an animation; for backward compatibility, Document is also set
+	Photo                 *[]PhotoSize     `json:"photo,omitempty"`                   // Optional. Message is a photo, available sizes of the photo
+	Sticker               *Sticker         `json:"sticker,omitempty"`                 // Optional. Message is a sticker, information about the sticker
+	Video                 *Video           `json:"video,omitempty"`                   // Optional. Message is a video, information about the video
+	VideoNote             *VideoNote       `json:"video_note,omitempty"`              // Optional. Message is a video note, information about the video message
+	Voice                 *Voice           `json:"voice,omitempty"`                   // Optional. Message is a voice message, information about the file
+	Caption               string           `json:"caption,omitempty"`                 // Optional. Caption for the document, photo or video, 0-200 characters
+	Contact               *Contact         `json:"contact,omitempty"`                 // Optional. Message is a shared contact, information about the contact
+	Location              *Location        `json:"location,omitempty"`                // Optional. Message is a shared location, information about the location
+	Venue                 *Venue           `json:"venue,omitempty"`                   // Optional. Message is a venue, information about the venue
+	Poll                  *Poll            `json:"poll,omitempty"`                    // Optional. Message is a native poll, information about the poll
+	NewChatMember         *User            `json:"new_chat_member,omitempty"`         // Optional. A new member was added to the group, information about them (this member may be the bot itself)
+	NewChatMembers        []User           `json:"new_chat_members,omitempty"`        // Optional. New members added to the group, which may include the bot itself
+	LeftChatMember        *User            `json:"left_chat_member,omitempty"`        // Optional. A member was removed from the group, information about them (this member may be the bot itself)
+	NewChatTitle          string           `json:"new_chat_title,omitempty"`          // Optional. A chat title was changed to this value
+	NewChatPhoto          *[]PhotoSize     `json:"new_chat_photo,omitempty"`          // Optional. A chat photo was change to this value
+	DeleteChatPhoto       bool             `json:"delete_chat_photo,omitempty"`       // Optional. Service message: the chat photo was deleted
+	GroupChatCreated      bool             `json:"group_chat_created,omitempty"`      // Optional. Service message: the group has been created
+	SuperGroupChatCreated bool             `json:"supergroup_chat_created,omitempty"` // Optional. Service message: the supergroup has been created
+	ChannelChatCreated    bool             `json:"channel_chat_created,omitempty"`    // Optional. Service message: the channel has been created
+	MigrateToChatID       int64            `json:"migrate_to_chat_id,omitempty"`      // Optional. The group has been migrated to a supergroup with the specified
 	// 	identifier, not exceeding 1e13 by absolute value
-	MigrateFromChatID int64 `json:"migrate_from_chat_id"` // Optional. The supergroup has been migrated from a group with the specified
+	MigrateFromChatID int64 `json:"migrate_from_chat_id,omitempty"` // Optional. The supergroup has been migrated from a group with the specified
 	// 	identifier, not exceeding 1e13 by absolute value
-	PinnedMessage *Message `json:"pinned_message"` // Optional. Specified message was pinned. Note that the Message object in this
+	PinnedMessage *Message `json:"pinned_message,omitempty"` // Optional. Specified message was pinned. Note that the Message object in this
 	// 	field will not contain further reply_to_message fields even if it is itself a reply.
+	ReplyMarkup       *InlineKeyboardMarkup `json:"reply_markup,omitempty"`       // Optional. Inline keyboard attached to the message
+	Invoice           *Invoice              `json:"invoice,omitempty"`            // Optional. Message is an invoice for a payment
+	SuccessfulPayment *SuccessfulPayment    `json:"successful_payment,omitempty"` // Optional. Service message about a successful payment
+
+	VideoChatScheduled           *VideoChatScheduled           `json:"video_chat_scheduled,omitempty"`            // Optional. Service message: a video chat was scheduled
+	VideoChatStarted             *VideoChatStarted             `json:"video_chat_started,omitempty"`              // Optional. Service message: a video chat was started
+	VideoChatEnded               *VideoChatEnded               `json:"video_chat_ended,omitempty"`                // Optional. Service message: a video chat ended
+	VideoChatParticipantsInvited *VideoChatParticipantsInvited `json:"video_chat_participants_invited,omitempty"` // Optional. Service message: users were invited to a video chat
+	BoostAdded                   *ChatBoostAdded               `json:"boost_added,omitempty"`                     // Optional. Service message: a user boosted the chat
+
+	raw json.RawMessage
+}
+
+// Raw returns the JSON the message was decoded from, so fields not yet
+// supported by this library can be read. It is nil if the message was not
+// decoded from JSON.
+func (m *Message) Raw() json.RawMessage {
+	return m.raw
+}
+
+// UnmarshalJSON decodes a message, keeping a copy of its JSON for Raw.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type message Message
+	if err := json.Unmarshal(data, (*message)(m)); err != nil {
+		return err
+	}
+
+	m.raw = append(json.RawMessage(nil), data...)
+
+	return nil
+}
+
+// MarshalJSON encodes a message. Fields of the JSON it was decoded from
+// which are not supported by this library are kept, so a received
+// message encodes back to the same payload. Unsupported fields of nested
+// objects other than messages are lost.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	return marshalWithRaw(message(m), m.raw)
 }
 
 // Time converts the message timestamp into a Time.
@@ -170,6 +560,154 @@ func (m *Message) Time() time.Time {
 	return time.Unix(int64(m.Date), 0)
 }
 
+// ForwardTime converts the date the original message was sent into a
+// Time. It is the zero Time if the message was not forwarded.
+func (m *Message) ForwardTime() time.Time {
+	return unixTime(int64(m.ForwardDate))
+}
+
+// EditTime converts the date the message was last edited into a Time. It
+// is the zero Time if the message was not edited.
+func (m *Message) EditTime() time.Time {
+	return unixTime(int64(m.EditDate))
+}
+
+// unixTime converts an optional Unix timestamp into a Time, leaving it as
+// the zero Time if the timestamp is not set.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0)
+}
+
+// UnixTime is a time.Time which is encoded in JSON as a Unix timestamp,
+// the way Telegram sends dates, with 0 for the zero Time.
+//
+// Dates in the library's types stay as int for compatibility. UnixTime is
+// for applications decoding or storing them with their own types, such as
+//
+//	var dates struct {
+//		Date     tgbotapi.UnixTime `json:"date"`
+//		EditDate tgbotapi.UnixTime `json:"edit_date"`
+//	}
+//	json.Unmarshal(message.Raw(), &dates)
+type UnixTime struct {
+	time.Time
+}
+
+// MarshalJSON encodes the time as a Unix timestamp.
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("0"), nil
+	}
+
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
+
+// UnmarshalJSON decodes a Unix timestamp.
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	sec, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	t.Time = unixTime(sec)
+
+	return nil
+}
+
+// IsPhoto returns if the message is a photo.
+func (m *Message) IsPhoto() bool {
+	return m.Photo != nil && len(*m.Photo) > 0
+}
+
+// IsVideo returns if the message is a video.
+func (m *Message) IsVideo() bool {
+	return m.Video != nil
+}
+
+// IsVoice returns if the message is a voice message.
+func (m *Message) IsVoice() bool {
+	return m.Voice != nil
+}
+
+// IsSticker returns if the message is a sticker.
+func (m *Message) IsSticker() bool {
+	return m.Sticker != nil
+}
+
+// IsForwarded returns if the message was forwarded from another chat.
+func (m *Message) IsForwarded() bool {
+	return m.ForwardFrom != nil || m.ForwardFromChat != nil || m.ForwardDate != 0
+}
+
+// IsReply returns if the message is a reply to another message.
+func (m *Message) IsReply() bool {
+	return m.ReplyToMessage != nil
+}
+
+// IsServiceMessage returns if the message is about a change to the chat,
+// such as a new member or title, rather than sent by a user.
+func (m *Message) IsServiceMessage() bool {
+	return m.NewChatMember != nil ||
+		m.LeftChatMember != nil ||
+		m.NewChatTitle != "" ||
+		m.NewChatPhoto != nil ||
+		m.DeleteChatPhoto ||
+		m.GroupChatCreated ||
+		m.SuperGroupChatCreated ||
+		m.ChannelChatCreated ||
+		m.MigrateToChatID != 0 ||
+		m.MigrateFromChatID != 0 ||
+		m.PinnedMessage != nil ||
+		m.SuccessfulPayment != nil
+}
+
+// ContentType returns the kind of content in the message: "text", "audio",
+// "animation", "document", "game", "photo", "sticker", "video", "voice", "contact",
+// "location", "venue", "invoice", "service", or "unknown" for anything
+// else.
+func (m *Message) ContentType() string {
+	switch {
+	case m.Text != "":
+		return "text"
+	case m.Audio != nil:
+		return "audio"
+	case m.Animation != nil:
+		return "animation"
+	case m.Document != nil:
+		return "document"
+	case m.Game != nil:
+		return "game"
+	case m.IsPhoto():
+		return "photo"
+	case m.IsSticker():
+		return "sticker"
+	case m.IsVideo():
+		return "video"
+	case m.IsVoice():
+		return "voice"
+	case m.Contact != nil:
+		return "contact"
+	case m.Venue != nil:
+		return "venue"
+	case m.Location != nil:
+		return "location"
+	case m.Invoice != nil:
+		return "invoice"
+	case m.IsServiceMessage():
+		return "service"
+	}
+
+	return "unknown"
+}
+
 // IsCommand returns true if message starts with '/'.
 func (m *Message) IsCommand() bool {
 	return m.Text != "" && m.Text[0] == '/'
@@ -193,6 +731,56 @@ func (m *Message) Command() string {
 	return command
 }
 
+// BestPhoto returns the largest size of the photo in the message, or nil
+// if it has no photo.
+func (m *Message) BestPhoto() *PhotoSize {
+	if m.Photo == nil {
+		return nil
+	}
+
+	return PhotoSizes(*m.Photo).Largest()
+}
+
+// Link returns the t.me permalink to the message, or an empty string if
+// it has none. Only messages in supergroups and channels have links; in
+// those without a username, the link only works for their members.
+// Messages in forum topics link to the message within its topic.
+func (m *Message) Link() string {
+	if m.Chat == nil || !(m.Chat.IsSuperGroup() || m.Chat.IsChannel()) {
+		return ""
+	}
+
+	path := strconv.Itoa(m.MessageID)
+	if m.IsTopicMessage && m.MessageThreadID != 0 {
+		path = strconv.Itoa(m.MessageThreadID) + "/" + path
+	}
+
+	if m.Chat.UserName != "" {
+		return fmt.Sprintf("https://t.me/%s/%s", m.Chat.UserName, path)
+	}
+
+	// Supergroup and channel IDs are their internal ID prefixed with -100.
+	id := strconv.FormatInt(m.Chat.ID, 10)
+	if !strings.HasPrefix(id, "-100") {
+		return ""
+	}
+
+	return fmt.Sprintf("https://t.me/c/%s/%s", id[len("-100"):], path)
+}
+
+// CommandWithAt checks if the message was a command and if it was,
+// returns the command. If the Message was not a command, it returns an
+// empty string.
+//
+// Unlike Command, it keeps the @botname the command was addressed to.
+func (m *Message) CommandWithAt() string {
+	if !m.IsCommand() {
+		return ""
+	}
+
+	return strings.SplitN(m.Text, " ", 2)[0][1:]
+}
+
 // CommandArguments checks if the message was a command and if it was,
 // returns all text after the command name. If the Message was not a
 // command, it returns an empty string.
@@ -211,12 +799,12 @@ func (m *Message) CommandArguments() string {
 
 // This object represents one special entity in a text message. For example, hashtags, usernames, URLs, etc.
 type MessageEntity struct {
-	Type string `json:"type"` //Type of the entity. One of mention (@username), hashtag, bot_command, url, email, bold (bold text),
+	Type EntityType `json:"type"` //Type of the entity. One of mention (@username), hashtag, bot_command, url, email, bold (bold text),
 	//	italic (italic text), code (monowidth string), pre (monowidth block), text_link (for clickable text URLs)
-	Offset int    `json:"offset"` // Offset in UTF-16 code units to the start of the entity
-	Length int    `json:"length"` // Length of the entity in UTF-16 code units
-	URL    string `json:"url"`    // Optional. For “text_link” only, url that will be opened after user taps on the text
-	User   *User  `json:"user"`   // optional
+	Offset int    `json:"offset"`         // Offset in UTF-16 code units to the start of the entity
+	Length int    `json:"length"`         // Length of the entity in UTF-16 code units
+	URL    string `json:"url,omitempty"`  // Optional. For “text_link” only, url that will be opened after user taps on the text
+	User   *User  `json:"user,omitempty"` // optional
 }
 
 // ParseURL attempts to parse a URL contained within a MessageEntity.
@@ -230,66 +818,132 @@ func (entity MessageEntity) ParseURL() (*url.URL, error) {
 
 // This object represents one size of a photo or a file / sticker thumbnail.
 type PhotoSize struct {
-	FileID   string `json:"file_id"`   // Unique identifier for this file
-	Width    int    `json:"width"`     // Photo width
-	Height   int    `json:"height"`    // Photo height
-	FileSize int    `json:"file_size"` // Optional. File size
+	FileID   string `json:"file_id"`             // Unique identifier for this file
+	Width    int    `json:"width"`               // Photo width
+	Height   int    `json:"height"`              // Photo height
+	FileSize int    `json:"file_size,omitempty"` // Optional. File size
+}
+
+// PhotoSizes are the sizes a photo is available in.
+type PhotoSizes []PhotoSize
+
+// Largest returns the size with the most pixels, or nil if there are no
+// sizes.
+func (sizes PhotoSizes) Largest() *PhotoSize {
+	return sizes.best(func(size, best PhotoSize) bool {
+		return size.Width*size.Height > best.Width*best.Height
+	})
+}
+
+// Smallest returns the size with the fewest pixels, or nil if there are
+// no sizes.
+func (sizes PhotoSizes) Smallest() *PhotoSize {
+	return sizes.best(func(size, best PhotoSize) bool {
+		return size.Width*size.Height < best.Width*best.Height
+	})
+}
+
+// ClosestTo returns the size nearest to width by height, or nil if there
+// are no sizes.
+func (sizes PhotoSizes) ClosestTo(width, height int) *PhotoSize {
+	distance := func(size PhotoSize) int {
+		return abs(size.Width-width) + abs(size.Height-height)
+	}
+
+	return sizes.best(func(size, best PhotoSize) bool {
+		return distance(size) < distance(best)
+	})
+}
+
+// best returns the size for which better returns true compared with
+// every other size.
+func (sizes PhotoSizes) best(better func(size, best PhotoSize) bool) *PhotoSize {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	best := sizes[0]
+	for _, size := range sizes[1:] {
+		if better(size, best) {
+			best = size
+		}
+	}
+
+	return &best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
 }
 
 // This object represents an audio file to be treated as music by the Telegram clients.
 type Audio struct {
-	FileID    string `json:"file_id"`   // Unique identifier for this file
-	Duration  int    `json:"duration"`  // Duration of the audio in seconds as defined by sender
-	Performer string `json:"performer"` // Optional. Performer of the audio as defined by sender or by audio tags
-	Title     string `json:"title"`     // Optional. Title of the audio as defined by sender or by audio tags
-	MimeType  string `json:"mime_type"` // Optional. MIME type of the file as defined by sender
-	FileSize  int    `json:"file_size"` // Optional. File size
+	FileID    string `json:"file_id"`             // Unique identifier for this file
+	Duration  int    `json:"duration"`            // Duration of the audio in seconds as defined by sender
+	Performer string `json:"performer,omitempty"` // Optional. Performer of the audio as defined by sender or by audio tags
+	Title     string `json:"title,omitempty"`     // Optional. Title of the audio as defined by sender or by audio tags
+	MimeType  string `json:"mime_type,omitempty"` // Optional. MIME type of the file as defined by sender
+	FileSize  int    `json:"file_size,omitempty"` // Optional. File size
 }
 
 // This object represents a general file (as opposed to photos, voice messages and audio files).
 type Document struct {
-	FileID    string     `json:"file_id"`   // Unique file identifier
-	Thumbnail *PhotoSize `json:"thumb"`     // Optional. Document thumbnail as defined by sender
-	FileName  string     `json:"file_name"` // Optional. Original filename as defined by sender
-	MimeType  string     `json:"mime_type"` // Optional. MIME type of the file as defined by sender
-	FileSize  int        `json:"file_size"` // Optional. File size
+	FileID    string     `json:"file_id"`             // Unique file identifier
+	Thumbnail *PhotoSize `json:"thumb,omitempty"`     // Optional. Document thumbnail as defined by sender
+	FileName  string     `json:"file_name,omitempty"` // Optional. Original filename as defined by sender
+	MimeType  string     `json:"mime_type,omitempty"` // Optional. MIME type of the file as defined by sender
+	FileSize  int        `json:"file_size,omitempty"` // Optional. File size
 }
 
 // This object represents a sticker.
 type Sticker struct {
-	FileID    string     `json:"file_id"`   // Unique identifier for this file
-	Width     int        `json:"width"`     // Sticker width
-	Height    int        `json:"height"`    // Sticker height
-	Thumbnail *PhotoSize `json:"thumb"`     // Optional. Sticker thumbnail in .webp or .jpg format
-	Emoji     string     `json:"emoji"`     // optional
-	FileSize  int        `json:"file_size"` // Optional. File size
+	FileID    string     `json:"file_id"`             // Unique identifier for this file
+	Width     int        `json:"width"`               // Sticker width
+	Height    int        `json:"height"`              // Sticker height
+	Thumbnail *PhotoSize `json:"thumb,omitempty"`     // Optional. Sticker thumbnail in .webp or .jpg format
+	Emoji     string     `json:"emoji,omitempty"`     // optional
+	FileSize  int        `json:"file_size,omitempty"` // Optional. File size
 }
 
 // This object represents a video file.
 type Video struct {
-	FileID    string     `json:"file_id"`   // Unique identifier for this file
-	Width     int        `json:"width"`     // Video width as defined by sender
-	Height    int        `json:"height"`    // Video height as defined by sender
-	Duration  int        `json:"duration"`  // Duration of the video in seconds as defined by sender
-	Thumbnail *PhotoSize `json:"thumb"`     // Optional. Video thumbnail
-	MimeType  string     `json:"mime_type"` // Optional. Mime type of a file as defined by sender
-	FileSize  int        `json:"file_size"` // Optional. File size
+	FileID    string     `json:"file_id"`             // Unique identifier for this file
+	Width     int        `json:"width"`               // Video width as defined by sender
+	Height    int        `json:"height"`              // Video height as defined by sender
+	Duration  int        `json:"duration"`            // Duration of the video in seconds as defined by sender
+	Thumbnail *PhotoSize `json:"thumb,omitempty"`     // Optional. Video thumbnail
+	MimeType  string     `json:"mime_type,omitempty"` // Optional. Mime type of a file as defined by sender
+	FileSize  int        `json:"file_size,omitempty"` // Optional. File size
+}
+
+// This object represents a video message.
+type VideoNote struct {
+	FileID    string     `json:"file_id"`             // Unique identifier for this file
+	Length    int        `json:"length"`              // Video width and height as defined by sender
+	Duration  int        `json:"duration"`            // Duration of the video in seconds as defined by sender
+	Thumbnail *PhotoSize `json:"thumb,omitempty"`     // Optional. Video thumbnail
+	FileSize  int        `json:"file_size,omitempty"` // Optional. File size
 }
 
 // This object represents a voice note.
 type Voice struct {
-	FileID   string `json:"file_id"`   // Unique identifier for this file
-	Duration int    `json:"duration"`  // Duration of the audio in seconds as defined by sender
-	MimeType string `json:"mime_type"` // Optional. MIME type of the file as defined by sender
-	FileSize int    `json:"file_size"` // Optional. File size
+	FileID   string `json:"file_id"`             // Unique identifier for this file
+	Duration int    `json:"duration"`            // Duration of the audio in seconds as defined by sender
+	MimeType string `json:"mime_type,omitempty"` // Optional. MIME type of the file as defined by sender
+	FileSize int    `json:"file_size,omitempty"` // Optional. File size
 }
 
 // This object represents a phone contact.
 type Contact struct {
-	PhoneNumber string `json:"phone_number"` // Contact's phone number
-	FirstName   string `json:"first_name"`   // Contact's first name
-	LastName    string `json:"last_name"`    // Optional. Contact's last name
-	UserID      int    `json:"user_id"`      // Optional. Contact's user identifier in Telegram
+	PhoneNumber string `json:"phone_number"`        // Contact's phone number
+	FirstName   string `json:"first_name"`          // Contact's first name
+	LastName    string `json:"last_name,omitempty"` // Optional. Contact's last name
+	UserID      int64  `json:"user_id,omitempty"`   // Optional. Contact's user identifier in Telegram
+	VCard       string `json:"vcard,omitempty"`     // Optional. Additional data about the contact in the form of a vCard
 }
 
 // This object represents a point on the map.
@@ -300,10 +954,13 @@ type Location struct {
 
 // This object represents a venue.
 type Venue struct {
-	Location     Location `json:"location"`      // Venue location
-	Title        string   `json:"title"`         // Name of the venue
-	Address      string   `json:"address"`       // Address of the venue
-	FoursquareID string   `json:"foursquare_id"` // Optional. Foursquare identifier of the venue
+	Location        Location `json:"location"`                    // Venue location
+	Title           string   `json:"title"`                       // Name of the venue
+	Address         string   `json:"address"`                     // Address of the venue
+	FoursquareID    string   `json:"foursquare_id,omitempty"`     // Optional. Foursquare identifier of the venue
+	FoursquareType  string   `json:"foursquare_type,omitempty"`   // Optional. Foursquare type of the venue
+	GooglePlaceID   string   `json:"google_place_id,omitempty"`   // Optional. Google Places identifier of the venue
+	GooglePlaceType string   `json:"google_place_type,omitempty"` // Optional. Google Places type of the venue
 }
 
 // This object represent a user's profile pictures.
@@ -346,6 +1003,31 @@ type ReplyKeyboardMarkup struct {
 	// 	2) if the bot's message is a reply (has reply_to_message_id), sender of the original message.
 }
 
+// BotCommand is a command shown in the bot's command menu.
+type BotCommand struct {
+	Command     string `json:"command"` // without the leading /
+	Description string `json:"description"`
+}
+
+// BotCommandScope is the set of users a list of commands is shown to.
+type BotCommandScope struct {
+	Type   string `json:"type"`
+	ChatID int64  `json:"chat_id,omitempty"` // for chat, chat_administrators and chat_member scopes
+	UserID int64  `json:"user_id,omitempty"` // for the chat_member scope
+}
+
+// ReplyParameters describes the message being replied to, optionally
+// quoting only part of it.
+type ReplyParameters struct {
+	MessageID                int             `json:"message_id"`
+	ChatID                   int64           `json:"chat_id,omitempty"`                     // optional, if the message is in another chat
+	AllowSendingWithoutReply bool            `json:"allow_sending_without_reply,omitempty"` // optional
+	Quote                    string          `json:"quote,omitempty"`                       // optional, part of the message to quote
+	QuoteParseMode           string          `json:"quote_parse_mode,omitempty"`            // optional
+	QuoteEntities            []MessageEntity `json:"quote_entities,omitempty"`              // optional, formatting of the quote
+	QuotePosition            int             `json:"quote_position,omitempty"`              // optional, in UTF-16 code units
+}
+
 // This object represents one button of the reply keyboard.
 // For simple text buttons String can be used instead of this object to specify text of the button.
 // Optional fields are mutually exclusive.
@@ -391,6 +1073,23 @@ type InlineKeyboardButton struct {
 	SwitchInlineQuery            *string       `json:"switch_inline_query,omitempty"`              // optional
 	SwitchInlineQueryCurrentChat *string       `json:"switch_inline_query_current_chat,omitempty"` // optional
 	CallbackGame                 *CallbackGame `json:"callback_game,omitempty"`                    // optional
+	Pay                          bool          `json:"pay,omitempty"`                              // optional, must be the first button of an invoice
+	WebApp                       *WebAppInfo   `json:"web_app,omitempty"`                          // optional
+	LoginURL                     *LoginURL     `json:"login_url,omitempty"`                        // optional
+}
+
+// WebAppInfo describes a Web App to open from a button.
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// LoginURL is a button that logs the user in to a website with Telegram
+// Login when pressed.
+type LoginURL struct {
+	URL                string `json:"url"`
+	ForwardText        string `json:"forward_text,omitempty"`         // optional
+	BotUsername        string `json:"bot_username,omitempty"`         // optional
+	RequestWriteAccess bool   `json:"request_write_access,omitempty"` // optional
 }
 
 // CallbackQuery is data sent when a keyboard button with callback data
@@ -398,11 +1097,11 @@ type InlineKeyboardButton struct {
 type CallbackQuery struct {
 	ID              string   `json:"id"`
 	From            *User    `json:"from"`
-	Message         *Message `json:"message"`           // optional
-	InlineMessageID string   `json:"inline_message_id"` // optional
+	Message         *Message `json:"message,omitempty"`           // optional
+	InlineMessageID string   `json:"inline_message_id,omitempty"` // optional
 	ChatInstance    string   `json:"chat_instance"`
-	Data            string   `json:"data"`            // optional
-	GameShortName   string   `json:"game_short_name"` // optional
+	Data            string   `json:"data,omitempty"`            // optional
+	GameShortName   string   `json:"game_short_name,omitempty"` // optional
 }
 
 // ForceReply allows the Bot to have users directly reply to it without
@@ -415,43 +1114,165 @@ type ForceReply struct {
 }
 
 // ChatMember is information about a member in a chat.
+//
+// Which rights are set depends on Status: administrators have the rights
+// to manage the chat, and restricted members have the permissions to
+// send messages. Use HasPermission to check either.
 type ChatMember struct {
-	User   *User  `json:"user"`
-	Status string `json:"status"`
+	User      *User        `json:"user"`
+	Status    MemberStatus `json:"status"`
+	UntilDate int64        `json:"until_date,omitempty"` // optional
+	// InChat is whether a restricted user is a member of the chat. It is
+	// sent as is_member, which IsMember can't share a name with.
+	InChat bool `json:"is_member,omitempty"`
+
+	IsAnonymous         bool `json:"is_anonymous,omitempty"`           // optional, administrators only
+	CanBeEdited         bool `json:"can_be_edited,omitempty"`          // optional, administrators only
+	CanManageChat       bool `json:"can_manage_chat,omitempty"`        // optional, administrators only
+	CanChangeInfo       bool `json:"can_change_info,omitempty"`        // optional
+	CanPostMessages     bool `json:"can_post_messages,omitempty"`      // optional, administrators of channels only
+	CanEditMessages     bool `json:"can_edit_messages,omitempty"`      // optional, administrators of channels only
+	CanDeleteMessages   bool `json:"can_delete_messages,omitempty"`    // optional, administrators only
+	CanInviteUsers      bool `json:"can_invite_users,omitempty"`       // optional
+	CanRestrictMembers  bool `json:"can_restrict_members,omitempty"`   // optional, administrators only
+	CanPinMessages      bool `json:"can_pin_messages,omitempty"`       // optional
+	CanPromoteMembers   bool `json:"can_promote_members,omitempty"`    // optional, administrators only
+	CanManageVideoChats bool `json:"can_manage_video_chats,omitempty"` // optional, administrators only
+
+	CanSendMessages       bool `json:"can_send_messages,omitempty"`         // optional, restricted members only
+	CanSendMediaMessages  bool `json:"can_send_media_messages,omitempty"`   // optional, restricted members only
+	CanSendPolls          bool `json:"can_send_polls,omitempty"`            // optional, restricted members only
+	CanSendOtherMessages  bool `json:"can_send_other_messages,omitempty"`   // optional, restricted members only
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"` // optional, restricted members only
+}
+
+// ChatPermissions are the actions members of a chat, or a restricted
+// member, are allowed to take.
+type ChatPermissions struct {
+	CanSendMessages       bool `json:"can_send_messages,omitempty"`         // optional
+	CanSendMediaMessages  bool `json:"can_send_media_messages,omitempty"`   // optional, implies CanSendMessages
+	CanSendPolls          bool `json:"can_send_polls,omitempty"`            // optional, implies CanSendMessages
+	CanSendOtherMessages  bool `json:"can_send_other_messages,omitempty"`   // optional, implies CanSendMediaMessages
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"` // optional, implies CanSendMediaMessages
+	CanChangeInfo         bool `json:"can_change_info,omitempty"`           // optional
+	CanInviteUsers        bool `json:"can_invite_users,omitempty"`          // optional
+	CanPinMessages        bool `json:"can_pin_messages,omitempty"`          // optional
+}
+
+// UntilTime converts the date restrictions on the ChatMember end into a
+// Time. It is the zero Time if they don't end.
+func (chat ChatMember) UntilTime() time.Time {
+	return unixTime(chat.UntilDate)
 }
 
 // IsCreator returns if the ChatMember was the creator of the chat.
-func (chat ChatMember) IsCreator() bool { return chat.Status == "creator" }
+func (chat ChatMember) IsCreator() bool { return chat.Status == MemberStatusCreator }
 
 // IsAdministrator returns if the ChatMember is a chat administrator.
-func (chat ChatMember) IsAdministrator() bool { return chat.Status == "administrator" }
+func (chat ChatMember) IsAdministrator() bool { return chat.Status == MemberStatusAdministrator }
 
 // IsMember returns if the ChatMember is a current member of the chat.
-func (chat ChatMember) IsMember() bool { return chat.Status == "member" }
+func (chat ChatMember) IsMember() bool { return chat.Status == MemberStatusMember }
+
+// IsPresent returns if the ChatMember is in the chat, whatever their
+// status.
+func (chat ChatMember) IsPresent() bool {
+	switch chat.Status {
+	case MemberStatusCreator, MemberStatusAdministrator, MemberStatusMember:
+		return true
+	case MemberStatusRestricted:
+		return chat.InChat
+	}
+
+	return false
+}
 
 // HasLeft returns if the ChatMember left the chat.
-func (chat ChatMember) HasLeft() bool { return chat.Status == "left" }
+func (chat ChatMember) HasLeft() bool { return chat.Status == MemberStatusLeft }
 
 // WasKicked returns if the ChatMember was kicked from the chat.
-func (chat ChatMember) WasKicked() bool { return chat.Status == "kicked" }
+func (chat ChatMember) WasKicked() bool { return chat.Status == MemberStatusKicked }
+
+// Poll is a native poll.
+type Poll struct {
+	ID                    string       `json:"id"`
+	Question              string       `json:"question"`
+	Options               []PollOption `json:"options"`
+	TotalVoterCount       int          `json:"total_voter_count"`
+	IsClosed              bool         `json:"is_closed"`
+	IsAnonymous           bool         `json:"is_anonymous"`
+	Type                  string       `json:"type"` // "regular" or "quiz"
+	AllowsMultipleAnswers bool         `json:"allows_multiple_answers"`
+	CorrectOptionID       int          `json:"correct_option_id"` // optional, for quizzes
+}
+
+// MarshalJSON encodes a poll. CorrectOptionID is only included for
+// quizzes, as 0 is the ID of their first option.
+func (p Poll) MarshalJSON() ([]byte, error) {
+	type poll Poll
+
+	var correct *int
+	if p.Type == "quiz" {
+		correct = &p.CorrectOptionID
+	}
+
+	return json.Marshal(struct {
+		poll
+		CorrectOptionID *int `json:"correct_option_id,omitempty"`
+	}{poll(p), correct})
+}
+
+// PollOption is an option in a poll, with the number of users who chose
+// it.
+type PollOption struct {
+	Text       string `json:"text"`
+	VoterCount int    `json:"voter_count"`
+}
+
+// PollAnswer is a user's answer in a non-anonymous poll.
+type PollAnswer struct {
+	PollID    string `json:"poll_id"`
+	User      *User  `json:"user"`
+	OptionIDs []int  `json:"option_ids"` // empty if the user retracted their vote
+}
 
 // Game is a game within Telegram.
 type Game struct {
 	Title        string          `json:"title"`
 	Description  string          `json:"description"`
 	Photo        []PhotoSize     `json:"photo"`
-	Text         string          `json:"text"`
-	TextEntities []MessageEntity `json:"text_entities"`
+	Text         string          `json:"text,omitempty"`
+	TextEntities []MessageEntity `json:"text_entities,omitempty"`
 	Animation    Animation       `json:"animation"`
 }
 
-// Animation is a GIF animation demonstrating the game.
+// MarshalJSON encodes a game. Animation is only included if the game
+// has one.
+func (g Game) MarshalJSON() ([]byte, error) {
+	type game Game
+
+	var animation *Animation
+	if g.Animation.FileID != "" {
+		animation = &g.Animation
+	}
+
+	return json.Marshal(struct {
+		game
+		Animation *Animation `json:"animation,omitempty"`
+	}{game(g), animation})
+}
+
+// Animation is a GIF or a video without sound, sent as a message or
+// demonstrating a game.
 type Animation struct {
-	FileID   string    `json:"file_id"`
-	Thumb    PhotoSize `json:"thumb"`
-	FileName string    `json:"file_name"`
-	MimeType string    `json:"mime_type"`
-	FileSize int       `json:"file_size"`
+	FileID   string     `json:"file_id"`
+	Width    int        `json:"width"`
+	Height   int        `json:"height"`
+	Duration int        `json:"duration"`
+	Thumb    *PhotoSize `json:"thumb,omitempty"`
+	FileName string     `json:"file_name,omitempty"`
+	MimeType string     `json:"mime_type,omitempty"`
+	FileSize int        `json:"file_size,omitempty"`
 }
 
 // GameHighScore is a user's score and position on the leaderboard.
@@ -473,6 +1294,12 @@ type WebhookInfo struct {
 	LastErrorMessage     string `json:"last_error_message"` // optional
 }
 
+// LastErrorTime converts the date of the last error delivering an update
+// into a Time. It is the zero Time if there has been no error.
+func (info WebhookInfo) LastErrorTime() time.Time {
+	return unixTime(int64(info.LastErrorDate))
+}
+
 // IsSet returns true if a webhook is currently set.
 func (info WebhookInfo) IsSet() bool {
 	return info.URL != ""
@@ -480,11 +1307,11 @@ func (info WebhookInfo) IsSet() bool {
 
 // InlineQuery is a Query from Telegram for an inline request.
 type InlineQuery struct {
-	ID       string    `json:"id"`       // Unique identifier for this query
-	From     *User     `json:"from"`     // Sender
-	Location *Location `json:"location"` // Optional. Sender location, only for bots that request user location
-	Query    string    `json:"query"`    // Text of the query
-	Offset   string    `json:"offset"`   // Offset of the results to be returned, can be controlled by the bot
+	ID       string    `json:"id"`                 // Unique identifier for this query
+	From     *User     `json:"from"`               // Sender
+	Location *Location `json:"location,omitempty"` // Optional. Sender location, only for bots that request user location
+	Query    string    `json:"query"`              // Text of the query
+	Offset   string    `json:"offset"`             // Offset of the results to be returned, can be controlled by the bot
 }
 
 // Represents a link to an article or web page.
@@ -636,11 +1463,11 @@ type InlineQueryResultGame struct {
 
 // Represents a result of an inline query that was chosen by the user and sent to their chat partner.
 type ChosenInlineResult struct {
-	ResultID        string    `json:"result_id"`         // The unique identifier for the result that was chosen
-	From            *User     `json:"from"`              // The user that chose the result
-	Location        *Location `json:"location"`          // Optional. Sender location, only for bots that require user location
-	InlineMessageID string    `json:"inline_message_id"` // Optional. Identifier of the sent inline message. Available only if there is an inline keyboard attached to the message. Will be also received in callback queries and can be used to edit the message.
-	Query           string    `json:"query"`             // The query that was used to obtain the result
+	ResultID        string    `json:"result_id"`                   // The unique identifier for the result that was chosen
+	From            *User     `json:"from"`                        // The user that chose the result
+	Location        *Location `json:"location,omitempty"`          // Optional. Sender location, only for bots that require user location
+	InlineMessageID string    `json:"inline_message_id,omitempty"` // Optional. Identifier of the sent inline message. Available only if there is an inline keyboard attached to the message. Will be also received in callback queries and can be used to edit the message.
+	Query           string    `json:"query"`                       // The query that was used to obtain the result
 }
 
 // InputTextMessageContent contains text for displaying
@@ -672,3 +1499,204 @@ type InputContactMessageContent struct {
 	FirstName   string `json:"first_name"`   //  	Contact's first name
 	LastName    string `json:"last_name"`    // Optional. Contact's last name
 }
+
+// BaseInputMedia is the base type for media sent in a media group or
+// used to edit a message's media.
+//
+// Media may be any RequestFileData. Files to upload are sent as separate
+// parts of the request and referenced with attach://.
+type BaseInputMedia struct {
+	Type      string          `json:"type"`
+	Media     RequestFileData `json:"media"`
+	Caption   string          `json:"caption,omitempty"`
+	ParseMode string          `json:"parse_mode,omitempty"`
+}
+
+// InputMediaPhoto is a photo to send as part of a media group.
+type InputMediaPhoto struct {
+	BaseInputMedia
+}
+
+// InputMediaVideo is a video to send as part of a media group.
+type InputMediaVideo struct {
+	BaseInputMedia
+	Thumb             RequestFileData `json:"thumb,omitempty"`
+	Width             int             `json:"width,omitempty"`
+	Height            int             `json:"height,omitempty"`
+	Duration          int             `json:"duration,omitempty"`
+	SupportsStreaming bool            `json:"supports_streaming,omitempty"`
+}
+
+// InputMediaAudio is an audio file to use when editing a message's media.
+type InputMediaAudio struct {
+	BaseInputMedia
+	Thumb     RequestFileData `json:"thumb,omitempty"`
+	Duration  int             `json:"duration,omitempty"`
+	Performer string          `json:"performer,omitempty"`
+	Title     string          `json:"title,omitempty"`
+}
+
+// InputMediaDocument is a document to use when editing a message's media.
+type InputMediaDocument struct {
+	BaseInputMedia
+	Thumb RequestFileData `json:"thumb,omitempty"`
+}
+
+// LabeledPrice is a portion of the price of goods or services, in the
+// smallest units of the currency, such as cents for USD.
+type LabeledPrice struct {
+	Label  string `json:"label"`
+	Amount int    `json:"amount"`
+}
+
+// Invoice contains basic information about an invoice.
+type Invoice struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	StartParameter string `json:"start_parameter"`
+	Currency       string `json:"currency"`
+	TotalAmount    int    `json:"total_amount"` // in the smallest units of the currency
+}
+
+// ShippingAddress is a shipping address given by a user.
+type ShippingAddress struct {
+	CountryCode string `json:"country_code"` // ISO 3166-1 alpha-2
+	State       string `json:"state"`
+	City        string `json:"city"`
+	StreetLine1 string `json:"street_line1"`
+	StreetLine2 string `json:"street_line2"`
+	PostCode    string `json:"post_code"`
+}
+
+// OrderInfo is information about an order given by a user.
+type OrderInfo struct {
+	Name            string           `json:"name,omitempty"`             // optional
+	PhoneNumber     string           `json:"phone_number,omitempty"`     // optional
+	Email           string           `json:"email,omitempty"`            // optional
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"` // optional
+}
+
+// ShippingOption is a way of shipping goods, with its price.
+type ShippingOption struct {
+	ID     string         `json:"id"`
+	Title  string         `json:"title"`
+	Prices []LabeledPrice `json:"prices"`
+}
+
+// SuccessfulPayment contains basic information about a successful
+// payment.
+type SuccessfulPayment struct {
+	Currency                string     `json:"currency"`
+	TotalAmount             int        `json:"total_amount"` // in the smallest units of the currency
+	InvoicePayload          string     `json:"invoice_payload"`
+	ShippingOptionID        string     `json:"shipping_option_id,omitempty"` // optional
+	OrderInfo               *OrderInfo `json:"order_info,omitempty"`         // optional
+	TelegramPaymentChargeID string     `json:"telegram_payment_charge_id"`
+	ProviderPaymentChargeID string     `json:"provider_payment_charge_id"`
+}
+
+// ShippingQuery asks for the shipping options and prices for an invoice
+// with a flexible price, once the user has given a shipping address.
+type ShippingQuery struct {
+	ID              string           `json:"id"`
+	From            *User            `json:"from"`
+	InvoicePayload  string           `json:"invoice_payload"`
+	ShippingAddress *ShippingAddress `json:"shipping_address"`
+}
+
+// ChatJoinRequest is a request by a user to join a chat, which the bot
+// may approve or decline.
+type ChatJoinRequest struct {
+	Chat *Chat `json:"chat"`
+	From *User `json:"from"`
+	// UserChatID is the private chat with the user, which the bot may
+	// message until the request is handled.
+	UserChatID int64  `json:"user_chat_id,omitempty"`
+	Date       int    `json:"date"`
+	Bio        string `json:"bio,omitempty"`
+}
+
+// ChatMemberUpdated is a change to a member's status in a chat.
+type ChatMemberUpdated struct {
+	Chat *Chat `json:"chat"`
+	// From is who changed the status.
+	From          *User      `json:"from"`
+	Date          int        `json:"date"`
+	OldChatMember ChatMember `json:"old_chat_member"`
+	NewChatMember ChatMember `json:"new_chat_member"`
+}
+
+// VideoChatScheduled is a video chat scheduled to start at StartDate.
+type VideoChatScheduled struct {
+	StartDate int `json:"start_date"`
+}
+
+// VideoChatStarted is a video chat having started.
+type VideoChatStarted struct{}
+
+// VideoChatEnded is a video chat having ended, after Duration seconds.
+type VideoChatEnded struct {
+	Duration int `json:"duration"`
+}
+
+// VideoChatParticipantsInvited is users being invited to a video chat.
+type VideoChatParticipantsInvited struct {
+	Users []User `json:"users,omitempty"`
+}
+
+// ChatBoostAdded is a user boosting a chat BoostCount times.
+type ChatBoostAdded struct {
+	BoostCount int `json:"boost_count"`
+}
+
+// ChatBoostSource is where a boost came from: "premium" for a user
+// boosting the chat, "gift_code" or "giveaway".
+type ChatBoostSource struct {
+	Source string `json:"source"`
+	// User is who boosted the chat, or was given the boost. It may be
+	// missing for unclaimed giveaways.
+	User *User `json:"user,omitempty"`
+}
+
+// booster returns the user a boost came from, if known.
+func (s *ChatBoostSource) booster() *User {
+	if s == nil {
+		return nil
+	}
+
+	return s.User
+}
+
+// ChatBoost is a boost of a chat.
+type ChatBoost struct {
+	BoostID        string           `json:"boost_id"`
+	AddDate        int              `json:"add_date"`
+	ExpirationDate int              `json:"expiration_date"`
+	Source         *ChatBoostSource `json:"source"`
+}
+
+// ChatBoostUpdated is a boost added to or changed in a chat.
+type ChatBoostUpdated struct {
+	Chat  *Chat     `json:"chat"`
+	Boost ChatBoost `json:"boost"`
+}
+
+// ChatBoostRemoved is a boost removed from a chat.
+type ChatBoostRemoved struct {
+	Chat       *Chat            `json:"chat"`
+	BoostID    string           `json:"boost_id"`
+	RemoveDate int              `json:"remove_date"`
+	Source     *ChatBoostSource `json:"source"`
+}
+
+// PreCheckoutQuery asks to confirm that an order can be fulfilled before
+// the user is charged for it.
+type PreCheckoutQuery struct {
+	ID               string     `json:"id"`
+	From             *User      `json:"from"`
+	Currency         string     `json:"currency"`
+	TotalAmount      int        `json:"total_amount"` // in the smallest units of the currency
+	InvoicePayload   string     `json:"invoice_payload"`
+	ShippingOptionID string     `json:"shipping_option_id,omitempty"` // optional
+	OrderInfo        *OrderInfo `json:"order_info,omitempty"`         // optional
+}
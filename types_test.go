@@ -1,13 +1,18 @@
 package tgbotapi_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestUserStringWith(t *testing.T) {
-	user := tgbotapi.User{0, "Test", "Test", ""}
+	user := tgbotapi.User{ID: 0, FirstName: "Test", LastName: "Test", UserName: ""}
 
 	if user.String() != "Test Test" {
 		t.Fail()
@@ -15,7 +20,7 @@ func TestUserStringWith(t *testing.T) {
 }
 
 func TestUserStringWithUserName(t *testing.T) {
-	user := tgbotapi.User{0, "Test", "Test", "@test"}
+	user := tgbotapi.User{ID: 0, FirstName: "Test", LastName: "Test", UserName: "@test"}
 
 	if user.String() != "@test" {
 		t.Fail()
@@ -87,6 +92,14 @@ func TestCommandWithBotName(t *testing.T) {
 	}
 }
 
+func TestCommandWithAtWithBotName(t *testing.T) {
+	message := tgbotapi.Message{Text: "/command@testbot arg"}
+
+	if message.CommandWithAt() != "command@testbot" {
+		t.Fail()
+	}
+}
+
 func TestMessageCommandArgumentsWithArguments(t *testing.T) {
 	message := tgbotapi.Message{Text: "/command with arguments"}
 	if message.CommandArguments() != "with arguments" {
@@ -156,6 +169,17 @@ func TestChatIsSuperGroup(t *testing.T) {
 	}
 }
 
+func TestGroupChatLargeID(t *testing.T) {
+	var chat tgbotapi.GroupChat
+	if err := json.Unmarshal([]byte(`{"id":-1001234567890,"title":"Group"}`), &chat); err != nil {
+		t.Fatal(err)
+	}
+
+	if chat.ID != -1001234567890 {
+		t.Errorf("expected the full ID, got %d", chat.ID)
+	}
+}
+
 func TestFileLink(t *testing.T) {
 	file := tgbotapi.File{FilePath: "test/test.txt"}
 
@@ -163,3 +187,380 @@ func TestFileLink(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestUpdateRaw(t *testing.T) {
+	data := `{"update_id":1,"message":{"message_id":2,"text":"hi","new_field":{"a":1}},"new_update_field":true}`
+
+	var update tgbotapi.Update
+	if err := json.Unmarshal([]byte(data), &update); err != nil {
+		t.Fatal(err)
+	}
+
+	if update.UpdateID != 1 || update.Message.Text != "hi" {
+		t.Fatal("typed fields were not decoded")
+	}
+
+	var fields struct {
+		NewUpdateField bool `json:"new_update_field"`
+	}
+	if err := json.Unmarshal(update.Raw(), &fields); err != nil || !fields.NewUpdateField {
+		t.Error("new_update_field missing from update.Raw()", err)
+	}
+
+	var message struct {
+		NewField struct{ A int } `json:"new_field"`
+	}
+	if err := json.Unmarshal(update.Message.Raw(), &message); err != nil || message.NewField.A != 1 {
+		t.Error("new_field missing from message.Raw()", err)
+	}
+}
+
+func TestMessageLink(t *testing.T) {
+	tests := []struct {
+		chat tgbotapi.Chat
+		link string
+	}{
+		{tgbotapi.Chat{ID: -1001234567890, Type: "channel", UserName: "channel"}, "https://t.me/channel/42"},
+		{tgbotapi.Chat{ID: -1001234567890, Type: "supergroup"}, "https://t.me/c/1234567890/42"},
+		{tgbotapi.Chat{ID: -123456, Type: "group"}, ""},
+		{tgbotapi.Chat{ID: 123456, Type: "private", UserName: "user"}, ""},
+	}
+
+	for _, test := range tests {
+		chat := test.chat
+		message := tgbotapi.Message{MessageID: 42, Chat: &chat}
+		if link := message.Link(); link != test.link {
+			t.Errorf("%s chat: got %q, expected %q", chat.Type, link, test.link)
+		}
+	}
+
+	forum := tgbotapi.Chat{ID: -1001234567890, Type: "supergroup"}
+	message := tgbotapi.Message{MessageID: 42, Chat: &forum, MessageThreadID: 7, IsTopicMessage: true}
+	if link := message.Link(); link != "https://t.me/c/1234567890/7/42" {
+		t.Errorf("got %q for a message in a topic", link)
+	}
+
+	forum.UserName = "forum"
+	if link := message.Link(); link != "https://t.me/forum/7/42" {
+		t.Errorf("got %q for a message in a public topic", link)
+	}
+
+	// Replies outside of topics have a thread ID too, but no topic.
+	message.IsTopicMessage = false
+	if link := message.Link(); link != "https://t.me/forum/42" {
+		t.Errorf("got %q for a reply thread", link)
+	}
+}
+
+func TestUserMentions(t *testing.T) {
+	user := tgbotapi.User{ID: 42, FirstName: "A <b>", LastName: "Smith-Jones."}
+
+	if mention := user.MentionHTML(); mention != `<a href="tg://user?id=42">A &lt;b&gt; Smith-Jones.</a>` {
+		t.Errorf("got HTML mention %s", mention)
+	}
+	if mention := user.MentionMarkdownV2(); mention != `[A <b\> Smith\-Jones\.](tg://user?id=42)` {
+		t.Errorf("got MarkdownV2 mention %s", mention)
+	}
+
+	name, entity := user.MentionEntity(6)
+	if name != "A <b> Smith-Jones." || entity.Type != "text_mention" ||
+		entity.Offset != 6 || entity.Length != 18 || entity.User.ID != 42 {
+		t.Errorf("got %q, %+v", name, entity)
+	}
+
+	user.UserName = "some_user"
+	if mention := user.MentionMarkdownV2(); mention != `@some\_user` {
+		t.Errorf("got MarkdownV2 mention %s", mention)
+	}
+}
+
+func TestPhotoSizes(t *testing.T) {
+	sizes := tgbotapi.PhotoSizes{
+		{FileID: "medium", Width: 320, Height: 240},
+		{FileID: "large", Width: 1280, Height: 960},
+		{FileID: "small", Width: 90, Height: 68},
+	}
+
+	if size := sizes.Largest(); size.FileID != "large" {
+		t.Errorf("largest is %s", size.FileID)
+	}
+	if size := sizes.Smallest(); size.FileID != "small" {
+		t.Errorf("smallest is %s", size.FileID)
+	}
+	if size := sizes.ClosestTo(400, 300); size.FileID != "medium" {
+		t.Errorf("closest to 400x300 is %s", size.FileID)
+	}
+	if tgbotapi.PhotoSizes(nil).Largest() != nil {
+		t.Error("expected no size")
+	}
+
+	photo := []tgbotapi.PhotoSize(sizes)
+	message := tgbotapi.Message{Photo: &photo}
+	if size := message.BestPhoto(); size.FileID != "large" {
+		t.Errorf("best photo is %s", size.FileID)
+	}
+}
+
+func TestTimeAccessors(t *testing.T) {
+	message := tgbotapi.Message{Date: 1500000000, EditDate: 1500000060}
+
+	if !message.EditTime().Equal(time.Unix(1500000060, 0)) {
+		t.Error("wrong edit time", message.EditTime())
+	}
+	if !message.ForwardTime().IsZero() {
+		t.Error("expected zero forward time for a message that was not forwarded")
+	}
+
+	info := tgbotapi.WebhookInfo{LastErrorDate: 1500000000}
+	if !info.LastErrorTime().Equal(time.Unix(1500000000, 0)) {
+		t.Error("wrong last error time", info.LastErrorTime())
+	}
+
+	if !(tgbotapi.ChatMember{}).UntilTime().IsZero() {
+		t.Error("expected zero until time")
+	}
+}
+
+func TestUpdateAccessors(t *testing.T) {
+	user := &tgbotapi.User{ID: 1}
+	chat := &tgbotapi.Chat{ID: 2}
+
+	callback := tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		From:    user,
+		Message: &tgbotapi.Message{MessageID: 3, Chat: chat, From: &tgbotapi.User{ID: 4}},
+	}}
+	if callback.SentFrom() != user || callback.FromChat() != chat || callback.EffectiveMessage().MessageID != 3 {
+		t.Error("wrong callback query accessors")
+	}
+
+	inline := tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: user}}
+	if inline.SentFrom() != user || inline.FromChat() != nil || inline.EffectiveMessage() != nil {
+		t.Error("wrong inline query accessors")
+	}
+
+	post := tgbotapi.Update{EditedChannelPost: &tgbotapi.Message{Chat: chat}}
+	if post.SentFrom() != nil || post.FromChat() != chat {
+		t.Error("wrong channel post accessors")
+	}
+}
+
+func TestUpdateKindAndString(t *testing.T) {
+	update := tgbotapi.Update{
+		UpdateID: 42,
+		Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 1, UserName: "bob"},
+			Text: "a message which is far too long to be logged in full",
+		},
+	}
+
+	if update.Kind() != tgbotapi.UpdateTypeMessage {
+		t.Errorf("expected message kind, got %s", update.Kind())
+	}
+
+	expected := `update 42 (message) chat=123 user=bob text="a message which is far too long …"`
+	if update.String() != expected {
+		t.Errorf("expected %s, got %s", expected, update.String())
+	}
+
+	if s := (tgbotapi.Update{UpdateID: 1}).String(); s != "update 1 (unknown)" {
+		t.Errorf("unexpected string for empty update: %s", s)
+	}
+
+	updates := map[string]tgbotapi.Update{"u": update}
+	if kind := updates["u"].Kind(); kind != tgbotapi.UpdateTypeMessage {
+		t.Errorf("expected message kind from map value, got %s", kind)
+	}
+}
+
+func TestMessageContentType(t *testing.T) {
+	photo := []tgbotapi.PhotoSize{{FileID: "photo"}}
+
+	tests := []struct {
+		message     tgbotapi.Message
+		contentType string
+	}{
+		{tgbotapi.Message{Text: "hi"}, "text"},
+		{tgbotapi.Message{Photo: &photo, Caption: "caption"}, "photo"},
+		{tgbotapi.Message{Voice: &tgbotapi.Voice{}}, "voice"},
+		{tgbotapi.Message{Venue: &tgbotapi.Venue{}, Location: &tgbotapi.Location{}}, "venue"},
+		{tgbotapi.Message{NewChatTitle: "title"}, "service"},
+		{tgbotapi.Message{}, "unknown"},
+	}
+
+	for _, test := range tests {
+		if contentType := test.message.ContentType(); contentType != test.contentType {
+			t.Errorf("got %s, expected %s", contentType, test.contentType)
+		}
+	}
+
+	message := tgbotapi.Message{Text: "fwd", ForwardDate: 1, ReplyToMessage: &tgbotapi.Message{}}
+	if !message.IsForwarded() || !message.IsReply() || message.IsServiceMessage() {
+		t.Error("wrong predicates for a forwarded reply")
+	}
+}
+
+func TestUnixTimeJSON(t *testing.T) {
+	var dates struct {
+		Date     tgbotapi.UnixTime `json:"date"`
+		EditDate tgbotapi.UnixTime `json:"edit_date"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"date":1500000000}`), &dates); err != nil {
+		t.Fatal(err)
+	}
+	if !dates.Date.Equal(time.Unix(1500000000, 0)) || !dates.EditDate.IsZero() {
+		t.Errorf("got %v and %v", dates.Date, dates.EditDate)
+	}
+
+	data, err := json.Marshal(dates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"date":1500000000,"edit_date":0}` {
+		t.Errorf("got %s", data)
+	}
+}
+
+var roundTripUpdates = []string{
+	`{"update_id":1,"message":{"message_id":2,"from":{"id":3,"is_bot":false,"first_name":"Bob","language_code":"en"},"chat":{"id":3,"type":"private","first_name":"Bob"},"date":1500000000,"text":"/start now","entities":[{"type":"bot_command","offset":0,"length":6}]}}`,
+	`{"update_id":2,"edited_channel_post":{"message_id":5,"chat":{"id":-100,"type":"channel","title":"News"},"date":1500000000,"edit_date":1500000100,"photo":[{"file_id":"a","width":90,"height":60}],"caption":"hi","has_protected_content":true}}`,
+	`{"update_id":3,"callback_query":{"id":"9","from":{"id":3,"is_bot":false,"first_name":"Bob"},"chat_instance":"42","data":"yes","message":{"message_id":2,"chat":{"id":3,"type":"private"},"date":1,"reply_to_message":{"message_id":1,"chat":{"id":3,"type":"private"},"date":0,"sticker":{"file_id":"s","width":512,"height":512}}}}}`,
+	`{"update_id":4,"poll":{"id":"p","question":"?","options":[{"text":"a","voter_count":0}],"total_voter_count":0,"is_closed":true,"is_anonymous":true,"type":"quiz","allows_multiple_answers":false,"correct_option_id":0}}`,
+	`{"update_id":5,"message_reaction":{"chat":{"id":3}}}`,
+	`{"update_id":6,"poll":{"id":"r","question":"?","options":[],"total_voter_count":0,"is_closed":false,"is_anonymous":false,"type":"regular","allows_multiple_answers":true}}`,
+	`{"update_id":7,"message":{"message_id":8,"chat":{"id":3,"type":"private"},"date":1,"game":{"title":"G","description":"d","photo":[{"file_id":"g","width":1,"height":1}]}}}`,
+}
+
+func TestUpdateJSONRoundTrip(t *testing.T) {
+	for _, payload := range roundTripUpdates {
+		var update tgbotapi.Update
+		if err := json.Unmarshal([]byte(payload), &update); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(update)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var expected, actual interface{}
+		json.Unmarshal([]byte(payload), &expected)
+		json.Unmarshal(data, &actual)
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %s, got %s", payload, data)
+		}
+	}
+}
+
+func FuzzUpdateJSONRoundTrip(f *testing.F) {
+	for _, payload := range roundTripUpdates {
+		f.Add([]byte(payload))
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var update tgbotapi.Update
+		if err := json.Unmarshal(payload, &update); err != nil {
+			return
+		}
+
+		first, err := json.Marshal(update)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var original, encoded map[string]interface{}
+		if json.Unmarshal(payload, &original) != nil || json.Unmarshal(first, &encoded) != nil {
+			t.Fatalf("encoded %s from %s", first, payload)
+		}
+
+		// Every field of the update itself is kept, supported or not.
+		for key, value := range original {
+			if value != nil && !hasKeyFold(encoded, key) {
+				t.Errorf("%s was lost encoding %s as %s", key, payload, first)
+			}
+		}
+		compareJSON(t, "update", original, encoded)
+
+		var decoded tgbotapi.Update
+		if err := json.Unmarshal(first, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(first, second) {
+			t.Errorf("encoding changed from %s to %s", first, second)
+		}
+	})
+}
+
+// compareJSON checks that decoded JSON was encoded with the same values.
+// Objects are compared by the keys in both, as unset fields may be added
+// and unsupported ones of nested objects are dropped. Nulls decode to
+// zero values, so they aren't compared, nor are objects with keys that
+// only differ in case, which decode to the same field.
+func compareJSON(t *testing.T, path string, original, encoded interface{}) {
+	t.Helper()
+
+	if original == nil {
+		return
+	}
+
+	switch o := original.(type) {
+	case map[string]interface{}:
+		e, ok := encoded.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, value := range o {
+			if hasFoldedKey(o, key) {
+				return
+			}
+			if v, ok := e[key]; ok {
+				compareJSON(t, path+"."+key, value, v)
+			}
+		}
+		return
+	case []interface{}:
+		e, ok := encoded.([]interface{})
+		if !ok || len(e) != len(o) {
+			break
+		}
+		for i := range o {
+			compareJSON(t, path+"["+strconv.Itoa(i)+"]", o[i], e[i])
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(original, encoded) {
+		t.Errorf("%s: expected %v, got %v", path, original, encoded)
+	}
+}
+
+// hasKeyFold returns if an object has a key equal to key apart from case.
+func hasKeyFold(object map[string]interface{}, key string) bool {
+	for other := range object {
+		if strings.EqualFold(other, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasFoldedKey returns if an object has another key equal to key apart
+// from case.
+func hasFoldedKey(object map[string]interface{}, key string) bool {
+	for other := range object {
+		if other != key && strings.EqualFold(other, key) {
+			return true
+		}
+	}
+
+	return false
+}
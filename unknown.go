@@ -0,0 +1,112 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownFields returns the fields of the JSON the update was decoded
+// from which this library has no field for, as paths such as
+// "message.reaction", or "message_reaction" for a kind of update it
+// doesn't support. They are sorted, and each is only listed once.
+//
+// It is nil if the update was not decoded from JSON.
+func (u *Update) UnknownFields() []string {
+	if u.raw == nil {
+		return nil
+	}
+
+	found := make(map[string]bool)
+	unknownFields(u.raw, reflect.TypeOf(u).Elem(), "", found)
+	if len(found) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(found))
+	for field := range found {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// unknownFields adds the paths of fields in raw which t doesn't decode
+// to found.
+func unknownFields(raw json.RawMessage, t reflect.Type, path string, found map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return
+		}
+
+		known := make(map[string]reflect.Type)
+		jsonFields(t, known)
+
+		for key, value := range fields {
+			fieldType, ok := known[strings.ToLower(key)]
+			if !ok {
+				found[path+key] = true
+				continue
+			}
+
+			unknownFields(value, fieldType, path+key+".", found)
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return
+		}
+
+		for _, item := range items {
+			unknownFields(item, t.Elem(), path, found)
+		}
+	}
+}
+
+// jsonFields adds the type of each field of a struct to fields by its
+// name in JSON, lowercased as encoding/json matches them regardless of
+// case.
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			jsonFields(field.Type, fields)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+}
+
+// reportUnknownFields calls OnUnknownFields if it is set and the update
+// has fields this library doesn't support.
+func (bot *BotAPI) reportUnknownFields(update *Update) {
+	if bot.OnUnknownFields == nil {
+		return
+	}
+
+	if fields := update.UnknownFields(); len(fields) > 0 {
+		bot.OnUnknownFields(update, fields)
+	}
+}
@@ -0,0 +1,67 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestUpdateUnknownFields(t *testing.T) {
+	var update tgbotapi.Update
+	err := json.Unmarshal([]byte(`{
+		"update_id": 1,
+		"message": {
+			"message_id": 2,
+			"date": 0,
+			"chat": {"id": 3, "type": "private", "emoji_status": "x"},
+			"entities": [{"type": "bold", "offset": 0, "length": 1}, {"type": "bold", "offset": 0, "length": 1, "extra": 1}],
+			"reply_to_message": {"message_id": 1, "date": 0, "quote": {}},
+			"Text": "case doesn't matter"
+		},
+		"message_reaction": {}
+	}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"message.chat.emoji_status",
+		"message.entities.extra",
+		"message.reply_to_message.quote",
+		"message_reaction",
+	}
+	if fields := update.UnknownFields(); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+
+	json.Unmarshal([]byte(`{"update_id": 1, "message": {"message_id": 2, "date": 0}}`), &update)
+	if fields := update.UnknownFields(); fields != nil {
+		t.Errorf("expected no unknown fields, got %v", fields)
+	}
+}
+
+func TestOnUnknownFields(t *testing.T) {
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		return nil
+	})
+
+	var reported []string
+	bot.OnUnknownFields = func(update *tgbotapi.Update, fields []string) {
+		reported = fields
+	}
+
+	handler, ch := bot.WebhookHandler()
+
+	body := `{"update_id": 1, "message_reaction": {"chat": {"id": 1}}}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	<-ch
+
+	if !reflect.DeepEqual(reported, []string{"message_reaction"}) {
+		t.Errorf("expected the unsupported update reported, got %v", reported)
+	}
+}
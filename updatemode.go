@@ -0,0 +1,341 @@
+package tgbotapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// UpdateMode is how an UpdateSwitcher gets updates.
+type UpdateMode int
+
+// Modes of an UpdateSwitcher.
+const (
+	// UpdateModeNone is before the switcher is started, once it is
+	// stopped, or after a switch failed part way.
+	UpdateModeNone UpdateMode = iota
+	UpdateModePolling
+	UpdateModeWebhook
+)
+
+func (m UpdateMode) String() string {
+	switch m {
+	case UpdateModePolling:
+		return "polling"
+	case UpdateModeWebhook:
+		return "webhook"
+	}
+
+	return "none"
+}
+
+// DefaultWebhookCheckInterval is how often an UpdateSwitcher checks if
+// its webhook is failing, unless CheckInterval is set.
+const DefaultWebhookCheckInterval = time.Minute
+
+// UpdateSwitcher gets updates by polling or from a webhook, and can switch
+// between them while the bot runs, such as to fall back to polling when
+// Telegram can't reach the webhook. Updates from either are sent to the
+// one channel returned by Updates, so handlers don't notice a switch.
+//
+// The switcher is the webhook's handler, and must be served at the path
+// of the webhook's URL for updates to be received from it.
+type UpdateSwitcher struct {
+	// DropPendingUpdates drops the updates waiting at Telegram when
+	// switching, instead of getting them in the new mode.
+	DropPendingUpdates bool
+	// FallbackAfter, if set, switches to polling once Telegram has failed
+	// to deliver updates to the webhook for this long.
+	FallbackAfter time.Duration
+	// CheckInterval is how often getWebhookInfo is checked for delivery
+	// errors while FallbackAfter is set, or DefaultWebhookCheckInterval
+	// if it is zero.
+	CheckInterval time.Duration
+
+	bot     *BotAPI
+	polling UpdateConfig
+	webhook WebhookConfig
+	handler http.Handler
+	ch      chan Update
+
+	// mu is held while switching.
+	mu      sync.Mutex
+	mode    UpdateMode
+	stop    context.CancelFunc // stops polling or checking the webhook
+	stopped chan struct{}      // closed once it has stopped
+
+	deliveredMu sync.Mutex
+	offset      int       // one more than the last update sent to ch
+	received    time.Time // when the webhook last received an update
+}
+
+// NewUpdateSwitcher creates an UpdateSwitcher which polls with polling
+// or sets webhook. Neither is used until UsePolling or UseWebhook is
+// called.
+func NewUpdateSwitcher(bot *BotAPI, polling UpdateConfig, webhook WebhookConfig) *UpdateSwitcher {
+	s := &UpdateSwitcher{
+		bot:     bot,
+		polling: polling,
+		webhook: webhook,
+		ch:      make(chan Update, bot.Buffer),
+	}
+
+	s.handler = bot.webhookHandler(func(update *Update) {
+		s.ch <- *update
+		s.delivered(update.UpdateID, true)
+	})
+
+	return s
+}
+
+// Updates returns the channel updates are sent to in either mode. It is
+// never closed.
+func (s *UpdateSwitcher) Updates() UpdatesChannel {
+	return s.ch
+}
+
+// ServeHTTP receives updates from the webhook. Updates still arriving
+// after switching to polling are sent to the channel as well.
+func (s *UpdateSwitcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Mode returns the mode in use.
+func (s *UpdateSwitcher) Mode() UpdateMode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.mode
+}
+
+// UsePolling deletes the webhook and starts polling, from after the last
+// update received from the webhook. It does nothing if already polling.
+func (s *UpdateSwitcher) UsePolling(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usePolling(ctx)
+}
+
+func (s *UpdateSwitcher) usePolling(ctx context.Context) error {
+	if s.mode == UpdateModePolling {
+		return nil
+	}
+
+	if err := s.deleteWebhook(ctx); err != nil {
+		return err
+	}
+	s.stopRunning()
+
+	config := s.polling
+	if offset := s.nextOffset(); offset > config.Offset {
+		config.Offset = offset
+	}
+
+	pollCtx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	err := s.bot.startPolling(pollCtx, config, func(update *Update) bool {
+		select {
+		case s.ch <- *update:
+			s.delivered(update.UpdateID, false)
+			return true
+		case <-pollCtx.Done():
+			return false
+		}
+	}, func() { close(stopped) })
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.mode, s.stop, s.stopped = UpdateModePolling, cancel, stopped
+
+	return nil
+}
+
+// UseWebhook stops polling and sets the webhook. Updates already sent to
+// the channel by polling are confirmed first, so Telegram doesn't send
+// them again to the webhook. It does nothing if the webhook is in use.
+func (s *UpdateSwitcher) UseWebhook(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mode == UpdateModeWebhook {
+		return nil
+	}
+
+	wasPolling := s.mode == UpdateModePolling
+	s.stopRunning()
+
+	if s.DropPendingUpdates {
+		if err := s.deleteWebhook(ctx); err != nil {
+			return err
+		}
+	} else if offset := s.nextOffset(); wasPolling && offset != 0 {
+		v := url.Values{}
+		v.Add("offset", strconv.Itoa(offset))
+		v.Add("limit", "1")
+
+		if _, err := s.bot.makeRequestContext(ctx, "getUpdates", v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.bot.SetWebhook(s.webhook); err != nil {
+		return err
+	}
+	s.mode = UpdateModeWebhook
+
+	if s.FallbackAfter > 0 {
+		s.startChecking(time.Now())
+	}
+
+	return nil
+}
+
+// startChecking starts checking if the webhook set at since is failing.
+func (s *UpdateSwitcher) startChecking(since time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		s.checkWebhook(ctx, since)
+	}()
+
+	s.stop, s.stopped = cancel, stopped
+}
+
+// Stop stops polling or checking the webhook. A webhook which is set is
+// left set, so Telegram keeps updates for it until the bot is started
+// again.
+func (s *UpdateSwitcher) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopRunning()
+}
+
+// stopRunning stops polling or checking the webhook, and waits for it to
+// return.
+func (s *UpdateSwitcher) stopRunning() {
+	if s.stop != nil {
+		s.stop()
+		<-s.stopped
+	}
+
+	s.mode, s.stop, s.stopped = UpdateModeNone, nil, nil
+}
+
+// deleteWebhook deletes the webhook, dropping pending updates if
+// DropPendingUpdates is set.
+func (s *UpdateSwitcher) deleteWebhook(ctx context.Context) error {
+	v := url.Values{}
+	if s.DropPendingUpdates {
+		v.Add("drop_pending_updates", "true")
+	}
+
+	_, err := s.bot.makeRequestContext(ctx, "deleteWebhook", v)
+	return err
+}
+
+// delivered notes an update was sent to the channel.
+func (s *UpdateSwitcher) delivered(updateID int, fromWebhook bool) {
+	s.deliveredMu.Lock()
+	defer s.deliveredMu.Unlock()
+
+	if updateID >= s.offset {
+		s.offset = updateID + 1
+	}
+	if fromWebhook {
+		s.received = time.Now()
+	}
+}
+
+func (s *UpdateSwitcher) nextOffset() int {
+	s.deliveredMu.Lock()
+	defer s.deliveredMu.Unlock()
+
+	return s.offset
+}
+
+func (s *UpdateSwitcher) lastReceived() time.Time {
+	s.deliveredMu.Lock()
+	defer s.deliveredMu.Unlock()
+
+	return s.received
+}
+
+// checkWebhook checks getWebhookInfo until ctx is done, falling back to
+// polling once updates have failed to be delivered for FallbackAfter.
+func (s *UpdateSwitcher) checkWebhook(ctx context.Context, since time.Time) {
+	interval := s.CheckInterval
+	if interval <= 0 {
+		interval = DefaultWebhookCheckInterval
+	}
+
+	var failingSince time.Time
+
+	for sleepContext(ctx, interval) == nil {
+		var info WebhookInfo
+		if _, err := s.bot.makeRequestInto(ctx, "getWebhookInfo", url.Values{}, &info); err != nil {
+			if ctx.Err() == nil {
+				log.Println(err)
+			}
+			continue
+		}
+
+		if !s.webhookFailing(info, since) {
+			failingSince = time.Time{}
+			continue
+		}
+
+		if failingSince.IsZero() {
+			failingSince = info.LastErrorTime()
+		}
+
+		if time.Since(failingSince) >= s.FallbackAfter {
+			log.Printf("Webhook failing since %s: %s, switching to polling", failingSince.Format(time.RFC3339), info.LastErrorMessage)
+
+			// Switching waits for this goroutine to return, so it is
+			// done from another.
+			go s.fallBack(ctx, since)
+			return
+		}
+	}
+}
+
+// webhookFailing returns if Telegram has updates it failed to deliver to
+// the webhook, with no update received since the last error.
+func (s *UpdateSwitcher) webhookFailing(info WebhookInfo, since time.Time) bool {
+	if info.PendingUpdateCount == 0 || info.LastErrorDate == 0 {
+		return false
+	}
+
+	// The error date is in seconds.
+	lastError := info.LastErrorTime().Add(time.Second)
+
+	return lastError.After(since) && lastError.After(s.lastReceived())
+}
+
+// fallBack switches to polling, unless ctx is done as the mode was
+// changed or the switcher stopped first. If it can't, the webhook is
+// checked again, so switching is retried.
+func (s *UpdateSwitcher) fallBack(ctx context.Context, since time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err := s.usePolling(context.Background()); err != nil {
+		log.Println(err)
+		s.startChecking(since)
+	}
+}
@@ -0,0 +1,165 @@
+package tgbotapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestUpdateSwitcher(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "getUpdates":
+			mu.Lock()
+			calls = append(calls, method+" offset="+r.FormValue("offset")+" limit="+r.FormValue("limit"))
+			mu.Unlock()
+
+			if r.FormValue("offset") == "" {
+				return []tgbotapi.Update{{UpdateID: 1}}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return []tgbotapi.Update{}
+		case "setWebhook", "deleteWebhook":
+			mu.Lock()
+			calls = append(calls, method+" url="+r.FormValue("url"))
+			mu.Unlock()
+			return true
+		}
+		return nil
+	})
+
+	webhookURL, _ := url.Parse("https://example.com/hook")
+	s := tgbotapi.NewUpdateSwitcher(bot, tgbotapi.NewUpdate(0), tgbotapi.WebhookConfig{URL: webhookURL})
+	defer s.Stop()
+
+	ctx := context.Background()
+
+	if err := s.UsePolling(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if update := receiveUpdate(t, s.Updates()); update.UpdateID != 1 {
+		t.Errorf("expected update 1 from polling, got %d", update.UpdateID)
+	}
+
+	if err := s.UseWebhook(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s.Mode() != tgbotapi.UpdateModeWebhook {
+		t.Errorf("expected webhook mode, got %s", s.Mode())
+	}
+
+	mu.Lock()
+	last := calls[len(calls)-2:]
+	mu.Unlock()
+	if last[0] != "getUpdates offset=2 limit=1" || last[1] != "setWebhook url=https://example.com/hook" {
+		t.Errorf("expected the update confirmed before setting the webhook, got %v", last)
+	}
+
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/hook", strings.NewReader(`{"update_id":5}`)))
+	if update := receiveUpdate(t, s.Updates()); update.UpdateID != 5 {
+		t.Errorf("expected update 5 from the webhook, got %d", update.UpdateID)
+	}
+
+	mu.Lock()
+	calls = nil
+	mu.Unlock()
+
+	if err := s.UsePolling(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for deadline := time.Now().Add(time.Second); ; {
+		mu.Lock()
+		n := len(calls)
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) < 2 || calls[0] != "deleteWebhook url=" || calls[1] != "getUpdates offset=6 limit=" {
+		t.Errorf("expected polling to resume after the webhook's update, got %v", calls)
+	}
+}
+
+func TestUpdateSwitcherFallback(t *testing.T) {
+	deleted := make(chan string, 1)
+
+	bot := newTestBot(t, func(method string, r *http.Request) interface{} {
+		switch method {
+		case "getWebhookInfo":
+			return tgbotapi.WebhookInfo{
+				URL:                "https://example.com/hook",
+				PendingUpdateCount: 3,
+				LastErrorDate:      int(time.Now().Unix()),
+				LastErrorMessage:   "Connection refused",
+			}
+		case "deleteWebhook":
+			select {
+			case deleted <- r.FormValue("drop_pending_updates"):
+			default:
+			}
+			return true
+		case "getUpdates":
+			time.Sleep(10 * time.Millisecond)
+			return []tgbotapi.Update{}
+		}
+		return true
+	})
+
+	webhookURL, _ := url.Parse("https://example.com/hook")
+	s := tgbotapi.NewUpdateSwitcher(bot, tgbotapi.NewUpdate(0), tgbotapi.WebhookConfig{URL: webhookURL})
+	s.FallbackAfter = time.Millisecond
+	s.CheckInterval = 10 * time.Millisecond
+	s.DropPendingUpdates = true
+	defer s.Stop()
+
+	if err := s.UseWebhook(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Setting the webhook drops pending updates first.
+	<-deleted
+
+	select {
+	case drop := <-deleted:
+		if drop != "true" {
+			t.Errorf("expected pending updates dropped, got %q", drop)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a fallback to polling")
+	}
+
+	for deadline := time.Now().Add(time.Second); s.Mode() != tgbotapi.UpdateModePolling; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected polling mode, got %s", s.Mode())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func receiveUpdate(t *testing.T, updates tgbotapi.UpdatesChannel) tgbotapi.Update {
+	t.Helper()
+
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("update was not delivered")
+	}
+
+	return tgbotapi.Update{}
+}
@@ -0,0 +1,124 @@
+package tgbotapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// sendCachedFile sends a file, reusing the file_id of an earlier upload
+// with the same contents from UploadCache if there is one. After a new
+// upload, the file_id Telegram returns is saved for next time.
+//
+// Files from a FileReader are read into memory to be hashed.
+func (bot *BotAPI) sendCachedFile(ctx context.Context, config Fileable) (Message, error) {
+	file, err := newRequestFileData(config.getFile())
+	if err != nil {
+		return Message{}, err
+	}
+
+	if !file.NeedsUpload() {
+		return bot.uploadAndSend(ctx, config.Method(), config)
+	}
+
+	if f, ok := file.(FileReader); ok {
+		data, err := ioutil.ReadAll(f.Reader)
+		if err != nil {
+			return Message{}, err
+		}
+
+		file = FileBytes{Name: f.Name, Bytes: data}
+	}
+
+	_, reader, err := file.UploadData()
+	if err != nil {
+		return Message{}, err
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(h, reader)
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+	if err != nil {
+		return Message{}, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	key := "file:" + config.name() + ":" + hash
+
+	fileID, ok, err := bot.UploadCache.Get(key)
+	if err != nil {
+		return Message{}, err
+	}
+
+	params, err := config.params()
+	if err != nil {
+		return Message{}, err
+	}
+
+	// On a hit the cached file_id is sent in place of the file, but a
+	// thumbnail is still uploaded.
+	sent := file
+	if ok {
+		sent = FileID(fileID)
+	}
+
+	files, err := fileableFiles(config, sent, params)
+	if err != nil {
+		return Message{}, err
+	}
+
+	resp, err := bot.uploadFilesInto(ctx, config.Method(), params, files, nil)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var message Message
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return Message{}, err
+	}
+
+	bot.debugLog(config.Method(), nil, message)
+
+	if ok {
+		return message, nil
+	}
+
+	if id := messageFileID(message); id != "" {
+		if err := bot.UploadCache.Set(key, []byte(id), 0); err != nil {
+			return message, err
+		}
+	}
+
+	return message, nil
+}
+
+// messageFileID returns the file_id of the file sent in a message, using
+// the largest size for photos.
+func messageFileID(message Message) string {
+	switch {
+	case message.BestPhoto() != nil:
+		return message.BestPhoto().FileID
+	case message.Audio != nil:
+		return message.Audio.FileID
+	case message.Animation != nil:
+		return message.Animation.FileID
+	case message.Document != nil:
+		return message.Document.FileID
+	case message.Sticker != nil:
+		return message.Sticker.FileID
+	case message.Video != nil:
+		return message.Video.FileID
+	case message.VideoNote != nil:
+		return message.VideoNote.FileID
+	case message.Voice != nil:
+		return message.Voice.FileID
+	}
+
+	return ""
+}
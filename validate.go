@@ -0,0 +1,178 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"os"
+
+	// Formats photos may be uploaded in, for reading their dimensions.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// UploadLimits are the limits uploaded files are validated against.
+type UploadLimits struct {
+	PhotoSize int64 // maximum size of photos, in bytes
+	FileSize  int64 // maximum size of all other files, in bytes
+}
+
+// DefaultUploadLimits are the limits of the Telegram Bot API servers.
+var DefaultUploadLimits = UploadLimits{
+	PhotoSize: 10 << 20,
+	FileSize:  50 << 20,
+}
+
+// LocalServerUploadLimits are the limits of a local Bot API server.
+var LocalServerUploadLimits = UploadLimits{
+	PhotoSize: 10 << 20,
+	FileSize:  2000 << 20,
+}
+
+// Limits on the dimensions of uploaded photos.
+const (
+	MaxPhotoDimensions  = 10000 // maximum of width plus height
+	MaxPhotoAspectRatio = 20
+)
+
+// FileTooLargeError happens when a file is larger than allowed.
+type FileTooLargeError struct {
+	Name  string
+	Size  int64
+	Limit int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("%s is %d bytes, over the limit of %d bytes", e.Name, e.Size, e.Limit)
+}
+
+// PhotoDimensionsError happens when a photo is too large or too narrow.
+type PhotoDimensionsError struct {
+	Width  int
+	Height int
+}
+
+func (e *PhotoDimensionsError) Error() string {
+	return fmt.Sprintf("photo dimensions %dx%d must total at most %d with a ratio of at most %d",
+		e.Width, e.Height, MaxPhotoDimensions, MaxPhotoAspectRatio)
+}
+
+// StickerFormatError happens when a sticker is not a WEBP, TGS or WEBM
+// file.
+type StickerFormatError struct {
+	Name string
+}
+
+func (e *StickerFormatError) Error() string {
+	return fmt.Sprintf("%s is not a WEBP, TGS or WEBM sticker", e.Name)
+}
+
+// ValidateUpload checks the file in a config against limits before it is
+// uploaded, returning a FileTooLargeError, PhotoDimensionsError or
+// StickerFormatError if it would be rejected.
+//
+// Only what can be learned without reading the file ahead of the upload
+// is checked: a FileReader is only checked by its Size, if known. Files
+// which are not uploaded are not checked.
+func ValidateUpload(config Fileable, limits UploadLimits) error {
+	if config.useExistingFile() {
+		return nil
+	}
+
+	file, err := newRequestFileData(config.getFile())
+	if err != nil {
+		return err
+	}
+	if !file.NeedsUpload() {
+		return nil
+	}
+
+	name, size, content, err := inspectFile(file)
+	if err != nil {
+		return err
+	}
+
+	limit := limits.FileSize
+	if config.name() == "photo" {
+		limit = limits.PhotoSize
+	}
+	if limit > 0 && size > limit {
+		return &FileTooLargeError{Name: name, Size: size, Limit: limit}
+	}
+
+	if content == nil {
+		return nil
+	}
+
+	switch config.name() {
+	case "photo":
+		return validatePhoto(content)
+	case "sticker":
+		return validateSticker(name, content)
+	}
+
+	return nil
+}
+
+// inspectFile returns the name and size of a file, and its contents if
+// they can be read without consuming the file. Size is -1 if unknown.
+func inspectFile(file RequestFileData) (string, int64, []byte, error) {
+	switch f := file.(type) {
+	case FileBytes:
+		return f.Name, int64(len(f.Bytes)), f.Bytes, nil
+	case FileReader:
+		return f.Name, f.Size, nil, nil
+	case FilePath:
+		info, err := os.Stat(string(f))
+		if err != nil {
+			return "", 0, nil, err
+		}
+
+		// Only the start of the file is needed to check its format.
+		handle, err := os.Open(string(f))
+		if err != nil {
+			return "", 0, nil, err
+		}
+		defer handle.Close()
+
+		header, err := ioutil.ReadAll(io.LimitReader(handle, 64<<10))
+		if err != nil {
+			return "", 0, nil, err
+		}
+
+		return string(f), info.Size(), header, nil
+	}
+
+	return "", -1, nil, nil
+}
+
+func validatePhoto(content []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		// Leave formats we can't read for Telegram to judge.
+		return nil
+	}
+
+	width, height := config.Width, config.Height
+	if width+height > MaxPhotoDimensions ||
+		width > height*MaxPhotoAspectRatio || height > width*MaxPhotoAspectRatio {
+		return &PhotoDimensionsError{Width: width, Height: height}
+	}
+
+	return nil
+}
+
+func validateSticker(name string, content []byte) error {
+	switch {
+	case len(content) >= 12 && string(content[:4]) == "RIFF" && string(content[8:12]) == "WEBP":
+	case len(content) >= 2 && content[0] == 0x1f && content[1] == 0x8b: // TGS is gzipped
+	case len(content) >= 4 && bytes.Equal(content[:4], []byte{0x1a, 0x45, 0xdf, 0xa3}):
+	default:
+		return &StickerFormatError{Name: name}
+	}
+
+	return nil
+}
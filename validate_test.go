@@ -0,0 +1,43 @@
+package tgbotapi_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestValidateUpload(t *testing.T) {
+	var wide bytes.Buffer
+	png.Encode(&wide, image.NewGray(image.Rect(0, 0, 2100, 100)))
+
+	limits := tgbotapi.DefaultUploadLimits
+
+	tests := []struct {
+		config tgbotapi.Fileable
+		err    string
+	}{
+		{tgbotapi.NewPhoto(ChatID, tgbotapi.FilePath("tests/image.jpg")), ""},
+		{tgbotapi.NewPhoto(ChatID, tgbotapi.FileBytes{Name: "wide.png", Bytes: wide.Bytes()}), "*tgbotapi.PhotoDimensionsError"},
+		{tgbotapi.NewDocument(ChatID, tgbotapi.FileReader{Name: "big.zip", Reader: strings.NewReader(""), Size: 60 << 20}), "*tgbotapi.FileTooLargeError"},
+		{tgbotapi.NewSticker(ChatID, tgbotapi.FileBytes{Name: "sticker.jpg", Bytes: []byte("not a sticker")}), "*tgbotapi.StickerFormatError"},
+		{tgbotapi.NewSticker(ChatID, tgbotapi.FileID(ExistingStickerFileID)), ""},
+	}
+
+	for _, test := range tests {
+		err := tgbotapi.ValidateUpload(test.config, limits)
+
+		got := ""
+		if err != nil {
+			got = fmt.Sprintf("%T", err)
+		}
+
+		if got != test.err {
+			t.Errorf("%T: got %s, expected %s", test.config, got, test.err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package tgbotapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultWebAppInitDataMaxAge is how old Web App init data accepted by
+// ValidateWebAppInitData may be.
+const DefaultWebAppInitDataMaxAge = 24 * time.Hour
+
+// WebAppUser is a user in Web App init data.
+type WebAppUser struct {
+	ID              int64  `json:"id"`
+	IsBot           bool   `json:"is_bot,omitempty"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name,omitempty"`
+	UserName        string `json:"username,omitempty"`
+	LanguageCode    string `json:"language_code,omitempty"`
+	IsPremium       bool   `json:"is_premium,omitempty"`
+	AllowsWriteToPM bool   `json:"allows_write_to_pm,omitempty"`
+	PhotoURL        string `json:"photo_url,omitempty"`
+}
+
+// WebAppChat is a chat in Web App init data.
+type WebAppChat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	UserName string `json:"username,omitempty"`
+	PhotoURL string `json:"photo_url,omitempty"`
+}
+
+// WebAppInitData is the data a Web App is opened with, which it should
+// send to its backend to be validated.
+type WebAppInitData struct {
+	// QueryID is used to answer the Web App query, if it was opened
+	// from a keyboard button.
+	QueryID string
+	// User is the user who opened the Web App.
+	User *WebAppUser
+	// Receiver is the other user in a private chat the Web App was
+	// opened from an attachment menu in.
+	Receiver *WebAppUser
+	// Chat is the chat the Web App was opened from an attachment menu
+	// in, if it is a group or channel.
+	Chat         *WebAppChat
+	ChatType     string
+	ChatInstance string
+	StartParam   string
+	// CanSendAfter is how many seconds after opening a message may be
+	// sent with answerWebAppQuery.
+	CanSendAfter int
+	AuthDate     int64
+	Hash         string
+}
+
+// AuthTime returns when the Web App was opened.
+func (data WebAppInitData) AuthTime() time.Time {
+	return time.Unix(data.AuthDate, 0)
+}
+
+// ValidateWebAppInitData checks that the init data a Web App was opened
+// with, as sent by it to the backend, was signed for the bot with token
+// within DefaultWebAppInitDataMaxAge, and parses it.
+//
+// It returns an error with ErrInvalidHash or ErrAuthExpired if the data
+// can't be trusted.
+func ValidateWebAppInitData(initData, botToken string) (WebAppInitData, error) {
+	return ValidateWebAppInitDataMaxAge(initData, botToken, DefaultWebAppInitDataMaxAge)
+}
+
+// ValidateWebAppInitDataMaxAge is ValidateWebAppInitData accepting data
+// up to maxAge old, or of any age if it is zero.
+func ValidateWebAppInitDataMaxAge(initData, botToken string, maxAge time.Duration) (WebAppInitData, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return WebAppInitData{}, err
+	}
+
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(botToken))
+	if !checkDataHash(values, mac.Sum(nil)) {
+		return WebAppInitData{}, errors.New(ErrInvalidHash)
+	}
+
+	authDate, err := checkAuthDate(values, maxAge)
+	if err != nil {
+		return WebAppInitData{}, err
+	}
+
+	data := WebAppInitData{
+		QueryID:      values.Get("query_id"),
+		ChatType:     values.Get("chat_type"),
+		ChatInstance: values.Get("chat_instance"),
+		StartParam:   values.Get("start_param"),
+		AuthDate:     authDate,
+		Hash:         values.Get("hash"),
+	}
+
+	if canSendAfter := values.Get("can_send_after"); canSendAfter != "" {
+		if data.CanSendAfter, err = strconv.Atoi(canSendAfter); err != nil {
+			return WebAppInitData{}, err
+		}
+	}
+
+	fields := []struct {
+		key   string
+		value interface{}
+	}{
+		{"user", &data.User},
+		{"receiver", &data.Receiver},
+		{"chat", &data.Chat},
+	}
+	for _, field := range fields {
+		if raw := values.Get(field.key); raw != "" {
+			if err := json.Unmarshal([]byte(raw), field.value); err != nil {
+				return WebAppInitData{}, err
+			}
+		}
+	}
+
+	return data, nil
+}
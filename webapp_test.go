@@ -0,0 +1,70 @@
+package tgbotapi_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// signWebAppInitData signs values as Telegram signs Web App init data.
+func signWebAppInitData(values url.Values, token string) string {
+	var lines []string
+	for key := range values {
+		lines = append(lines, key+"="+values.Get(key))
+	}
+	sort.Strings(lines)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(token))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+
+	return values.Encode()
+}
+
+func TestValidateWebAppInitData(t *testing.T) {
+	values := url.Values{
+		"query_id":       {"AAH"},
+		"user":           {`{"id":42,"first_name":"Ann","username":"ann","is_premium":true}`},
+		"chat":           {`{"id":-100,"type":"supergroup","title":"Group"}`},
+		"can_send_after": {"5"},
+		"auth_date":      {strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	initData := signWebAppInitData(values, "token")
+
+	data, err := tgbotapi.ValidateWebAppInitData(initData, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.QueryID != "AAH" || data.User == nil || data.User.ID != 42 || !data.User.IsPremium {
+		t.Errorf("got %+v", data)
+	}
+	if data.Chat == nil || data.Chat.Title != "Group" || data.Receiver != nil || data.CanSendAfter != 5 {
+		t.Errorf("got %+v", data)
+	}
+
+	if _, err := tgbotapi.ValidateWebAppInitData(initData, "other token"); err == nil || err.Error() != tgbotapi.ErrInvalidHash {
+		t.Errorf("expected invalid hash, got %v", err)
+	}
+
+	values.Set("auth_date", strconv.FormatInt(time.Now().Add(-48*time.Hour).Unix(), 10))
+	values.Del("hash")
+	initData = signWebAppInitData(values, "token")
+
+	if _, err := tgbotapi.ValidateWebAppInitData(initData, "token"); err == nil || err.Error() != tgbotapi.ErrAuthExpired {
+		t.Errorf("expected expired data, got %v", err)
+	}
+	if _, err := tgbotapi.ValidateWebAppInitDataMaxAge(initData, "token", 0); err != nil {
+		t.Errorf("data of any age was refused: %v", err)
+	}
+}